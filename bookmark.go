@@ -167,7 +167,11 @@ func (u *Updater) getNextBookmarkID() (int, error) {
 	if err != nil {
 		return 0, fmt.Errorf("read document: %w", err)
 	}
+	return nextBookmarkID(raw), nil
+}
 
+// nextBookmarkID returns one more than the highest bookmark ID in raw
+func nextBookmarkID(raw []byte) int {
 	// Find all bookmark IDs using the pattern from constants
 	matches := bookmarkIDPattern.FindAllStringSubmatch(string(raw), -1)
 
@@ -181,7 +185,7 @@ func (u *Updater) getNextBookmarkID() (int, error) {
 		}
 	}
 
-	return maxID + 1, nil
+	return maxID + 1
 }
 
 // validateBookmarkName validates a bookmark name according to Word specifications
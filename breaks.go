@@ -38,6 +38,35 @@ func (u *Updater) InsertPageBreak(opts BreakOptions) error {
 	return nil
 }
 
+// InsertColumnBreak inserts a column break into the document. Text after
+// the break continues at the top of the next column of a multi-column
+// section (or on the next page in a single-column section).
+func (u *Updater) InsertColumnBreak(opts BreakOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	// Read document.xml
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	// Insert column break at the specified position
+	updated, err := insertBreakAtPosition(raw, generateColumnBreakXML(), opts)
+	if err != nil {
+		return fmt.Errorf("insert column break: %w", err)
+	}
+
+	// Write updated document
+	if err := os.WriteFile(docPath, updated, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+
+	return nil
+}
+
 // InsertSectionBreak inserts a section break into the document
 func (u *Updater) InsertSectionBreak(opts BreakOptions) error {
 	if u == nil {
@@ -53,6 +82,11 @@ func (u *Updater) InsertSectionBreak(opts BreakOptions) error {
 	if err := validateSectionBreakType(opts.SectionType); err != nil {
 		return fmt.Errorf("invalid section break type: %w", err)
 	}
+	if opts.PageLayout != nil {
+		if err := validatePageLayoutOptions(*opts.PageLayout); err != nil {
+			return err
+		}
+	}
 
 	// Generate section break XML with optional page layout
 	sectionBreakXML := generateSectionBreakXML(opts.SectionType, opts.PageLayout)
@@ -85,6 +119,11 @@ func generatePageBreakXML() []byte {
 	return []byte(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
 }
 
+// generateColumnBreakXML creates the XML for a column break
+func generateColumnBreakXML() []byte {
+	return []byte(`<w:p><w:r><w:br w:type="column"/></w:r></w:p>`)
+}
+
 // generateSectionBreakXML creates the XML for a section break
 // Section breaks are more complex and define how the next section starts
 func generateSectionBreakXML(breakType SectionBreakType, pageLayout *PageLayoutOptions) []byte {
@@ -120,8 +159,20 @@ func generateSectionBreakXML(breakType SectionBreakType, pageLayout *PageLayoutO
 		pageLayout.MarginFooter,
 		pageLayout.MarginGutter))
 
-	// Default column count
-	buf.WriteString(`<w:cols w:space="720"/>`)
+	// Page borders
+	if pageLayout.Borders != nil {
+		buf.WriteString(generatePageBordersXMLWithPrefix("w", *pageLayout.Borders))
+	}
+
+	// Column layout (single column by default)
+	if pageLayout.Columns != nil {
+		buf.WriteString(generateSectionColumnsXML(*pageLayout.Columns))
+	} else {
+		buf.WriteString(`<w:cols w:space="720"/>`)
+	}
+
+	// Vertical alignment
+	buf.WriteString(generateVerticalAlignXMLWithPrefix("w", pageLayout.VerticalAlign))
 
 	buf.WriteString("</w:sectPr>")
 	buf.WriteString("</w:pPr>")
@@ -250,6 +301,10 @@ func (u *Updater) SetPageLayout(pageLayout PageLayoutOptions) error {
 		return fmt.Errorf("updater is nil")
 	}
 
+	if err := validatePageLayoutOptions(pageLayout); err != nil {
+		return err
+	}
+
 	// Read document.xml
 	docPath := filepath.Join(u.tempDir, "word", "document.xml")
 	raw, err := os.ReadFile(docPath)
@@ -347,8 +402,20 @@ func generateSectionPropertiesXMLWithPrefix(ns string, pageLayout PageLayoutOpti
 		ns, pageLayout.MarginFooter,
 		ns, pageLayout.MarginGutter)
 
-	// Default column settings
-	fmt.Fprintf(&buf, `<%s:cols %s:space="720"/>`, ns, ns)
+	// Page borders
+	if pageLayout.Borders != nil {
+		buf.WriteString(generatePageBordersXMLWithPrefix(ns, *pageLayout.Borders))
+	}
+
+	// Column settings (single column by default)
+	if pageLayout.Columns != nil {
+		buf.WriteString(generateSectionColumnsXMLWithPrefix(ns, *pageLayout.Columns))
+	} else {
+		fmt.Fprintf(&buf, `<%s:cols %s:space="720"/>`, ns, ns)
+	}
+
+	// Vertical alignment
+	buf.WriteString(generateVerticalAlignXMLWithPrefix(ns, pageLayout.VerticalAlign))
 
 	fmt.Fprintf(&buf, "</%s:sectPr>", ns)
 
@@ -20,11 +20,37 @@ const (
 	// Although both column and bar charts emit a <c:barChart> element in OpenXML,
 	// they are kept as distinct constants so callers do not need to set
 	// BarChartOptions.Direction manually.
-	ChartKindBar     ChartKind = "bar"
-	ChartKindLine    ChartKind = "lineChart"    // Line chart
-	ChartKindPie     ChartKind = "pieChart"     // Pie chart
-	ChartKindArea    ChartKind = "areaChart"    // Area chart
-	ChartKindScatter ChartKind = "scatterChart" // Scatter chart (XY chart)
+	ChartKindBar      ChartKind = "bar"
+	ChartKindLine     ChartKind = "lineChart"     // Line chart
+	ChartKindPie      ChartKind = "pieChart"      // Pie chart
+	ChartKindArea     ChartKind = "areaChart"     // Area chart
+	ChartKindScatter  ChartKind = "scatterChart"  // Scatter chart (XY chart)
+	ChartKindDoughnut ChartKind = "doughnutChart" // Doughnut chart
+	ChartKindRadar    ChartKind = "radarChart"    // Radar (spider) chart
+	ChartKindBubble   ChartKind = "bubbleChart"   // Bubble chart (XY chart with sizes)
+	// ChartKindStock creates a high-low-close chart from three series (high,
+	// low, close) or an open-high-low-close chart with up/down bars from four
+	// series (open, high, low, close).
+	ChartKindStock ChartKind = "stockChart"
+
+	// 3-D variants, rotated according to ChartOptions.View3D
+	ChartKindColumn3D ChartKind = "column3D"    // 3-D column chart
+	ChartKindBar3D    ChartKind = "bar3D"       // 3-D bar chart
+	ChartKindLine3D   ChartKind = "line3DChart" // 3-D line chart
+	ChartKindPie3D    ChartKind = "pie3DChart"  // 3-D pie chart
+	ChartKindArea3D   ChartKind = "area3DChart" // 3-D area chart
+
+	// Office 2016 chartex kinds, written as cx:chartSpace parts with a text
+	// fallback for older versions of Word. They are numbered separately
+	// (chartExN.xml) and are not seen by GetChartCount, GetChartData,
+	// GetChartOptions or UpdateChart.
+	ChartKindWaterfall  ChartKind = "waterfall"  // Waterfall (bridge) chart
+	ChartKindHistogram  ChartKind = "histogram"  // Histogram of the values of one series
+	ChartKindPareto     ChartKind = "pareto"     // Sorted columns with a cumulative percentage line
+	ChartKindBoxWhisker ChartKind = "boxWhisker" // Box & whisker chart
+	ChartKindTreemap    ChartKind = "treemap"    // Treemap of hierarchical categories
+	ChartKindSunburst   ChartKind = "sunburst"   // Sunburst of hierarchical categories
+	ChartKindFunnel     ChartKind = "funnel"     // Funnel chart
 )
 
 // ChartOptions defines comprehensive options for chart creation
@@ -70,14 +96,50 @@ type ChartOptions struct {
 	// Chart-level rendering properties (nil = library defaults)
 	Properties *ChartProperties
 
+	// Secondary axes of combo charts, used by series with SecondaryAxis set
+	// (nil = value axis on the right, hidden category axis)
+	SecondaryValueAxis    *AxisOptions
+	SecondaryCategoryAxis *AxisOptions
+
 	// Bar/column-specific options (nil = clustered column defaults)
 	BarChartOptions *BarChartOptions
 
 	// Scatter chart-specific options (nil = marker defaults)
 	ScatterChartOptions *ScatterChartOptions
+
+	// Doughnut chart-specific options (nil = 50% hole, first slice at 0°)
+	DoughnutChartOptions *DoughnutChartOptions
+
+	// Radar chart-specific options (nil = standard radar)
+	RadarChartOptions *RadarChartOptions
+
+	// Bubble chart-specific options (nil = 100% bubble scale)
+	BubbleChartOptions *BubbleChartOptions
+
+	// Stock chart-specific options (nil = white up bars, black down bars)
+	StockChartOptions *StockChartOptions
+
+	// 3-D view for the 3-D chart kinds (nil = Word's default rotation)
+	View3D *View3DOptions
+
+	// Waterfall chart-specific options (nil = no subtotals, connector lines shown)
+	WaterfallChartOptions *WaterfallChartOptions
+
+	// Histogram and pareto binning (nil = automatic bins for histograms;
+	// pareto charts without it total each category)
+	HistogramChartOptions *HistogramChartOptions
+
+	// Box & whisker chart-specific options (nil = exclusive quartiles, mean markers and outliers)
+	BoxWhiskerChartOptions *BoxWhiskerChartOptions
+
+	// Treemap and sunburst parent category levels (nil = a single level)
+	HierarchyChartOptions *HierarchyChartOptions
 }
 
-// InsertChart creates a new chart and inserts it into the document
+// InsertChart creates a new chart and inserts it into the document.
+// The chart indexes of GetChartCount, GetChartData, GetChartOptions and
+// UpdateChart count only classic charts; chartex kinds such as
+// ChartKindWaterfall are left out of that numbering.
 func (u *Updater) InsertChart(opts ChartOptions) error {
 	if u == nil {
 		return fmt.Errorf("updater is nil")
@@ -91,6 +153,12 @@ func (u *Updater) InsertChart(opts ChartOptions) error {
 	// Apply defaults
 	opts = applyChartDefaults(opts)
 
+	// Waterfall, histogram, treemap and the other Office 2016 kinds are
+	// written as chartex parts
+	if isChartExKind(opts.ChartKind) {
+		return u.insertChartEx(opts)
+	}
+
 	// Find next available chart index
 	chartIndex := u.findNextChartIndex()
 
@@ -136,6 +204,9 @@ func (u *Updater) InsertChart(opts ChartOptions) error {
 
 // validateChartOptions validates chart creation options
 func validateChartOptions(opts ChartOptions) error {
+	if isChartExKind(opts.ChartKind) {
+		return validateChartExOptions(opts)
+	}
 	if len(opts.Categories) == 0 {
 		return fmt.Errorf("categories cannot be empty")
 	}
@@ -151,6 +222,9 @@ func validateChartOptions(opts ChartOptions) error {
 		if len(series.Values) != len(opts.Categories) {
 			return fmt.Errorf("series[%d] values length (%d) must match categories length (%d)", i, len(series.Values), len(opts.Categories))
 		}
+		if err := validateSeriesFormat(i, series, seriesChartKind(opts, series)); err != nil {
+			return err
+		}
 	}
 
 	// Validate axes if provided
@@ -164,6 +238,16 @@ func validateChartOptions(opts ChartOptions) error {
 			return err
 		}
 	}
+	if opts.SecondaryValueAxis != nil {
+		if err := validateAxisOptions("SecondaryValueAxis", opts.SecondaryValueAxis); err != nil {
+			return err
+		}
+	}
+	if opts.SecondaryCategoryAxis != nil {
+		if err := validateAxisOptions("SecondaryCategoryAxis", opts.SecondaryCategoryAxis); err != nil {
+			return err
+		}
+	}
 
 	// Validate bar chart options if provided
 	if opts.BarChartOptions != nil {
@@ -175,6 +259,16 @@ func validateChartOptions(opts ChartOptions) error {
 		}
 	}
 
+	// Validate options of the doughnut, radar, bubble, stock and 3-D kinds
+	if err := validateChartKindOptions(opts); err != nil {
+		return err
+	}
+
+	// Validate combo chart plot groups
+	if err := validateComboChart(opts); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -192,6 +286,11 @@ func validateAxisOptions(name string, axis *AxisOptions) error {
 	if axis.MajorUnit != nil && axis.MinorUnit != nil && *axis.MinorUnit >= *axis.MajorUnit {
 		return fmt.Errorf("%s: MinorUnit must be less than MajorUnit", name)
 	}
+	switch axis.Crosses {
+	case "", AxisCrossesAutoZero, AxisCrossesMin, AxisCrossesMax:
+	default:
+		return fmt.Errorf("%s: Crosses %q is not supported", name, axis.Crosses)
+	}
 	return nil
 }
 
@@ -254,13 +353,19 @@ func applyChartDefaults(opts ChartOptions) ChartOptions {
 	}
 	opts.Properties.PlotVisibleOnly = true // Always true
 
-	// Apply bar chart defaults if chart is bar/column type
-	if opts.ChartKind == ChartKindColumn || opts.ChartKind == ChartKindBar {
+	// Apply secondary axis defaults for combo charts
+	if hasSecondaryAxis(opts) {
+		opts.SecondaryValueAxis, opts.SecondaryCategoryAxis = applySecondaryAxisDefaults(opts.SecondaryValueAxis, opts.SecondaryCategoryAxis)
+	}
+
+	// Apply bar chart defaults if chart is bar/column type, or a combo
+	// chart has a bar/column plot group
+	if barKind := barPlotKind(opts); barKind != "" {
 		if opts.BarChartOptions == nil {
 			opts.BarChartOptions = &BarChartOptions{}
 		}
 		if opts.BarChartOptions.Direction == "" {
-			if opts.ChartKind == ChartKindColumn {
+			if barKind == ChartKindColumn || barKind == ChartKindColumn3D {
 				opts.BarChartOptions.Direction = BarDirectionColumn
 			} else {
 				opts.BarChartOptions.Direction = BarDirectionBar
@@ -274,6 +379,11 @@ func applyChartDefaults(opts ChartOptions) ChartOptions {
 		}
 	}
 
+	// Apply 3-D view defaults for the 3-D chart kinds
+	if is3DChartKind(opts.ChartKind) && opts.View3D == nil {
+		opts.View3D = defaultView3D(opts.ChartKind)
+	}
+
 	// Apply data label defaults if specified
 	if opts.DataLabels != nil {
 		if opts.DataLabels.Position == "" {
@@ -331,29 +441,20 @@ func generateChartXML(opts ChartOptions) []byte {
 	}
 
 	buf.WriteString(`<c:autoTitleDeleted val="0"/>`)
+
+	// 3-D rotation
+	if is3DChartKind(opts.ChartKind) {
+		buf.WriteString(generateView3DXML(opts.View3D))
+	}
+
 	buf.WriteString(`<c:plotArea>`)
 	buf.WriteString(`<c:layout/>`)
 
 	// Generate chart type specific content
-	switch opts.ChartKind {
-	case ChartKindColumn, ChartKindBar: // both emit <c:barChart> with different barDir
-		buf.WriteString(generateBarChartXML(opts))
-	case ChartKindLine:
-		buf.WriteString(generateLineChartXML(opts))
-	case ChartKindPie:
-		buf.WriteString(generatePieChartXML(opts))
-	case ChartKindArea:
-		buf.WriteString(generateAreaChartXML(opts))
-	case ChartKindScatter:
-		buf.WriteString(generateScatterChartXML(opts))
-	default:
-		buf.WriteString(generateBarChartXML(opts)) // Default to bar/column
-	}
-
-	// Axes (category and value for most chart types, except pie)
-	if opts.ChartKind != ChartKindPie {
-		buf.WriteString(generateCategoryAxisXML(opts.CategoryAxis))
-		buf.WriteString(generateValueAxisXML(opts.ValueAxis))
+	if isComboChart(opts) {
+		buf.WriteString(generateComboPlotXML(opts))
+	} else {
+		buf.WriteString(generateSinglePlotXML(opts))
 	}
 
 	buf.WriteString(`</c:plotArea>`)
@@ -379,8 +480,65 @@ func generateChartXML(opts ChartOptions) []byte {
 	return buf.Bytes()
 }
 
+// generateSinglePlotXML generates the chart group and axes of a chart with
+// a single plot group
+func generateSinglePlotXML(opts ChartOptions) string {
+	var buf bytes.Buffer
+
+	switch opts.ChartKind {
+	case ChartKindColumn, ChartKindBar: // both emit <c:barChart> with different barDir
+		buf.WriteString(generateBarChartXML(opts))
+	case ChartKindLine:
+		buf.WriteString(generateLineChartXML(opts))
+	case ChartKindPie:
+		buf.WriteString(generatePieChartXML(opts))
+	case ChartKindArea:
+		buf.WriteString(generateAreaChartXML(opts))
+	case ChartKindScatter:
+		buf.WriteString(generateScatterChartXML(opts))
+	case ChartKindDoughnut:
+		buf.WriteString(generateDoughnutChartXML(opts))
+	case ChartKindRadar:
+		buf.WriteString(generateRadarChartXML(opts))
+	case ChartKindBubble:
+		buf.WriteString(generateBubbleChartXML(opts))
+	case ChartKindStock:
+		buf.WriteString(generateStockChartXML(opts))
+	case ChartKindColumn3D, ChartKindBar3D:
+		buf.WriteString(generateBar3DChartXML(opts))
+	case ChartKindLine3D, ChartKindArea3D:
+		buf.WriteString(generateLineArea3DChartXML(opts))
+	case ChartKindPie3D:
+		buf.WriteString(generatePie3DChartXML(opts))
+	default:
+		buf.WriteString(generateBarChartXML(opts)) // Default to bar/column
+	}
+
+	// Axes (category and value for most chart types, none for pie and
+	// doughnut, two value axes for bubble)
+	switch {
+	case !chartKindHasAxes(opts.ChartKind):
+	case opts.ChartKind == ChartKindBubble:
+		buf.WriteString(generateValueAxisWithIDsXML(opts.CategoryAxis, 2071991400, 2071991240, "midCat"))
+		buf.WriteString(generateValueAxisWithIDsXML(opts.ValueAxis, 2071991240, 2071991400, "midCat"))
+	default:
+		buf.WriteString(generateCategoryAxisXML(opts.CategoryAxis))
+		buf.WriteString(generateValueAxisXML(opts.ValueAxis))
+		if hasSeriesAxis(opts) {
+			buf.WriteString(generateSeriesAxisXML())
+		}
+	}
+
+	return buf.String()
+}
+
 // generateBarChartXML generates bar/column chart XML with extended options
 func generateBarChartXML(opts ChartOptions) string {
+	return generateBarPlotXML(opts, singlePlotGroup(opts))
+}
+
+// generateBarPlotXML generates the barChart of one plot group
+func generateBarPlotXML(opts ChartOptions, group plotGroup) string {
 	var buf bytes.Buffer
 
 	buf.WriteString(`<c:barChart>`)
@@ -389,8 +547,9 @@ func generateBarChartXML(opts ChartOptions) string {
 	buf.WriteString(fmt.Sprintf(`<c:varyColors val="%d"/>`, boolToInt(opts.BarChartOptions.VaryColors)))
 
 	// Series
-	for i, series := range opts.Series {
-		buf.WriteString(generateSeriesXML(i, series, opts))
+	seriesOpts := group.seriesOptions(opts)
+	for _, i := range group.series {
+		buf.WriteString(generateSeriesXML(i, opts.Series[i], seriesOpts))
 	}
 
 	// Data labels (chart-level default)
@@ -402,8 +561,9 @@ func generateBarChartXML(opts ChartOptions) string {
 
 	buf.WriteString(fmt.Sprintf(`<c:gapWidth val="%d"/>`, opts.BarChartOptions.GapWidth))
 	buf.WriteString(fmt.Sprintf(`<c:overlap val="%d"/>`, opts.BarChartOptions.Overlap))
-	buf.WriteString(`<c:axId val="2071991400"/>`)
-	buf.WriteString(`<c:axId val="2071991240"/>`)
+	catAxID, valAxID := group.axisIDs()
+	buf.WriteString(fmt.Sprintf(`<c:axId val="%d"/>`, catAxID))
+	buf.WriteString(fmt.Sprintf(`<c:axId val="%d"/>`, valAxID))
 	buf.WriteString(`</c:barChart>`)
 
 	return buf.String()
@@ -411,6 +571,11 @@ func generateBarChartXML(opts ChartOptions) string {
 
 // generateLineChartXML generates line chart XML with extended options
 func generateLineChartXML(opts ChartOptions) string {
+	return generateLinePlotXML(opts, singlePlotGroup(opts))
+}
+
+// generateLinePlotXML generates the lineChart of one plot group
+func generateLinePlotXML(opts ChartOptions, group plotGroup) string {
 	var buf bytes.Buffer
 
 	buf.WriteString(`<c:lineChart>`)
@@ -418,8 +583,9 @@ func generateLineChartXML(opts ChartOptions) string {
 	buf.WriteString(`<c:varyColors val="0"/>`)
 
 	// Series
-	for i, series := range opts.Series {
-		buf.WriteString(generateSeriesXML(i, series, opts))
+	seriesOpts := group.seriesOptions(opts)
+	for _, i := range group.series {
+		buf.WriteString(generateSeriesXML(i, opts.Series[i], seriesOpts))
 	}
 
 	// Data labels
@@ -429,8 +595,9 @@ func generateLineChartXML(opts ChartOptions) string {
 		buf.WriteString(`<c:dLbls><c:showLegendKey val="0"/><c:showVal val="0"/><c:showCatName val="0"/><c:showSerName val="0"/><c:showPercent val="0"/><c:showBubbleSize val="0"/></c:dLbls>`)
 	}
 
-	buf.WriteString(`<c:axId val="2071991400"/>`)
-	buf.WriteString(`<c:axId val="2071991240"/>`)
+	catAxID, valAxID := group.axisIDs()
+	buf.WriteString(fmt.Sprintf(`<c:axId val="%d"/>`, catAxID))
+	buf.WriteString(fmt.Sprintf(`<c:axId val="%d"/>`, valAxID))
 	buf.WriteString(`</c:lineChart>`)
 
 	return buf.String()
@@ -463,6 +630,11 @@ func generatePieChartXML(opts ChartOptions) string {
 
 // generateAreaChartXML generates area chart XML with extended options
 func generateAreaChartXML(opts ChartOptions) string {
+	return generateAreaPlotXML(opts, singlePlotGroup(opts))
+}
+
+// generateAreaPlotXML generates the areaChart of one plot group
+func generateAreaPlotXML(opts ChartOptions, group plotGroup) string {
 	var buf bytes.Buffer
 
 	buf.WriteString(`<c:areaChart>`)
@@ -470,8 +642,9 @@ func generateAreaChartXML(opts ChartOptions) string {
 	buf.WriteString(`<c:varyColors val="0"/>`)
 
 	// Series
-	for i, series := range opts.Series {
-		buf.WriteString(generateSeriesXML(i, series, opts))
+	seriesOpts := group.seriesOptions(opts)
+	for _, i := range group.series {
+		buf.WriteString(generateSeriesXML(i, opts.Series[i], seriesOpts))
 	}
 
 	// Data labels
@@ -481,8 +654,9 @@ func generateAreaChartXML(opts ChartOptions) string {
 		buf.WriteString(`<c:dLbls><c:showLegendKey val="0"/><c:showVal val="0"/><c:showCatName val="0"/><c:showSerName val="0"/><c:showPercent val="0"/><c:showBubbleSize val="0"/></c:dLbls>`)
 	}
 
-	buf.WriteString(`<c:axId val="2071991400"/>`)
-	buf.WriteString(`<c:axId val="2071991240"/>`)
+	catAxID, valAxID := group.axisIDs()
+	buf.WriteString(fmt.Sprintf(`<c:axId val="%d"/>`, catAxID))
+	buf.WriteString(fmt.Sprintf(`<c:axId val="%d"/>`, valAxID))
 	buf.WriteString(`</c:areaChart>`)
 
 	return buf.String()
@@ -554,14 +728,22 @@ func generateScatterSeriesXML(index int, series SeriesOptions, opts ChartOptions
 	buf.WriteString(fmt.Sprintf(`<c:strCache><c:ptCount val="1"/><c:pt idx="0"><c:v>%s</c:v></c:pt></c:strCache></c:strRef></c:tx>`,
 		xmlEscape(series.Name)))
 
-	// Shape properties (color)
-	if series.Color != "" {
-		buf.WriteString(`<c:spPr>`)
-		color := normalizeHexColor(series.Color)
-		buf.WriteString(fmt.Sprintf(`<a:solidFill><a:srgbClr val="%s"/></a:solidFill>`, color))
-		buf.WriteString(`</c:spPr>`)
+	// Shape properties (color, fill and line)
+	buf.WriteString(generateShapePropertiesXML(series.Color, series.Fill, series.Line))
+
+	// Marker for scatter
+	scatterStyle := "marker"
+	if series.Marker != nil {
+		buf.WriteString(generateMarkerXML(series.Marker))
+	} else if !strings.Contains(scatterStyle, "line") || strings.Contains(scatterStyle, "Marker") {
+		buf.WriteString(`<c:marker><c:symbol val="circle"/></c:marker>`)
+	} else {
+		buf.WriteString(`<c:marker><c:symbol val="none"/></c:marker>`)
 	}
 
+	// Data points, data labels, trendlines and error bars
+	buf.WriteString(generateSeriesExtrasXML(series, ChartKindScatter))
+
 	// X values (instead of categories) - use XValues if provided, otherwise use category indices
 	// Column offset: +2 for series data (B,C,D... when no XValues, C,D,E... when XValues exist)
 	colOffset := 2
@@ -597,25 +779,12 @@ func generateScatterSeriesXML(index int, series SeriesOptions, opts ChartOptions
 	buf.WriteString(`</c:numCache></c:numRef></c:yVal>`)
 
 	// Smooth line for scatter
-	scatterStyle := "marker"
 	if scOpts := opts.ScatterChartOptions; scOpts != nil {
 		if strings.HasPrefix(scOpts.ScatterStyle, "smooth") {
 			buf.WriteString(`<c:smooth val="1"/>`)
 		}
 	}
 
-	// Marker for scatter
-	if !strings.Contains(scatterStyle, "line") || strings.Contains(scatterStyle, "Marker") {
-		buf.WriteString(`<c:marker><c:symbol val="circle"/></c:marker>`)
-	} else {
-		buf.WriteString(`<c:marker><c:symbol val="none"/></c:marker>`)
-	}
-
-	// Per-series data labels
-	if series.DataLabels != nil {
-		buf.WriteString(generateDataLabelsXML(series.DataLabels))
-	}
-
 	buf.WriteString(`</c:ser>`)
 
 	return buf.String()
@@ -740,9 +909,14 @@ func generateSheetXML(opts ChartOptions) []byte {
 <worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
   <sheetData>`)
 
-	// Determine if we need X values column (for scatter charts with XValues)
-	hasXValues := opts.ChartKind == ChartKindScatter && len(opts.Series) > 0 && len(opts.Series[0].XValues) > 0
-	seriesColOffset := 2 // Column B by default
+	// Determine if we need X values column (for scatter and bubble charts with XValues)
+	hasXValues := (opts.ChartKind == ChartKindScatter || opts.ChartKind == ChartKindBubble) &&
+		len(opts.Series) > 0 && len(opts.Series[0].XValues) > 0
+	// Treemap and sunburst parent category levels take the columns before
+	// the categories, outermost first
+	parentLevels := hierarchyParentCategories(opts)
+	categoryCol := len(parentLevels) + 1
+	seriesColOffset := categoryCol + 1 // Column B by default
 	if hasXValues {
 		seriesColOffset = 3 // Column C when column B is reserved for X values
 	}
@@ -757,6 +931,13 @@ func generateSheetXML(opts ChartOptions) []byte {
 		col := columnLetter(i + seriesColOffset)
 		buf.WriteString(fmt.Sprintf(`<c r="%s1" t="str"><v>%s</v></c>`, col, xmlEscape(series.Name)))
 	}
+	if opts.ChartKind == ChartKindBubble {
+		// Bubble sizes follow the value columns of all series
+		for i, series := range opts.Series {
+			col := columnLetter(bubbleSizeColumn(i, len(opts.Series), seriesColOffset))
+			buf.WriteString(fmt.Sprintf(`<c r="%s1" t="str"><v>%s</v></c>`, col, xmlEscape(bubbleSizeHeader(series.Name))))
+		}
+	}
 	buf.WriteString(`</row>`)
 
 	// Data rows
@@ -764,8 +945,11 @@ func generateSheetXML(opts ChartOptions) []byte {
 		rowNum := i + 2
 		buf.WriteString(fmt.Sprintf(`<row r="%d">`, rowNum))
 
-		// Category in column A
-		buf.WriteString(fmt.Sprintf(`<c r="A%d" t="str"><v>%s</v></c>`, rowNum, xmlEscape(category)))
+		// Parent categories, then the category in column A or after them
+		for level, parents := range parentLevels {
+			buf.WriteString(fmt.Sprintf(`<c r="%s%d" t="str"><v>%s</v></c>`, columnLetter(level+1), rowNum, xmlEscape(parents[i])))
+		}
+		buf.WriteString(fmt.Sprintf(`<c r="%s%d" t="str"><v>%s</v></c>`, columnLetter(categoryCol), rowNum, xmlEscape(category)))
 
 		// X values in column B (if applicable)
 		if hasXValues && len(opts.Series[0].XValues) > i {
@@ -779,6 +963,14 @@ func generateSheetXML(opts ChartOptions) []byte {
 				buf.WriteString(fmt.Sprintf(`<c r="%s%d"><v>%g</v></c>`, col, rowNum, opts.Series[j].Values[i]))
 			}
 		}
+		if opts.ChartKind == ChartKindBubble {
+			for j := range opts.Series {
+				col := columnLetter(bubbleSizeColumn(j, len(opts.Series), seriesColOffset))
+				if i < len(opts.Series[j].BubbleSizes) {
+					buf.WriteString(fmt.Sprintf(`<c r="%s%d"><v>%g</v></c>`, col, rowNum, opts.Series[j].BubbleSizes[i]))
+				}
+			}
+		}
 
 		buf.WriteString(`</row>`)
 	}
@@ -852,6 +1044,15 @@ func (u *Updater) insertChartDrawing(chartIndex int, relID string, opts ChartOpt
 		return fmt.Errorf("generate drawing xml: %w", err)
 	}
 
+	return u.insertChartContent(raw, drawingXML, opts)
+}
+
+// insertChartContent inserts the drawing paragraph of a chart, with its
+// caption, at the position given by the chart options
+func (u *Updater) insertChartContent(raw, drawingXML []byte, opts ChartOptions) error {
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	var err error
+
 	// Handle caption if specified
 	contentToInsert := drawingXML
 	if opts.Caption != nil {
@@ -1003,12 +1204,12 @@ func generateSeriesXML(index int, series SeriesOptions, opts ChartOptions) strin
 		xmlEscape(series.Name)))
 
 	// Shape properties (color, etc.)
-	if series.Color != "" || series.InvertIfNegative {
+	if opts.ChartKind == ChartKindStock {
+		buf.WriteString(stockSeriesShapeXML)
+	} else if hasShapeProperties(series.Color, series.Fill, series.Line) || series.InvertIfNegative {
 		buf.WriteString(`<c:spPr>`)
-		if series.Color != "" {
-			color := normalizeHexColor(series.Color)
-			buf.WriteString(fmt.Sprintf(`<a:solidFill><a:srgbClr val="%s"/></a:solidFill>`, color))
-		}
+		buf.WriteString(generateFillXML(series.Color, series.Fill))
+		buf.WriteString(generateLineXML(series.Line))
 		buf.WriteString(`</c:spPr>`)
 	}
 
@@ -1018,14 +1219,22 @@ func generateSeriesXML(index int, series SeriesOptions, opts ChartOptions) strin
 	}
 
 	// Line chart marker must be emitted before cat/val in c:ser child order.
-	if opts.ChartKind == ChartKindLine {
-		if series.ShowMarkers {
+	if opts.ChartKind == ChartKindStock {
+		buf.WriteString(stockSeriesMarkerXML(index, len(opts.Series)))
+	} else if chartKindHasMarkers(opts.ChartKind) {
+		if series.Marker != nil {
+			buf.WriteString(generateMarkerXML(series.Marker))
+		} else if series.ShowMarkers || (opts.ChartKind == ChartKindRadar && radarStyle(opts) == RadarStyleMarker) {
 			buf.WriteString(`<c:marker><c:symbol val="circle"/></c:marker>`)
 		} else {
 			buf.WriteString(`<c:marker><c:symbol val="none"/></c:marker>`)
 		}
 	}
 
+	// Data points, data labels (overriding the chart-level ones), trendlines
+	// and error bars
+	buf.WriteString(generateSeriesExtrasXML(series, opts.ChartKind))
+
 	// Categories
 	buf.WriteString(fmt.Sprintf(`<c:cat><c:strRef><c:f>Sheet1!$A$2:$A$%d</c:f>`, len(opts.Categories)+1))
 	buf.WriteString(fmt.Sprintf(`<c:strCache><c:ptCount val="%d"/>`, len(opts.Categories)))
@@ -1045,17 +1254,12 @@ func generateSeriesXML(index int, series SeriesOptions, opts ChartOptions) strin
 	buf.WriteString(`</c:numCache></c:numRef></c:val>`)
 
 	// Line chart specific: smooth and markers
-	if opts.ChartKind == ChartKindLine {
+	if opts.ChartKind == ChartKindLine || opts.ChartKind == ChartKindLine3D {
 		if series.Smooth {
 			buf.WriteString(`<c:smooth val="1"/>`)
 		}
 	}
 
-	// Per-series data labels (overrides chart-level)
-	if series.DataLabels != nil {
-		buf.WriteString(generateDataLabelsXML(series.DataLabels))
-	}
-
 	buf.WriteString(`</c:ser>`)
 
 	return buf.String()
@@ -1083,10 +1287,16 @@ func generateDataLabelsXML(labels *DataLabelOptions) string {
 
 // generateCategoryAxisXML generates category axis XML with extended options
 func generateCategoryAxisXML(axis *AxisOptions) string {
+	return generateCategoryAxisWithIDsXML(axis, 2071991400, 2071991240)
+}
+
+// generateCategoryAxisWithIDsXML generates a category axis with the given
+// axis ID and crossing axis ID. Combo charts add a secondary category axis.
+func generateCategoryAxisWithIDsXML(axis *AxisOptions, axID, crossAxID int) string {
 	var buf bytes.Buffer
 
 	buf.WriteString(`<c:catAx>`)
-	buf.WriteString(`<c:axId val="2071991400"/>`)
+	buf.WriteString(fmt.Sprintf(`<c:axId val="%d"/>`, axID))
 
 	// Scaling
 	buf.WriteString(`<c:scaling>`)
@@ -1117,12 +1327,12 @@ func generateCategoryAxisXML(axis *AxisOptions) string {
 	buf.WriteString(fmt.Sprintf(`<c:minorTickMark val="%s"/>`, axis.MinorTickMark))
 	buf.WriteString(fmt.Sprintf(`<c:tickLblPos val="%s"/>`, axis.TickLabelPos))
 
-	buf.WriteString(`<c:crossAx val="2071991240"/>`)
+	buf.WriteString(fmt.Sprintf(`<c:crossAx val="%d"/>`, crossAxID))
 
 	if axis.CrossesAt != nil {
 		buf.WriteString(fmt.Sprintf(`<c:crossesAt val="%g"/>`, *axis.CrossesAt))
 	} else {
-		buf.WriteString(`<c:crosses val="autoZero"/>`)
+		buf.WriteString(fmt.Sprintf(`<c:crosses val="%s"/>`, axisCrosses(axis)))
 	}
 
 	buf.WriteString(`<c:auto val="1"/>`)
@@ -1141,10 +1351,16 @@ func generateCategoryAxisXML(axis *AxisOptions) string {
 
 // generateValueAxisXML generates value axis XML with extended options
 func generateValueAxisXML(axis *AxisOptions) string {
+	return generateValueAxisWithIDsXML(axis, 2071991240, 2071991400, "between")
+}
+
+// generateValueAxisWithIDsXML generates a value axis with the given axis ID,
+// crossing axis ID and crossBetween value. Bubble charts use two value axes.
+func generateValueAxisWithIDsXML(axis *AxisOptions, axID, crossAxID int, crossBetween string) string {
 	var buf bytes.Buffer
 
 	buf.WriteString(`<c:valAx>`)
-	buf.WriteString(`<c:axId val="2071991240"/>`)
+	buf.WriteString(fmt.Sprintf(`<c:axId val="%d"/>`, axID))
 
 	// Scaling
 	buf.WriteString(`<c:scaling>`)
@@ -1183,15 +1399,15 @@ func generateValueAxisXML(axis *AxisOptions) string {
 	buf.WriteString(fmt.Sprintf(`<c:minorTickMark val="%s"/>`, axis.MinorTickMark))
 	buf.WriteString(fmt.Sprintf(`<c:tickLblPos val="%s"/>`, axis.TickLabelPos))
 
-	buf.WriteString(`<c:crossAx val="2071991400"/>`)
+	buf.WriteString(fmt.Sprintf(`<c:crossAx val="%d"/>`, crossAxID))
 
 	if axis.CrossesAt != nil {
 		buf.WriteString(fmt.Sprintf(`<c:crossesAt val="%g"/>`, *axis.CrossesAt))
 	} else {
-		buf.WriteString(`<c:crosses val="autoZero"/>`)
+		buf.WriteString(fmt.Sprintf(`<c:crosses val="%s"/>`, axisCrosses(axis)))
 	}
 
-	buf.WriteString(`<c:crossBetween val="between"/>`)
+	buf.WriteString(fmt.Sprintf(`<c:crossBetween val="%s"/>`, crossBetween))
 
 	// Minor gridlines
 	if axis.MinorGridlines {
@@ -1203,6 +1419,14 @@ func generateValueAxisXML(axis *AxisOptions) string {
 	return buf.String()
 }
 
+// axisCrosses returns where the axis crosses its perpendicular axis
+func axisCrosses(axis *AxisOptions) AxisCrosses {
+	if axis.Crosses == "" {
+		return AxisCrossesAutoZero
+	}
+	return axis.Crosses
+}
+
 // generateAxisTitleXML generates axis title XML
 func generateAxisTitleXML(title string, overlay bool) string {
 	var buf bytes.Buffer
@@ -1283,18 +1507,24 @@ func (u *Updater) addChartRelationship(chartIndex int) (string, error) {
 
 // addContentTypeOverride adds a content type override for a chart in [Content_Types].xml.
 func (u *Updater) addContentTypeOverride(chartIndex int) error {
+	chartPart := fmt.Sprintf("/word/charts/chart%d.xml", chartIndex)
+	return u.addPartContentTypeOverride(chartPart, ChartContentType)
+}
+
+// addPartContentTypeOverride adds a content type override for a part in
+// [Content_Types].xml unless one is already present.
+func (u *Updater) addPartContentTypeOverride(partName, contentType string) error {
 	contentTypesPath := filepath.Join(u.tempDir, "[Content_Types].xml")
 	raw, err := os.ReadFile(contentTypesPath)
 	if err != nil {
 		return fmt.Errorf("read content types: %w", err)
 	}
 
-	chartPart := fmt.Sprintf("/word/charts/chart%d.xml", chartIndex)
-	if bytes.Contains(raw, []byte(chartPart)) {
+	if bytes.Contains(raw, []byte(partName)) {
 		return nil // already present
 	}
 
-	insert := fmt.Sprintf("\n  <Override PartName=\"%s\" ContentType=\"%s\"/>\n", chartPart, ChartContentType)
+	insert := fmt.Sprintf("\n  <Override PartName=\"%s\" ContentType=\"%s\"/>\n", partName, contentType)
 	closer := []byte("</Types>")
 	pos := bytes.LastIndex(raw, closer)
 	if pos == -1 {
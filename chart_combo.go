@@ -0,0 +1,196 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// plotGroup is one chart group of the plot area: the series plotted as one
+// kind against either the primary or the secondary axes
+type plotGroup struct {
+	kind      ChartKind
+	secondary bool
+	series    []int // indexes into ChartOptions.Series
+}
+
+// singlePlotGroup returns the plot group holding every series of a chart
+// that is not a combo chart
+func singlePlotGroup(opts ChartOptions) plotGroup {
+	group := plotGroup{kind: opts.ChartKind}
+	for i := range opts.Series {
+		group.series = append(group.series, i)
+	}
+	return group
+}
+
+// seriesOptions returns the chart options used to generate the group's
+// series, whose markers and smoothing depend on the group kind
+func (g plotGroup) seriesOptions(opts ChartOptions) ChartOptions {
+	opts.ChartKind = g.kind
+	return opts
+}
+
+// axisIDs returns the IDs of the category and value axes of the group
+func (g plotGroup) axisIDs() (int, int) {
+	if g.secondary {
+		return 2071991700, 2071991800
+	}
+	return 2071991400, 2071991240
+}
+
+// seriesChartKind returns the plot group kind of a series
+func seriesChartKind(opts ChartOptions, series SeriesOptions) ChartKind {
+	if series.ChartKind != "" {
+		return series.ChartKind
+	}
+	if opts.ChartKind == "" {
+		return ChartKindColumn
+	}
+	return opts.ChartKind
+}
+
+// isComboChart reports whether the series span more than one plot group
+func isComboChart(opts ChartOptions) bool {
+	for _, series := range opts.Series {
+		if series.SecondaryAxis || seriesChartKind(opts, series) != seriesChartKind(opts, SeriesOptions{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSecondaryAxis reports whether any series is plotted on the secondary axes
+func hasSecondaryAxis(opts ChartOptions) bool {
+	for _, series := range opts.Series {
+		if series.SecondaryAxis {
+			return true
+		}
+	}
+	return false
+}
+
+// barPlotKind returns the bar/column kind plotted by the chart, if any
+func barPlotKind(opts ChartOptions) ChartKind {
+	if isBarChartKind(opts.ChartKind) {
+		return opts.ChartKind
+	}
+	for _, series := range opts.Series {
+		if isBarChartKind(series.ChartKind) {
+			return series.ChartKind
+		}
+	}
+	return ""
+}
+
+// chartPlotGroups groups the series of a combo chart by kind and axis, in
+// order of first appearance
+func chartPlotGroups(opts ChartOptions) []plotGroup {
+	var groups []plotGroup
+	for i, series := range opts.Series {
+		kind := seriesChartKind(opts, series)
+		found := false
+		for g := range groups {
+			if groups[g].kind == kind && groups[g].secondary == series.SecondaryAxis {
+				groups[g].series = append(groups[g].series, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, plotGroup{kind: kind, secondary: series.SecondaryAxis, series: []int{i}})
+		}
+	}
+	return groups
+}
+
+// validateComboChart validates the plot groups of a combo chart. Column,
+// bar, line and area groups can be combined.
+func validateComboChart(opts ChartOptions) error {
+	if !isComboChart(opts) {
+		return nil
+	}
+	primary := false
+	hasColumn, hasBar := false, false
+	for i, series := range opts.Series {
+		kind := seriesChartKind(opts, series)
+		switch kind {
+		case ChartKindColumn:
+			hasColumn = true
+		case ChartKindBar:
+			hasBar = true
+		case ChartKindLine, ChartKindArea:
+		default:
+			return fmt.Errorf("series[%d]: chart kind %q cannot be combined with other kinds", i, kind)
+		}
+		if !series.SecondaryAxis {
+			primary = true
+		}
+	}
+	if kind := seriesChartKind(opts, SeriesOptions{}); kind != ChartKindColumn && kind != ChartKindBar && kind != ChartKindLine && kind != ChartKindArea {
+		return fmt.Errorf("chart kind %q cannot be combined with other kinds", kind)
+	}
+	if hasColumn && hasBar {
+		return fmt.Errorf("column and bar series cannot be combined")
+	}
+	if !primary {
+		return fmt.Errorf("at least one series must be plotted on the primary axis")
+	}
+	return nil
+}
+
+// applySecondaryAxisDefaults sets default values for the secondary axes. By
+// default the value axis sits on the right without gridlines and the
+// category axis is hidden. The caller's axis options are copied, not changed.
+func applySecondaryAxisDefaults(valAxis, catAxis *AxisOptions) (*AxisOptions, *AxisOptions) {
+	defaulted := valAxis == nil
+	if valAxis == nil {
+		valAxis = &AxisOptions{}
+	} else {
+		copied := *valAxis
+		valAxis = &copied
+	}
+	if valAxis.Position == "" {
+		valAxis.Position = AxisPositionRight
+	}
+	if valAxis.Crosses == "" && valAxis.CrossesAt == nil {
+		valAxis.Crosses = AxisCrossesMax
+	}
+	valAxis = applyAxisDefaults(valAxis, false)
+	if defaulted {
+		valAxis.MajorGridlines = false
+	}
+
+	hidden := catAxis == nil
+	if catAxis != nil {
+		copied := *catAxis
+		catAxis = &copied
+	}
+	catAxis = applyAxisDefaults(catAxis, true)
+	catAxis.Visible = !hidden
+	return valAxis, catAxis
+}
+
+// generateComboPlotXML generates the chart groups and axes of a combo chart
+func generateComboPlotXML(opts ChartOptions) string {
+	var buf bytes.Buffer
+
+	for _, group := range chartPlotGroups(opts) {
+		switch group.kind {
+		case ChartKindLine:
+			buf.WriteString(generateLinePlotXML(opts, group))
+		case ChartKindArea:
+			buf.WriteString(generateAreaPlotXML(opts, group))
+		default:
+			buf.WriteString(generateBarPlotXML(opts, group))
+		}
+	}
+
+	buf.WriteString(generateCategoryAxisXML(opts.CategoryAxis))
+	buf.WriteString(generateValueAxisXML(opts.ValueAxis))
+	if hasSecondaryAxis(opts) {
+		buf.WriteString(generateCategoryAxisWithIDsXML(opts.SecondaryCategoryAxis, 2071991700, 2071991800))
+		buf.WriteString(generateValueAxisWithIDsXML(opts.SecondaryValueAxis, 2071991800, 2071991700, "between"))
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,129 @@
+package godocx
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInsertComboChart(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Results:</w:t></w:r></w:p>`))
+	minVal, maxVal := 0.0, 1.0
+	if err := u.InsertChart(ChartOptions{
+		Position:   PositionEnd,
+		ChartKind:  ChartKindColumn,
+		Title:      "Revenue and margin",
+		Categories: []string{"Q1", "Q2", "Q3"},
+		Series: []SeriesOptions{
+			{Name: "Revenue", Values: []float64{120, 150, 170}},
+			{Name: "Margin", Values: []float64{0.21, 0.24, 0.27}, ChartKind: ChartKindLine, SecondaryAxis: true, ShowMarkers: true},
+			{Name: "Costs", Values: []float64{95, 114, 124}},
+		},
+		SecondaryValueAxis: &AxisOptions{Title: "Margin", NumberFormat: "0%", Min: &minVal, Max: &maxVal},
+	}); err != nil {
+		t.Fatalf("InsertChart: %v", err)
+	}
+
+	chart := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+	checkWellFormedXML(t, chart)
+	bar := chart[strings.Index(chart, "<c:barChart>"):strings.Index(chart, "</c:barChart>")]
+	line := chart[strings.Index(chart, "<c:lineChart>"):strings.Index(chart, "</c:lineChart>")]
+	if !strings.Contains(bar, "Revenue") || !strings.Contains(bar, "Costs") || strings.Contains(bar, "Margin") {
+		t.Errorf("unexpected column group %s", bar)
+	}
+	for _, want := range []string{
+		`<c:idx val="1"/><c:order val="1"/><c:tx><c:strRef><c:f>Sheet1!$C$1</c:f>`,
+		`<c:marker><c:symbol val="circle"/></c:marker>`,
+		`<c:axId val="2071991700"/><c:axId val="2071991800"/>`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %s in line group %s", want, line)
+		}
+	}
+	if !strings.Contains(bar, `<c:idx val="2"/>`) || !strings.Contains(bar, `<c:f>Sheet1!$D$2:$D$4</c:f>`) {
+		t.Errorf("expected the third series to keep its index and workbook column, got %s", bar)
+	}
+	for _, want := range []string{
+		`<c:catAx><c:axId val="2071991700"/><c:scaling><c:orientation val="minMax"/></c:scaling><c:delete val="1"/>`,
+		`<c:valAx><c:axId val="2071991800"/><c:scaling><c:min val="0"/><c:max val="1"/><c:orientation val="minMax"/></c:scaling><c:delete val="0"/><c:axPos val="r"/>`,
+		`<c:numFmt formatCode="0%" sourceLinked="0"/>`,
+		`<c:crossAx val="2071991700"/><c:crosses val="max"/>`,
+	} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("expected %s in chart XML", want)
+		}
+	}
+	if strings.Count(chart, "<c:valAx>") != 2 || strings.Count(chart, "<c:catAx>") != 2 {
+		t.Errorf("expected two axis pairs, got %s", chart)
+	}
+
+	// Updating keeps each series in its group
+	if err := u.UpdateChart(1, ChartData{
+		Categories: []string{"Q1", "Q2", "Q3", "Q4"},
+		Series: []SeriesData{
+			{Name: "Revenue", Values: []float64{120, 150, 170, 190}},
+			{Name: "Margin", Values: []float64{0.21, 0.24, 0.27, 0.3}},
+			{Name: "Costs", Values: []float64{95, 114, 124, 133}},
+		},
+	}); err != nil {
+		t.Fatalf("UpdateChart: %v", err)
+	}
+	chart = readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+	checkWellFormedXML(t, chart)
+	line = chart[strings.Index(chart, "<c:lineChart>"):strings.Index(chart, "</c:lineChart>")]
+	if !strings.Contains(line, `<c:idx val="1"/>`) || !strings.Contains(line, `<c:v>0.3</c:v>`) || !strings.Contains(line, `<c:axId val="2071991800"/>`) {
+		t.Errorf("unexpected updated line group %s", line)
+	}
+	data, err := u.GetChartData(1)
+	if err != nil {
+		t.Fatalf("GetChartData: %v", err)
+	}
+	if len(data.Series) != 3 || data.Series[2].Name != "Costs" || data.Series[2].Values[3] != 133 {
+		t.Errorf("unexpected chart data %+v", data)
+	}
+}
+
+func TestInsertComboChart_Validation(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Results:</w:t></w:r></w:p>`))
+	for name, opts := range map[string]ChartOptions{
+		"pie":            {ChartKind: ChartKindPie, Series: []SeriesOptions{{Name: "A", Values: []float64{1}}, {Name: "B", Values: []float64{2}, ChartKind: ChartKindLine}}},
+		"scatter series": {Series: []SeriesOptions{{Name: "A", Values: []float64{1}}, {Name: "B", Values: []float64{2}, ChartKind: ChartKindScatter}}},
+		"column and bar": {Series: []SeriesOptions{{Name: "A", Values: []float64{1}}, {Name: "B", Values: []float64{2}, ChartKind: ChartKindBar}}},
+		"no primary":     {Series: []SeriesOptions{{Name: "A", Values: []float64{1}, SecondaryAxis: true}}},
+		"crosses":        {Series: []SeriesOptions{{Name: "A", Values: []float64{1}, SecondaryAxis: true}, {Name: "B", Values: []float64{2}}}, SecondaryValueAxis: &AxisOptions{Crosses: "middle"}},
+	} {
+		opts.Categories = []string{"x"}
+		if err := u.InsertChart(opts); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestInsertComboChart_KeepsCallerAxisOptions(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Results:</w:t></w:r></w:p>`))
+	valAxis := &AxisOptions{Title: "Margin"}
+	catAxis := &AxisOptions{Title: "Quarter"}
+	if err := u.InsertChart(ChartOptions{
+		Position:   PositionEnd,
+		ChartKind:  ChartKindColumn,
+		Categories: []string{"Q1", "Q2"},
+		Series: []SeriesOptions{
+			{Name: "Revenue", Values: []float64{120, 150}},
+			{Name: "Margin", Values: []float64{0.21, 0.24}, ChartKind: ChartKindLine, SecondaryAxis: true},
+		},
+		SecondaryValueAxis:    valAxis,
+		SecondaryCategoryAxis: catAxis,
+	}); err != nil {
+		t.Fatalf("InsertChart: %v", err)
+	}
+	if *valAxis != (AxisOptions{Title: "Margin"}) {
+		t.Errorf("secondary value axis options were changed: %+v", *valAxis)
+	}
+	if *catAxis != (AxisOptions{Title: "Quarter"}) {
+		t.Errorf("secondary category axis options were changed: %+v", *catAxis)
+	}
+	chart := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+	if !strings.Contains(chart, `<c:axPos val="r"/>`) || !strings.Contains(chart, `<c:crosses val="max"/>`) {
+		t.Errorf("expected secondary axis defaults in chart XML, got %s", chart)
+	}
+}
@@ -0,0 +1,651 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WaterfallChartOptions defines options specific to waterfall charts
+type WaterfallChartOptions struct {
+	Subtotals          []int // Zero-based indexes of the points drawn as totals from the axis
+	HideConnectorLines bool  // Hide the lines between consecutive columns (default: false)
+}
+
+// HistogramChartOptions defines the binning of histogram and pareto charts.
+// Set BinCount or BinWidth; when both are zero the bins are automatic.
+type HistogramChartOptions struct {
+	BinCount           int      // Number of bins (0 for automatic)
+	BinWidth           float64  // Width of each bin (0 for automatic)
+	Underflow          *float64 // Values at or below go to one underflow bin (nil for none)
+	Overflow           *float64 // Values above go to one overflow bin (nil for none)
+	IntervalClosedLeft bool     // Bins include their lower bound, [a, b), instead of their upper bound (default: false)
+}
+
+// QuartileMethod defines how box & whisker charts compute quartiles
+type QuartileMethod string
+
+const (
+	QuartileExclusive QuartileMethod = "exclusive" // Median excluded from the quartiles (default)
+	QuartileInclusive QuartileMethod = "inclusive" // Median included in the quartiles
+)
+
+// BoxWhiskerChartOptions defines options specific to box & whisker charts
+type BoxWhiskerChartOptions struct {
+	QuartileMethod  QuartileMethod // Quartile calculation (default: exclusive)
+	ShowMeanLine    bool           // Connect the means of the boxes of a series (default: false)
+	HideMeanMarkers bool           // Hide the mean marker of each box (default: false)
+	ShowInnerPoints bool           // Show the points between the whiskers (default: false)
+	HideOutliers    bool           // Hide the points beyond the whiskers (default: false)
+}
+
+// ParentLabelLayout defines how treemap charts label parent categories
+type ParentLabelLayout string
+
+const (
+	ParentLabelOverlapping ParentLabelLayout = "overlapping" // Drawn over their children (default)
+	ParentLabelBanner      ParentLabelLayout = "banner"      // In a banner above their children
+	ParentLabelNone        ParentLabelLayout = "none"        // Not shown
+)
+
+// HierarchyChartOptions defines the parent category levels of treemap and
+// sunburst charts. ChartOptions.Categories holds the innermost level.
+type HierarchyChartOptions struct {
+	ParentCategories [][]string        // Parent labels per level, outermost first; each level holds one label per category
+	ParentLabels     ParentLabelLayout // Treemap parent label layout (default: overlapping)
+}
+
+// isChartExKind reports whether the kind is written as a chartex part
+func isChartExKind(kind ChartKind) bool {
+	switch kind {
+	case ChartKindWaterfall, ChartKindHistogram, ChartKindPareto, ChartKindBoxWhisker,
+		ChartKindTreemap, ChartKindSunburst, ChartKindFunnel:
+		return true
+	}
+	return false
+}
+
+// isHierarchyChartKind reports whether the kind plots hierarchical categories
+func isHierarchyChartKind(kind ChartKind) bool {
+	return kind == ChartKindTreemap || kind == ChartKindSunburst
+}
+
+// isBinnedChart reports whether the chart bins its values instead of plotting
+// one point per category
+func isBinnedChart(opts ChartOptions) bool {
+	return opts.ChartKind == ChartKindHistogram || (opts.ChartKind == ChartKindPareto && opts.HistogramChartOptions != nil)
+}
+
+// chartExRequiresCategories reports whether the chart kind needs one category
+// per value. Binned charts take values only and box & whisker charts group
+// their values by category when categories are given.
+func chartExRequiresCategories(opts ChartOptions) bool {
+	return !isBinnedChart(opts) && opts.ChartKind != ChartKindBoxWhisker
+}
+
+// hierarchyParentCategories returns the parent category levels of a treemap
+// or sunburst chart, outermost first
+func hierarchyParentCategories(opts ChartOptions) [][]string {
+	if !isHierarchyChartKind(opts.ChartKind) || opts.HierarchyChartOptions == nil {
+		return nil
+	}
+	return opts.HierarchyChartOptions.ParentCategories
+}
+
+// validateChartExOptions validates the options of a chartex chart
+func validateChartExOptions(opts ChartOptions) error {
+	kind := opts.ChartKind
+	if len(opts.Series) == 0 {
+		return fmt.Errorf("at least one series is required")
+	}
+	if len(opts.Series) > 1 && kind != ChartKindBoxWhisker && kind != ChartKindFunnel {
+		return fmt.Errorf("%s charts take a single series", kind)
+	}
+	if chartExRequiresCategories(opts) && len(opts.Categories) == 0 {
+		return fmt.Errorf("categories cannot be empty")
+	}
+
+	for i, series := range opts.Series {
+		if strings.TrimSpace(series.Name) == "" {
+			return fmt.Errorf("series[%d] name cannot be empty", i)
+		}
+		if len(series.Values) == 0 {
+			return fmt.Errorf("series[%d] values cannot be empty", i)
+		}
+		if len(opts.Categories) > 0 && len(series.Values) != len(opts.Categories) {
+			return fmt.Errorf("series[%d] values length (%d) must match categories length (%d)", i, len(series.Values), len(opts.Categories))
+		}
+		if len(series.Values) != len(opts.Series[0].Values) {
+			return fmt.Errorf("series[%d] values length (%d) must match series[0] values length (%d)", i, len(series.Values), len(opts.Series[0].Values))
+		}
+		if series.ChartKind != "" || series.SecondaryAxis {
+			return fmt.Errorf("series[%d]: %s charts cannot be combined with other kinds", i, kind)
+		}
+		if err := validateSeriesFormat(i, series, kind); err != nil {
+			return err
+		}
+	}
+
+	if opts.CategoryAxis != nil {
+		if err := validateAxisOptions("CategoryAxis", opts.CategoryAxis); err != nil {
+			return err
+		}
+	}
+	if opts.ValueAxis != nil {
+		if err := validateAxisOptions("ValueAxis", opts.ValueAxis); err != nil {
+			return err
+		}
+	}
+
+	if w := opts.WaterfallChartOptions; w != nil {
+		seen := make(map[int]bool)
+		for _, idx := range w.Subtotals {
+			if idx < 0 || idx >= len(opts.Categories) {
+				return fmt.Errorf("WaterfallChartOptions.Subtotals: index %d is out of range", idx)
+			}
+			if seen[idx] {
+				return fmt.Errorf("WaterfallChartOptions.Subtotals: index %d is listed more than once", idx)
+			}
+			seen[idx] = true
+		}
+	}
+	if h := opts.HistogramChartOptions; h != nil {
+		if h.BinCount < 0 || h.BinWidth < 0 {
+			return fmt.Errorf("HistogramChartOptions: bin count and width cannot be negative")
+		}
+		if h.BinCount > 0 && h.BinWidth > 0 {
+			return fmt.Errorf("HistogramChartOptions: set either BinCount or BinWidth")
+		}
+		if h.Underflow != nil && h.Overflow != nil && *h.Underflow >= *h.Overflow {
+			return fmt.Errorf("HistogramChartOptions: Underflow must be less than Overflow")
+		}
+	}
+	if b := opts.BoxWhiskerChartOptions; b != nil {
+		switch b.QuartileMethod {
+		case "", QuartileExclusive, QuartileInclusive:
+		default:
+			return fmt.Errorf("BoxWhiskerChartOptions.QuartileMethod %q is not supported", b.QuartileMethod)
+		}
+	}
+	if h := opts.HierarchyChartOptions; h != nil {
+		if !isHierarchyChartKind(kind) {
+			return fmt.Errorf("HierarchyChartOptions only applies to treemap and sunburst charts")
+		}
+		for level, parents := range h.ParentCategories {
+			if len(parents) != len(opts.Categories) {
+				return fmt.Errorf("HierarchyChartOptions.ParentCategories[%d] length (%d) must match categories length (%d)", level, len(parents), len(opts.Categories))
+			}
+		}
+		switch h.ParentLabels {
+		case "", ParentLabelOverlapping, ParentLabelBanner, ParentLabelNone:
+		default:
+			return fmt.Errorf("HierarchyChartOptions.ParentLabels %q is not supported", h.ParentLabels)
+		}
+	}
+
+	return nil
+}
+
+// applyChartExDefaults fills in blank categories for charts given values only,
+// so the embedded workbook keeps one row per value
+func applyChartExDefaults(opts ChartOptions) ChartOptions {
+	if len(opts.Categories) == 0 {
+		opts.Categories = make([]string, len(opts.Series[0].Values))
+	}
+	return opts
+}
+
+// insertChartEx creates a chartex part with its embedded workbook and inserts
+// it into the document, wrapped in mc:AlternateContent with a text fallback
+func (u *Updater) insertChartEx(opts ChartOptions) error {
+	opts = applyChartExDefaults(opts)
+	chartIndex := u.findNextChartExIndex()
+
+	// Create chartex XML file
+	chartPath := filepath.Join(u.tempDir, "word", "charts", fmt.Sprintf("chartEx%d.xml", chartIndex))
+	if err := os.MkdirAll(filepath.Dir(chartPath), 0o755); err != nil {
+		return fmt.Errorf("create charts directory: %w", err)
+	}
+	if err := atomicWriteFile(chartPath, generateChartExXML(opts), 0o644); err != nil {
+		return fmt.Errorf("write chartex xml: %w", err)
+	}
+
+	// Create embedded workbook
+	workbookPath := filepath.Join(u.tempDir, "word", "embeddings", fmt.Sprintf("Microsoft_Excel_Worksheet_chartEx%d.xlsx", chartIndex))
+	if err := u.createEmbeddedWorkbook(workbookPath, opts); err != nil {
+		return fmt.Errorf("create embedded workbook: %w", err)
+	}
+
+	// Create chartex relationships file
+	chartRelsPath := filepath.Join(u.tempDir, "word", "charts", "_rels", fmt.Sprintf("chartEx%d.xml.rels", chartIndex))
+	if err := u.createChartRelationships(chartRelsPath, workbookPath); err != nil {
+		return fmt.Errorf("create chart relationships: %w", err)
+	}
+
+	// Add chartex relationship to document.xml.rels
+	relID, err := u.addDocumentRelationship(ChartExRelType, fmt.Sprintf("charts/chartEx%d.xml", chartIndex))
+	if err != nil {
+		return fmt.Errorf("add chart relationship: %w", err)
+	}
+
+	// Insert chart drawing into document
+	drawingXML, err := u.generateChartExDrawing(chartIndex, relID, opts)
+	if err != nil {
+		return fmt.Errorf("generate drawing xml: %w", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "document.xml"))
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+	if err := u.insertChartContent(raw, drawingXML, opts); err != nil {
+		return fmt.Errorf("insert chart drawing: %w", err)
+	}
+
+	// Update content types
+	if err := u.addPartContentTypeOverride(fmt.Sprintf("/word/charts/chartEx%d.xml", chartIndex), ChartExContentType); err != nil {
+		return fmt.Errorf("add content type: %w", err)
+	}
+	if err := u.addImageContentType(".xlsx", XLSXContentType); err != nil {
+		return fmt.Errorf("add workbook content type: %w", err)
+	}
+
+	return nil
+}
+
+// findNextChartExIndex finds the next available chartex index by scanning
+// chart files
+func (u *Updater) findNextChartExIndex() int {
+	entries, err := os.ReadDir(filepath.Join(u.tempDir, "word", "charts"))
+	if err != nil {
+		return 1
+	}
+
+	maxIndex := 0
+	for _, entry := range entries {
+		if matches := chartExFilePattern.FindStringSubmatch(entry.Name()); matches != nil {
+			if idx, err := strconv.Atoi(matches[1]); err == nil && idx > maxIndex {
+				maxIndex = idx
+			}
+		}
+	}
+
+	return maxIndex + 1
+}
+
+// generateChartExDrawing creates the inline drawing of a chartex chart. Word
+// 2016 and later render the mc:Choice; older versions show the fallback text.
+func (u *Updater) generateChartExDrawing(chartIndex int, relID string, opts ChartOptions) ([]byte, error) {
+	docPrID, err := u.getNextDocPrId()
+	if err != nil {
+		return nil, fmt.Errorf("get next docPr id: %w", err)
+	}
+
+	// Offset the IDs by half an increment to keep them apart from the IDs of
+	// the chart with the same index
+	anchorID := ChartAnchorIDBase + uint32(chartIndex)*ChartIDIncrement + ChartIDIncrement/2
+	editID := ChartEditIDBase + uint32(chartIndex)*ChartIDIncrement + ChartIDIncrement/2
+
+	requires, requiresNS := "cx1", chartEx1NS
+	if opts.ChartKind == ChartKindFunnel {
+		requires, requiresNS = "cx2", chartEx2NS
+	}
+
+	template := `<w:p><w:r><mc:AlternateContent xmlns:mc="%s"><mc:Choice xmlns:%s="%s" Requires="%s"><w:drawing><wp:inline xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" distT="0" distB="0" distL="0" distR="0" wp14:anchorId="%08X" wp14:editId="%08X" xmlns:wp14="http://schemas.microsoft.com/office/word/2010/wordprocessingDrawing"><wp:extent cx="%d" cy="%d"/><wp:effectExtent l="0" t="0" r="15875" b="12700"/><wp:docPr id="%d" name="Chart %d"/><wp:cNvGraphicFramePr/><a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:graphicData uri="%s"><cx:chart xmlns:cx="%s" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" r:id="%s"/></a:graphicData></a:graphic></wp:inline></w:drawing></mc:Choice><mc:Fallback><w:t>This chart isn't available in your version of Word.</w:t></mc:Fallback></mc:AlternateContent></w:r></w:p>`
+
+	return fmt.Appendf(nil, template, MarkupCompatibilityNS, requires, requiresNS, requires,
+		anchorID, editID, opts.Width, opts.Height, docPrID, chartIndex, ChartExNS, ChartExNS, relID), nil
+}
+
+// generateChartExXML creates the cx:chartSpace content of a chartex chart
+func generateChartExXML(opts ChartOptions) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	buf.WriteString("\n")
+	buf.WriteString(`<cx:chartSpace xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"`)
+	buf.WriteString(` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`)
+	buf.WriteString(` xmlns:cx="` + ChartExNS + `">`)
+
+	// Data: one data block per series, referencing the embedded workbook
+	buf.WriteString(`<cx:chartData>`)
+	buf.WriteString(`<cx:externalData r:id="rId1" cx:autoUpdate="0"/>`)
+	for i, series := range opts.Series {
+		buf.WriteString(generateChartExDataXML(i, series, opts))
+	}
+	buf.WriteString(`</cx:chartData>`)
+
+	buf.WriteString(`<cx:chart>`)
+	if opts.Title != "" {
+		buf.WriteString(fmt.Sprintf(`<cx:title pos="t" align="ctr" overlay="%d">`, boolToInt(opts.TitleOverlay)))
+		buf.WriteString(generateChartExTextXML(opts.Title))
+		buf.WriteString(`</cx:title>`)
+	}
+
+	buf.WriteString(`<cx:plotArea><cx:plotAreaRegion>`)
+	for i, series := range opts.Series {
+		buf.WriteString(generateChartExSeriesXML(i, series, opts))
+	}
+	if opts.ChartKind == ChartKindPareto {
+		// The cumulative percentage line is owned by the column series
+		buf.WriteString(`<cx:series layoutId="paretoLine" ownerIdx="0" uniqueId="{00000000-0000-4000-8000-000000000100}">`)
+		buf.WriteString(`<cx:axisId val="2"/></cx:series>`)
+	}
+	buf.WriteString(`</cx:plotAreaRegion>`)
+	buf.WriteString(generateChartExAxesXML(opts))
+	buf.WriteString(`</cx:plotArea>`)
+
+	if opts.Legend.Show {
+		pos := opts.Legend.Position
+		if pos == "tr" {
+			pos = "r" // chartex legends have no top-right position
+		}
+		buf.WriteString(fmt.Sprintf(`<cx:legend pos="%s" align="ctr" overlay="%d"/>`, pos, boolToInt(opts.Legend.Overlay)))
+	}
+
+	buf.WriteString(`</cx:chart>`)
+	buf.WriteString(`</cx:chartSpace>`)
+
+	return buf.Bytes()
+}
+
+// generateChartExTextXML generates a cx:tx element holding literal text
+func generateChartExTextXML(text string) string {
+	return `<cx:tx><cx:txData><cx:v>` + xmlEscape(text) + `</cx:v></cx:txData></cx:tx>`
+}
+
+// generateChartExDataXML generates the cx:data block of a series: its
+// categories, from the innermost level out, and its values
+func generateChartExDataXML(index int, series SeriesOptions, opts ChartOptions) string {
+	var buf bytes.Buffer
+
+	parents := hierarchyParentCategories(opts)
+	categoryCol := len(parents) + 1
+	lastRow := len(opts.Categories) + 1
+	valCol := columnLetter(categoryCol + 1 + index)
+
+	buf.WriteString(fmt.Sprintf(`<cx:data id="%d">`, index))
+
+	if !isBinnedChart(opts) && hasCategoryLabels(opts.Categories) {
+		buf.WriteString(fmt.Sprintf(`<cx:strDim type="cat"><cx:f>Sheet1!$A$2:$%s$%d</cx:f>`, columnLetter(categoryCol), lastRow))
+		buf.WriteString(generateChartExStringLevelXML(opts.Categories))
+		for level := len(parents) - 1; level >= 0; level-- {
+			buf.WriteString(generateChartExStringLevelXML(parents[level]))
+		}
+		buf.WriteString(`</cx:strDim>`)
+	}
+
+	// Treemap and sunburst charts size their rectangles and rings by value
+	dimType := "val"
+	if isHierarchyChartKind(opts.ChartKind) {
+		dimType = "size"
+	}
+	buf.WriteString(fmt.Sprintf(`<cx:numDim type="%s"><cx:f>Sheet1!$%s$2:$%s$%d</cx:f>`, dimType, valCol, valCol, lastRow))
+	buf.WriteString(fmt.Sprintf(`<cx:lvl ptCount="%d" formatCode="General">`, len(series.Values)))
+	for j, val := range series.Values {
+		buf.WriteString(fmt.Sprintf(`<cx:pt idx="%d">%g</cx:pt>`, j, val))
+	}
+	buf.WriteString(`</cx:lvl></cx:numDim>`)
+
+	buf.WriteString(`</cx:data>`)
+
+	return buf.String()
+}
+
+// generateChartExStringLevelXML generates a cx:lvl of category labels
+func generateChartExStringLevelXML(labels []string) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf(`<cx:lvl ptCount="%d">`, len(labels)))
+	for j, label := range labels {
+		buf.WriteString(fmt.Sprintf(`<cx:pt idx="%d">%s</cx:pt>`, j, xmlEscape(label)))
+	}
+	buf.WriteString(`</cx:lvl>`)
+
+	return buf.String()
+}
+
+// hasCategoryLabels reports whether any category has a label
+func hasCategoryLabels(categories []string) bool {
+	for _, category := range categories {
+		if category != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// chartExLayoutID returns the series layout of a chartex kind
+func chartExLayoutID(kind ChartKind) string {
+	switch kind {
+	case ChartKindHistogram, ChartKindPareto:
+		return "clusteredColumn"
+	case ChartKindTreemap:
+		return "treemap"
+	case ChartKindSunburst:
+		return "sunburst"
+	case ChartKindBoxWhisker:
+		return "boxWhisker"
+	case ChartKindFunnel:
+		return "funnel"
+	}
+	return "waterfall"
+}
+
+// generateChartExSeriesXML generates a cx:series element
+func generateChartExSeriesXML(index int, series SeriesOptions, opts ChartOptions) string {
+	var buf bytes.Buffer
+
+	valCol := columnLetter(len(hierarchyParentCategories(opts)) + 2 + index)
+
+	buf.WriteString(fmt.Sprintf(`<cx:series layoutId="%s" uniqueId="{00000000-0000-4000-8000-%012X}">`, chartExLayoutID(opts.ChartKind), index))
+
+	// Series name
+	buf.WriteString(fmt.Sprintf(`<cx:tx><cx:txData><cx:f>Sheet1!$%s$1</cx:f><cx:v>%s</cx:v></cx:txData></cx:tx>`, valCol, xmlEscape(series.Name)))
+
+	// Shape properties and per-point overrides
+	if hasShapeProperties(series.Color, series.Fill, series.Line) {
+		buf.WriteString(`<cx:spPr>` + generateFillXML(series.Color, series.Fill) + generateLineXML(series.Line) + `</cx:spPr>`)
+	}
+	for _, point := range series.Points {
+		buf.WriteString(fmt.Sprintf(`<cx:dataPt idx="%d">`, point.Index))
+		if hasShapeProperties(point.Color, point.Fill, point.Line) {
+			buf.WriteString(`<cx:spPr>` + generateFillXML(point.Color, point.Fill) + generateLineXML(point.Line) + `</cx:spPr>`)
+		}
+		buf.WriteString(`</cx:dataPt>`)
+	}
+
+	// Data labels: the series' own, the chart-level ones, or category names
+	// on treemap and sunburst charts
+	labels := series.DataLabels
+	if labels == nil {
+		labels = opts.DataLabels
+	}
+	if labels == nil && isHierarchyChartKind(opts.ChartKind) {
+		labels = &DataLabelOptions{ShowCategoryName: true}
+	}
+	if labels != nil {
+		buf.WriteString(generateChartExDataLabelsXML(labels))
+	}
+
+	buf.WriteString(fmt.Sprintf(`<cx:dataId val="%d"/>`, index))
+	buf.WriteString(generateChartExLayoutXML(opts))
+	if opts.ChartKind == ChartKindPareto {
+		buf.WriteString(`<cx:axisId val="1"/>`)
+	}
+
+	buf.WriteString(`</cx:series>`)
+
+	return buf.String()
+}
+
+// generateChartExDataLabelsXML generates a cx:dataLabels element
+func generateChartExDataLabelsXML(labels *DataLabelOptions) string {
+	var buf bytes.Buffer
+
+	if labels.Position != "" {
+		buf.WriteString(fmt.Sprintf(`<cx:dataLabels pos="%s">`, labels.Position))
+	} else {
+		buf.WriteString(`<cx:dataLabels>`)
+	}
+	buf.WriteString(fmt.Sprintf(`<cx:visibility seriesName="%d" categoryName="%d" value="%d"/>`,
+		boolToInt(labels.ShowSeriesName), boolToInt(labels.ShowCategoryName), boolToInt(labels.ShowValue)))
+	buf.WriteString(`</cx:dataLabels>`)
+
+	return buf.String()
+}
+
+// generateChartExLayoutXML generates the cx:layoutPr of a series, which holds
+// the kind-specific options
+func generateChartExLayoutXML(opts ChartOptions) string {
+	var buf bytes.Buffer
+
+	switch opts.ChartKind {
+	case ChartKindWaterfall:
+		buf.WriteString(`<cx:layoutPr>`)
+		if w := opts.WaterfallChartOptions; w != nil {
+			if w.HideConnectorLines {
+				buf.WriteString(`<cx:visibility connectorLines="0"/>`)
+			}
+			if len(w.Subtotals) > 0 {
+				buf.WriteString(`<cx:subtotals>`)
+				for _, idx := range w.Subtotals {
+					buf.WriteString(fmt.Sprintf(`<cx:idx val="%d"/>`, idx))
+				}
+				buf.WriteString(`</cx:subtotals>`)
+			}
+		}
+		buf.WriteString(`</cx:layoutPr>`)
+	case ChartKindHistogram, ChartKindPareto:
+		buf.WriteString(`<cx:layoutPr>`)
+		if isBinnedChart(opts) {
+			buf.WriteString(generateChartExBinningXML(opts.HistogramChartOptions))
+		} else {
+			buf.WriteString(`<cx:aggregation/>`)
+		}
+		buf.WriteString(`</cx:layoutPr>`)
+	case ChartKindBoxWhisker:
+		b := opts.BoxWhiskerChartOptions
+		if b == nil {
+			b = &BoxWhiskerChartOptions{}
+		}
+		method := b.QuartileMethod
+		if method == "" {
+			method = QuartileExclusive
+		}
+		buf.WriteString(`<cx:layoutPr>`)
+		buf.WriteString(fmt.Sprintf(`<cx:visibility meanLine="%d" meanMarker="%d" nonoutliers="%d" outliers="%d"/>`,
+			boolToInt(b.ShowMeanLine), boolToInt(!b.HideMeanMarkers), boolToInt(b.ShowInnerPoints), boolToInt(!b.HideOutliers)))
+		buf.WriteString(fmt.Sprintf(`<cx:statistics quartileMethod="%s"/>`, method))
+		buf.WriteString(`</cx:layoutPr>`)
+	case ChartKindTreemap:
+		layout := ParentLabelOverlapping
+		if h := opts.HierarchyChartOptions; h != nil && h.ParentLabels != "" {
+			layout = h.ParentLabels
+		}
+		buf.WriteString(fmt.Sprintf(`<cx:layoutPr><cx:parentLabelLayout val="%s"/></cx:layoutPr>`, layout))
+	}
+
+	return buf.String()
+}
+
+// generateChartExBinningXML generates a cx:binning element; without options
+// the bins are automatic
+func generateChartExBinningXML(h *HistogramChartOptions) string {
+	if h == nil {
+		h = &HistogramChartOptions{}
+	}
+	var buf bytes.Buffer
+
+	interval := "r"
+	if h.IntervalClosedLeft {
+		interval = "l"
+	}
+	buf.WriteString(fmt.Sprintf(`<cx:binning intervalClosed="%s"`, interval))
+	if h.Underflow != nil {
+		buf.WriteString(fmt.Sprintf(` underflow="%g"`, *h.Underflow))
+	}
+	if h.Overflow != nil {
+		buf.WriteString(fmt.Sprintf(` overflow="%g"`, *h.Overflow))
+	}
+	switch {
+	case h.BinCount > 0:
+		buf.WriteString(fmt.Sprintf(`><cx:binCount val="%d"/></cx:binning>`, h.BinCount))
+	case h.BinWidth > 0:
+		buf.WriteString(fmt.Sprintf(`><cx:binSize val="%g"/></cx:binning>`, h.BinWidth))
+	default:
+		buf.WriteString(`/>`)
+	}
+
+	return buf.String()
+}
+
+// generateChartExAxesXML generates the axes of a chartex chart. Treemap and
+// sunburst charts have none and funnel charts only label their categories.
+func generateChartExAxesXML(opts ChartOptions) string {
+	if isHierarchyChartKind(opts.ChartKind) {
+		return ""
+	}
+	var buf bytes.Buffer
+
+	// Gap between columns as a fraction of their width
+	gapWidth := 0.5
+	switch opts.ChartKind {
+	case ChartKindHistogram, ChartKindPareto:
+		gapWidth = 0
+	case ChartKindBoxWhisker:
+		gapWidth = 1
+	case ChartKindFunnel:
+		gapWidth = 0.06
+	}
+
+	buf.WriteString(`<cx:axis id="0">`)
+	buf.WriteString(fmt.Sprintf(`<cx:catScaling gapWidth="%g"/>`, gapWidth))
+	if opts.CategoryAxis.Title != "" {
+		buf.WriteString(`<cx:title>` + generateChartExTextXML(opts.CategoryAxis.Title) + `</cx:title>`)
+	}
+	buf.WriteString(`<cx:tickLabels/>`)
+	buf.WriteString(`</cx:axis>`)
+	if opts.ChartKind == ChartKindFunnel {
+		return buf.String()
+	}
+
+	axis := opts.ValueAxis
+	buf.WriteString(`<cx:axis id="1"><cx:valScaling`)
+	if axis.Max != nil {
+		buf.WriteString(fmt.Sprintf(` max="%g"`, *axis.Max))
+	}
+	if axis.Min != nil {
+		buf.WriteString(fmt.Sprintf(` min="%g"`, *axis.Min))
+	}
+	if axis.MajorUnit != nil {
+		buf.WriteString(fmt.Sprintf(` majorUnit="%g"`, *axis.MajorUnit))
+	}
+	if axis.MinorUnit != nil {
+		buf.WriteString(fmt.Sprintf(` minorUnit="%g"`, *axis.MinorUnit))
+	}
+	buf.WriteString(`/>`)
+	if axis.Title != "" {
+		buf.WriteString(`<cx:title>` + generateChartExTextXML(axis.Title) + `</cx:title>`)
+	}
+	if axis.MajorGridlines {
+		buf.WriteString(`<cx:majorGridlines/>`)
+	}
+	if axis.MinorGridlines {
+		buf.WriteString(`<cx:minorGridlines/>`)
+	}
+	buf.WriteString(`<cx:tickLabels/>`)
+	if axis.NumberFormat != "" && axis.NumberFormat != "General" {
+		buf.WriteString(fmt.Sprintf(`<cx:numFmt formatCode="%s" sourceLinked="0"/>`, xmlEscape(axis.NumberFormat)))
+	}
+	buf.WriteString(`</cx:axis>`)
+
+	// Pareto charts plot the cumulative percentage against a second axis
+	if opts.ChartKind == ChartKindPareto {
+		buf.WriteString(`<cx:axis id="2"><cx:valScaling max="1" min="0"/><cx:units unit="percentage"/><cx:tickLabels/></cx:axis>`)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,272 @@
+package godocx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInsertChartEx_Waterfall(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Bridge:</w:t></w:r></w:p>`))
+	if err := u.InsertChart(ChartOptions{
+		Position:   PositionEnd,
+		ChartKind:  ChartKindWaterfall,
+		Title:      "Operating profit",
+		Categories: []string{"Revenue", "Costs", "Gross", "Opex", "Profit"},
+		Series: []SeriesOptions{{
+			Name:   "Amount",
+			Values: []float64{500, -320, 180, -90, 90},
+			Points: []DataPointOptions{{Index: 4, Color: "00B050"}},
+		}},
+		WaterfallChartOptions: &WaterfallChartOptions{Subtotals: []int{2, 4}},
+		DataLabels:            &DataLabelOptions{ShowValue: true, Position: DataLabelOutsideEnd},
+		ValueAxis:             &AxisOptions{Title: "EUR k", NumberFormat: "#,##0"},
+		Caption:               &CaptionOptions{Type: CaptionFigure, Description: "Profit bridge", Position: CaptionAfter},
+	}); err != nil {
+		t.Fatalf("InsertChart: %v", err)
+	}
+
+	chart := readWordPart(t, u, filepath.Join("charts", "chartEx1.xml"))
+	checkWellFormedXML(t, chart)
+	for _, want := range []string{
+		`<cx:chartSpace xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:cx="` + ChartExNS + `">`,
+		`<cx:chartData><cx:externalData r:id="rId1" cx:autoUpdate="0"/><cx:data id="0"><cx:strDim type="cat"><cx:f>Sheet1!$A$2:$A$6</cx:f><cx:lvl ptCount="5"><cx:pt idx="0">Revenue</cx:pt>`,
+		`<cx:numDim type="val"><cx:f>Sheet1!$B$2:$B$6</cx:f><cx:lvl ptCount="5" formatCode="General"><cx:pt idx="0">500</cx:pt><cx:pt idx="1">-320</cx:pt>`,
+		`<cx:title pos="t" align="ctr" overlay="0"><cx:tx><cx:txData><cx:v>Operating profit</cx:v></cx:txData></cx:tx></cx:title>`,
+		`<cx:series layoutId="waterfall" uniqueId="{00000000-0000-4000-8000-000000000000}"><cx:tx><cx:txData><cx:f>Sheet1!$B$1</cx:f><cx:v>Amount</cx:v></cx:txData></cx:tx>`,
+		`<cx:dataPt idx="4"><cx:spPr><a:solidFill><a:srgbClr val="00B050"/></a:solidFill></cx:spPr></cx:dataPt>`,
+		`<cx:dataLabels pos="outEnd"><cx:visibility seriesName="0" categoryName="0" value="1"/></cx:dataLabels><cx:dataId val="0"/><cx:layoutPr><cx:subtotals><cx:idx val="2"/><cx:idx val="4"/></cx:subtotals></cx:layoutPr></cx:series>`,
+		`<cx:axis id="0"><cx:catScaling gapWidth="0.5"/><cx:tickLabels/></cx:axis>`,
+		`<cx:axis id="1"><cx:valScaling/><cx:title><cx:tx><cx:txData><cx:v>EUR k</cx:v></cx:txData></cx:tx></cx:title><cx:majorGridlines/><cx:tickLabels/><cx:numFmt formatCode="#,##0" sourceLinked="0"/></cx:axis>`,
+	} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("expected %s in chartex XML, got %s", want, chart)
+		}
+	}
+
+	rels := readWordPart(t, u, filepath.Join("charts", "_rels", "chartEx1.xml.rels"))
+	if !strings.Contains(rels, `Target="../embeddings/Microsoft_Excel_Worksheet_chartEx1.xlsx"`) {
+		t.Errorf("unexpected chartex relationships %s", rels)
+	}
+	docRels := readWordPart(t, u, filepath.Join("_rels", "document.xml.rels"))
+	if !strings.Contains(docRels, `Type="`+ChartExRelType+`" Target="charts/chartEx1.xml"`) {
+		t.Errorf("expected a chartex relationship in %s", docRels)
+	}
+	contentTypes, err := os.ReadFile(filepath.Join(u.TempDir(), "[Content_Types].xml"))
+	if err != nil {
+		t.Fatalf("read content types: %v", err)
+	}
+	for _, want := range []string{
+		`<Override PartName="/word/charts/chartEx1.xml" ContentType="` + ChartExContentType + `"/>`,
+		`Extension="xlsx"`,
+	} {
+		if !strings.Contains(string(contentTypes), want) {
+			t.Errorf("expected %s in content types", want)
+		}
+	}
+
+	doc := readDocXML(t, u)
+	for _, want := range []string{
+		`<mc:AlternateContent xmlns:mc="` + MarkupCompatibilityNS + `"><mc:Choice xmlns:cx1="http://schemas.microsoft.com/office/drawing/2015/9/8/chartex" Requires="cx1"><w:drawing>`,
+		`<a:graphicData uri="` + ChartExNS + `"><cx:chart xmlns:cx="` + ChartExNS + `"`,
+		`<mc:Fallback><w:t>This chart isn't available in your version of Word.</w:t></mc:Fallback>`,
+		`Profit bridge`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %s in document", want)
+		}
+	}
+
+	sheet := readWorkbookSheet(t, filepath.Join(u.TempDir(), "word", "embeddings", "Microsoft_Excel_Worksheet_chartEx1.xlsx"))
+	if !strings.Contains(sheet, `<c r="A6" t="str"><v>Profit</v></c><c r="B6"><v>90</v></c>`) {
+		t.Errorf("unexpected workbook sheet %s", sheet)
+	}
+
+	// Chartex parts are kept apart from the chart parts
+	if count, err := u.GetChartCount(); err != nil || count != 0 {
+		t.Errorf("GetChartCount = %d, %v; want 0", count, err)
+	}
+}
+
+func TestInsertChartEx_Kinds(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Dashboards:</w:t></w:r></w:p>`))
+	under, over := 10.0, 90.0
+	charts := []ChartOptions{
+		{
+			ChartKind:  ChartKindTreemap,
+			Categories: []string{"Paris", "Lyon", "Berlin", "Munich"},
+			Series:     []SeriesOptions{{Name: "Sales", Values: []float64{40, 15, 30, 20}}},
+			HierarchyChartOptions: &HierarchyChartOptions{
+				ParentCategories: [][]string{{"Europe", "Europe", "Europe", "Europe"}, {"France", "France", "Germany", "Germany"}},
+				ParentLabels:     ParentLabelBanner,
+			},
+		},
+		{
+			ChartKind: ChartKindHistogram,
+			Series:    []SeriesOptions{{Name: "Scores", Values: []float64{12, 45, 47, 63, 71, 88, 95}}},
+			HistogramChartOptions: &HistogramChartOptions{
+				BinWidth: 20, Underflow: &under, Overflow: &over,
+			},
+		},
+		{
+			ChartKind:  ChartKindPareto,
+			Categories: []string{"Late", "Damaged", "Wrong item"},
+			Series:     []SeriesOptions{{Name: "Complaints", Values: []float64{42, 17, 8}}},
+		},
+		{
+			ChartKind:  ChartKindBoxWhisker,
+			Categories: []string{"A", "A", "A", "B", "B", "B"},
+			Series: []SeriesOptions{
+				{Name: "2024", Values: []float64{3, 5, 9, 2, 4, 6}},
+				{Name: "2025", Values: []float64{4, 6, 8, 3, 5, 9}},
+			},
+			BoxWhiskerChartOptions: &BoxWhiskerChartOptions{QuartileMethod: QuartileInclusive, ShowMeanLine: true},
+		},
+		{
+			ChartKind:  ChartKindFunnel,
+			Categories: []string{"Leads", "Qualified", "Won"},
+			Series:     []SeriesOptions{{Name: "Pipeline", Values: []float64{1200, 400, 90}}},
+		},
+		{
+			ChartKind:  ChartKindSunburst,
+			Categories: []string{"Q1", "Q2"},
+			Series:     []SeriesOptions{{Name: "Hours", Values: []float64{10, 12}}},
+		},
+	}
+	for i, opts := range charts {
+		opts.Position = PositionEnd
+		if err := u.InsertChart(opts); err != nil {
+			t.Fatalf("InsertChart %s: %v", opts.ChartKind, err)
+		}
+		checkWellFormedXML(t, readWordPart(t, u, filepath.Join("charts", "chartEx"+string(rune('1'+i))+".xml")))
+	}
+
+	treemap := readWordPart(t, u, filepath.Join("charts", "chartEx1.xml"))
+	for _, want := range []string{
+		`<cx:strDim type="cat"><cx:f>Sheet1!$A$2:$C$5</cx:f><cx:lvl ptCount="4"><cx:pt idx="0">Paris</cx:pt><cx:pt idx="1">Lyon</cx:pt><cx:pt idx="2">Berlin</cx:pt><cx:pt idx="3">Munich</cx:pt></cx:lvl><cx:lvl ptCount="4"><cx:pt idx="0">France</cx:pt>`,
+		`</cx:lvl><cx:lvl ptCount="4"><cx:pt idx="0">Europe</cx:pt>`,
+		`<cx:numDim type="size"><cx:f>Sheet1!$D$2:$D$5</cx:f>`,
+		`<cx:dataLabels><cx:visibility seriesName="0" categoryName="1" value="0"/></cx:dataLabels>`,
+		`<cx:layoutPr><cx:parentLabelLayout val="banner"/></cx:layoutPr>`,
+	} {
+		if !strings.Contains(treemap, want) {
+			t.Errorf("expected %s in treemap XML", want)
+		}
+	}
+	if strings.Contains(treemap, "<cx:axis") {
+		t.Error("treemap charts have no axes")
+	}
+	sheet := readWorkbookSheet(t, filepath.Join(u.TempDir(), "word", "embeddings", "Microsoft_Excel_Worksheet_chartEx1.xlsx"))
+	if !strings.Contains(sheet, `<row r="2"><c r="A2" t="str"><v>Europe</v></c><c r="B2" t="str"><v>France</v></c><c r="C2" t="str"><v>Paris</v></c><c r="D2"><v>40</v></c></row>`) {
+		t.Errorf("unexpected treemap sheet %s", sheet)
+	}
+
+	histogram := readWordPart(t, u, filepath.Join("charts", "chartEx2.xml"))
+	for _, want := range []string{
+		`<cx:data id="0"><cx:numDim type="val"><cx:f>Sheet1!$B$2:$B$8</cx:f>`,
+		`<cx:series layoutId="clusteredColumn"`,
+		`<cx:layoutPr><cx:binning intervalClosed="r" underflow="10" overflow="90"><cx:binSize val="20"/></cx:binning></cx:layoutPr>`,
+		`<cx:catScaling gapWidth="0"/>`,
+	} {
+		if !strings.Contains(histogram, want) {
+			t.Errorf("expected %s in histogram XML", want)
+		}
+	}
+	if strings.Contains(histogram, "<cx:strDim") {
+		t.Error("histograms take values only")
+	}
+
+	pareto := readWordPart(t, u, filepath.Join("charts", "chartEx3.xml"))
+	for _, want := range []string{
+		`<cx:layoutPr><cx:aggregation/></cx:layoutPr><cx:axisId val="1"/></cx:series>`,
+		`<cx:series layoutId="paretoLine" ownerIdx="0"`,
+		`<cx:axis id="2"><cx:valScaling max="1" min="0"/><cx:units unit="percentage"/><cx:tickLabels/></cx:axis>`,
+	} {
+		if !strings.Contains(pareto, want) {
+			t.Errorf("expected %s in pareto XML", want)
+		}
+	}
+
+	box := readWordPart(t, u, filepath.Join("charts", "chartEx4.xml"))
+	for _, want := range []string{
+		`<cx:data id="1"><cx:strDim type="cat">`,
+		`<cx:numDim type="val"><cx:f>Sheet1!$C$2:$C$7</cx:f>`,
+		`<cx:dataId val="1"/><cx:layoutPr><cx:visibility meanLine="1" meanMarker="1" nonoutliers="0" outliers="1"/><cx:statistics quartileMethod="inclusive"/></cx:layoutPr>`,
+	} {
+		if !strings.Contains(box, want) {
+			t.Errorf("expected %s in box & whisker XML", want)
+		}
+	}
+
+	funnel := readWordPart(t, u, filepath.Join("charts", "chartEx5.xml"))
+	if !strings.Contains(funnel, `<cx:axis id="0"><cx:catScaling gapWidth="0.06"/><cx:tickLabels/></cx:axis></cx:plotArea>`) {
+		t.Errorf("unexpected funnel axes %s", funnel)
+	}
+	doc := readDocXML(t, u)
+	if !strings.Contains(doc, `<mc:Choice xmlns:cx2="http://schemas.microsoft.com/office/drawing/2015/10/21/chartex" Requires="cx2">`) {
+		t.Error("expected funnel charts to require the later chartex version")
+	}
+	if strings.Count(doc, "<mc:AlternateContent") != len(charts) {
+		t.Errorf("expected %d chartex drawings", len(charts))
+	}
+}
+
+func TestInsertChartEx_Validation(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Results:</w:t></w:r></w:p>`))
+	two := []SeriesOptions{{Name: "A", Values: []float64{1, 2}}, {Name: "B", Values: []float64{3, 4}}}
+	for name, opts := range map[string]ChartOptions{
+		"waterfall series":   {ChartKind: ChartKindWaterfall, Categories: []string{"x", "y"}, Series: two},
+		"no categories":      {ChartKind: ChartKindFunnel, Series: two[:1]},
+		"subtotal range":     {ChartKind: ChartKindWaterfall, Categories: []string{"x", "y"}, Series: two[:1], WaterfallChartOptions: &WaterfallChartOptions{Subtotals: []int{2}}},
+		"bin count and size": {ChartKind: ChartKindHistogram, Series: two[:1], HistogramChartOptions: &HistogramChartOptions{BinCount: 3, BinWidth: 2}},
+		"quartile method":    {ChartKind: ChartKindBoxWhisker, Series: two, BoxWhiskerChartOptions: &BoxWhiskerChartOptions{QuartileMethod: "median"}},
+		"parent length":      {ChartKind: ChartKindSunburst, Categories: []string{"x", "y"}, Series: two[:1], HierarchyChartOptions: &HierarchyChartOptions{ParentCategories: [][]string{{"p"}}}},
+		"hierarchy kind":     {ChartKind: ChartKindFunnel, Categories: []string{"x", "y"}, Series: two[:1], HierarchyChartOptions: &HierarchyChartOptions{}},
+		"combo series":       {ChartKind: ChartKindFunnel, Categories: []string{"x", "y"}, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2}, ChartKind: ChartKindLine}}},
+		"trendline":          {ChartKind: ChartKindFunnel, Categories: []string{"x", "y"}, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2}, Trendlines: []TrendlineOptions{{}}}}},
+		"box lengths":        {ChartKind: ChartKindBoxWhisker, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2}}, {Name: "B", Values: []float64{1}}}},
+	} {
+		if err := u.InsertChart(opts); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestInsertChartEx_SeparateFromChartIndexes(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Charts:</w:t></w:r></w:p>`))
+	for _, opts := range []ChartOptions{
+		{ChartKind: ChartKindColumn, Series: []SeriesOptions{{Name: "Columns", Values: []float64{1, 2}}}},
+		{ChartKind: ChartKindWaterfall, Series: []SeriesOptions{{Name: "Bridge", Values: []float64{5, -2}}}},
+		{ChartKind: ChartKindLine, Series: []SeriesOptions{{Name: "Trend", Values: []float64{3, 4}}}},
+	} {
+		opts.Position = PositionEnd
+		opts.Categories = []string{"A", "B"}
+		if err := u.InsertChart(opts); err != nil {
+			t.Fatalf("InsertChart(%s): %v", opts.ChartKind, err)
+		}
+	}
+
+	if count, err := u.GetChartCount(); err != nil || count != 2 {
+		t.Fatalf("expected 2 classic charts, got %d (%v)", count, err)
+	}
+	data, err := u.GetChartData(2)
+	if err != nil {
+		t.Fatalf("GetChartData: %v", err)
+	}
+	if len(data.Series) != 1 || data.Series[0].Name != "Trend" {
+		t.Errorf("expected chart 2 to be the line chart, got %+v", data.Series)
+	}
+	if opts, err := u.GetChartOptions(2); err != nil || opts.ChartKind != ChartKindLine {
+		t.Errorf("expected chart 2 options of a line chart, got %q (%v)", opts.ChartKind, err)
+	}
+	if err := u.UpdateChart(2, ChartData{Categories: []string{"A", "B"}, Series: []SeriesData{{Name: "Trend", Values: []float64{7, 8}}}}); err != nil {
+		t.Fatalf("UpdateChart: %v", err)
+	}
+	if _, err := u.GetChartData(3); err == nil {
+		t.Error("expected no third classic chart")
+	}
+	if chartEx := readWordPart(t, u, filepath.Join("charts", "chartEx1.xml")); !strings.Contains(chartEx, "Bridge") {
+		t.Errorf("expected the waterfall chart in chartEx1.xml, got %s", chartEx)
+	}
+}
@@ -33,6 +33,15 @@ const (
 	AxisPositionTop    AxisPosition = "t" // Top
 )
 
+// AxisCrosses defines where an axis crosses its perpendicular axis
+type AxisCrosses string
+
+const (
+	AxisCrossesAutoZero AxisCrosses = "autoZero" // At zero, or the minimum (default)
+	AxisCrossesMin      AxisCrosses = "min"      // At the minimum
+	AxisCrossesMax      AxisCrosses = "max"      // At the maximum
+)
+
 // TickMark defines tick mark type
 type TickMark string
 
@@ -113,7 +122,8 @@ type AxisOptions struct {
 	MinorGridlines bool // Show minor gridlines (default: false)
 
 	// Crossing
-	CrossesAt *float64 // Where axis crosses (nil for auto)
+	CrossesAt *float64    // Where axis crosses (nil for auto)
+	Crosses   AxisCrosses // Where axis crosses when CrossesAt is nil (default: autoZero)
 }
 
 // LegendOptions defines legend customization
@@ -127,12 +137,26 @@ type LegendOptions struct {
 type SeriesOptions struct {
 	Name             string            // Series name
 	Values           []float64         // Data values (Y-axis for scatter, values for other charts)
-	XValues          []float64         // X values for scatter charts (if nil, uses category indices)
+	XValues          []float64         // X values for scatter and bubble charts (if nil, uses category indices)
+	BubbleSizes      []float64         // Bubble sizes for bubble charts (one per category)
 	Color            string            // Hex color (e.g., "FF0000")
 	InvertIfNegative bool              // Use different color for negative values (default: false)
 	Smooth           bool              // Smooth lines (for line charts) (default: false)
 	ShowMarkers      bool              // Show markers (for line charts) (default: false)
 	DataLabels       *DataLabelOptions // Data labels for this series (nil for default)
+
+	// Formatting
+	Fill       *FillOptions       // Gradient or pattern fill (overrides Color)
+	Line       *LineOptions       // Series line, or the border of bars, slices and areas (nil for default)
+	Marker     *MarkerOptions     // Marker symbol and size (line, radar and scatter charts; overrides ShowMarkers)
+	Points     []DataPointOptions // Per-data-point overrides, such as pie slice colors and explosion
+	Trendlines []TrendlineOptions // Trendlines (column, bar, line, area, scatter and bubble charts)
+	ErrorBars  []ErrorBarOptions  // Error bars, at most one set per direction (same kinds as Trendlines)
+
+	// Combo charts: plots the series as another kind than ChartOptions.ChartKind
+	// (column, bar, line or area) and/or against the secondary axes
+	ChartKind     ChartKind // Plot group kind (default: ChartOptions.ChartKind)
+	SecondaryAxis bool      // Plot on the secondary value axis (default: false)
 }
 
 // ChartProperties defines chart-level properties
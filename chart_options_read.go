@@ -0,0 +1,950 @@
+package godocx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GetChartOptions reads the full definition of chart N (1-based) back into
+// ChartOptions: the chart kind and plot groups, titles, axes, legend, data
+// labels, series data and formatting, kind specific options and chart
+// properties. The result can be adjusted and passed to InsertChart to
+// recreate the chart, or have its series replaced to clone the chart's
+// styling onto new data.
+//
+// Width and Height are taken from the drawing that shows the chart in the
+// document body, and are left 0 when none is found. Position, Anchor and
+// Caption are not read. Charts of the chartex kinds (waterfall, histogram,
+// pareto, box & whisker, treemap, sunburst and funnel) are not numbered
+// among the charts and cannot be read.
+func (u *Updater) GetChartOptions(chartIndex int) (ChartOptions, error) {
+	if u == nil {
+		return ChartOptions{}, fmt.Errorf("updater is nil")
+	}
+	if chartIndex < 1 {
+		return ChartOptions{}, fmt.Errorf("chart index must be >= 1")
+	}
+	chartName := fmt.Sprintf("chart%d.xml", chartIndex)
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "charts", chartName))
+	if err != nil {
+		return ChartOptions{}, fmt.Errorf("read %s: %w", chartName, err)
+	}
+
+	var space chartSpaceXML
+	if err := xml.Unmarshal(raw, &space); err != nil {
+		return ChartOptions{}, NewXMLParseError(chartName, err)
+	}
+	opts, err := space.chartOptions()
+	if err != nil {
+		return ChartOptions{}, fmt.Errorf("%s: %w", chartName, err)
+	}
+	opts.Width, opts.Height = u.chartExtent(chartIndex)
+	return opts, nil
+}
+
+// The types below mirror the parts of a chart XML document read by
+// GetChartOptions. Their tags carry no namespace so that both prefixed
+// (c:, a:) and default-namespace documents decode.
+
+// chartValXML is an element whose value is held in a val attribute
+type chartValXML struct {
+	Val string `xml:"val,attr"`
+}
+
+// boolOr returns the boolean value of the element, or def when the element
+// is absent. An element without a val attribute is true.
+func (v *chartValXML) boolOr(def bool) bool {
+	if v == nil {
+		return def
+	}
+	return v.Val == "" || v.Val == "1" || v.Val == "true"
+}
+
+// intOr returns the integer value of the element, or def when the element
+// is absent or not an integer
+func (v *chartValXML) intOr(def int) int {
+	if v == nil {
+		return def
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v.Val))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// floatPtr returns the numeric value of the element, or nil when the
+// element is absent or not a number
+func (v *chartValXML) floatPtr() *float64 {
+	if v == nil {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v.Val), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// str returns the value of the element, or "" when the element is absent
+func (v *chartValXML) str() string {
+	if v == nil {
+		return ""
+	}
+	return v.Val
+}
+
+type chartSpaceXML struct {
+	Date1904         *chartValXML `xml:"date1904"`
+	Lang             *chartValXML `xml:"lang"`
+	RoundedCorners   *chartValXML `xml:"roundedCorners"`
+	Style            *chartValXML `xml:"style"`
+	AlternateContent *struct {
+		Fallback struct {
+			Style *chartValXML `xml:"style"`
+		} `xml:"Fallback"`
+	} `xml:"AlternateContent"`
+	Chart chartElementXML `xml:"chart"`
+}
+
+type chartElementXML struct {
+	Title            *chartTitleXML   `xml:"title"`
+	View3D           *chartView3DXML  `xml:"view3D"`
+	PlotArea         chartPlotAreaXML `xml:"plotArea"`
+	Legend           *chartLegendXML  `xml:"legend"`
+	PlotVisOnly      *chartValXML     `xml:"plotVisOnly"`
+	DispBlanksAs     *chartValXML     `xml:"dispBlanksAs"`
+	ShowDLblsOverMax *chartValXML     `xml:"showDLblsOverMax"`
+}
+
+type chartTitleXML struct {
+	Tx struct {
+		Rich *struct {
+			P []struct {
+				R []struct {
+					T string `xml:"t"`
+				} `xml:"r"`
+			} `xml:"p"`
+		} `xml:"rich"`
+		StrRef *struct {
+			StrCache chartCacheXML `xml:"strCache"`
+		} `xml:"strRef"`
+	} `xml:"tx"`
+	Overlay *chartValXML `xml:"overlay"`
+}
+
+// text returns the title text. Paragraphs of rich text are joined with
+// line breaks.
+func (t *chartTitleXML) text() string {
+	if t == nil {
+		return ""
+	}
+	if t.Tx.Rich != nil {
+		paragraphs := make([]string, 0, len(t.Tx.Rich.P))
+		for _, p := range t.Tx.Rich.P {
+			var sb strings.Builder
+			for _, r := range p.R {
+				sb.WriteString(r.T)
+			}
+			paragraphs = append(paragraphs, sb.String())
+		}
+		return strings.Join(paragraphs, "\n")
+	}
+	if t.Tx.StrRef != nil {
+		if values := t.Tx.StrRef.StrCache.strings(); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+type chartView3DXML struct {
+	RotX        *chartValXML `xml:"rotX"`
+	RotY        *chartValXML `xml:"rotY"`
+	RAngAx      *chartValXML `xml:"rAngAx"`
+	Perspective *chartValXML `xml:"perspective"`
+}
+
+type chartPlotAreaXML struct {
+	CatAx  []chartAxisXML `xml:"catAx"`
+	DateAx []chartAxisXML `xml:"dateAx"`
+	ValAx  []chartAxisXML `xml:"valAx"`
+	SerAx  []chartAxisXML `xml:"serAx"`
+	// Groups holds the remaining children in document order; the chart
+	// groups are those named *Chart
+	Groups []chartGroupXML `xml:",any"`
+}
+
+// axis returns the axis with the given ID, or nil
+func (p *chartPlotAreaXML) axis(id string) *chartAxisXML {
+	for _, axes := range [][]chartAxisXML{p.CatAx, p.DateAx, p.ValAx, p.SerAx} {
+		for i := range axes {
+			if axes[i].AxID.str() == id {
+				return &axes[i]
+			}
+		}
+	}
+	return nil
+}
+
+type chartGroupXML struct {
+	XMLName        xml.Name
+	BarDir         *chartValXML       `xml:"barDir"`
+	Grouping       *chartValXML       `xml:"grouping"`
+	ScatterStyle   *chartValXML       `xml:"scatterStyle"`
+	RadarStyle     *chartValXML       `xml:"radarStyle"`
+	VaryColors     *chartValXML       `xml:"varyColors"`
+	Series         []chartSeriesXML   `xml:"ser"`
+	DLbls          *chartDataLabelXML `xml:"dLbls"`
+	GapWidth       *chartValXML       `xml:"gapWidth"`
+	Overlap        *chartValXML       `xml:"overlap"`
+	FirstSliceAng  *chartValXML       `xml:"firstSliceAng"`
+	HoleSize       *chartValXML       `xml:"holeSize"`
+	BubbleScale    *chartValXML       `xml:"bubbleScale"`
+	ShowNegBubbles *chartValXML       `xml:"showNegBubbles"`
+	UpDownBars     *struct {
+		UpBars struct {
+			SpPr *chartShapeXML `xml:"spPr"`
+		} `xml:"upBars"`
+		DownBars struct {
+			SpPr *chartShapeXML `xml:"spPr"`
+		} `xml:"downBars"`
+	} `xml:"upDownBars"`
+	AxID []chartValXML `xml:"axId"`
+}
+
+// axisIDs returns the category and value axis IDs of the group, empty for
+// groups without axes
+func (g *chartGroupXML) axisIDs() (string, string) {
+	if len(g.AxID) < 2 {
+		return "", ""
+	}
+	return g.AxID[0].Val, g.AxID[1].Val
+}
+
+// kind returns the chart kind plotted by the group
+func (g *chartGroupXML) kind() (ChartKind, error) {
+	horizontal := g.BarDir.str() == string(BarDirectionBar)
+	switch g.XMLName.Local {
+	case "barChart":
+		if horizontal {
+			return ChartKindBar, nil
+		}
+		return ChartKindColumn, nil
+	case "bar3DChart":
+		if horizontal {
+			return ChartKindBar3D, nil
+		}
+		return ChartKindColumn3D, nil
+	case "lineChart":
+		return ChartKindLine, nil
+	case "line3DChart":
+		return ChartKindLine3D, nil
+	case "pieChart":
+		return ChartKindPie, nil
+	case "pie3DChart":
+		return ChartKindPie3D, nil
+	case "areaChart":
+		return ChartKindArea, nil
+	case "area3DChart":
+		return ChartKindArea3D, nil
+	case "scatterChart":
+		return ChartKindScatter, nil
+	case "doughnutChart":
+		return ChartKindDoughnut, nil
+	case "radarChart":
+		return ChartKindRadar, nil
+	case "bubbleChart":
+		return ChartKindBubble, nil
+	case "stockChart":
+		return ChartKindStock, nil
+	}
+	return "", fmt.Errorf("unsupported chart group %q", g.XMLName.Local)
+}
+
+type chartAxisXML struct {
+	AxID    *chartValXML `xml:"axId"`
+	Scaling struct {
+		Min *chartValXML `xml:"min"`
+		Max *chartValXML `xml:"max"`
+	} `xml:"scaling"`
+	Delete         *chartValXML   `xml:"delete"`
+	AxPos          *chartValXML   `xml:"axPos"`
+	MajorGridlines *struct{}      `xml:"majorGridlines"`
+	MinorGridlines *struct{}      `xml:"minorGridlines"`
+	Title          *chartTitleXML `xml:"title"`
+	NumFmt         *struct {
+		FormatCode string `xml:"formatCode,attr"`
+	} `xml:"numFmt"`
+	MajorTickMark *chartValXML `xml:"majorTickMark"`
+	MinorTickMark *chartValXML `xml:"minorTickMark"`
+	TickLblPos    *chartValXML `xml:"tickLblPos"`
+	Crosses       *chartValXML `xml:"crosses"`
+	CrossesAt     *chartValXML `xml:"crossesAt"`
+	MajorUnit     *chartValXML `xml:"majorUnit"`
+	MinorUnit     *chartValXML `xml:"minorUnit"`
+}
+
+type chartLegendXML struct {
+	LegendPos *chartValXML `xml:"legendPos"`
+	Overlay   *chartValXML `xml:"overlay"`
+}
+
+type chartDataLabelXML struct {
+	Delete          *chartValXML `xml:"delete"`
+	ShowLegendKey   *chartValXML `xml:"showLegendKey"`
+	ShowVal         *chartValXML `xml:"showVal"`
+	ShowCatName     *chartValXML `xml:"showCatName"`
+	ShowSerName     *chartValXML `xml:"showSerName"`
+	ShowPercent     *chartValXML `xml:"showPercent"`
+	DLblPos         *chartValXML `xml:"dLblPos"`
+	ShowLeaderLines *chartValXML `xml:"showLeaderLines"`
+}
+
+type chartSeriesXML struct {
+	Idx *chartValXML `xml:"idx"`
+	Tx  struct {
+		V      string `xml:"v"`
+		StrRef *struct {
+			StrCache chartCacheXML `xml:"strCache"`
+		} `xml:"strRef"`
+	} `xml:"tx"`
+	SpPr             *chartShapeXML      `xml:"spPr"`
+	InvertIfNegative *chartValXML        `xml:"invertIfNegative"`
+	Marker           *chartMarkerXML     `xml:"marker"`
+	DPt              []chartDataPointXML `xml:"dPt"`
+	DLbls            *chartDataLabelXML  `xml:"dLbls"`
+	Trendline        []chartTrendlineXML `xml:"trendline"`
+	ErrBars          []chartErrorBarsXML `xml:"errBars"`
+	Cat              *chartDataXML       `xml:"cat"`
+	Val              *chartDataXML       `xml:"val"`
+	XVal             *chartDataXML       `xml:"xVal"`
+	YVal             *chartDataXML       `xml:"yVal"`
+	BubbleSize       *chartDataXML       `xml:"bubbleSize"`
+	Smooth           *chartValXML        `xml:"smooth"`
+}
+
+// name returns the series name
+func (s *chartSeriesXML) name() string {
+	if s.Tx.StrRef != nil {
+		if values := s.Tx.StrRef.StrCache.strings(); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return s.Tx.V
+}
+
+// chartDataXML is the data source of a series: a reference with a cached
+// copy of the values, or a literal
+type chartDataXML struct {
+	StrRef *struct {
+		StrCache chartCacheXML `xml:"strCache"`
+	} `xml:"strRef"`
+	NumRef *struct {
+		NumCache chartCacheXML `xml:"numCache"`
+	} `xml:"numRef"`
+	MultiLvlStrRef *struct {
+		MultiLvlStrCache struct {
+			Lvl []chartCacheXML `xml:"lvl"`
+		} `xml:"multiLvlStrCache"`
+	} `xml:"multiLvlStrRef"`
+	StrLit *chartCacheXML `xml:"strLit"`
+	NumLit *chartCacheXML `xml:"numLit"`
+}
+
+// cache returns the cached points of the data source. Of a multi-level
+// category reference the innermost level is returned.
+func (d *chartDataXML) cache() *chartCacheXML {
+	switch {
+	case d == nil:
+		return nil
+	case d.StrRef != nil:
+		return &d.StrRef.StrCache
+	case d.NumRef != nil:
+		return &d.NumRef.NumCache
+	case d.MultiLvlStrRef != nil && len(d.MultiLvlStrRef.MultiLvlStrCache.Lvl) > 0:
+		return &d.MultiLvlStrRef.MultiLvlStrCache.Lvl[0]
+	case d.StrLit != nil:
+		return d.StrLit
+	case d.NumLit != nil:
+		return d.NumLit
+	}
+	return nil
+}
+
+// strings returns the cached points of the data source as text
+func (d *chartDataXML) strings() []string {
+	if c := d.cache(); c != nil {
+		return c.strings()
+	}
+	return nil
+}
+
+// numbers returns the cached points of the data source as numbers
+func (d *chartDataXML) numbers() []float64 {
+	if c := d.cache(); c != nil {
+		return c.numbers()
+	}
+	return nil
+}
+
+type chartCacheXML struct {
+	PtCount *chartValXML `xml:"ptCount"`
+	Pt      []struct {
+		Idx int    `xml:"idx,attr"`
+		V   string `xml:"v"`
+	} `xml:"pt"`
+}
+
+// strings returns the points by index. Points missing from a sparse cache
+// are empty.
+func (c *chartCacheXML) strings() []string {
+	count := c.PtCount.intOr(0)
+	for _, pt := range c.Pt {
+		if pt.Idx >= count {
+			count = pt.Idx + 1
+		}
+	}
+	values := make([]string, count)
+	for _, pt := range c.Pt {
+		if pt.Idx >= 0 {
+			values[pt.Idx] = pt.V
+		}
+	}
+	return values
+}
+
+// numbers returns the points by index. Missing and non-numeric points
+// are 0.
+func (c *chartCacheXML) numbers() []float64 {
+	texts := c.strings()
+	values := make([]float64, len(texts))
+	for i, text := range texts {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+			values[i] = f
+		}
+	}
+	return values
+}
+
+type chartColorXML struct {
+	SrgbClr *chartValXML `xml:"srgbClr"`
+}
+
+type chartShapeXML struct {
+	SolidFill *chartColorXML `xml:"solidFill"`
+	GradFill  *struct {
+		Gs []struct {
+			Pos     int          `xml:"pos,attr"`
+			SrgbClr *chartValXML `xml:"srgbClr"`
+		} `xml:"gsLst>gs"`
+		Lin *struct {
+			Ang int `xml:"ang,attr"`
+		} `xml:"lin"`
+	} `xml:"gradFill"`
+	PattFill *struct {
+		Prst  string        `xml:"prst,attr"`
+		FgClr chartColorXML `xml:"fgClr"`
+		BgClr chartColorXML `xml:"bgClr"`
+	} `xml:"pattFill"`
+	Ln *struct {
+		W         int            `xml:"w,attr"`
+		NoFill    *struct{}      `xml:"noFill"`
+		SolidFill *chartColorXML `xml:"solidFill"`
+		PrstDash  *chartValXML   `xml:"prstDash"`
+	} `xml:"ln"`
+}
+
+type chartMarkerXML struct {
+	Symbol *chartValXML   `xml:"symbol"`
+	Size   *chartValXML   `xml:"size"`
+	SpPr   *chartShapeXML `xml:"spPr"`
+}
+
+type chartDataPointXML struct {
+	Idx       *chartValXML    `xml:"idx"`
+	Marker    *chartMarkerXML `xml:"marker"`
+	Explosion *chartValXML    `xml:"explosion"`
+	SpPr      *chartShapeXML  `xml:"spPr"`
+}
+
+type chartTrendlineXML struct {
+	Name          string         `xml:"name"`
+	SpPr          *chartShapeXML `xml:"spPr"`
+	TrendlineType *chartValXML   `xml:"trendlineType"`
+	Order         *chartValXML   `xml:"order"`
+	Period        *chartValXML   `xml:"period"`
+	Forward       *chartValXML   `xml:"forward"`
+	Backward      *chartValXML   `xml:"backward"`
+	Intercept     *chartValXML   `xml:"intercept"`
+	DispRSqr      *chartValXML   `xml:"dispRSqr"`
+	DispEq        *chartValXML   `xml:"dispEq"`
+}
+
+type chartErrorBarsXML struct {
+	ErrDir     *chartValXML   `xml:"errDir"`
+	ErrBarType *chartValXML   `xml:"errBarType"`
+	ErrValType *chartValXML   `xml:"errValType"`
+	NoEndCap   *chartValXML   `xml:"noEndCap"`
+	Plus       *chartDataXML  `xml:"plus"`
+	Minus      *chartDataXML  `xml:"minus"`
+	Val        *chartValXML   `xml:"val"`
+	SpPr       *chartShapeXML `xml:"spPr"`
+}
+
+// chartOptions converts the decoded chart into ChartOptions
+func (cs *chartSpaceXML) chartOptions() (ChartOptions, error) {
+	chart := &cs.Chart
+	plotArea := &chart.PlotArea
+
+	var groups []*chartGroupXML
+	for i := range plotArea.Groups {
+		if strings.HasSuffix(plotArea.Groups[i].XMLName.Local, "Chart") {
+			groups = append(groups, &plotArea.Groups[i])
+		}
+	}
+	if len(groups) == 0 {
+		return ChartOptions{}, fmt.Errorf("chart has no chart group")
+	}
+
+	primary := groups[0]
+	kind, err := primary.kind()
+	if err != nil {
+		return ChartOptions{}, err
+	}
+	opts := ChartOptions{
+		ChartKind:    kind,
+		Title:        chart.Title.text(),
+		TitleOverlay: chart.Title != nil && chart.Title.Overlay.boolOr(false),
+		Properties:   cs.properties(),
+	}
+
+	// Series of every group, in series index order. Series of the groups
+	// after the first carry their own kind and axes.
+	type indexedSeries struct {
+		idx    int
+		series SeriesOptions
+	}
+	var all []indexedSeries
+	var secondary *chartGroupXML
+	primaryCat, primaryVal := primary.axisIDs()
+	for _, group := range groups {
+		groupKind, err := group.kind()
+		if err != nil {
+			return ChartOptions{}, err
+		}
+		catID, valID := group.axisIDs()
+		onSecondary := catID != primaryCat || valID != primaryVal
+		if onSecondary && secondary == nil {
+			secondary = group
+		}
+		for i := range group.Series {
+			ser := &group.Series[i]
+			series := readChartSeries(ser, groupKind)
+			if groupKind != kind {
+				series.ChartKind = groupKind
+			}
+			series.SecondaryAxis = onSecondary
+			all = append(all, indexedSeries{idx: ser.Idx.intOr(len(all)), series: series})
+		}
+		if opts.Categories == nil && len(group.Series) > 0 {
+			opts.Categories = readChartCategories(&group.Series[0])
+		}
+	}
+	sort.SliceStable(all, func(a, b int) bool { return all[a].idx < all[b].idx })
+	for _, s := range all {
+		opts.Series = append(opts.Series, s.series)
+	}
+
+	// Axes
+	if catAx := plotArea.axis(primaryCat); catAx != nil {
+		opts.CategoryAxis = catAx.axisOptions()
+		opts.CategoryAxisTitle = opts.CategoryAxis.Title
+	}
+	if valAx := plotArea.axis(primaryVal); valAx != nil {
+		opts.ValueAxis = valAx.axisOptions()
+		opts.ValueAxisTitle = opts.ValueAxis.Title
+	}
+	if secondary != nil {
+		catID, valID := secondary.axisIDs()
+		// A hidden secondary category axis is the default; leaving it nil
+		// keeps it hidden when the options are inserted again
+		if catAx := plotArea.axis(catID); catAx != nil && !catAx.Delete.boolOr(false) {
+			opts.SecondaryCategoryAxis = catAx.axisOptions()
+		}
+		if valAx := plotArea.axis(valID); valAx != nil {
+			opts.SecondaryValueAxis = valAx.axisOptions()
+		}
+	}
+
+	// Legend
+	opts.Legend = &LegendOptions{}
+	if chart.Legend != nil {
+		opts.Legend.Show = true
+		opts.Legend.Position = chart.Legend.LegendPos.str()
+		if opts.Legend.Position == "" {
+			opts.Legend.Position = "r"
+		}
+		opts.Legend.Overlay = chart.Legend.Overlay.boolOr(false)
+	}
+	opts.ShowLegend = opts.Legend.Show
+	opts.LegendPosition = opts.Legend.Position
+
+	opts.DataLabels = readChartDataLabels(primary.DLbls, kind)
+
+	if chart.View3D != nil {
+		opts.View3D = &View3DOptions{
+			RotX:           chart.View3D.RotX.intOr(0),
+			RotY:           chart.View3D.RotY.intOr(0),
+			Perspective:    chart.View3D.Perspective.intOr(0),
+			RightAngleAxes: chart.View3D.RAngAx.boolOr(false),
+		}
+	}
+
+	for _, group := range groups {
+		readChartGroupOptions(&opts, group)
+	}
+
+	return opts, nil
+}
+
+// properties returns the chart properties of the chart space
+func (cs *chartSpaceXML) properties() *ChartProperties {
+	style := cs.Style
+	if style == nil && cs.AlternateContent != nil {
+		style = cs.AlternateContent.Fallback.Style
+	}
+	return &ChartProperties{
+		Style:                 ChartStyle(style.intOr(0)),
+		RoundedCorners:        cs.RoundedCorners.boolOr(false),
+		Date1904:              cs.Date1904.boolOr(false),
+		Language:              cs.Lang.str(),
+		PlotVisibleOnly:       cs.Chart.PlotVisOnly.boolOr(true),
+		DisplayBlanksAs:       cs.Chart.DispBlanksAs.str(),
+		ShowDataLabelsOverMax: cs.Chart.ShowDLblsOverMax.boolOr(false),
+	}
+}
+
+// readChartGroupOptions sets the kind specific options held by a chart
+// group. The first group of each kind wins.
+func readChartGroupOptions(opts *ChartOptions, group *chartGroupXML) {
+	switch group.XMLName.Local {
+	case "barChart", "bar3DChart":
+		if opts.BarChartOptions != nil {
+			return
+		}
+		direction := BarDirection(group.BarDir.str())
+		if direction == "" {
+			direction = BarDirectionColumn
+		}
+		grouping := BarGrouping(group.Grouping.str())
+		if grouping == "" {
+			grouping = BarGroupingClustered
+		}
+		opts.BarChartOptions = &BarChartOptions{
+			Direction:  direction,
+			Grouping:   grouping,
+			GapWidth:   group.GapWidth.intOr(150),
+			Overlap:    group.Overlap.intOr(0),
+			VaryColors: group.VaryColors.boolOr(false),
+		}
+	case "scatterChart":
+		if opts.ScatterChartOptions == nil {
+			opts.ScatterChartOptions = &ScatterChartOptions{
+				ScatterStyle: group.ScatterStyle.str(),
+				VaryColors:   group.VaryColors.boolOr(false),
+			}
+		}
+	case "doughnutChart":
+		if opts.DoughnutChartOptions == nil {
+			opts.DoughnutChartOptions = &DoughnutChartOptions{
+				HoleSize:        group.HoleSize.intOr(0),
+				FirstSliceAngle: group.FirstSliceAng.intOr(0),
+			}
+		}
+	case "radarChart":
+		if opts.RadarChartOptions == nil {
+			opts.RadarChartOptions = &RadarChartOptions{Style: RadarStyle(group.RadarStyle.str())}
+		}
+	case "bubbleChart":
+		if opts.BubbleChartOptions == nil {
+			opts.BubbleChartOptions = &BubbleChartOptions{
+				Scale:        group.BubbleScale.intOr(0),
+				ShowNegative: group.ShowNegBubbles.boolOr(false),
+			}
+		}
+	case "stockChart":
+		if opts.StockChartOptions == nil && group.UpDownBars != nil {
+			up, _, _ := readShapeProperties(group.UpDownBars.UpBars.SpPr)
+			down, _, _ := readShapeProperties(group.UpDownBars.DownBars.SpPr)
+			opts.StockChartOptions = &StockChartOptions{UpBarColor: up, DownBarColor: down}
+		}
+	}
+}
+
+// axisOptions converts the axis into AxisOptions
+func (ax *chartAxisXML) axisOptions() *AxisOptions {
+	axis := &AxisOptions{
+		Title:          ax.Title.text(),
+		TitleOverlay:   ax.Title != nil && ax.Title.Overlay.boolOr(false),
+		Min:            ax.Scaling.Min.floatPtr(),
+		Max:            ax.Scaling.Max.floatPtr(),
+		MajorUnit:      ax.MajorUnit.floatPtr(),
+		MinorUnit:      ax.MinorUnit.floatPtr(),
+		Visible:        !ax.Delete.boolOr(false),
+		Position:       AxisPosition(ax.AxPos.str()),
+		MajorTickMark:  TickMark(ax.MajorTickMark.str()),
+		MinorTickMark:  TickMark(ax.MinorTickMark.str()),
+		TickLabelPos:   TickLabelPosition(ax.TickLblPos.str()),
+		MajorGridlines: ax.MajorGridlines != nil,
+		MinorGridlines: ax.MinorGridlines != nil,
+		CrossesAt:      ax.CrossesAt.floatPtr(),
+		Crosses:        AxisCrosses(ax.Crosses.str()),
+	}
+	if ax.NumFmt != nil {
+		axis.NumberFormat = ax.NumFmt.FormatCode
+	}
+	return axis
+}
+
+// readChartCategories returns the category labels of a series. Scatter and
+// bubble series have X values instead, which are returned as text.
+func readChartCategories(ser *chartSeriesXML) []string {
+	if ser.Cat != nil {
+		return ser.Cat.strings()
+	}
+	if ser.XVal != nil {
+		return ser.XVal.strings()
+	}
+	return nil
+}
+
+// readChartSeries converts a series of a chart group of the given kind
+func readChartSeries(ser *chartSeriesXML, kind ChartKind) SeriesOptions {
+	series := SeriesOptions{
+		Name:             ser.name(),
+		InvertIfNegative: ser.InvertIfNegative.boolOr(false),
+		Smooth:           ser.Smooth.boolOr(false),
+		DataLabels:       readChartDataLabels(ser.DLbls, ""),
+	}
+
+	switch kind {
+	case ChartKindScatter, ChartKindBubble:
+		series.Values = ser.YVal.numbers()
+		series.XValues = ser.XVal.numbers()
+		if ser.BubbleSize != nil {
+			series.BubbleSizes = ser.BubbleSize.numbers()
+		}
+	default:
+		series.Values = ser.Val.numbers()
+	}
+
+	// Stock chart series always get the generated line and markers
+	if kind != ChartKindStock {
+		series.Color, series.Fill, series.Line = readShapeProperties(ser.SpPr)
+		series.Marker, series.ShowMarkers = readSeriesMarker(ser.Marker)
+	}
+
+	for _, dPt := range ser.DPt {
+		point := DataPointOptions{
+			Index:     dPt.Idx.intOr(0),
+			Explosion: dPt.Explosion.intOr(0),
+		}
+		point.Color, point.Fill, point.Line = readShapeProperties(dPt.SpPr)
+		if dPt.Marker != nil {
+			point.Marker = readMarkerOptions(dPt.Marker)
+		}
+		series.Points = append(series.Points, point)
+	}
+
+	for _, tl := range ser.Trendline {
+		trendline := TrendlineOptions{
+			Type:            TrendlineType(tl.TrendlineType.str()),
+			Name:            tl.Name,
+			Order:           tl.Order.intOr(0),
+			Period:          tl.Period.intOr(0),
+			Intercept:       tl.Intercept.floatPtr(),
+			DisplayRSquared: tl.DispRSqr.boolOr(false),
+			DisplayEquation: tl.DispEq.boolOr(false),
+		}
+		if f := tl.Forward.floatPtr(); f != nil {
+			trendline.Forward = *f
+		}
+		if f := tl.Backward.floatPtr(); f != nil {
+			trendline.Backward = *f
+		}
+		_, _, trendline.Line = readShapeProperties(tl.SpPr)
+		series.Trendlines = append(series.Trendlines, trendline)
+	}
+
+	for _, eb := range ser.ErrBars {
+		errBars := ErrorBarOptions{
+			Direction: ErrorBarDirection(eb.ErrDir.str()),
+			Type:      ErrorBarType(eb.ErrBarType.str()),
+			ValueType: ErrorBarValueType(eb.ErrValType.str()),
+			NoEndCap:  eb.NoEndCap.boolOr(false),
+			Plus:      eb.Plus.numbers(),
+			Minus:     eb.Minus.numbers(),
+		}
+		if f := eb.Val.floatPtr(); f != nil {
+			errBars.Value = *f
+		}
+		_, _, errBars.Line = readShapeProperties(eb.SpPr)
+		series.ErrorBars = append(series.ErrorBars, errBars)
+	}
+
+	return series
+}
+
+// readChartDataLabels converts data labels. Labels that show nothing, and
+// the percentage labels generated by default for pie and doughnut charts
+// of the given kind, are returned as nil.
+func readChartDataLabels(dl *chartDataLabelXML, kind ChartKind) *DataLabelOptions {
+	if dl == nil || dl.Delete.boolOr(false) {
+		return nil
+	}
+	labels := &DataLabelOptions{
+		ShowValue:        dl.ShowVal.boolOr(false),
+		ShowCategoryName: dl.ShowCatName.boolOr(false),
+		ShowSeriesName:   dl.ShowSerName.boolOr(false),
+		ShowPercent:      dl.ShowPercent.boolOr(false),
+		ShowLegendKey:    dl.ShowLegendKey.boolOr(false),
+		Position:         DataLabelPosition(dl.DLblPos.str()),
+		ShowLeaderLines:  dl.ShowLeaderLines.boolOr(false),
+	}
+	if !labels.ShowValue && !labels.ShowCategoryName && !labels.ShowSeriesName && !labels.ShowPercent && !labels.ShowLegendKey {
+		return nil
+	}
+	switch kind {
+	case ChartKindPie, ChartKindPie3D, ChartKindDoughnut:
+		if *labels == (DataLabelOptions{ShowPercent: true, ShowLeaderLines: true}) {
+			return nil
+		}
+	}
+	return labels
+}
+
+// readSeriesMarker converts the marker of a series. A plain circle or no
+// marker is reported through showMarkers alone, as generated from the
+// ShowMarkers option.
+func readSeriesMarker(m *chartMarkerXML) (*MarkerOptions, bool) {
+	if m == nil {
+		return nil, false
+	}
+	marker := readMarkerOptions(m)
+	switch {
+	case *marker == (MarkerOptions{Symbol: MarkerSymbolNone}):
+		return nil, false
+	case *marker == (MarkerOptions{Symbol: MarkerSymbolCircle}):
+		return nil, true
+	}
+	return marker, marker.Symbol != MarkerSymbolNone
+}
+
+// readMarkerOptions converts a marker
+func readMarkerOptions(m *chartMarkerXML) *MarkerOptions {
+	marker := &MarkerOptions{
+		Symbol: MarkerSymbol(m.Symbol.str()),
+		Size:   m.Size.intOr(0),
+	}
+	marker.Color, _, marker.Line = readShapeProperties(m.SpPr)
+	return marker
+}
+
+// readShapeProperties converts shape properties into a solid color, a
+// gradient or pattern fill, and a line. Widths are converted from EMUs to
+// points.
+func readShapeProperties(sp *chartShapeXML) (string, *FillOptions, *LineOptions) {
+	if sp == nil {
+		return "", nil, nil
+	}
+
+	var color string
+	var fill *FillOptions
+	switch {
+	case sp.GradFill != nil:
+		gradient := &GradientFill{}
+		for _, gs := range sp.GradFill.Gs {
+			gradient.Stops = append(gradient.Stops, GradientStop{
+				Position: float64(gs.Pos) / 1000,
+				Color:    gs.SrgbClr.str(),
+			})
+		}
+		if sp.GradFill.Lin != nil {
+			gradient.Angle = float64(sp.GradFill.Lin.Ang) / 60000
+		}
+		fill = &FillOptions{Gradient: gradient}
+	case sp.PattFill != nil:
+		fill = &FillOptions{Pattern: &PatternFill{
+			Pattern:    sp.PattFill.Prst,
+			Foreground: sp.PattFill.FgClr.SrgbClr.str(),
+			Background: sp.PattFill.BgClr.SrgbClr.str(),
+		}}
+	case sp.SolidFill != nil:
+		color = sp.SolidFill.SrgbClr.str()
+	}
+
+	var line *LineOptions
+	if ln := sp.Ln; ln != nil {
+		line = &LineOptions{
+			Width: math.Round(float64(ln.W)/12700*100) / 100,
+			None:  ln.NoFill != nil,
+			Dash:  LineDash(ln.PrstDash.str()),
+		}
+		if ln.SolidFill != nil {
+			line.Color = ln.SolidFill.SrgbClr.str()
+		}
+	}
+
+	return color, fill, line
+}
+
+// chartExtentRe matches the size of a drawing
+var chartExtentRe = regexp.MustCompile(`<wp:extent\s+cx="(\d+)"\s+cy="(\d+)"`)
+
+// chartExtent returns the width and height in EMUs of the drawing that
+// shows chart N in the document body, or zeros when there is none
+func (u *Updater) chartExtent(chartIndex int) (int, int) {
+	var rels relationships
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "_rels", "document.xml.rels"))
+	if err != nil || xml.Unmarshal(raw, &rels) != nil {
+		return 0, 0
+	}
+	target := fmt.Sprintf("charts/chart%d.xml", chartIndex)
+	relID := ""
+	for _, rel := range rels.Relationships {
+		if strings.TrimPrefix(rel.Target, "/word/") == target {
+			relID = rel.ID
+			break
+		}
+	}
+	if relID == "" {
+		return 0, 0
+	}
+
+	doc, err := os.ReadFile(filepath.Join(u.tempDir, "word", "document.xml"))
+	if err != nil {
+		return 0, 0
+	}
+	content := string(doc)
+	ref := strings.Index(content, `r:id="`+relID+`"`)
+	if ref == -1 {
+		return 0, 0
+	}
+	start := strings.LastIndex(content[:ref], "<wp:extent")
+	if start == -1 {
+		return 0, 0
+	}
+	m := chartExtentRe.FindStringSubmatch(content[start:ref])
+	if m == nil {
+		return 0, 0
+	}
+	return atoiOrZero(m[1]), atoiOrZero(m[2])
+}
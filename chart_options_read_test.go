@@ -0,0 +1,252 @@
+package godocx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetChartOptions(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Results:</w:t></w:r></w:p>`))
+	minVal, maxVal, unit, intercept := 0.0, 200.0, 50.0, 10.0
+	if err := u.InsertChart(ChartOptions{
+		Position:   PositionEnd,
+		ChartKind:  ChartKindBar,
+		Title:      "Revenue and margin",
+		Categories: []string{"Q1", "Q2", "Q3"},
+		Series: []SeriesOptions{
+			{
+				Name: "Revenue", Values: []float64{120, 150, 170}, Color: "1F77B4", InvertIfNegative: true,
+				Line:       &LineOptions{Color: "000000", Width: 1.5, Dash: LineDashDash},
+				Points:     []DataPointOptions{{Index: 1, Color: "FF0000"}},
+				Trendlines: []TrendlineOptions{{Type: TrendlinePolynomial, Order: 3, Intercept: &intercept, DisplayEquation: true}},
+				ErrorBars:  []ErrorBarOptions{{ValueType: ErrorBarCustom, Plus: []float64{1, 2, 3}, Minus: []float64{3, 2, 1}}},
+			},
+			{
+				Name: "Margin", Values: []float64{0.21, 0.24, 0.27}, ChartKind: ChartKindLine, SecondaryAxis: true,
+				Marker:     &MarkerOptions{Symbol: MarkerSymbolDiamond, Size: 7, Color: "00FF00"},
+				DataLabels: &DataLabelOptions{ShowValue: true, Position: DataLabelOutsideEnd},
+			},
+			{
+				Name: "Costs", Values: []float64{95, 114, 124},
+				Fill: &FillOptions{Gradient: &GradientFill{Stops: []GradientStop{{Position: 0, Color: "FFFFFF"}, {Position: 100, Color: "2CA02C"}}, Angle: 90}},
+			},
+		},
+		CategoryAxis:       &AxisOptions{Title: "Quarter", TickLabelPos: TickLabelLow},
+		ValueAxis:          &AxisOptions{Title: "USD", Min: &minVal, Max: &maxVal, MajorUnit: &unit, NumberFormat: "#,##0", MinorGridlines: true},
+		SecondaryValueAxis: &AxisOptions{Title: "Margin", NumberFormat: "0%"},
+		Legend:             &LegendOptions{Show: true, Position: "b"},
+		DataLabels:         &DataLabelOptions{ShowValue: true, ShowSeriesName: true},
+		Properties:         &ChartProperties{Style: ChartStyleColorful, Language: "en-GB", DisplayBlanksAs: "zero"},
+		BarChartOptions:    &BarChartOptions{Grouping: BarGroupingStacked, GapWidth: 80, Overlap: 100},
+		Width:              5000000,
+		Height:             3000000,
+	}); err != nil {
+		t.Fatalf("InsertChart: %v", err)
+	}
+
+	opts, err := u.GetChartOptions(1)
+	if err != nil {
+		t.Fatalf("GetChartOptions: %v", err)
+	}
+	if opts.ChartKind != ChartKindBar || opts.Title != "Revenue and margin" || opts.Width != 5000000 || opts.Height != 3000000 {
+		t.Errorf("unexpected chart: kind %q, title %q, size %dx%d", opts.ChartKind, opts.Title, opts.Width, opts.Height)
+	}
+	if !reflect.DeepEqual(opts.Categories, []string{"Q1", "Q2", "Q3"}) || len(opts.Series) != 3 {
+		t.Fatalf("unexpected data: %v, %d series", opts.Categories, len(opts.Series))
+	}
+	if want := (BarChartOptions{Direction: BarDirectionBar, Grouping: BarGroupingStacked, GapWidth: 80, Overlap: 100}); opts.BarChartOptions == nil || *opts.BarChartOptions != want {
+		t.Errorf("unexpected bar options %+v", opts.BarChartOptions)
+	}
+	if want := (ChartProperties{Style: ChartStyleColorful, Language: "en-GB", PlotVisibleOnly: true, DisplayBlanksAs: "zero"}); *opts.Properties != want {
+		t.Errorf("unexpected properties %+v", *opts.Properties)
+	}
+	if want := (LegendOptions{Show: true, Position: "b"}); *opts.Legend != want {
+		t.Errorf("unexpected legend %+v", *opts.Legend)
+	}
+	if want := (DataLabelOptions{ShowValue: true, ShowSeriesName: true, Position: DataLabelBestFit}); opts.DataLabels == nil || *opts.DataLabels != want {
+		t.Errorf("unexpected data labels %+v", opts.DataLabels)
+	}
+
+	valAxis := opts.ValueAxis
+	if valAxis == nil || valAxis.Title != "USD" || *valAxis.Min != 0 || *valAxis.Max != 200 || *valAxis.MajorUnit != 50 ||
+		valAxis.NumberFormat != "#,##0" || !valAxis.MinorGridlines || !valAxis.Visible || valAxis.Position != AxisPositionLeft {
+		t.Errorf("unexpected value axis %+v", valAxis)
+	}
+	if opts.CategoryAxis == nil || opts.CategoryAxis.Title != "Quarter" || opts.CategoryAxis.TickLabelPos != TickLabelLow {
+		t.Errorf("unexpected category axis %+v", opts.CategoryAxis)
+	}
+	if opts.SecondaryValueAxis == nil || opts.SecondaryValueAxis.NumberFormat != "0%" || opts.SecondaryValueAxis.Position != AxisPositionRight {
+		t.Errorf("unexpected secondary value axis %+v", opts.SecondaryValueAxis)
+	}
+	if opts.SecondaryCategoryAxis != nil {
+		t.Errorf("expected the hidden secondary category axis to be left nil, got %+v", opts.SecondaryCategoryAxis)
+	}
+
+	revenue, margin, costs := opts.Series[0], opts.Series[1], opts.Series[2]
+	if revenue.Color != "1F77B4" || !revenue.InvertIfNegative || revenue.ChartKind != "" || revenue.SecondaryAxis {
+		t.Errorf("unexpected revenue series %+v", revenue)
+	}
+	if want := (LineOptions{Color: "000000", Width: 1.5, Dash: LineDashDash}); revenue.Line == nil || *revenue.Line != want {
+		t.Errorf("unexpected revenue line %+v", revenue.Line)
+	}
+	if len(revenue.Points) != 1 || revenue.Points[0].Index != 1 || revenue.Points[0].Color != "FF0000" {
+		t.Errorf("unexpected revenue points %+v", revenue.Points)
+	}
+	if len(revenue.Trendlines) != 1 || revenue.Trendlines[0].Type != TrendlinePolynomial || revenue.Trendlines[0].Order != 3 ||
+		*revenue.Trendlines[0].Intercept != 10 || !revenue.Trendlines[0].DisplayEquation {
+		t.Errorf("unexpected revenue trendlines %+v", revenue.Trendlines)
+	}
+	if len(revenue.ErrorBars) != 1 || revenue.ErrorBars[0].ValueType != ErrorBarCustom ||
+		!reflect.DeepEqual(revenue.ErrorBars[0].Plus, []float64{1, 2, 3}) || !reflect.DeepEqual(revenue.ErrorBars[0].Minus, []float64{3, 2, 1}) {
+		t.Errorf("unexpected revenue error bars %+v", revenue.ErrorBars)
+	}
+	if margin.ChartKind != ChartKindLine || !margin.SecondaryAxis || !reflect.DeepEqual(margin.Values, []float64{0.21, 0.24, 0.27}) {
+		t.Errorf("unexpected margin series %+v", margin)
+	}
+	if want := (MarkerOptions{Symbol: MarkerSymbolDiamond, Size: 7, Color: "00FF00"}); margin.Marker == nil || *margin.Marker != want || !margin.ShowMarkers {
+		t.Errorf("unexpected margin marker %+v", margin.Marker)
+	}
+	if margin.DataLabels == nil || !margin.DataLabels.ShowValue || margin.DataLabels.Position != DataLabelOutsideEnd {
+		t.Errorf("unexpected margin data labels %+v", margin.DataLabels)
+	}
+	if costs.Fill == nil || costs.Fill.Gradient == nil || costs.Fill.Gradient.Angle != 90 ||
+		!reflect.DeepEqual(costs.Fill.Gradient.Stops, []GradientStop{{Position: 0, Color: "FFFFFF"}, {Position: 100, Color: "2CA02C"}}) {
+		t.Errorf("unexpected costs fill %+v", costs.Fill)
+	}
+
+	// The options read back recreate the same chart
+	opts.Position = PositionEnd
+	if err := u.InsertChart(opts); err != nil {
+		t.Fatalf("InsertChart from read options: %v", err)
+	}
+	original := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+	recreated := readWordPart(t, u, filepath.Join("charts", "chart2.xml"))
+	if original != recreated {
+		t.Errorf("recreated chart differs:\n%s\n%s", original, recreated)
+	}
+}
+
+func TestGetChartOptions_Kinds(t *testing.T) {
+	categories := []string{"1", "2", "3"}
+	tests := []ChartOptions{
+		{ChartKind: ChartKindColumn, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}}}},
+		{ChartKind: ChartKindLine, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}, Smooth: true, ShowMarkers: true}}},
+		{ChartKind: ChartKindArea, ShowLegend: true, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}}, {Name: "B", Values: []float64{3, 2, 1}}}},
+		{ChartKind: ChartKindPie, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}, Points: []DataPointOptions{{Index: 0, Color: "FF0000", Explosion: 10}}}}},
+		{ChartKind: ChartKindScatter, ScatterChartOptions: &ScatterChartOptions{ScatterStyle: "lineMarker"},
+			Series: []SeriesOptions{{Name: "A", Values: []float64{1, 4, 9}, XValues: []float64{1, 2, 3}, ErrorBars: []ErrorBarOptions{{Direction: ErrorBarDirectionX, ValueType: ErrorBarPercentage, Value: 5}}}}},
+		{ChartKind: ChartKindDoughnut, DoughnutChartOptions: &DoughnutChartOptions{HoleSize: 60, FirstSliceAngle: 45}, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}}}},
+		{ChartKind: ChartKindRadar, RadarChartOptions: &RadarChartOptions{Style: RadarStyleMarker}, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}}}},
+		{ChartKind: ChartKindBubble, BubbleChartOptions: &BubbleChartOptions{Scale: 150, ShowNegative: true},
+			Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}, XValues: []float64{4, 5, 6}, BubbleSizes: []float64{7, 8, 9}}}},
+		{ChartKind: ChartKindStock, StockChartOptions: &StockChartOptions{UpBarColor: "00FF00", DownBarColor: "FF0000"}, Series: []SeriesOptions{
+			{Name: "Open", Values: []float64{10, 11, 12}}, {Name: "High", Values: []float64{13, 14, 15}},
+			{Name: "Low", Values: []float64{8, 9, 10}}, {Name: "Close", Values: []float64{11, 12, 13}},
+		}},
+		{ChartKind: ChartKindColumn3D, View3D: &View3DOptions{RotX: 20, RotY: 40, Perspective: 45}, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}}}},
+		{ChartKind: ChartKindPie3D, Series: []SeriesOptions{{Name: "A", Values: []float64{1, 2, 3}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.ChartKind), func(t *testing.T) {
+			u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Chart:</w:t></w:r></w:p>`))
+			tt.Position = PositionEnd
+			tt.Categories = categories
+			if err := u.InsertChart(tt); err != nil {
+				t.Fatalf("InsertChart: %v", err)
+			}
+			opts, err := u.GetChartOptions(1)
+			if err != nil {
+				t.Fatalf("GetChartOptions: %v", err)
+			}
+			if opts.ChartKind != tt.ChartKind || len(opts.Series) != len(tt.Series) {
+				t.Fatalf("read kind %q with %d series", opts.ChartKind, len(opts.Series))
+			}
+			for i, series := range tt.Series {
+				if !reflect.DeepEqual(opts.Series[i].Values, series.Values) || opts.Series[i].Name != series.Name {
+					t.Errorf("series[%d]: read %q %v", i, opts.Series[i].Name, opts.Series[i].Values)
+				}
+			}
+
+			opts.Position = PositionEnd
+			if err := u.InsertChart(opts); err != nil {
+				t.Fatalf("InsertChart from read options: %v", err)
+			}
+			original := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+			recreated := readWordPart(t, u, filepath.Join("charts", "chart2.xml"))
+			if original != recreated {
+				t.Errorf("recreated chart differs:\n%s\n%s", original, recreated)
+			}
+		})
+	}
+}
+
+func TestGetChartOptions_UnprefixedTemplate(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p/>`))
+	chartsDir := filepath.Join(u.TempDir(), "word", "charts")
+	if err := os.MkdirAll(chartsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	chart := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<chartSpace xmlns="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"
+ xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" xmlns:c14="http://schemas.microsoft.com/office/drawing/2007/8/2/chart">
+<roundedCorners val="0"/>
+<mc:AlternateContent><mc:Choice Requires="c14"><c14:style val="102"/></mc:Choice><mc:Fallback><style val="2"/></mc:Fallback></mc:AlternateContent>
+<chart><title><tx><rich><a:bodyPr/><a:p><a:r><a:t>Sales </a:t></a:r><a:r><a:t>2024</a:t></a:r></a:p></rich></tx><overlay val="0"/></title>
+<plotArea><layout/>
+<lineChart><grouping val="standard"/><varyColors val="0"/>
+<ser><idx val="0"/><order val="0"/><tx><v>North</v></tx>
+<spPr><a:ln w="28575"><a:solidFill><a:srgbClr val="4472C4"/></a:solidFill></a:ln></spPr>
+<marker><symbol val="none"/></marker>
+<cat><multiLvlStrRef><f>Sheet1!$A$2:$B$3</f><multiLvlStrCache><ptCount val="2"/>
+<lvl><pt idx="0"><v>Jan</v></pt><pt idx="1"><v>Feb</v></pt></lvl><lvl><pt idx="0"><v>2024</v></pt></lvl>
+</multiLvlStrCache></multiLvlStrRef></cat>
+<val><numRef><f>Sheet1!$C$2:$C$3</f><numCache><formatCode>General</formatCode><ptCount val="2"/><pt idx="1"><v>7.5</v></pt></numCache></numRef></val>
+<smooth val="0"/></ser>
+<marker val="1"/><axId val="10"/><axId val="20"/></lineChart>
+<dateAx><axId val="10"/><scaling><orientation val="minMax"/></scaling><delete/><axPos val="b"/><numFmt formatCode="mmm" sourceLinked="1"/><crossAx val="20"/></dateAx>
+<valAx><axId val="20"/><scaling><orientation val="minMax"/><max val="10"/></scaling><delete val="0"/><axPos val="l"/><majorGridlines/><crossAx val="10"/><crossesAt val="1"/></valAx>
+</plotArea><plotVisOnly val="1"/></chart></chartSpace>`
+	if err := os.WriteFile(filepath.Join(chartsDir, "chart1.xml"), []byte(chart), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := u.GetChartOptions(1)
+	if err != nil {
+		t.Fatalf("GetChartOptions: %v", err)
+	}
+	if opts.ChartKind != ChartKindLine || opts.Title != "Sales 2024" || opts.Properties.Style != ChartStyle2 {
+		t.Errorf("unexpected chart: kind %q, title %q, style %d", opts.ChartKind, opts.Title, opts.Properties.Style)
+	}
+	if opts.Width != 0 || opts.Height != 0 || opts.Legend.Show {
+		t.Errorf("expected no size and no legend, got %dx%d, %+v", opts.Width, opts.Height, *opts.Legend)
+	}
+	if !reflect.DeepEqual(opts.Categories, []string{"Jan", "Feb"}) || !reflect.DeepEqual(opts.Series[0].Values, []float64{0, 7.5}) {
+		t.Errorf("unexpected data %v %v", opts.Categories, opts.Series[0].Values)
+	}
+	if s := opts.Series[0]; s.Name != "North" || s.Marker != nil || s.ShowMarkers || s.Line == nil || s.Line.Width != 2.25 || s.Line.Color != "4472C4" {
+		t.Errorf("unexpected series %+v", s)
+	}
+	if ax := opts.CategoryAxis; ax == nil || ax.Visible || ax.NumberFormat != "mmm" {
+		t.Errorf("unexpected date axis %+v", ax)
+	}
+	if ax := opts.ValueAxis; ax == nil || ax.Min != nil || *ax.Max != 10 || *ax.CrossesAt != 1 || !ax.MajorGridlines {
+		t.Errorf("unexpected value axis %+v", ax)
+	}
+}
+
+func TestGetChartOptions_Errors(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p/>`))
+	if _, err := u.GetChartOptions(0); err == nil {
+		t.Error("expected an error for chart index 0")
+	}
+	if _, err := u.GetChartOptions(1); err == nil {
+		t.Error("expected an error for a missing chart")
+	}
+	var nilUpdater *Updater
+	if _, err := nilUpdater.GetChartOptions(1); err == nil {
+		t.Error("expected an error for a nil updater")
+	}
+}
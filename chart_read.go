@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -57,11 +58,25 @@ func parseChartDataFromXML(raw []byte) (ChartData, error) {
 
 	// Series blocks
 	serBlocks := extractBlocks(content, "<"+tag("ser")+">", "<"+tag("ser")+" ", "</"+tag("ser")+">")
+	// Combo charts spread the series over several chart groups; read them
+	// in index order
+	idxRe := regexp.MustCompile(`<` + regexp.QuoteMeta(tag("idx")) + ` val="(\d+)"`)
+	serIndex := func(block string) int {
+		if m := idxRe.FindStringSubmatch(block); m != nil {
+			return atoiOrZero(m[1])
+		}
+		return 0
+	}
+	sort.SliceStable(serBlocks, func(a, b int) bool { return serIndex(serBlocks[a]) < serIndex(serBlocks[b]) })
 	data.Categories = extractCategoriesFromSer(serBlocks, tag, vRe)
 	for _, block := range serBlocks {
 		name := extractSeriesName(block, tag, vRe)
 		values := extractSeriesValues(block, tag, len(data.Categories), vRe)
-		data.Series = append(data.Series, SeriesData{Name: name, Values: values})
+		bubbleSizes := extractNumericValuesFromDataRef(block, tag, "bubbleSize", vRe)
+		if len(bubbleSizes) == 0 {
+			bubbleSizes = nil
+		}
+		data.Series = append(data.Series, SeriesData{Name: name, Values: values, BubbleSizes: bubbleSizes})
 	}
 
 	return data, nil
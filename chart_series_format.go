@@ -0,0 +1,669 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// MarkerSymbol defines the symbol drawn at each data point
+type MarkerSymbol string
+
+const (
+	MarkerSymbolAuto     MarkerSymbol = "auto"     // Chosen by the application
+	MarkerSymbolCircle   MarkerSymbol = "circle"   // Circle (default)
+	MarkerSymbolDash     MarkerSymbol = "dash"     // Short horizontal dash
+	MarkerSymbolDiamond  MarkerSymbol = "diamond"  // Diamond
+	MarkerSymbolDot      MarkerSymbol = "dot"      // Small dot
+	MarkerSymbolNone     MarkerSymbol = "none"     // No marker
+	MarkerSymbolPlus     MarkerSymbol = "plus"     // Plus sign
+	MarkerSymbolSquare   MarkerSymbol = "square"   // Square
+	MarkerSymbolStar     MarkerSymbol = "star"     // Star
+	MarkerSymbolTriangle MarkerSymbol = "triangle" // Triangle
+	MarkerSymbolX        MarkerSymbol = "x"        // Cross
+)
+
+// LineDash defines the dash style of a line
+type LineDash string
+
+const (
+	LineDashSolid            LineDash = "solid"         // Solid (default)
+	LineDashDot              LineDash = "dot"           // Dots
+	LineDashDash             LineDash = "dash"          // Dashes
+	LineDashLongDash         LineDash = "lgDash"        // Long dashes
+	LineDashDashDot          LineDash = "dashDot"       // Dash, dot
+	LineDashLongDashDot      LineDash = "lgDashDot"     // Long dash, dot
+	LineDashLongDashDotDot   LineDash = "lgDashDotDot"  // Long dash, dot, dot
+	LineDashSystemDash       LineDash = "sysDash"       // Short dashes
+	LineDashSystemDot        LineDash = "sysDot"        // Round dots
+	LineDashSystemDashDot    LineDash = "sysDashDot"    // Short dash, dot
+	LineDashSystemDashDotDot LineDash = "sysDashDotDot" // Short dash, dot, dot
+)
+
+// TrendlineType defines the regression used by a trendline
+type TrendlineType string
+
+const (
+	TrendlineLinear        TrendlineType = "linear"    // Linear (default)
+	TrendlineExponential   TrendlineType = "exp"       // Exponential
+	TrendlineLogarithmic   TrendlineType = "log"       // Logarithmic
+	TrendlinePolynomial    TrendlineType = "poly"      // Polynomial of order Order
+	TrendlinePower         TrendlineType = "power"     // Power
+	TrendlineMovingAverage TrendlineType = "movingAvg" // Moving average over Period points
+)
+
+// ErrorBarDirection defines the axis along which error bars are drawn
+type ErrorBarDirection string
+
+const (
+	ErrorBarDirectionX ErrorBarDirection = "x" // Horizontal (scatter and bubble charts only)
+	ErrorBarDirectionY ErrorBarDirection = "y" // Vertical (default)
+)
+
+// ErrorBarType defines which side of the data point error bars extend to
+type ErrorBarType string
+
+const (
+	ErrorBarBoth  ErrorBarType = "both"  // Plus and minus (default)
+	ErrorBarPlus  ErrorBarType = "plus"  // Plus only
+	ErrorBarMinus ErrorBarType = "minus" // Minus only
+)
+
+// ErrorBarValueType defines how the error amount is computed
+type ErrorBarValueType string
+
+const (
+	ErrorBarFixed      ErrorBarValueType = "fixedVal"   // Fixed value (default)
+	ErrorBarPercentage ErrorBarValueType = "percentage" // Percentage of each value
+	ErrorBarStdDev     ErrorBarValueType = "stdDev"     // Multiple of the standard deviation
+	ErrorBarStdErr     ErrorBarValueType = "stdErr"     // Standard error
+	ErrorBarCustom     ErrorBarValueType = "cust"       // Custom plus/minus amounts per point
+)
+
+// LineOptions defines the line of a series, or the border of its shapes
+type LineOptions struct {
+	Color string   // Hex color (e.g., "FF0000") (default: automatic)
+	Width float64  // Width in points (0-1584, 0 for default)
+	Dash  LineDash // Dash style (default: solid)
+	None  bool     // Hide the line (default: false)
+}
+
+// GradientStop is one color stop of a gradient fill
+type GradientStop struct {
+	Position float64 // Position along the gradient in percent (0-100)
+	Color    string  // Hex color (e.g., "FF0000")
+}
+
+// GradientFill defines a linear gradient fill
+type GradientFill struct {
+	Stops []GradientStop // At least two color stops
+	Angle float64        // Direction in degrees, clockwise from left to right (0-359)
+}
+
+// PatternFill defines a preset pattern fill
+type PatternFill struct {
+	Pattern    string // Preset pattern (e.g., "pct50", "dkDnDiag", "smCheck")
+	Foreground string // Hex color of the pattern (default: "000000")
+	Background string // Hex color behind the pattern (default: "FFFFFF")
+}
+
+// FillOptions defines a gradient or pattern fill. Set one of them; a solid
+// fill is set with the Color field of the series or data point.
+type FillOptions struct {
+	Gradient *GradientFill // Linear gradient fill
+	Pattern  *PatternFill  // Preset pattern fill
+}
+
+// MarkerOptions defines the marker drawn at each data point of line, radar
+// and scatter charts
+type MarkerOptions struct {
+	Symbol MarkerSymbol // Marker symbol (default: circle)
+	Size   int          // Size in points (2-72, 0 for default)
+	Color  string       // Hex fill color (default: automatic)
+	Line   *LineOptions // Marker border (nil for default)
+}
+
+// DataPointOptions overrides the formatting of one data point, such as a pie
+// slice or a highlighted bar
+type DataPointOptions struct {
+	Index     int            // Zero-based index of the data point
+	Color     string         // Hex fill color (e.g., "FF0000")
+	Fill      *FillOptions   // Gradient or pattern fill (overrides Color)
+	Line      *LineOptions   // Line or border (nil for default)
+	Marker    *MarkerOptions // Marker (line, radar and scatter charts)
+	Explosion int            // Distance the slice is pulled out in percent of the radius (pie and doughnut charts)
+}
+
+// TrendlineOptions defines a trendline fitted to the values of a series
+type TrendlineOptions struct {
+	Type            TrendlineType // Regression type (default: linear)
+	Name            string        // Name shown in the legend (default: generated by the application)
+	Order           int           // Polynomial order (2-6, default: 2)
+	Period          int           // Moving average period (2 to the number of values, default: 2)
+	Forward         float64       // Forecast forward, in category units
+	Backward        float64       // Forecast backward, in category units
+	Intercept       *float64      // Force the Y intercept (linear, exponential and polynomial only)
+	DisplayRSquared bool          // Show the R-squared value on the chart
+	DisplayEquation bool          // Show the equation on the chart
+	Line            *LineOptions  // Trendline line (nil for default)
+}
+
+// ErrorBarOptions defines error bars drawn at each data point of a series
+type ErrorBarOptions struct {
+	Direction ErrorBarDirection // Direction (default: y)
+	Type      ErrorBarType      // Plus, minus or both (default: both)
+	ValueType ErrorBarValueType // How the error amount is computed (default: fixed)
+	Value     float64           // Fixed amount, percentage or number of standard deviations
+	Plus      []float64         // Custom plus amounts, one per value
+	Minus     []float64         // Custom minus amounts, one per value
+	NoEndCap  bool              // Omit the end caps (default: false)
+	Line      *LineOptions      // Error bar line (nil for default)
+}
+
+// chartKindHasTrendlines reports whether series of the kind take trendlines
+// and error bars
+func chartKindHasTrendlines(kind ChartKind) bool {
+	switch kind {
+	case ChartKindColumn, ChartKindBar, ChartKindLine, ChartKindArea, ChartKindScatter, ChartKindBubble:
+		return true
+	}
+	return false
+}
+
+// validateSeriesFormat validates the formatting options of a series plotted
+// as the given kind
+func validateSeriesFormat(i int, series SeriesOptions, kind ChartKind) error {
+	prefix := fmt.Sprintf("series[%d]", i)
+	if err := validateChartColor(prefix+".Color", series.Color); err != nil {
+		return err
+	}
+	if err := validateFillOptions(prefix, series.Fill); err != nil {
+		return err
+	}
+	if err := validateLineOptions(prefix+".Line", series.Line); err != nil {
+		return err
+	}
+	if series.Marker != nil {
+		if !chartKindHasMarkers(kind) && kind != ChartKindScatter {
+			return fmt.Errorf("%s: markers are not supported by %q charts", prefix, kind)
+		}
+		if err := validateMarkerOptions(prefix+".Marker", series.Marker); err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[int]bool)
+	for j, point := range series.Points {
+		name := fmt.Sprintf("%s.Points[%d]", prefix, j)
+		if point.Index < 0 || point.Index >= len(series.Values) {
+			return fmt.Errorf("%s: index %d is out of range", name, point.Index)
+		}
+		if seen[point.Index] {
+			return fmt.Errorf("%s: data point %d is formatted more than once", name, point.Index)
+		}
+		seen[point.Index] = true
+		if point.Explosion != 0 {
+			if kind != ChartKindPie && kind != ChartKindPie3D && kind != ChartKindDoughnut {
+				return fmt.Errorf("%s: explosion is only supported by pie and doughnut charts", name)
+			}
+			if point.Explosion < 0 || point.Explosion > 400 {
+				return fmt.Errorf("%s: explosion must be between 0 and 400", name)
+			}
+		}
+		if err := validateChartColor(name+".Color", point.Color); err != nil {
+			return err
+		}
+		if err := validateFillOptions(name, point.Fill); err != nil {
+			return err
+		}
+		if err := validateLineOptions(name+".Line", point.Line); err != nil {
+			return err
+		}
+		if point.Marker != nil {
+			if !chartKindHasMarkers(kind) && kind != ChartKindScatter {
+				return fmt.Errorf("%s: markers are not supported by %q charts", name, kind)
+			}
+			if err := validateMarkerOptions(name+".Marker", point.Marker); err != nil {
+				return err
+			}
+		}
+	}
+
+	if (len(series.Trendlines) > 0 || len(series.ErrorBars) > 0) && !chartKindHasTrendlines(kind) {
+		return fmt.Errorf("%s: trendlines and error bars are not supported by %q charts", prefix, kind)
+	}
+	for j, trendline := range series.Trendlines {
+		if err := validateTrendline(fmt.Sprintf("%s.Trendlines[%d]", prefix, j), trendline, len(series.Values)); err != nil {
+			return err
+		}
+	}
+	directions := make(map[ErrorBarDirection]bool)
+	for j, errBars := range series.ErrorBars {
+		name := fmt.Sprintf("%s.ErrorBars[%d]", prefix, j)
+		direction := errBars.Direction
+		switch direction {
+		case "", ErrorBarDirectionY:
+			direction = ErrorBarDirectionY
+		case ErrorBarDirectionX:
+			if kind != ChartKindScatter && kind != ChartKindBubble {
+				return fmt.Errorf("%s: X error bars are only supported by scatter and bubble charts", name)
+			}
+		default:
+			return fmt.Errorf("%s: direction %q is not supported", name, errBars.Direction)
+		}
+		if directions[direction] {
+			return fmt.Errorf("%s: only one set of error bars per direction is allowed", name)
+		}
+		directions[direction] = true
+		if err := validateErrorBars(name, errBars, len(series.Values)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTrendline validates a trendline of a series with count values
+func validateTrendline(name string, trendline TrendlineOptions, count int) error {
+	switch trendline.Type {
+	case "", TrendlineLinear, TrendlineExponential, TrendlineLogarithmic, TrendlinePower:
+	case TrendlinePolynomial:
+		if trendline.Order != 0 && (trendline.Order < 2 || trendline.Order > 6) {
+			return fmt.Errorf("%s: polynomial order must be between 2 and 6", name)
+		}
+	case TrendlineMovingAverage:
+		if trendline.Period != 0 && (trendline.Period < 2 || trendline.Period > count) {
+			return fmt.Errorf("%s: moving average period must be between 2 and the number of values (%d)", name, count)
+		}
+		if trendline.DisplayRSquared || trendline.DisplayEquation {
+			return fmt.Errorf("%s: moving averages cannot display the R-squared value or equation", name)
+		}
+		if trendline.Forward != 0 || trendline.Backward != 0 {
+			return fmt.Errorf("%s: moving averages cannot forecast", name)
+		}
+	default:
+		return fmt.Errorf("%s: type %q is not supported", name, trendline.Type)
+	}
+	if trendline.Forward < 0 || trendline.Backward < 0 {
+		return fmt.Errorf("%s: forecast periods cannot be negative", name)
+	}
+	if trendline.Intercept != nil {
+		switch trendline.Type {
+		case "", TrendlineLinear, TrendlineExponential, TrendlinePolynomial:
+		default:
+			return fmt.Errorf("%s: intercept is not supported by %q trendlines", name, trendline.Type)
+		}
+		if trendline.Type == TrendlineExponential && *trendline.Intercept <= 0 {
+			return fmt.Errorf("%s: exponential intercept must be positive", name)
+		}
+	}
+	return validateLineOptions(name+".Line", trendline.Line)
+}
+
+// validateErrorBars validates error bars of a series with count values
+func validateErrorBars(name string, errBars ErrorBarOptions, count int) error {
+	switch errBars.Type {
+	case "", ErrorBarBoth, ErrorBarPlus, ErrorBarMinus:
+	default:
+		return fmt.Errorf("%s: type %q is not supported", name, errBars.Type)
+	}
+	switch errBars.ValueType {
+	case "", ErrorBarFixed, ErrorBarPercentage, ErrorBarStdDev, ErrorBarStdErr:
+		if len(errBars.Plus) > 0 || len(errBars.Minus) > 0 {
+			return fmt.Errorf("%s: plus and minus amounts require the custom value type", name)
+		}
+		if errBars.Value < 0 {
+			return fmt.Errorf("%s: value cannot be negative", name)
+		}
+	case ErrorBarCustom:
+		if errBars.Type != ErrorBarMinus && len(errBars.Plus) != count {
+			return fmt.Errorf("%s: plus amounts length (%d) must match values length (%d)", name, len(errBars.Plus), count)
+		}
+		if errBars.Type != ErrorBarPlus && len(errBars.Minus) != count {
+			return fmt.Errorf("%s: minus amounts length (%d) must match values length (%d)", name, len(errBars.Minus), count)
+		}
+	default:
+		return fmt.Errorf("%s: value type %q is not supported", name, errBars.ValueType)
+	}
+	return validateLineOptions(name+".Line", errBars.Line)
+}
+
+// validateMarkerOptions validates marker options
+func validateMarkerOptions(name string, marker *MarkerOptions) error {
+	switch marker.Symbol {
+	case "", MarkerSymbolAuto, MarkerSymbolCircle, MarkerSymbolDash, MarkerSymbolDiamond, MarkerSymbolDot,
+		MarkerSymbolNone, MarkerSymbolPlus, MarkerSymbolSquare, MarkerSymbolStar, MarkerSymbolTriangle, MarkerSymbolX:
+	default:
+		return fmt.Errorf("%s: symbol %q is not supported", name, marker.Symbol)
+	}
+	if marker.Size != 0 && (marker.Size < 2 || marker.Size > 72) {
+		return fmt.Errorf("%s: size must be between 2 and 72", name)
+	}
+	if err := validateChartColor(name+".Color", marker.Color); err != nil {
+		return err
+	}
+	return validateLineOptions(name+".Line", marker.Line)
+}
+
+// validateLineOptions validates line options
+func validateLineOptions(name string, line *LineOptions) error {
+	if line == nil {
+		return nil
+	}
+	if line.Width < 0 || line.Width > 1584 {
+		return fmt.Errorf("%s: width must be between 0 and 1584 points", name)
+	}
+	if err := validateChartColor(name+".Color", line.Color); err != nil {
+		return err
+	}
+	switch line.Dash {
+	case "", LineDashSolid, LineDashDot, LineDashDash, LineDashLongDash, LineDashDashDot, LineDashLongDashDot,
+		LineDashLongDashDotDot, LineDashSystemDash, LineDashSystemDot, LineDashSystemDashDot, LineDashSystemDashDotDot:
+	default:
+		return fmt.Errorf("%s: dash style %q is not supported", name, line.Dash)
+	}
+	return nil
+}
+
+// validateFillOptions validates fill options
+func validateFillOptions(name string, fill *FillOptions) error {
+	if fill == nil {
+		return nil
+	}
+	if fill.Gradient != nil && fill.Pattern != nil {
+		return fmt.Errorf("%s: set either a gradient or a pattern fill", name)
+	}
+	if gradient := fill.Gradient; gradient != nil {
+		if len(gradient.Stops) < 2 {
+			return fmt.Errorf("%s: gradient fill needs at least two stops", name)
+		}
+		for _, stop := range gradient.Stops {
+			if stop.Position < 0 || stop.Position > 100 {
+				return fmt.Errorf("%s: gradient stop positions must be between 0 and 100", name)
+			}
+			if stop.Color == "" {
+				return fmt.Errorf("%s: gradient stops need a color", name)
+			}
+			if err := validateChartColor(name+".Gradient", stop.Color); err != nil {
+				return err
+			}
+		}
+		if gradient.Angle < 0 || gradient.Angle >= 360 {
+			return fmt.Errorf("%s: gradient angle must be between 0 and 359", name)
+		}
+	}
+	if pattern := fill.Pattern; pattern != nil {
+		if pattern.Pattern == "" {
+			return fmt.Errorf("%s: pattern fill needs a preset pattern", name)
+		}
+		if !presetPatterns[pattern.Pattern] {
+			return fmt.Errorf("%s: pattern %q is not supported", name, pattern.Pattern)
+		}
+		if err := validateChartColor(name+".Pattern.Foreground", pattern.Foreground); err != nil {
+			return err
+		}
+		if err := validateChartColor(name+".Pattern.Background", pattern.Background); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateChartColor checks that a color, if set, is a hex color
+func validateChartColor(name, color string) error {
+	if color != "" && normalizeHexColor(color) == "" {
+		return fmt.Errorf("%s: %q is not a hex color", name, color)
+	}
+	return nil
+}
+
+// presetPatterns lists the DrawingML preset patterns (ST_PresetPatternVal)
+var presetPatterns = map[string]bool{
+	"pct5": true, "pct10": true, "pct20": true, "pct25": true, "pct30": true, "pct40": true,
+	"pct50": true, "pct60": true, "pct70": true, "pct75": true, "pct80": true, "pct90": true,
+	"horz": true, "vert": true, "ltHorz": true, "ltVert": true, "dkHorz": true, "dkVert": true,
+	"narHorz": true, "narVert": true, "dashHorz": true, "dashVert": true, "cross": true,
+	"dnDiag": true, "upDiag": true, "ltDnDiag": true, "ltUpDiag": true, "dkDnDiag": true, "dkUpDiag": true,
+	"wdDnDiag": true, "wdUpDiag": true, "dashDnDiag": true, "dashUpDiag": true, "diagCross": true,
+	"smCheck": true, "lgCheck": true, "smGrid": true, "lgGrid": true, "dotGrid": true,
+	"smConfetti": true, "lgConfetti": true, "horzBrick": true, "diagBrick": true,
+	"solidDmnd": true, "openDmnd": true, "dotDmnd": true, "plaid": true, "sphere": true,
+	"weave": true, "divot": true, "shingle": true, "wave": true, "trellis": true, "zigZag": true,
+}
+
+// hasShapeProperties reports whether a color, fill or line is set
+func hasShapeProperties(color string, fill *FillOptions, line *LineOptions) bool {
+	return color != "" || fill != nil || line != nil
+}
+
+// generateShapePropertiesXML generates a c:spPr element with the given solid
+// color or fill, and line. It returns an empty string when none is set.
+func generateShapePropertiesXML(color string, fill *FillOptions, line *LineOptions) string {
+	if !hasShapeProperties(color, fill, line) {
+		return ""
+	}
+	return `<c:spPr>` + generateFillXML(color, fill) + generateLineXML(line) + `</c:spPr>`
+}
+
+// generateFillXML generates the DrawingML fill of a shape: the gradient or
+// pattern fill if set, else a solid fill of the color
+func generateFillXML(color string, fill *FillOptions) string {
+	var buf bytes.Buffer
+
+	switch {
+	case fill != nil && fill.Gradient != nil:
+		buf.WriteString(`<a:gradFill rotWithShape="1"><a:gsLst>`)
+		for _, stop := range fill.Gradient.Stops {
+			buf.WriteString(fmt.Sprintf(`<a:gs pos="%d"><a:srgbClr val="%s"/></a:gs>`,
+				int(stop.Position*1000), normalizeHexColor(stop.Color)))
+		}
+		buf.WriteString(`</a:gsLst>`)
+		buf.WriteString(fmt.Sprintf(`<a:lin ang="%d" scaled="0"/>`, int(fill.Gradient.Angle*60000)))
+		buf.WriteString(`</a:gradFill>`)
+	case fill != nil && fill.Pattern != nil:
+		fg, bg := fill.Pattern.Foreground, fill.Pattern.Background
+		if fg == "" {
+			fg = "000000"
+		}
+		if bg == "" {
+			bg = "FFFFFF"
+		}
+		buf.WriteString(fmt.Sprintf(`<a:pattFill prst="%s">`, xmlEscape(fill.Pattern.Pattern)))
+		buf.WriteString(fmt.Sprintf(`<a:fgClr><a:srgbClr val="%s"/></a:fgClr>`, normalizeHexColor(fg)))
+		buf.WriteString(fmt.Sprintf(`<a:bgClr><a:srgbClr val="%s"/></a:bgClr>`, normalizeHexColor(bg)))
+		buf.WriteString(`</a:pattFill>`)
+	case color != "":
+		buf.WriteString(fmt.Sprintf(`<a:solidFill><a:srgbClr val="%s"/></a:solidFill>`, normalizeHexColor(color)))
+	}
+
+	return buf.String()
+}
+
+// generateLineXML generates the DrawingML line of a shape. Widths are
+// given in points and written in EMUs.
+func generateLineXML(line *LineOptions) string {
+	if line == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+
+	if line.Width > 0 {
+		buf.WriteString(fmt.Sprintf(`<a:ln w="%d">`, int(line.Width*12700)))
+	} else {
+		buf.WriteString(`<a:ln>`)
+	}
+	if line.None {
+		buf.WriteString(`<a:noFill/>`)
+	} else if line.Color != "" {
+		buf.WriteString(fmt.Sprintf(`<a:solidFill><a:srgbClr val="%s"/></a:solidFill>`, normalizeHexColor(line.Color)))
+	}
+	if line.Dash != "" && !line.None {
+		buf.WriteString(fmt.Sprintf(`<a:prstDash val="%s"/>`, line.Dash))
+	}
+	buf.WriteString(`</a:ln>`)
+
+	return buf.String()
+}
+
+// generateMarkerXML generates a c:marker element
+func generateMarkerXML(marker *MarkerOptions) string {
+	var buf bytes.Buffer
+
+	symbol := marker.Symbol
+	if symbol == "" {
+		symbol = MarkerSymbolCircle
+	}
+	buf.WriteString(fmt.Sprintf(`<c:marker><c:symbol val="%s"/>`, symbol))
+	if marker.Size != 0 {
+		buf.WriteString(fmt.Sprintf(`<c:size val="%d"/>`, marker.Size))
+	}
+	buf.WriteString(generateShapePropertiesXML(marker.Color, nil, marker.Line))
+	buf.WriteString(`</c:marker>`)
+
+	return buf.String()
+}
+
+// generateSeriesExtrasXML generates the elements of a series between its
+// marker and its data, in c:ser child order: data points, data labels,
+// trendlines and error bars
+func generateSeriesExtrasXML(series SeriesOptions, kind ChartKind) string {
+	var buf bytes.Buffer
+
+	for _, point := range series.Points {
+		buf.WriteString(generateDataPointXML(point, kind))
+	}
+	if series.DataLabels != nil {
+		buf.WriteString(generateDataLabelsXML(series.DataLabels))
+	}
+	for _, trendline := range series.Trendlines {
+		buf.WriteString(generateTrendlineXML(trendline))
+	}
+	for _, errBars := range series.ErrorBars {
+		buf.WriteString(generateErrorBarsXML(errBars, kind))
+	}
+
+	return buf.String()
+}
+
+// generateDataPointXML generates a c:dPt element
+func generateDataPointXML(point DataPointOptions, kind ChartKind) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf(`<c:dPt><c:idx val="%d"/>`, point.Index))
+	if isBarChartKind(kind) || kind == ChartKindBubble {
+		buf.WriteString(`<c:invertIfNegative val="0"/>`)
+	}
+	if point.Marker != nil {
+		buf.WriteString(generateMarkerXML(point.Marker))
+	}
+	if kind == ChartKindBubble {
+		buf.WriteString(`<c:bubble3D val="0"/>`)
+	}
+	if point.Explosion != 0 {
+		buf.WriteString(fmt.Sprintf(`<c:explosion val="%d"/>`, point.Explosion))
+	}
+	buf.WriteString(generateShapePropertiesXML(point.Color, point.Fill, point.Line))
+	buf.WriteString(`</c:dPt>`)
+
+	return buf.String()
+}
+
+// generateTrendlineXML generates a c:trendline element
+func generateTrendlineXML(trendline TrendlineOptions) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<c:trendline>`)
+	if trendline.Name != "" {
+		buf.WriteString(fmt.Sprintf(`<c:name>%s</c:name>`, xmlEscape(trendline.Name)))
+	}
+	buf.WriteString(generateShapePropertiesXML("", nil, trendline.Line))
+
+	kind := trendline.Type
+	if kind == "" {
+		kind = TrendlineLinear
+	}
+	buf.WriteString(fmt.Sprintf(`<c:trendlineType val="%s"/>`, kind))
+	switch kind {
+	case TrendlinePolynomial:
+		order := trendline.Order
+		if order == 0 {
+			order = 2
+		}
+		buf.WriteString(fmt.Sprintf(`<c:order val="%d"/>`, order))
+	case TrendlineMovingAverage:
+		period := trendline.Period
+		if period == 0 {
+			period = 2
+		}
+		buf.WriteString(fmt.Sprintf(`<c:period val="%d"/>`, period))
+	}
+	if trendline.Forward != 0 {
+		buf.WriteString(fmt.Sprintf(`<c:forward val="%g"/>`, trendline.Forward))
+	}
+	if trendline.Backward != 0 {
+		buf.WriteString(fmt.Sprintf(`<c:backward val="%g"/>`, trendline.Backward))
+	}
+	if trendline.Intercept != nil {
+		buf.WriteString(fmt.Sprintf(`<c:intercept val="%g"/>`, *trendline.Intercept))
+	}
+	buf.WriteString(fmt.Sprintf(`<c:dispRSqr val="%d"/>`, boolToInt(trendline.DisplayRSquared)))
+	buf.WriteString(fmt.Sprintf(`<c:dispEq val="%d"/>`, boolToInt(trendline.DisplayEquation)))
+	if trendline.DisplayRSquared || trendline.DisplayEquation {
+		buf.WriteString(`<c:trendlineLbl><c:numFmt formatCode="General" sourceLinked="0"/></c:trendlineLbl>`)
+	}
+	buf.WriteString(`</c:trendline>`)
+
+	return buf.String()
+}
+
+// generateErrorBarsXML generates a c:errBars element. The direction is only
+// written for scatter and bubble charts, whose error bars can run along
+// either axis.
+func generateErrorBarsXML(errBars ErrorBarOptions, kind ChartKind) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<c:errBars>`)
+	if kind == ChartKindScatter || kind == ChartKindBubble {
+		direction := errBars.Direction
+		if direction == "" {
+			direction = ErrorBarDirectionY
+		}
+		buf.WriteString(fmt.Sprintf(`<c:errDir val="%s"/>`, direction))
+	}
+	barType := errBars.Type
+	if barType == "" {
+		barType = ErrorBarBoth
+	}
+	valueType := errBars.ValueType
+	if valueType == "" {
+		valueType = ErrorBarFixed
+	}
+	buf.WriteString(fmt.Sprintf(`<c:errBarType val="%s"/>`, barType))
+	buf.WriteString(fmt.Sprintf(`<c:errValType val="%s"/>`, valueType))
+	buf.WriteString(fmt.Sprintf(`<c:noEndCap val="%d"/>`, boolToInt(errBars.NoEndCap)))
+	if valueType == ErrorBarCustom {
+		if barType != ErrorBarMinus {
+			buf.WriteString(`<c:plus>` + generateNumberLiteralXML(errBars.Plus) + `</c:plus>`)
+		}
+		if barType != ErrorBarPlus {
+			buf.WriteString(`<c:minus>` + generateNumberLiteralXML(errBars.Minus) + `</c:minus>`)
+		}
+	} else if valueType != ErrorBarStdErr {
+		buf.WriteString(fmt.Sprintf(`<c:val val="%g"/>`, errBars.Value))
+	}
+	buf.WriteString(generateShapePropertiesXML("", nil, errBars.Line))
+	buf.WriteString(`</c:errBars>`)
+
+	return buf.String()
+}
+
+// generateNumberLiteralXML generates a c:numLit element holding the values
+func generateNumberLiteralXML(values []float64) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf(`<c:numLit><c:formatCode>General</c:formatCode><c:ptCount val="%d"/>`, len(values)))
+	for j, val := range values {
+		buf.WriteString(fmt.Sprintf(`<c:pt idx="%d"><c:v>%g</c:v></c:pt>`, j, val))
+	}
+	buf.WriteString(`</c:numLit>`)
+
+	return buf.String()
+}
@@ -0,0 +1,204 @@
+package godocx
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInsertChart_SeriesFormatting(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Results:</w:t></w:r></w:p>`))
+	intercept := 0.0
+	if err := u.InsertChart(ChartOptions{
+		Position:   PositionEnd,
+		ChartKind:  ChartKindColumn,
+		Title:      "Sales",
+		Categories: []string{"Jan", "Feb", "Mar", "Apr"},
+		Series: []SeriesOptions{
+			{
+				Name:   "Actual",
+				Values: []float64{10, 14, 13, 18},
+				Fill: &FillOptions{Gradient: &GradientFill{
+					Stops: []GradientStop{{Position: 0, Color: "4472C4"}, {Position: 100, Color: "#1F3864"}},
+					Angle: 90,
+				}},
+				Line:   &LineOptions{Color: "000000", Width: 0.75},
+				Points: []DataPointOptions{{Index: 3, Color: "C00000"}},
+				Trendlines: []TrendlineOptions{
+					{Type: TrendlinePolynomial, Order: 3, Forward: 1, DisplayRSquared: true, DisplayEquation: true},
+					{Type: TrendlineLinear, Name: "Fit", Intercept: &intercept, Line: &LineOptions{Dash: LineDashSystemDash}},
+				},
+				ErrorBars: []ErrorBarOptions{{ValueType: ErrorBarCustom, Plus: []float64{1, 2, 1, 2}, Minus: []float64{0.5, 1, 0.5, 1}}},
+			},
+			{
+				Name:       "Target",
+				Values:     []float64{12, 12, 15, 15},
+				ChartKind:  ChartKindLine,
+				Marker:     &MarkerOptions{Symbol: MarkerSymbolDiamond, Size: 9, Color: "FFC000"},
+				Line:       &LineOptions{Color: "ED7D31", Width: 2.25, Dash: LineDashDash},
+				Trendlines: []TrendlineOptions{{Type: TrendlineMovingAverage, Period: 2}},
+				ErrorBars:  []ErrorBarOptions{{Type: ErrorBarPlus, ValueType: ErrorBarPercentage, Value: 5, NoEndCap: true}},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("InsertChart: %v", err)
+	}
+
+	chart := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+	checkWellFormedXML(t, chart)
+	for _, want := range []string{
+		`<c:spPr><a:gradFill rotWithShape="1"><a:gsLst><a:gs pos="0"><a:srgbClr val="4472C4"/></a:gs><a:gs pos="100000"><a:srgbClr val="1F3864"/></a:gs></a:gsLst><a:lin ang="5400000" scaled="0"/></a:gradFill><a:ln w="9525"><a:solidFill><a:srgbClr val="000000"/></a:solidFill></a:ln></c:spPr>`,
+		`<c:dPt><c:idx val="3"/><c:invertIfNegative val="0"/><c:spPr><a:solidFill><a:srgbClr val="C00000"/></a:solidFill></c:spPr></c:dPt>`,
+		`<c:trendline><c:trendlineType val="poly"/><c:order val="3"/><c:forward val="1"/><c:dispRSqr val="1"/><c:dispEq val="1"/><c:trendlineLbl>`,
+		`<c:trendline><c:name>Fit</c:name><c:spPr><a:ln><a:prstDash val="sysDash"/></a:ln></c:spPr><c:trendlineType val="linear"/><c:intercept val="0"/><c:dispRSqr val="0"/><c:dispEq val="0"/></c:trendline>`,
+		`<c:errBars><c:errBarType val="both"/><c:errValType val="cust"/><c:noEndCap val="0"/><c:plus><c:numLit><c:formatCode>General</c:formatCode><c:ptCount val="4"/><c:pt idx="0"><c:v>1</c:v></c:pt>`,
+		`<c:minus><c:numLit><c:formatCode>General</c:formatCode><c:ptCount val="4"/><c:pt idx="0"><c:v>0.5</c:v></c:pt>`,
+		`</c:errBars><c:cat>`,
+		`<c:spPr><a:ln w="28575"><a:solidFill><a:srgbClr val="ED7D31"/></a:solidFill><a:prstDash val="dash"/></a:ln></c:spPr><c:marker><c:symbol val="diamond"/><c:size val="9"/><c:spPr><a:solidFill><a:srgbClr val="FFC000"/></a:solidFill></c:spPr></c:marker>`,
+		`<c:trendline><c:trendlineType val="movingAvg"/><c:period val="2"/><c:dispRSqr val="0"/><c:dispEq val="0"/></c:trendline>`,
+		`<c:errBars><c:errBarType val="plus"/><c:errValType val="percentage"/><c:noEndCap val="1"/><c:val val="5"/></c:errBars>`,
+	} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("expected %s in chart XML", want)
+		}
+	}
+
+	// Updating the data keeps the formatting of each series
+	if err := u.UpdateChart(1, ChartData{
+		Categories: []string{"Jan", "Feb", "Mar", "Apr", "May"},
+		Series: []SeriesData{
+			{Name: "Actual", Values: []float64{10, 14, 13, 18, 21}},
+			{Name: "Target", Values: []float64{12, 12, 15, 15, 18}},
+		},
+	}); err != nil {
+		t.Fatalf("UpdateChart: %v", err)
+	}
+	chart = readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+	checkWellFormedXML(t, chart)
+	for _, want := range []string{
+		`<c:dPt><c:idx val="3"/>`,
+		`<c:trendlineType val="poly"/>`,
+		`<c:errBars><c:errBarType val="plus"/><c:errValType val="percentage"/><c:noEndCap val="1"/><c:val val="5"/></c:errBars><c:cat>`,
+		`<c:marker><c:symbol val="diamond"/>`,
+		`<c:pt idx="4"><c:v>21</c:v></c:pt>`,
+	} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("expected %s in updated chart XML", want)
+		}
+	}
+	if strings.Count(chart, "<c:errBars>") != 2 || strings.Count(chart, "<c:trendline>") != 3 {
+		t.Errorf("expected the trendlines and error bars to be kept once, got %s", chart)
+	}
+}
+
+func TestInsertChart_PieSliceAndScatterErrorBars(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Results:</w:t></w:r></w:p>`))
+	if err := u.InsertChart(ChartOptions{
+		Position:   PositionEnd,
+		ChartKind:  ChartKindPie,
+		Categories: []string{"North", "South", "East"},
+		Series: []SeriesOptions{{
+			Name:   "Share",
+			Values: []float64{50, 30, 20},
+			Points: []DataPointOptions{
+				{Index: 0, Explosion: 15, Fill: &FillOptions{Pattern: &PatternFill{Pattern: "dkDnDiag", Foreground: "4472C4"}}},
+			},
+		}},
+	}); err != nil {
+		t.Fatalf("InsertChart pie: %v", err)
+	}
+	if err := u.InsertChart(ChartOptions{
+		Position:   PositionEnd,
+		ChartKind:  ChartKindScatter,
+		Categories: []string{"1", "2", "3"},
+		Series: []SeriesOptions{{
+			Name:       "Measurements",
+			Values:     []float64{2.1, 3.9, 6.2},
+			Marker:     &MarkerOptions{Symbol: MarkerSymbolTriangle},
+			Trendlines: []TrendlineOptions{{Type: TrendlineExponential, Backward: 0.5}},
+			ErrorBars: []ErrorBarOptions{
+				{Direction: ErrorBarDirectionX, ValueType: ErrorBarFixed, Value: 0.1},
+				{ValueType: ErrorBarStdDev, Value: 1},
+			},
+		}},
+	}); err != nil {
+		t.Fatalf("InsertChart scatter: %v", err)
+	}
+
+	pie := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+	checkWellFormedXML(t, pie)
+	want := `<c:dPt><c:idx val="0"/><c:explosion val="15"/><c:spPr><a:pattFill prst="dkDnDiag"><a:fgClr><a:srgbClr val="4472C4"/></a:fgClr><a:bgClr><a:srgbClr val="FFFFFF"/></a:bgClr></a:pattFill></c:spPr></c:dPt><c:cat>`
+	if !strings.Contains(pie, want) {
+		t.Errorf("expected %s in pie chart XML", want)
+	}
+
+	scatter := readWordPart(t, u, filepath.Join("charts", "chart2.xml"))
+	checkWellFormedXML(t, scatter)
+	for _, want := range []string{
+		`</c:tx><c:marker><c:symbol val="triangle"/></c:marker><c:trendline>`,
+		`<c:trendlineType val="exp"/><c:backward val="0.5"/>`,
+		`<c:errBars><c:errDir val="x"/><c:errBarType val="both"/><c:errValType val="fixedVal"/><c:noEndCap val="0"/><c:val val="0.1"/></c:errBars>`,
+		`<c:errBars><c:errDir val="y"/><c:errBarType val="both"/><c:errValType val="stdDev"/><c:noEndCap val="0"/><c:val val="1"/></c:errBars><c:xVal>`,
+	} {
+		if !strings.Contains(scatter, want) {
+			t.Errorf("expected %s in scatter chart XML", want)
+		}
+	}
+}
+
+func TestInsertChart_SeriesFormattingValidation(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Results:</w:t></w:r></w:p>`))
+	for name, series := range map[string]SeriesOptions{
+		"point index":       {Points: []DataPointOptions{{Index: 2}}},
+		"duplicate point":   {Points: []DataPointOptions{{Index: 0}, {Index: 0}}},
+		"column explosion":  {Points: []DataPointOptions{{Index: 0, Explosion: 10}}},
+		"column marker":     {Marker: &MarkerOptions{}},
+		"polynomial order":  {Trendlines: []TrendlineOptions{{Type: TrendlinePolynomial, Order: 7}}},
+		"moving period":     {Trendlines: []TrendlineOptions{{Type: TrendlineMovingAverage, Period: 3}}},
+		"moving equation":   {Trendlines: []TrendlineOptions{{Type: TrendlineMovingAverage, DisplayEquation: true}}},
+		"power intercept":   {Trendlines: []TrendlineOptions{{Type: TrendlinePower, Intercept: new(float64)}}},
+		"trendline type":    {Trendlines: []TrendlineOptions{{Type: "cubic"}}},
+		"x error bars":      {ErrorBars: []ErrorBarOptions{{Direction: ErrorBarDirectionX}}},
+		"two y error bars":  {ErrorBars: []ErrorBarOptions{{}, {Direction: ErrorBarDirectionY}}},
+		"custom length":     {ErrorBars: []ErrorBarOptions{{ValueType: ErrorBarCustom, Plus: []float64{1}}}},
+		"fixed with custom": {ErrorBars: []ErrorBarOptions{{Plus: []float64{1, 1}}}},
+		"line width":        {Line: &LineOptions{Width: 2000}},
+		"line dash":         {Line: &LineOptions{Dash: "wavy"}},
+		"gradient stops":    {Fill: &FillOptions{Gradient: &GradientFill{Stops: []GradientStop{{Color: "FF0000"}}}}},
+		"two fills":         {Fill: &FillOptions{Gradient: &GradientFill{}, Pattern: &PatternFill{Pattern: "pct5"}}},
+		"pattern preset":    {Fill: &FillOptions{Pattern: &PatternFill{}}},
+		"unknown pattern":   {Fill: &FillOptions{Pattern: &PatternFill{Pattern: "pct55"}}},
+		"pattern color":     {Fill: &FillOptions{Pattern: &PatternFill{Pattern: "pct5", Background: "white"}}},
+		"stop color":        {Fill: &FillOptions{Gradient: &GradientFill{Stops: []GradientStop{{Color: "FF0000"}, {Position: 100, Color: "#12345"}}}}},
+		"series color":      {Color: "red"},
+		"point color":       {Points: []DataPointOptions{{Index: 1, Color: "GG0000"}}},
+		"line color":        {Line: &LineOptions{Color: "0000FF00"}},
+	} {
+		series.Name, series.Values = "A", []float64{1, 2}
+		if err := u.InsertChart(ChartOptions{Categories: []string{"x", "y"}, Series: []SeriesOptions{series}}); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+
+	if err := u.InsertChart(ChartOptions{
+		ChartKind:  ChartKindPie,
+		Categories: []string{"x", "y"},
+		Series:     []SeriesOptions{{Name: "A", Values: []float64{1, 2}, Trendlines: []TrendlineOptions{{}}}},
+	}); err == nil {
+		t.Error("expected a validation error for a pie chart trendline")
+	}
+	if err := u.InsertChart(ChartOptions{
+		ChartKind:  ChartKindLine,
+		Categories: []string{"x", "y"},
+		Series:     []SeriesOptions{{Name: "A", Values: []float64{1, 2}, Marker: &MarkerOptions{Size: 100}}},
+	}); err == nil {
+		t.Error("expected a validation error for a marker size")
+	}
+	if err := u.InsertChart(ChartOptions{
+		ChartKind:  ChartKindLine,
+		Categories: []string{"x", "y"},
+		Series:     []SeriesOptions{{Name: "A", Values: []float64{1, 2}, Marker: &MarkerOptions{Color: "blue"}}},
+	}); err == nil {
+		t.Error("expected a validation error for a marker color")
+	}
+}
@@ -0,0 +1,466 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// RadarStyle defines how radar chart series are drawn
+type RadarStyle string
+
+const (
+	RadarStyleStandard RadarStyle = "standard" // Lines only (default)
+	RadarStyleMarker   RadarStyle = "marker"   // Lines with markers
+	RadarStyleFilled   RadarStyle = "filled"   // Filled areas
+)
+
+// DoughnutChartOptions defines options specific to doughnut charts
+type DoughnutChartOptions struct {
+	HoleSize        int // Hole size as a percentage of the diameter (10-90, 0 = default 50)
+	FirstSliceAngle int // Angle of the first slice in degrees, clockwise from the top (0-360)
+}
+
+// RadarChartOptions defines options specific to radar charts
+type RadarChartOptions struct {
+	Style RadarStyle // Radar style (default: standard)
+}
+
+// BubbleChartOptions defines options specific to bubble charts
+type BubbleChartOptions struct {
+	Scale        int  // Bubble size as a percentage of the default (1-300, 0 = default 100)
+	ShowNegative bool // Show bubbles with negative sizes (default: false)
+}
+
+// StockChartOptions defines options specific to stock charts
+type StockChartOptions struct {
+	UpBarColor   string // Hex fill of up bars in open-high-low-close charts (default: "FFFFFF")
+	DownBarColor string // Hex fill of down bars in open-high-low-close charts (default: "000000")
+}
+
+// View3DOptions defines the rotation of a 3-D chart
+type View3DOptions struct {
+	RotX           int  // Rotation around the X axis in degrees (-90 to 90)
+	RotY           int  // Rotation around the Y axis in degrees (0-360)
+	Perspective    int  // Field of view in degrees (0-240), used when RightAngleAxes is false
+	RightAngleAxes bool // Draw the axes at right angles instead of in perspective
+}
+
+// isBarChartKind reports whether the kind emits a barChart or bar3DChart
+func isBarChartKind(kind ChartKind) bool {
+	switch kind {
+	case ChartKindColumn, ChartKindBar, ChartKindColumn3D, ChartKindBar3D:
+		return true
+	}
+	return false
+}
+
+// is3DChartKind reports whether the kind is one of the 3-D variants
+func is3DChartKind(kind ChartKind) bool {
+	switch kind {
+	case ChartKindColumn3D, ChartKindBar3D, ChartKindLine3D, ChartKindPie3D, ChartKindArea3D:
+		return true
+	}
+	return false
+}
+
+// chartKindHasAxes reports whether the kind is plotted against axes
+func chartKindHasAxes(kind ChartKind) bool {
+	switch kind {
+	case ChartKindPie, ChartKindPie3D, ChartKindDoughnut:
+		return false
+	}
+	return true
+}
+
+// chartKindHasMarkers reports whether the series of the kind carry a marker
+func chartKindHasMarkers(kind ChartKind) bool {
+	switch kind {
+	case ChartKindLine, ChartKindLine3D, ChartKindRadar:
+		return true
+	}
+	return false
+}
+
+// hasSeriesAxis reports whether the chart places its series along a depth
+// (series) axis, as 3-D line and area charts and standard 3-D bar charts do
+func hasSeriesAxis(opts ChartOptions) bool {
+	switch opts.ChartKind {
+	case ChartKindLine3D, ChartKindArea3D:
+		return true
+	case ChartKindColumn3D, ChartKindBar3D:
+		return opts.BarChartOptions != nil && opts.BarChartOptions.Grouping == BarGroupingStandard
+	}
+	return false
+}
+
+// radarStyle returns the radar style of the chart (default: standard)
+func radarStyle(opts ChartOptions) RadarStyle {
+	if opts.RadarChartOptions != nil && opts.RadarChartOptions.Style != "" {
+		return opts.RadarChartOptions.Style
+	}
+	return RadarStyleStandard
+}
+
+// validateChartKindOptions validates the options and series shape required
+// by the doughnut, radar, bubble, stock and 3-D chart kinds
+func validateChartKindOptions(opts ChartOptions) error {
+	if d := opts.DoughnutChartOptions; d != nil {
+		if d.HoleSize != 0 && (d.HoleSize < 10 || d.HoleSize > 90) {
+			return fmt.Errorf("DoughnutChartOptions.HoleSize must be between 10 and 90")
+		}
+		if d.FirstSliceAngle < 0 || d.FirstSliceAngle > 360 {
+			return fmt.Errorf("DoughnutChartOptions.FirstSliceAngle must be between 0 and 360")
+		}
+	}
+	if r := opts.RadarChartOptions; r != nil {
+		switch r.Style {
+		case "", RadarStyleStandard, RadarStyleMarker, RadarStyleFilled:
+		default:
+			return fmt.Errorf("RadarChartOptions.Style %q is not supported", r.Style)
+		}
+	}
+	if b := opts.BubbleChartOptions; b != nil {
+		if b.Scale < 0 || b.Scale > 300 {
+			return fmt.Errorf("BubbleChartOptions.Scale must be between 0 and 300")
+		}
+	}
+	if s := opts.StockChartOptions; s != nil {
+		if s.UpBarColor != "" && normalizeHexColor(s.UpBarColor) == "" {
+			return fmt.Errorf("StockChartOptions.UpBarColor %q is not a hex color", s.UpBarColor)
+		}
+		if s.DownBarColor != "" && normalizeHexColor(s.DownBarColor) == "" {
+			return fmt.Errorf("StockChartOptions.DownBarColor %q is not a hex color", s.DownBarColor)
+		}
+	}
+	if v := opts.View3D; v != nil {
+		if v.RotX < -90 || v.RotX > 90 {
+			return fmt.Errorf("View3D.RotX must be between -90 and 90")
+		}
+		if v.RotY < 0 || v.RotY > 360 {
+			return fmt.Errorf("View3D.RotY must be between 0 and 360")
+		}
+		if v.Perspective < 0 || v.Perspective > 240 {
+			return fmt.Errorf("View3D.Perspective must be between 0 and 240")
+		}
+	}
+
+	switch opts.ChartKind {
+	case ChartKindBubble:
+		for i, series := range opts.Series {
+			if len(series.BubbleSizes) != len(opts.Categories) {
+				return fmt.Errorf("series[%d] bubble sizes length (%d) must match categories length (%d)", i, len(series.BubbleSizes), len(opts.Categories))
+			}
+		}
+	case ChartKindStock:
+		if len(opts.Series) != 3 && len(opts.Series) != 4 {
+			return fmt.Errorf("stock chart requires 3 series (high, low, close) or 4 series (open, high, low, close), got %d", len(opts.Series))
+		}
+	}
+	return nil
+}
+
+// defaultView3D returns Word's default rotation for a 3-D chart kind
+func defaultView3D(kind ChartKind) *View3DOptions {
+	if kind == ChartKindPie3D {
+		return &View3DOptions{RotX: 30, RotY: 0, Perspective: 30}
+	}
+	return &View3DOptions{RotX: 15, RotY: 20, Perspective: 30, RightAngleAxes: true}
+}
+
+// generateView3DXML generates the view3D element of a 3-D chart
+func generateView3DXML(view *View3DOptions) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<c:view3D>`)
+	buf.WriteString(fmt.Sprintf(`<c:rotX val="%d"/>`, view.RotX))
+	buf.WriteString(fmt.Sprintf(`<c:rotY val="%d"/>`, view.RotY))
+	buf.WriteString(fmt.Sprintf(`<c:rAngAx val="%d"/>`, boolToInt(view.RightAngleAxes)))
+	if !view.RightAngleAxes {
+		buf.WriteString(fmt.Sprintf(`<c:perspective val="%d"/>`, view.Perspective))
+	}
+	buf.WriteString(`</c:view3D>`)
+	return buf.String()
+}
+
+// generateChartDataLabelsXML generates the chart-level data labels, showing
+// percentages by default for pie-like charts
+func generateChartDataLabelsXML(opts ChartOptions, percent bool) string {
+	if opts.DataLabels != nil {
+		return generateDataLabelsXML(opts.DataLabels)
+	}
+	if percent {
+		return `<c:dLbls><c:showLegendKey val="0"/><c:showVal val="0"/><c:showCatName val="0"/><c:showSerName val="0"/><c:showPercent val="1"/><c:showBubbleSize val="0"/><c:showLeaderLines val="1"/></c:dLbls>`
+	}
+	return `<c:dLbls><c:showLegendKey val="0"/><c:showVal val="0"/><c:showCatName val="0"/><c:showSerName val="0"/><c:showPercent val="0"/><c:showBubbleSize val="0"/></c:dLbls>`
+}
+
+// generateDoughnutChartXML generates doughnut chart XML
+func generateDoughnutChartXML(opts ChartOptions) string {
+	holeSize, firstSliceAngle := 50, 0
+	if d := opts.DoughnutChartOptions; d != nil {
+		if d.HoleSize != 0 {
+			holeSize = d.HoleSize
+		}
+		firstSliceAngle = d.FirstSliceAngle
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<c:doughnutChart>`)
+	buf.WriteString(`<c:varyColors val="1"/>`)
+	for i, series := range opts.Series {
+		buf.WriteString(generateSeriesXML(i, series, opts))
+	}
+	buf.WriteString(generateChartDataLabelsXML(opts, true))
+	buf.WriteString(fmt.Sprintf(`<c:firstSliceAng val="%d"/>`, firstSliceAngle))
+	buf.WriteString(fmt.Sprintf(`<c:holeSize val="%d"/>`, holeSize))
+	buf.WriteString(`</c:doughnutChart>`)
+	return buf.String()
+}
+
+// generateRadarChartXML generates radar chart XML
+func generateRadarChartXML(opts ChartOptions) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<c:radarChart>`)
+	buf.WriteString(fmt.Sprintf(`<c:radarStyle val="%s"/>`, radarStyle(opts)))
+	buf.WriteString(`<c:varyColors val="0"/>`)
+	for i, series := range opts.Series {
+		buf.WriteString(generateSeriesXML(i, series, opts))
+	}
+	buf.WriteString(generateChartDataLabelsXML(opts, false))
+	buf.WriteString(`<c:axId val="2071991400"/>`)
+	buf.WriteString(`<c:axId val="2071991240"/>`)
+	buf.WriteString(`</c:radarChart>`)
+	return buf.String()
+}
+
+// generateBubbleChartXML generates bubble chart XML
+func generateBubbleChartXML(opts ChartOptions) string {
+	scale, showNegative := 100, false
+	if b := opts.BubbleChartOptions; b != nil {
+		if b.Scale != 0 {
+			scale = b.Scale
+		}
+		showNegative = b.ShowNegative
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<c:bubbleChart>`)
+	buf.WriteString(`<c:varyColors val="0"/>`)
+	for i, series := range opts.Series {
+		buf.WriteString(generateBubbleSeriesXML(i, series, opts))
+	}
+	buf.WriteString(generateChartDataLabelsXML(opts, false))
+	buf.WriteString(fmt.Sprintf(`<c:bubbleScale val="%d"/>`, scale))
+	buf.WriteString(fmt.Sprintf(`<c:showNegBubbles val="%d"/>`, boolToInt(showNegative)))
+	buf.WriteString(`<c:axId val="2071991400"/>`)
+	buf.WriteString(`<c:axId val="2071991240"/>`)
+	buf.WriteString(`</c:bubbleChart>`)
+	return buf.String()
+}
+
+// bubbleXValues returns the X values of a bubble chart: the categories when
+// they are all numeric, otherwise the category positions 1..n
+func bubbleXValues(categories []string) []float64 {
+	if values, err := parseScatterXValues(categories); err == nil {
+		return values
+	}
+	values := make([]float64, len(categories))
+	for i := range values {
+		values[i] = float64(i + 1)
+	}
+	return values
+}
+
+// bubbleSizeColumn returns the workbook column of a series' bubble sizes,
+// which follow the value columns of all series
+func bubbleSizeColumn(index, seriesCount, seriesColOffset int) int {
+	return seriesColOffset + seriesCount + index
+}
+
+// bubbleSizeHeader returns the workbook header of a series' bubble sizes
+func bubbleSizeHeader(name string) string {
+	return name + " Size"
+}
+
+// generateBubbleSeriesXML generates series XML for bubble charts, which take
+// X values, Y values and bubble sizes
+func generateBubbleSeriesXML(index int, series SeriesOptions, opts ChartOptions) string {
+	var buf bytes.Buffer
+
+	seriesColOffset := 2
+	hasXValues := len(opts.Series[0].XValues) > 0
+	if hasXValues {
+		seriesColOffset = 3 // Column B holds the X values
+	}
+	lastRow := len(opts.Categories) + 1
+	valCol := columnLetter(index + seriesColOffset)
+	sizeCol := columnLetter(bubbleSizeColumn(index, len(opts.Series), seriesColOffset))
+
+	buf.WriteString(fmt.Sprintf(`<c:ser><c:idx val="%d"/><c:order val="%d"/>`, index, index))
+
+	// Series name
+	buf.WriteString(fmt.Sprintf(`<c:tx><c:strRef><c:f>Sheet1!$%s$1</c:f>`, valCol))
+	buf.WriteString(fmt.Sprintf(`<c:strCache><c:ptCount val="1"/><c:pt idx="0"><c:v>%s</c:v></c:pt></c:strCache></c:strRef></c:tx>`,
+		xmlEscape(series.Name)))
+
+	buf.WriteString(generateShapePropertiesXML(series.Color, series.Fill, series.Line))
+	buf.WriteString(fmt.Sprintf(`<c:invertIfNegative val="%d"/>`, boolToInt(series.InvertIfNegative)))
+
+	// Data points, data labels, trendlines and error bars precede the values
+	// in c:ser child order
+	buf.WriteString(generateSeriesExtrasXML(series, ChartKindBubble))
+
+	// X values from column B, or the numeric categories / category positions
+	if hasXValues {
+		buf.WriteString(fmt.Sprintf(`<c:xVal><c:numRef><c:f>Sheet1!$B$2:$B$%d</c:f>`, lastRow))
+		buf.WriteString(fmt.Sprintf(`<c:numCache><c:formatCode>General</c:formatCode><c:ptCount val="%d"/>`, len(series.XValues)))
+		for j, xVal := range series.XValues {
+			buf.WriteString(fmt.Sprintf(`<c:pt idx="%d"><c:v>%g</c:v></c:pt>`, j, xVal))
+		}
+		buf.WriteString(`</c:numCache></c:numRef></c:xVal>`)
+	} else {
+		xValues := bubbleXValues(opts.Categories)
+		buf.WriteString(fmt.Sprintf(`<c:xVal><c:numLit><c:formatCode>General</c:formatCode><c:ptCount val="%d"/>`, len(xValues)))
+		for j, xVal := range xValues {
+			buf.WriteString(fmt.Sprintf(`<c:pt idx="%d"><c:v>%g</c:v></c:pt>`, j, xVal))
+		}
+		buf.WriteString(`</c:numLit></c:xVal>`)
+	}
+
+	// Y values
+	buf.WriteString(fmt.Sprintf(`<c:yVal><c:numRef><c:f>Sheet1!$%s$2:$%s$%d</c:f>`, valCol, valCol, lastRow))
+	buf.WriteString(fmt.Sprintf(`<c:numCache><c:formatCode>General</c:formatCode><c:ptCount val="%d"/>`, len(series.Values)))
+	for j, val := range series.Values {
+		buf.WriteString(fmt.Sprintf(`<c:pt idx="%d"><c:v>%g</c:v></c:pt>`, j, val))
+	}
+	buf.WriteString(`</c:numCache></c:numRef></c:yVal>`)
+
+	// Bubble sizes
+	buf.WriteString(fmt.Sprintf(`<c:bubbleSize><c:numRef><c:f>Sheet1!$%s$2:$%s$%d</c:f>`, sizeCol, sizeCol, lastRow))
+	buf.WriteString(fmt.Sprintf(`<c:numCache><c:formatCode>General</c:formatCode><c:ptCount val="%d"/>`, len(series.BubbleSizes)))
+	for j, size := range series.BubbleSizes {
+		buf.WriteString(fmt.Sprintf(`<c:pt idx="%d"><c:v>%g</c:v></c:pt>`, j, size))
+	}
+	buf.WriteString(`</c:numCache></c:numRef></c:bubbleSize>`)
+
+	buf.WriteString(`<c:bubble3D val="0"/>`)
+	buf.WriteString(`</c:ser>`)
+
+	return buf.String()
+}
+
+// stockSeriesShapeXML hides the connecting line of a stock chart series;
+// only the high-low lines, up/down bars and close markers are drawn
+const stockSeriesShapeXML = `<c:spPr><a:ln w="19050"><a:noFill/></a:ln></c:spPr>`
+
+// stockSeriesMarkerXML returns the marker of a stock chart series. In a
+// high-low-close chart the close series is drawn as dots.
+func stockSeriesMarkerXML(index, seriesCount int) string {
+	if seriesCount == 3 && index == 2 {
+		return `<c:marker><c:symbol val="dot"/><c:size val="5"/></c:marker>`
+	}
+	return `<c:marker><c:symbol val="none"/></c:marker>`
+}
+
+// generateStockChartXML generates stock chart XML. Four series produce an
+// open-high-low-close chart with up/down bars.
+func generateStockChartXML(opts ChartOptions) string {
+	upColor, downColor := "FFFFFF", "000000"
+	if s := opts.StockChartOptions; s != nil {
+		if s.UpBarColor != "" {
+			upColor = normalizeHexColor(s.UpBarColor)
+		}
+		if s.DownBarColor != "" {
+			downColor = normalizeHexColor(s.DownBarColor)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<c:stockChart>`)
+	for i, series := range opts.Series {
+		buf.WriteString(generateSeriesXML(i, series, opts))
+	}
+	buf.WriteString(generateChartDataLabelsXML(opts, false))
+	buf.WriteString(`<c:hiLowLines><c:spPr><a:ln w="9525"><a:solidFill><a:srgbClr val="000000"/></a:solidFill></a:ln></c:spPr></c:hiLowLines>`)
+	if len(opts.Series) == 4 {
+		buf.WriteString(`<c:upDownBars><c:gapWidth val="150"/>`)
+		buf.WriteString(fmt.Sprintf(`<c:upBars><c:spPr><a:solidFill><a:srgbClr val="%s"/></a:solidFill></c:spPr></c:upBars>`, upColor))
+		buf.WriteString(fmt.Sprintf(`<c:downBars><c:spPr><a:solidFill><a:srgbClr val="%s"/></a:solidFill></c:spPr></c:downBars>`, downColor))
+		buf.WriteString(`</c:upDownBars>`)
+	}
+	buf.WriteString(`<c:axId val="2071991400"/>`)
+	buf.WriteString(`<c:axId val="2071991240"/>`)
+	buf.WriteString(`</c:stockChart>`)
+	return buf.String()
+}
+
+// generateBar3DChartXML generates 3-D bar/column chart XML
+func generateBar3DChartXML(opts ChartOptions) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<c:bar3DChart>`)
+	buf.WriteString(fmt.Sprintf(`<c:barDir val="%s"/>`, opts.BarChartOptions.Direction))
+	buf.WriteString(fmt.Sprintf(`<c:grouping val="%s"/>`, opts.BarChartOptions.Grouping))
+	buf.WriteString(fmt.Sprintf(`<c:varyColors val="%d"/>`, boolToInt(opts.BarChartOptions.VaryColors)))
+	for i, series := range opts.Series {
+		buf.WriteString(generateSeriesXML(i, series, opts))
+	}
+	buf.WriteString(generateChartDataLabelsXML(opts, false))
+	buf.WriteString(fmt.Sprintf(`<c:gapWidth val="%d"/>`, opts.BarChartOptions.GapWidth))
+	buf.WriteString(`<c:shape val="box"/>`)
+	buf.WriteString(`<c:axId val="2071991400"/>`)
+	buf.WriteString(`<c:axId val="2071991240"/>`)
+	if hasSeriesAxis(opts) {
+		buf.WriteString(`<c:axId val="2071991560"/>`)
+	}
+	buf.WriteString(`</c:bar3DChart>`)
+	return buf.String()
+}
+
+// generateLineArea3DChartXML generates 3-D line or area chart XML. Both
+// place their series along a series axis.
+func generateLineArea3DChartXML(opts ChartOptions) string {
+	element := string(opts.ChartKind)
+
+	var buf bytes.Buffer
+	buf.WriteString(`<c:` + element + `>`)
+	buf.WriteString(`<c:grouping val="standard"/>`)
+	buf.WriteString(`<c:varyColors val="0"/>`)
+	for i, series := range opts.Series {
+		buf.WriteString(generateSeriesXML(i, series, opts))
+	}
+	buf.WriteString(generateChartDataLabelsXML(opts, false))
+	buf.WriteString(`<c:axId val="2071991400"/>`)
+	buf.WriteString(`<c:axId val="2071991240"/>`)
+	buf.WriteString(`<c:axId val="2071991560"/>`)
+	buf.WriteString(`</c:` + element + `>`)
+	return buf.String()
+}
+
+// generatePie3DChartXML generates 3-D pie chart XML
+func generatePie3DChartXML(opts ChartOptions) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<c:pie3DChart>`)
+	buf.WriteString(`<c:varyColors val="1"/>`)
+	for i, series := range opts.Series {
+		buf.WriteString(generateSeriesXML(i, series, opts))
+	}
+	buf.WriteString(generateChartDataLabelsXML(opts, true))
+	buf.WriteString(`</c:pie3DChart>`)
+	return buf.String()
+}
+
+// generateSeriesAxisXML generates the depth axis of a 3-D chart
+func generateSeriesAxisXML() string {
+	var buf bytes.Buffer
+	buf.WriteString(`<c:serAx>`)
+	buf.WriteString(`<c:axId val="2071991560"/>`)
+	buf.WriteString(`<c:scaling><c:orientation val="minMax"/></c:scaling>`)
+	buf.WriteString(`<c:delete val="0"/>`)
+	buf.WriteString(`<c:axPos val="b"/>`)
+	buf.WriteString(`<c:majorTickMark val="out"/>`)
+	buf.WriteString(`<c:minorTickMark val="none"/>`)
+	buf.WriteString(`<c:tickLblPos val="nextTo"/>`)
+	buf.WriteString(`<c:crossAx val="2071991240"/>`)
+	buf.WriteString(`<c:crosses val="autoZero"/>`)
+	buf.WriteString(`</c:serAx>`)
+	return buf.String()
+}
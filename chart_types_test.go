@@ -0,0 +1,353 @@
+package godocx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readChartSheet returns the worksheet of the embedded workbook of chart N
+func readChartSheet(t *testing.T, u *Updater, chartIndex int) string {
+	t.Helper()
+	xlsxPath, err := u.findWorkbookPathForChart(chartIndex)
+	if err != nil {
+		t.Fatalf("find workbook: %v", err)
+	}
+	return readWorkbookSheet(t, xlsxPath)
+}
+
+// readWorkbookSheet returns the first worksheet of an embedded workbook
+func readWorkbookSheet(t *testing.T, xlsxPath string) string {
+	t.Helper()
+	zr, err := zip.OpenReader(xlsxPath)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatalf("open sheet: %v", err)
+			}
+			defer rc.Close()
+			raw, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("read sheet: %v", err)
+			}
+			return string(raw)
+		}
+	}
+	t.Fatal("workbook has no sheet1.xml")
+	return ""
+}
+
+// checkWellFormedXML fails the test when raw is not well-formed XML
+func checkWellFormedXML(t *testing.T, raw string) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(raw))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("XML is not well-formed: %v", err)
+		}
+	}
+}
+
+func TestInsertChart_MoreKinds(t *testing.T) {
+	categories := []string{"Mon", "Tue", "Wed"}
+	twoSeries := []SeriesOptions{
+		{Name: "North", Values: []float64{10, 12, 14}},
+		{Name: "South", Values: []float64{8, 9, 11}},
+	}
+	tests := []struct {
+		name  string
+		opts  ChartOptions
+		want  []string
+		avoid []string
+	}{
+		{
+			name: "doughnut",
+			opts: ChartOptions{ChartKind: ChartKindDoughnut, Series: twoSeries[:1],
+				DoughnutChartOptions: &DoughnutChartOptions{HoleSize: 65, FirstSliceAngle: 90}},
+			want:  []string{`<c:doughnutChart><c:varyColors val="1"/>`, `<c:firstSliceAng val="90"/><c:holeSize val="65"/></c:doughnutChart>`},
+			avoid: []string{`<c:catAx>`, `<c:valAx>`},
+		},
+		{
+			name: "radar with markers",
+			opts: ChartOptions{ChartKind: ChartKindRadar, Series: twoSeries,
+				RadarChartOptions: &RadarChartOptions{Style: RadarStyleMarker}},
+			want: []string{`<c:radarChart><c:radarStyle val="marker"/>`, `<c:marker><c:symbol val="circle"/></c:marker><c:cat>`, `<c:catAx>`},
+		},
+		{
+			name: "filled radar",
+			opts: ChartOptions{ChartKind: ChartKindRadar, Series: twoSeries,
+				RadarChartOptions: &RadarChartOptions{Style: RadarStyleFilled}},
+			want: []string{`<c:radarStyle val="filled"/>`, `<c:marker><c:symbol val="none"/></c:marker>`},
+		},
+		{
+			name: "bubble",
+			opts: ChartOptions{ChartKind: ChartKindBubble, Series: []SeriesOptions{
+				{Name: "North", Values: []float64{10, 12, 14}, BubbleSizes: []float64{3, 5, 7}},
+				{Name: "South", Values: []float64{8, 9, 11}, BubbleSizes: []float64{2, 4, 6}},
+			}, BubbleChartOptions: &BubbleChartOptions{Scale: 150}},
+			want: []string{
+				`<c:xVal><c:numLit><c:formatCode>General</c:formatCode><c:ptCount val="3"/><c:pt idx="0"><c:v>1</c:v></c:pt>`,
+				`<c:bubbleSize><c:numRef><c:f>Sheet1!$E$2:$E$4</c:f>`,
+				`<c:bubbleScale val="150"/><c:showNegBubbles val="0"/>`,
+				`<c:crossBetween val="midCat"/>`,
+			},
+			avoid: []string{`<c:catAx>`},
+		},
+		{
+			name: "high-low-close stock",
+			opts: ChartOptions{ChartKind: ChartKindStock, Series: []SeriesOptions{
+				{Name: "High", Values: []float64{12, 13, 15}},
+				{Name: "Low", Values: []float64{9, 10, 11}},
+				{Name: "Close", Values: []float64{11, 12, 14}},
+			}},
+			want: []string{
+				`<c:spPr><a:ln w="19050"><a:noFill/></a:ln></c:spPr><c:marker><c:symbol val="none"/></c:marker>`,
+				`<c:marker><c:symbol val="dot"/><c:size val="5"/></c:marker>`,
+				`<c:hiLowLines>`,
+			},
+			avoid: []string{`<c:upDownBars>`},
+		},
+		{
+			name: "open-high-low-close stock",
+			opts: ChartOptions{ChartKind: ChartKindStock, Series: []SeriesOptions{
+				{Name: "Open", Values: []float64{10, 12, 13}},
+				{Name: "High", Values: []float64{12, 13, 15}},
+				{Name: "Low", Values: []float64{9, 10, 11}},
+				{Name: "Close", Values: []float64{11, 12, 14}},
+			}, StockChartOptions: &StockChartOptions{UpBarColor: "#00AA00"}},
+			want: []string{
+				`<c:upDownBars><c:gapWidth val="150"/><c:upBars><c:spPr><a:solidFill><a:srgbClr val="00AA00"/>`,
+				`<c:downBars><c:spPr><a:solidFill><a:srgbClr val="000000"/>`,
+			},
+		},
+		{
+			name: "3-D column",
+			opts: ChartOptions{ChartKind: ChartKindColumn3D, Series: twoSeries},
+			want: []string{
+				`<c:autoTitleDeleted val="0"/><c:view3D><c:rotX val="15"/><c:rotY val="20"/><c:rAngAx val="1"/></c:view3D><c:plotArea>`,
+				`<c:bar3DChart><c:barDir val="col"/><c:grouping val="clustered"/>`,
+				`<c:shape val="box"/><c:axId val="2071991400"/><c:axId val="2071991240"/></c:bar3DChart>`,
+			},
+			avoid: []string{`<c:serAx>`},
+		},
+		{
+			name: "standard 3-D bar",
+			opts: ChartOptions{ChartKind: ChartKindBar3D, Series: twoSeries,
+				BarChartOptions: &BarChartOptions{Grouping: BarGroupingStandard}},
+			want: []string{`<c:barDir val="bar"/><c:grouping val="standard"/>`, `<c:axId val="2071991560"/></c:bar3DChart>`, `<c:serAx>`},
+		},
+		{
+			name: "3-D line",
+			opts: ChartOptions{ChartKind: ChartKindLine3D, Series: twoSeries,
+				View3D: &View3DOptions{RotX: 10, RotY: 40, Perspective: 45}},
+			want: []string{
+				`<c:view3D><c:rotX val="10"/><c:rotY val="40"/><c:rAngAx val="0"/><c:perspective val="45"/></c:view3D>`,
+				`<c:line3DChart><c:grouping val="standard"/>`,
+				`<c:axId val="2071991560"/></c:line3DChart>`,
+				`<c:serAx>`,
+			},
+		},
+		{
+			name:  "3-D pie",
+			opts:  ChartOptions{ChartKind: ChartKindPie3D, Series: twoSeries[:1]},
+			want:  []string{`<c:view3D><c:rotX val="30"/><c:rotY val="0"/><c:rAngAx val="0"/><c:perspective val="30"/></c:view3D>`, `<c:pie3DChart>`},
+			avoid: []string{`<c:catAx>`},
+		},
+		{
+			name: "3-D area",
+			opts: ChartOptions{ChartKind: ChartKindArea3D, Series: twoSeries},
+			want: []string{`<c:area3DChart><c:grouping val="standard"/>`, `<c:serAx>`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Chart:</w:t></w:r></w:p>`))
+			tt.opts.Position = PositionEnd
+			tt.opts.Categories = categories
+			if err := u.InsertChart(tt.opts); err != nil {
+				t.Fatalf("InsertChart: %v", err)
+			}
+			chart := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+			checkWellFormedXML(t, chart)
+			for _, want := range tt.want {
+				if !strings.Contains(chart, want) {
+					t.Errorf("expected %s in chart XML, got %s", want, chart)
+				}
+			}
+			for _, avoid := range tt.avoid {
+				if strings.Contains(chart, avoid) {
+					t.Errorf("unexpected %s in chart XML", avoid)
+				}
+			}
+			if sheet := readChartSheet(t, u, 1); !strings.Contains(sheet, `<c r="A4" t="str"><v>Wed</v></c>`) {
+				t.Errorf("unexpected workbook sheet %s", sheet)
+			}
+		})
+	}
+}
+
+func TestInsertChart_MoreKindsValidation(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Chart:</w:t></w:r></w:p>`))
+	series := []SeriesOptions{{Name: "A", Values: []float64{1, 2}}}
+	for name, opts := range map[string]ChartOptions{
+		"hole size":       {ChartKind: ChartKindDoughnut, DoughnutChartOptions: &DoughnutChartOptions{HoleSize: 95}},
+		"radar style":     {ChartKind: ChartKindRadar, RadarChartOptions: &RadarChartOptions{Style: "spiky"}},
+		"bubble sizes":    {ChartKind: ChartKindBubble},
+		"stock series":    {ChartKind: ChartKindStock},
+		"rotation":        {ChartKind: ChartKindColumn3D, View3D: &View3DOptions{RotX: 120}},
+		"up bar color":    {ChartKind: ChartKindStock, StockChartOptions: &StockChartOptions{UpBarColor: "green"}},
+		"bubble scale":    {ChartKind: ChartKindBubble, BubbleChartOptions: &BubbleChartOptions{Scale: 400}},
+		"first slice ang": {ChartKind: ChartKindDoughnut, DoughnutChartOptions: &DoughnutChartOptions{FirstSliceAngle: -1}},
+	} {
+		opts.Categories = []string{"x", "y"}
+		opts.Series = series
+		if err := u.InsertChart(opts); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestInsertBubbleChart_Workbook(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Chart:</w:t></w:r></w:p>`))
+	if err := u.InsertChart(ChartOptions{
+		ChartKind:  ChartKindBubble,
+		Position:   PositionEnd,
+		Categories: []string{"a", "b"},
+		Series: []SeriesOptions{
+			{Name: "Deals", Values: []float64{4, 6}, XValues: []float64{1.5, 2.5}, BubbleSizes: []float64{30, 50}},
+		},
+	}); err != nil {
+		t.Fatalf("InsertChart: %v", err)
+	}
+
+	sheet := readChartSheet(t, u, 1)
+	for _, want := range []string{
+		`<c r="B1" t="str"><v>X Values</v></c><c r="C1" t="str"><v>Deals</v></c><c r="D1" t="str"><v>Deals Size</v></c>`,
+		`<c r="B2"><v>1.5</v></c><c r="C2"><v>4</v></c><c r="D2"><v>30</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("expected %s in sheet, got %s", want, sheet)
+		}
+	}
+	chart := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+	for _, want := range []string{
+		`<c:tx><c:strRef><c:f>Sheet1!$C$1</c:f>`,
+		`<c:xVal><c:numRef><c:f>Sheet1!$B$2:$B$3</c:f>`,
+		`<c:yVal><c:numRef><c:f>Sheet1!$C$2:$C$3</c:f>`,
+		`<c:bubbleSize><c:numRef><c:f>Sheet1!$D$2:$D$3</c:f>`,
+	} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("expected %s in chart XML", want)
+		}
+	}
+}
+
+func TestUpdateChart_MoreKinds(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ChartOptions
+		data ChartData
+		keep []string
+	}{
+		{
+			name: "doughnut",
+			opts: ChartOptions{ChartKind: ChartKindDoughnut, DoughnutChartOptions: &DoughnutChartOptions{HoleSize: 70},
+				Series: []SeriesOptions{{Name: "Share", Values: []float64{1, 2}, Color: "FF0000"}}},
+			data: ChartData{Categories: []string{"A", "B", "C"}, Series: []SeriesData{{Name: "Share", Values: []float64{3, 4, 5}}}},
+			keep: []string{`<c:doughnutChart><c:varyColors val="1"/>`, `<c:holeSize val="70"/></c:doughnutChart>`, `<c:spPr><a:solidFill><a:srgbClr val="FF0000"/>`},
+		},
+		{
+			name: "radar",
+			opts: ChartOptions{ChartKind: ChartKindRadar, RadarChartOptions: &RadarChartOptions{Style: RadarStyleFilled},
+				Series: []SeriesOptions{{Name: "Skill", Values: []float64{1, 2}}}},
+			data: ChartData{Categories: []string{"A", "B", "C"}, Series: []SeriesData{{Name: "Skill", Values: []float64{3, 4, 5}}}},
+			keep: []string{`<c:radarChart><c:radarStyle val="filled"/>`, `<c:marker><c:symbol val="none"/></c:marker><c:cat>`},
+		},
+		{
+			name: "bubble",
+			opts: ChartOptions{ChartKind: ChartKindBubble,
+				Series: []SeriesOptions{{Name: "Deals", Values: []float64{1, 2}, BubbleSizes: []float64{5, 6}}}},
+			data: ChartData{Categories: []string{"10", "20", "30"}, Series: []SeriesData{{Name: "Deals", Values: []float64{3, 4, 5}, BubbleSizes: []float64{7, 8, 9}}}},
+			keep: []string{`<c:pt idx="2"><c:v>30</c:v></c:pt>`, `<c:bubbleSize><c:numRef><c:numCache><c:ptCount val="3"/>`, `<c:bubbleScale val="100"/>`},
+		},
+		{
+			name: "stock",
+			opts: ChartOptions{ChartKind: ChartKindStock, Series: []SeriesOptions{
+				{Name: "Open", Values: []float64{1, 2}}, {Name: "High", Values: []float64{3, 4}},
+				{Name: "Low", Values: []float64{0, 1}}, {Name: "Close", Values: []float64{2, 3}},
+			}},
+			data: ChartData{Categories: []string{"D1", "D2", "D3"}, Series: []SeriesData{
+				{Name: "Open", Values: []float64{1, 2, 3}}, {Name: "High", Values: []float64{3, 4, 5}},
+				{Name: "Low", Values: []float64{0, 1, 2}}, {Name: "Close", Values: []float64{2, 3, 4}},
+			}},
+			keep: []string{`<a:noFill/>`, `<c:hiLowLines>`, `<c:upDownBars><c:gapWidth val="150"/>`, `</c:upDownBars><c:axId val="2071991400"/>`},
+		},
+		{
+			name: "3-D column",
+			opts: ChartOptions{ChartKind: ChartKindColumn3D, Series: []SeriesOptions{{Name: "Sales", Values: []float64{1, 2}}}},
+			data: ChartData{Categories: []string{"A", "B", "C"}, Series: []SeriesData{{Name: "Sales", Values: []float64{3, 4, 5}}}},
+			keep: []string{`<c:view3D>`, `<c:bar3DChart><c:barDir val="col"/>`, `<c:gapWidth val="150"/><c:shape val="box"/><c:axId`},
+		},
+		{
+			name: "3-D line",
+			opts: ChartOptions{ChartKind: ChartKindLine3D, Series: []SeriesOptions{{Name: "Sales", Values: []float64{1, 2}}}},
+			data: ChartData{Categories: []string{"A", "B", "C"}, Series: []SeriesData{{Name: "Sales", Values: []float64{3, 4, 5}}}},
+			keep: []string{`<c:line3DChart><c:grouping val="standard"/>`, `<c:axId val="2071991560"/></c:line3DChart>`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Chart:</w:t></w:r></w:p>`))
+			tt.opts.Position = PositionEnd
+			tt.opts.Categories = []string{"A", "B"}
+			if err := u.InsertChart(tt.opts); err != nil {
+				t.Fatalf("InsertChart: %v", err)
+			}
+			if err := u.UpdateChart(1, tt.data); err != nil {
+				t.Fatalf("UpdateChart: %v", err)
+			}
+
+			chart := readWordPart(t, u, filepath.Join("charts", "chart1.xml"))
+			checkWellFormedXML(t, chart)
+			for _, want := range tt.keep {
+				if !strings.Contains(chart, want) {
+					t.Errorf("expected %s in updated chart XML, got %s", want, chart)
+				}
+			}
+
+			got, err := u.GetChartData(1)
+			if err != nil {
+				t.Fatalf("GetChartData: %v", err)
+			}
+			if !reflect.DeepEqual(got.Categories, tt.data.Categories) {
+				t.Errorf("categories: got %v, want %v", got.Categories, tt.data.Categories)
+			}
+			for i, series := range tt.data.Series {
+				if !reflect.DeepEqual(got.Series[i].Values, series.Values) || !reflect.DeepEqual(got.Series[i].BubbleSizes, series.BubbleSizes) {
+					t.Errorf("series %d: got %+v, want %+v", i, got.Series[i], series)
+				}
+			}
+
+			sheet := readChartSheet(t, u, 1)
+			if !strings.Contains(sheet, `<row r="4">`) {
+				t.Errorf("expected the workbook to hold the updated rows, got %s", sheet)
+			}
+			if tt.opts.ChartKind == ChartKindBubble && !strings.Contains(sheet, `<c r="C4"><v>9</v></c>`) {
+				t.Errorf("expected bubble sizes in column C, got %s", sheet)
+			}
+		})
+	}
+}
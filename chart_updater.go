@@ -165,7 +165,8 @@ func (u *Updater) TempDir() string {
 }
 
 // GetChartCount returns the number of charts embedded in the document.
-// Returns 0 if the document contains no charts.
+// Returns 0 if the document contains no charts. Chartex charts (waterfall,
+// histogram and the other Office 2016 kinds) are not counted.
 func (u *Updater) GetChartCount() (int, error) {
 	if u == nil {
 		return 0, errors.New("updater is nil")
@@ -310,6 +311,9 @@ func validateChartData(data ChartData) error {
 		if len(s.Values) != len(data.Categories) {
 			return fmt.Errorf("series[%d] values length (%d) must match categories length (%d)", i, len(s.Values), len(data.Categories))
 		}
+		if len(s.BubbleSizes) > 0 && len(s.BubbleSizes) != len(data.Categories) {
+			return fmt.Errorf("series[%d] bubble sizes length (%d) must match categories length (%d)", i, len(s.BubbleSizes), len(data.Categories))
+		}
 	}
 	return nil
 }
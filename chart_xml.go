@@ -5,6 +5,8 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -58,12 +60,21 @@ type ChartTitle struct {
 
 // PlotArea contains the chart type and series.
 type PlotArea struct {
-	BarChart     *ChartType `xml:"barChart,omitempty"`
-	LineChart    *ChartType `xml:"lineChart,omitempty"`
-	ScatterChart *ChartType `xml:"scatterChart,omitempty"`
-	PieChart     *ChartType `xml:"pieChart,omitempty"`
-	CatAx        []Axis     `xml:"catAx,omitempty"`
-	ValAx        []Axis     `xml:"valAx,omitempty"`
+	BarChart      *ChartType `xml:"barChart,omitempty"`
+	LineChart     *ChartType `xml:"lineChart,omitempty"`
+	ScatterChart  *ChartType `xml:"scatterChart,omitempty"`
+	PieChart      *ChartType `xml:"pieChart,omitempty"`
+	AreaChart     *ChartType `xml:"areaChart,omitempty"`
+	DoughnutChart *ChartType `xml:"doughnutChart,omitempty"`
+	RadarChart    *ChartType `xml:"radarChart,omitempty"`
+	BubbleChart   *ChartType `xml:"bubbleChart,omitempty"`
+	StockChart    *ChartType `xml:"stockChart,omitempty"`
+	Bar3DChart    *ChartType `xml:"bar3DChart,omitempty"`
+	Line3DChart   *ChartType `xml:"line3DChart,omitempty"`
+	Pie3DChart    *ChartType `xml:"pie3DChart,omitempty"`
+	Area3DChart   *ChartType `xml:"area3DChart,omitempty"`
+	CatAx         []Axis     `xml:"catAx,omitempty"`
+	ValAx         []Axis     `xml:"valAx,omitempty"`
 }
 
 // ChartType represents a chart with series data.
@@ -76,9 +87,11 @@ type Series struct {
 	Tx  SeriesText `xml:"tx"`
 	Cat *SeriesRef `xml:"cat,omitempty"`
 	Val *SeriesRef `xml:"val,omitempty"`
-	// For scatter charts
+	// For scatter and bubble charts
 	XVal *SeriesRef `xml:"xVal,omitempty"`
 	YVal *SeriesRef `xml:"yVal,omitempty"`
+	// For bubble charts
+	BubbleSize *SeriesRef `xml:"bubbleSize,omitempty"`
 }
 
 // SeriesText contains the series name.
@@ -318,43 +331,82 @@ func replaceFirstTextElement(content string, sectionStart int, section string, t
 
 // updateChartSeries updates the series data in the chart XML.
 func updateChartSeries(content string, data ChartData, nsPrefix string) (string, error) {
-	// Find the chart type (barChart, lineChart, scatterChart, pieChart)
-	chartTypes := []string{"barChart", "lineChart", "scatterChart", "pieChart", "areaChart"}
-
-	var chartType string
-	var chartStart, chartEnd int
+	// Find the chart type (barChart, lineChart, scatterChart, pieChart, ...)
+	chartTypes := []string{
+		"barChart", "lineChart", "scatterChart", "pieChart", "areaChart",
+		"doughnutChart", "radarChart", "bubbleChart", "stockChart",
+		"bar3DChart", "line3DChart", "pie3DChart", "area3DChart",
+	}
 
+	// Find the chart groups in document order; combo charts hold several
+	type chartGroup struct {
+		chartType  string
+		start, end int
+	}
+	var groups []chartGroup
 	for _, ct := range chartTypes {
-		chartStart = strings.Index(content, "<"+nsPrefix+ct+">")
-		if chartStart != -1 {
-			chartType = ct
-			chartEnd = strings.Index(content[chartStart:], "</"+nsPrefix+ct+">")
-			if chartEnd == -1 {
+		openTag, closeTag := "<"+nsPrefix+ct+">", "</"+nsPrefix+ct+">"
+		offset := 0
+		for {
+			start := strings.Index(content[offset:], openTag)
+			if start == -1 {
+				break
+			}
+			start += offset
+			end := strings.Index(content[start:], closeTag)
+			if end == -1 {
 				return "", fmt.Errorf("malformed chart XML: no closing tag for %s", ct)
 			}
-			chartEnd += chartStart
-			break
+			end += start
+			groups = append(groups, chartGroup{chartType: ct, start: start, end: end})
+			offset = end
 		}
 	}
 
-	if chartType == "" {
+	if len(groups) == 0 {
 		return "", fmt.Errorf("unsupported or missing chart type")
 	}
+	sort.Slice(groups, func(a, b int) bool { return groups[a].start < groups[b].start })
+
+	// Each series goes back to the group that held the series with its
+	// index; new series join the first group
+	owner := make(map[int]int)
+	idxRe := regexp.MustCompile(`<` + regexp.QuoteMeta(nsPrefix) + `idx val="(\d+)"`)
+	for gi, g := range groups {
+		section := content[g.start:g.end]
+		for _, block := range extractBlocks(section, "<"+nsPrefix+"ser>", "<"+nsPrefix+"ser ", "</"+nsPrefix+"ser>") {
+			if m := idxRe.FindStringSubmatch(block); m != nil {
+				owner[atoiOrZero(m[1])] = gi
+			}
+		}
+	}
+	groupIndexes := make([][]int, len(groups))
+	for i := range data.Series {
+		groupIndexes[owner[i]] = append(groupIndexes[owner[i]], i)
+	}
 
-	chartSection := content[chartStart:chartEnd]
-
-	// Update or remove series
-	updatedSeries, err := updateSeriesSection(chartSection, data, nsPrefix, chartType)
-	if err != nil {
-		return "", err
+	// Update or remove series, from the last group back so that the
+	// offsets of earlier groups stay valid
+	for gi := len(groups) - 1; gi >= 0; gi-- {
+		g := groups[gi]
+		groupData := data
+		groupData.Series = nil
+		for _, i := range groupIndexes[gi] {
+			groupData.Series = append(groupData.Series, data.Series[i])
+		}
+		updatedSeries, err := updateSeriesSection(content[g.start:g.end], groupData, nsPrefix, g.chartType, groupIndexes[gi])
+		if err != nil {
+			return "", err
+		}
+		content = content[:g.start] + updatedSeries + content[g.end:]
 	}
 
-	// Reconstruct content
-	return content[:chartStart] + updatedSeries + content[chartEnd:], nil
+	return content, nil
 }
 
 // updateSeriesSection updates the series within a chart section.
-func updateSeriesSection(chartSection string, data ChartData, nsPrefix, chartType string) (string, error) {
+// Series i is numbered indexes[i], as combo charts number series across groups.
+func updateSeriesSection(chartSection string, data ChartData, nsPrefix, chartType string, indexes []int) (string, error) {
 	// Find all series elements
 	serTags := findAllSeriesTags(chartSection, nsPrefix)
 
@@ -362,33 +414,99 @@ func updateSeriesSection(chartSection string, data ChartData, nsPrefix, chartTyp
 		return "", fmt.Errorf("no series found in chart")
 	}
 	var scatterXValues []float64
-	if chartType == "scatterChart" {
+	switch chartType {
+	case "scatterChart":
 		parsed, err := parseScatterXValues(data.Categories)
 		if err != nil {
 			return "", err
 		}
 		scatterXValues = parsed
+	case "bubbleChart":
+		scatterXValues = bubbleXValues(data.Categories)
+		for i, series := range data.Series {
+			if len(series.BubbleSizes) != len(data.Categories) {
+				return "", fmt.Errorf("series[%d] bubble sizes length (%d) must match categories length (%d)", i, len(series.BubbleSizes), len(data.Categories))
+			}
+		}
+	case "stockChart":
+		if len(data.Series) != 3 && len(data.Series) != 4 {
+			return "", fmt.Errorf("stock chart requires 3 or 4 series, got %d", len(data.Series))
+		}
 	}
 
-	// Build new series section
+	// Keep the chart-level elements before the first series (barDir,
+	// radarStyle, varyColors, ...) as they are
 	var buf bytes.Buffer
-	buf.WriteString("<" + nsPrefix + chartType + ">")
+	buf.WriteString(chartSection[:serTags[0]])
 
-	// Write series from data
+	// Write series from data, keeping the formatting of existing series
+	closeSer := "</" + nsPrefix + "ser>"
+	original := extractBlocks(chartSection, "<"+nsPrefix+"ser>", "<"+nsPrefix+"ser ", closeSer)
 	for i, series := range data.Series {
-		serXML, err := buildSeriesXML(series, data.Categories, scatterXValues, i, nsPrefix, chartType)
+		format := ""
+		if i < len(original) {
+			format = seriesFormatXML(original[i], nsPrefix)
+		}
+		serXML, err := buildSeriesXML(series, data.Categories, scatterXValues, indexes[i], nsPrefix, chartType, format)
 		if err != nil {
 			return "", err
 		}
 		buf.WriteString(serXML)
 	}
 
-	// Copy any non-series elements (like axId, etc.) from the original
-	buf.WriteString(copyNonSeriesElements(chartSection, nsPrefix))
+	// Copy the non-series elements (like axId, etc.) following the series
+	buf.WriteString(copyNonSeriesElements(chartSection[strings.LastIndex(chartSection, closeSer)+len(closeSer):], nsPrefix))
 
 	return buf.String(), nil
 }
 
+// seriesFormatXML returns the formatting elements of a series that sit
+// between its name and its data, such as spPr, invertIfNegative, marker,
+// data points, data labels, trendlines and error bars
+func seriesFormatXML(serBlock, nsPrefix string) string {
+	txClose := "</" + nsPrefix + "tx>"
+	start := strings.Index(serBlock, txClose)
+	if start == -1 {
+		return ""
+	}
+	start += len(txClose)
+
+	// Walk the child elements up to the first data element; nested elements
+	// such as the c:val of error bars are skipped with their parent
+	dataTags := map[string]bool{"cat": true, "val": true, "xVal": true, "yVal": true, "bubbleSize": true, "smooth": true, "bubble3D": true, "shape": true}
+	end := start
+	for {
+		open := strings.Index(serBlock[end:], "<"+nsPrefix)
+		if open == -1 {
+			break
+		}
+		pos := end + open
+		nameEnd := pos + 1 + len(nsPrefix)
+		for nameEnd < len(serBlock) && serBlock[nameEnd] != '>' && serBlock[nameEnd] != ' ' && serBlock[nameEnd] != '/' {
+			nameEnd++
+		}
+		tag := serBlock[pos+1+len(nsPrefix) : nameEnd]
+		if dataTags[tag] {
+			break
+		}
+		tagClose := strings.Index(serBlock[pos:], ">")
+		if tagClose == -1 {
+			break
+		}
+		elemEnd := pos + tagClose + 1
+		if serBlock[elemEnd-2] != '/' {
+			closeTag := "</" + nsPrefix + tag + ">"
+			idx := strings.Index(serBlock[elemEnd:], closeTag)
+			if idx == -1 {
+				break
+			}
+			elemEnd += idx + len(closeTag)
+		}
+		end = elemEnd
+	}
+	return serBlock[start:end]
+}
+
 // findAllSeriesTags finds positions of all series tags.
 func findAllSeriesTags(content, nsPrefix string) []int {
 	var positions []int
@@ -408,7 +526,7 @@ func findAllSeriesTags(content, nsPrefix string) []int {
 }
 
 // buildSeriesXML constructs XML for a single series.
-func buildSeriesXML(series SeriesData, categories []string, scatterXValues []float64, idx int, nsPrefix, chartType string) (string, error) {
+func buildSeriesXML(series SeriesData, categories []string, scatterXValues []float64, idx int, nsPrefix, chartType, format string) (string, error) {
 	var buf bytes.Buffer
 
 	buf.WriteString("<" + nsPrefix + "ser>")
@@ -420,8 +538,11 @@ func buildSeriesXML(series SeriesData, categories []string, scatterXValues []flo
 	buf.WriteString("<" + nsPrefix + "v>" + xmlEscape(series.Name) + "</" + nsPrefix + "v>")
 	buf.WriteString("</" + nsPrefix + "tx>")
 
-	// Categories (for most chart types except scatter)
-	if chartType != "scatterChart" {
+	// Formatting kept from the original series
+	buf.WriteString(format)
+
+	// Categories (for most chart types except scatter and bubble)
+	if chartType != "scatterChart" && chartType != "bubbleChart" {
 		buf.WriteString("<" + nsPrefix + "cat>")
 		buf.WriteString("<" + nsPrefix + "strRef>")
 		buf.WriteString("<" + nsPrefix + "strCache>")
@@ -454,7 +575,7 @@ func buildSeriesXML(series SeriesData, categories []string, scatterXValues []flo
 
 	// Values
 	valTag := "val"
-	if chartType == "scatterChart" {
+	if chartType == "scatterChart" || chartType == "bubbleChart" {
 		valTag = "yVal"
 	}
 
@@ -471,6 +592,23 @@ func buildSeriesXML(series SeriesData, categories []string, scatterXValues []flo
 	buf.WriteString("</" + nsPrefix + "numRef>")
 	buf.WriteString("</" + nsPrefix + valTag + ">")
 
+	// Bubble sizes
+	if chartType == "bubbleChart" {
+		buf.WriteString("<" + nsPrefix + "bubbleSize>")
+		buf.WriteString("<" + nsPrefix + "numRef>")
+		buf.WriteString("<" + nsPrefix + "numCache>")
+		buf.WriteString("<" + nsPrefix + "ptCount val=\"" + strconv.Itoa(len(series.BubbleSizes)) + "\"/>")
+		for i, size := range series.BubbleSizes {
+			buf.WriteString("<" + nsPrefix + "pt idx=\"" + strconv.Itoa(i) + "\">")
+			buf.WriteString("<" + nsPrefix + "v>" + formatFloat(size) + "</" + nsPrefix + "v>")
+			buf.WriteString("</" + nsPrefix + "pt>")
+		}
+		buf.WriteString("</" + nsPrefix + "numCache>")
+		buf.WriteString("</" + nsPrefix + "numRef>")
+		buf.WriteString("</" + nsPrefix + "bubbleSize>")
+		buf.WriteString("<" + nsPrefix + "bubble3D val=\"0\"/>")
+	}
+
 	buf.WriteString("</" + nsPrefix + "ser>")
 	return buf.String(), nil
 }
@@ -479,8 +617,26 @@ func buildSeriesXML(series SeriesData, categories []string, scatterXValues []flo
 func copyNonSeriesElements(chartSection, nsPrefix string) string {
 	var buf bytes.Buffer
 
-	// Find and copy axId elements
-	axIdTags := []string{"axId", "dLbls", "gapWidth", "overlap", "varyColors"}
+	// Find and copy the elements in schema order; each chart type uses a
+	// subset of them
+	axIdTags := []string{
+		"dLbls", "dropLines", "hiLowLines", "upDownBars", "marker",
+		"gapWidth", "overlap", "serLines", "gapDepth", "shape",
+		"firstSliceAng", "holeSize", "bubble3D", "bubbleScale",
+		"showNegBubbles", "sizeRepresents", "axId",
+	}
+
+	// Elements nested in an already copied element, such as the gapWidth
+	// of upDownBars, are skipped
+	var copied [][2]int
+	nested := func(pos int) bool {
+		for _, r := range copied {
+			if pos > r[0] && pos < r[1] {
+				return true
+			}
+		}
+		return false
+	}
 
 	for _, tag := range axIdTags {
 		startTag := "<" + nsPrefix + tag
@@ -502,7 +658,9 @@ func copyNonSeriesElements(chartSection, nsPrefix string) string {
 
 			// Self-closing element: <tag .../>
 			if openEnd > pos && chartSection[openEnd-1] == '/' {
-				buf.WriteString(chartSection[pos : openEnd+1])
+				if !nested(pos) {
+					buf.WriteString(chartSection[pos : openEnd+1])
+				}
 				offset = openEnd + 1
 				continue
 			}
@@ -513,7 +671,10 @@ func copyNonSeriesElements(chartSection, nsPrefix string) string {
 				break
 			}
 			end := openEnd + 1 + endRel + len(endTagStr)
-			buf.WriteString(chartSection[pos:end])
+			if !nested(pos) {
+				buf.WriteString(chartSection[pos:end])
+				copied = append(copied, [2]int{pos, end})
+			}
 			offset = end
 		}
 	}
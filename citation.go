@@ -0,0 +1,983 @@
+package godocx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceType identifies the kind of a bibliography source
+type SourceType string
+
+const (
+	SourceBook           SourceType = "Book"
+	SourceJournalArticle SourceType = "JournalArticle"
+	SourceWebsite        SourceType = "InternetSite"
+)
+
+// CitationStyle is the style of citations and the bibliography
+type CitationStyle string
+
+const (
+	// CitationStyleAPA cites by author and year, e.g. "(Smith, 2020)", and
+	// sorts the bibliography by author
+	CitationStyleAPA CitationStyle = "APA"
+
+	// CitationStyleIEEE cites by number, e.g. "[1]", numbering sources in
+	// the order they are first cited
+	CitationStyleIEEE CitationStyle = "IEEE"
+)
+
+// SourceAuthor is a person or organisation credited on a source
+type SourceAuthor struct {
+	First  string
+	Middle string
+	Last   string
+
+	// Corporate is an organisation name, used instead of a person's name
+	Corporate string
+}
+
+// Source is an entry of the document's bibliography, stored in the
+// bibliography custom XML part as Word does
+type Source struct {
+	// Tag is the unique name citations refer to, e.g. "Smi20"
+	Tag string
+
+	Type    SourceType
+	Authors []SourceAuthor
+	Title   string
+	Year    string
+
+	// Books
+	Publisher string
+	City      string
+	Edition   string
+
+	// Journal articles
+	JournalName string
+	Volume      string
+	Issue       string
+	Pages       string
+
+	// Websites
+	SiteName string
+	URL      string
+	Accessed time.Time
+}
+
+// CitationOptions defines options for inserting a citation
+type CitationOptions struct {
+	// Anchor is the text the citation is inserted after (required)
+	Anchor string
+
+	// Pages is the page or page range cited, e.g. "12" or "12-15"
+	Pages string
+}
+
+// BibliographyOptions defines options for inserting a bibliography
+type BibliographyOptions struct {
+	// Title is a Heading 1 paragraph above the bibliography. Empty means no
+	// title paragraph.
+	Title string
+
+	// Position where to insert the bibliography
+	Position InsertPosition
+
+	// Anchor text for position-based insertion
+	Anchor string
+}
+
+// DefaultBibliographyOptions returns default options: a "References"
+// heading at the end of the document
+func DefaultBibliographyOptions() BibliographyOptions {
+	return BibliographyOptions{
+		Title:    "References",
+		Position: PositionEnd,
+	}
+}
+
+// citationStyleSheets maps citation styles to the style sheets Word records
+// on the sources part
+var citationStyleSheets = map[CitationStyle][2]string{
+	CitationStyleAPA:  {`\APASixthEditionOfficeOnline.xsl`, "6"},
+	CitationStyleIEEE: {`\IEEE2006OfficeOnline.xsl`, "2006"},
+}
+
+var (
+	sourceElementPattern = regexp.MustCompile(`(?s)<b:Source>.*?</b:Source>`)
+	sourceTagPattern     = regexp.MustCompile(`<b:Tag>([^<]*)</b:Tag>`)
+	sourcesOpenPattern   = regexp.MustCompile(`<b:Sources\b[^>]*>`)
+	customXMLItemPattern = regexp.MustCompile(`^item(\d+)\.xml$`)
+)
+
+// AddSource adds a source to the document's bibliography, replacing any
+// source with the same tag. The bibliography custom XML part is created on
+// first use. Citation and bibliography results are refreshed.
+func (u *Updater) AddSource(src Source) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if err := validateSource(src); err != nil {
+		return err
+	}
+
+	path, content, err := u.ensureSourcesPart()
+	if err != nil {
+		return err
+	}
+
+	var element bytes.Buffer
+	writeSourceXML(&element, src)
+	replaced := false
+	content = sourceElementPattern.ReplaceAllFunc(content, func(existing []byte) []byte {
+		if m := sourceTagPattern.FindSubmatch(existing); m != nil && xmlUnescape(string(m[1])) == src.Tag && !replaced {
+			replaced = true
+			return element.Bytes()
+		}
+		return existing
+	})
+	if !replaced {
+		closeIdx := bytes.LastIndex(content, []byte("</b:Sources>"))
+		if closeIdx == -1 {
+			return NewXMLParseError(filepath.Base(path), fmt.Errorf("missing </b:Sources>"))
+		}
+		content = spliceBytes(content, closeIdx, closeIdx, element.Bytes())
+	}
+
+	if err := atomicWriteFile(path, content, 0o644); err != nil {
+		return NewXMLWriteError(filepath.Base(path), err)
+	}
+	return u.UpdateCitations()
+}
+
+// GetSources returns the sources of the document's bibliography in the order
+// they are stored
+func (u *Updater) GetSources() ([]Source, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+	lib, err := u.readSourceLibrary()
+	if err != nil || lib == nil {
+		return nil, err
+	}
+	return lib.sources, nil
+}
+
+// SetCitationStyle sets the style of citations and the bibliography and
+// refreshes their results
+func (u *Updater) SetCitationStyle(style CitationStyle) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	sheet, ok := citationStyleSheets[style]
+	if !ok {
+		return NewValidationError("style", fmt.Sprintf("unsupported citation style %q", style))
+	}
+
+	path, content, err := u.ensureSourcesPart()
+	if err != nil {
+		return err
+	}
+	loc := sourcesOpenPattern.FindIndex(content)
+	if loc == nil {
+		return NewXMLParseError(filepath.Base(path), fmt.Errorf("missing <b:Sources>"))
+	}
+	tag := content[loc[0]:loc[1]]
+	for _, attr := range [][2]string{{"SelectedStyle", sheet[0]}, {"StyleName", string(style)}, {"Version", sheet[1]}} {
+		tag = setXMLAttr(tag, attr[0], attr[1])
+	}
+	content = spliceBytes(content, loc[0], loc[1], tag)
+
+	if err := atomicWriteFile(path, content, 0o644); err != nil {
+		return NewXMLWriteError(filepath.Base(path), err)
+	}
+	return u.UpdateCitations()
+}
+
+// InsertCitation inserts a CITATION field for the source with the given tag
+// directly after the first occurrence of opts.Anchor. The cached result is
+// formatted in the document's citation style (default: APA).
+func (u *Updater) InsertCitation(tag string, opts CitationOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if opts.Anchor == "" {
+		return NewValidationError("anchor", "anchor text cannot be empty")
+	}
+	lib, err := u.readSourceLibrary()
+	if err != nil {
+		return err
+	}
+	if lib == nil || lib.source(tag) == nil {
+		return NewValidationError("tag", fmt.Sprintf("source %q not found", tag))
+	}
+
+	field := Field{Instruction: "CITATION " + tag + ` \l 1033`}
+	if opts.Pages != "" {
+		field.Switches = []string{fmt.Sprintf(`\p "%s"`, fieldQuoteEscaper.Replace(opts.Pages))}
+	}
+	var markup bytes.Buffer
+	markup.WriteString(`<w:r><w:t xml:space="preserve"> </w:t></w:r>`)
+	writeFieldXML(&markup, field, RunOptions{})
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return NewXMLParseError("document.xml", err)
+	}
+	raw, err = insertAtDocumentText(raw, 0, opts.Anchor, true, markup.String())
+	if err != nil {
+		return err
+	}
+
+	// A new citation can renumber the others in IEEE style.
+	raw = refreshCitations(raw, lib)
+	if err := atomicWriteFile(docPath, raw, 0o644); err != nil {
+		return NewXMLWriteError("document.xml", err)
+	}
+	return nil
+}
+
+// InsertBibliography inserts a BIBLIOGRAPHY field listing every source of
+// the document, prebuilt in the document's citation style
+func (u *Updater) InsertBibliography(opts BibliographyOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	lib, err := u.readSourceLibrary()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if opts.Title != "" {
+		buf.WriteString(`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r>`)
+		writeRunTextWithControls(&buf, opts.Title)
+		buf.WriteString(`</w:r></w:p>`)
+	}
+	buf.Write(generateBibliographyXML("BIBLIOGRAPHY", nil, CitationStyleAPA, nil))
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return NewXMLParseError("document.xml", err)
+	}
+	raw, err = insertTOCAtPosition(raw, buf.Bytes(), TOCOptions{Position: opts.Position, Anchor: opts.Anchor})
+	if err != nil {
+		return fmt.Errorf("insert bibliography: %w", err)
+	}
+
+	raw = refreshCitations(raw, lib)
+	if err := u.ensureFieldResultStyles(raw); err != nil {
+		return err
+	}
+	if err := atomicWriteFile(docPath, raw, 0o644); err != nil {
+		return NewXMLWriteError("document.xml", err)
+	}
+	return nil
+}
+
+// UpdateCitations recomputes the results of every CITATION and BIBLIOGRAPHY
+// field in the document body from the current sources and citation style
+func (u *Updater) UpdateCitations() error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	lib, err := u.readSourceLibrary()
+	if err != nil {
+		return err
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return NewXMLParseError("document.xml", err)
+	}
+	updated := refreshCitations(raw, lib)
+	if bytes.Equal(updated, raw) {
+		return nil
+	}
+	if err := u.ensureFieldResultStyles(updated); err != nil {
+		return err
+	}
+	if err := atomicWriteFile(docPath, updated, 0o644); err != nil {
+		return NewXMLWriteError("document.xml", err)
+	}
+	return nil
+}
+
+// validateSource checks that a source can be stored and cited
+func validateSource(src Source) error {
+	if src.Tag == "" {
+		return NewValidationError("Tag", "source tag cannot be empty")
+	}
+	for _, r := range src.Tag {
+		if !isLetter(r) && (r < '0' || r > '9') && r != '_' && r != '-' && r != '.' {
+			return NewValidationError("Tag", "source tag can only contain letters, digits, '_', '-' and '.'")
+		}
+	}
+	switch src.Type {
+	case SourceBook, SourceJournalArticle, SourceWebsite:
+	default:
+		return NewValidationError("Type", fmt.Sprintf("unsupported source type %q", src.Type))
+	}
+	if src.Title == "" {
+		return NewValidationError("Title", "source title cannot be empty")
+	}
+	return nil
+}
+
+// writeSourceXML writes a <b:Source> element
+func writeSourceXML(buf *bytes.Buffer, src Source) {
+	element := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(buf, "<b:%s>%s</b:%s>", name, xmlEscape(value), name)
+		}
+	}
+
+	buf.WriteString("<b:Source>")
+	element("Tag", src.Tag)
+	element("SourceType", string(src.Type))
+	element("Guid", sourceGUID(src.Tag))
+
+	var persons []SourceAuthor
+	corporate := ""
+	for _, a := range src.Authors {
+		if a.Corporate != "" {
+			corporate = a.Corporate
+			continue
+		}
+		persons = append(persons, a)
+	}
+	if len(persons) > 0 || corporate != "" {
+		buf.WriteString("<b:Author><b:Author>")
+		if corporate != "" {
+			element("Corporate", corporate)
+		} else {
+			buf.WriteString("<b:NameList>")
+			for _, p := range persons {
+				buf.WriteString("<b:Person>")
+				element("Last", p.Last)
+				element("First", p.First)
+				element("Middle", p.Middle)
+				buf.WriteString("</b:Person>")
+			}
+			buf.WriteString("</b:NameList>")
+		}
+		buf.WriteString("</b:Author></b:Author>")
+	}
+
+	element("Title", src.Title)
+	element("Year", src.Year)
+	element("City", src.City)
+	element("Publisher", src.Publisher)
+	element("Edition", src.Edition)
+	element("JournalName", src.JournalName)
+	element("Volume", src.Volume)
+	element("Issue", src.Issue)
+	element("Pages", src.Pages)
+	element("InternetSiteTitle", src.SiteName)
+	element("URL", src.URL)
+	if !src.Accessed.IsZero() {
+		element("YearAccessed", strconv.Itoa(src.Accessed.Year()))
+		element("MonthAccessed", src.Accessed.Month().String())
+		element("DayAccessed", strconv.Itoa(src.Accessed.Day()))
+	}
+	buf.WriteString("</b:Source>")
+}
+
+// sourceGUID derives a stable GUID for a source from its tag
+func sourceGUID(tag string) string {
+	h := fnv.New64a()
+	h.Write([]byte(tag))
+	sum := h.Sum64()
+	return fmt.Sprintf("{%08X-%04X-4%03X-8%03X-%012X}", uint32(sum>>32), uint16(sum>>16), uint16(sum)&0xFFF, uint16(sum>>4)&0xFFF, sum&0xFFFFFFFFFFFF)
+}
+
+// setXMLAttr sets an attribute of an opening tag, adding it if needed
+func setXMLAttr(tag []byte, name, value string) []byte {
+	attr := fmt.Sprintf(`%s="%s"`, name, xmlEscape(value))
+	pattern := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `="[^"]*"`)
+	if loc := pattern.FindIndex(tag); loc != nil {
+		return spliceBytes(tag, loc[0]+1, loc[1], []byte(attr))
+	}
+	end := len(tag) - 1
+	if bytes.HasSuffix(tag, []byte("/>")) {
+		end--
+	}
+	return spliceBytes(tag, end, end, []byte(" "+attr))
+}
+
+// sourcesPartPath returns the path of the custom XML part holding the
+// bibliography sources, or "" if the document has none
+func (u *Updater) sourcesPartPath() string {
+	entries, err := os.ReadDir(filepath.Join(u.tempDir, "customXml"))
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !customXMLItemPattern.MatchString(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(u.tempDir, "customXml", entry.Name())
+		if raw, err := os.ReadFile(path); err == nil && bytes.Contains(raw, []byte(BibliographyNS)) && bytes.Contains(raw, []byte("Sources")) {
+			return path
+		}
+	}
+	return ""
+}
+
+// ensureSourcesPart returns the path and content of the bibliography custom
+// XML part, creating it with its properties part, relationships and content
+// type when the document has none
+func (u *Updater) ensureSourcesPart() (string, []byte, error) {
+	if path := u.sourcesPartPath(); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, NewXMLParseError(filepath.Base(path), err)
+		}
+		return path, raw, nil
+	}
+
+	dir := filepath.Join(u.tempDir, "customXml")
+	if err := os.MkdirAll(filepath.Join(dir, "_rels"), 0o755); err != nil {
+		return "", nil, fmt.Errorf("create customXml directory: %w", err)
+	}
+	n := 1
+	for ; ; n++ {
+		if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("item%d.xml", n))); os.IsNotExist(err) {
+			break
+		}
+	}
+	item := fmt.Sprintf("item%d.xml", n)
+	props := fmt.Sprintf("itemProps%d.xml", n)
+	sheet := citationStyleSheets[CitationStyleAPA]
+
+	content := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" +
+		fmt.Sprintf(`<b:Sources SelectedStyle="%s" StyleName="%s" Version="%s" xmlns:b="%s" xmlns="%s"></b:Sources>`,
+			xmlEscape(sheet[0]), CitationStyleAPA, sheet[1], BibliographyNS, BibliographyNS))
+	propsXML := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" +
+		fmt.Sprintf(`<ds:datastoreItem ds:itemID="%s" xmlns:ds="%s"><ds:schemaRefs><ds:schemaRef ds:uri="%s"/></ds:schemaRefs></ds:datastoreItem>`,
+			sourceGUID(item), CustomXMLNS, BibliographyNS)
+	relsXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		fmt.Sprintf(`<Relationships xmlns="%s"><Relationship Id="rId1" Type="%s/customXmlProps" Target="%s"/></Relationships>`,
+			RelationshipsNS, OfficeDocumentNS, props)
+
+	files := []struct {
+		path string
+		data []byte
+	}{
+		{filepath.Join(dir, item), content},
+		{filepath.Join(dir, props), []byte(propsXML)},
+		{filepath.Join(dir, "_rels", item+".rels"), []byte(relsXML)},
+	}
+	for _, f := range files {
+		if err := atomicWriteFile(f.path, f.data, 0o644); err != nil {
+			return "", nil, NewXMLWriteError(filepath.Base(f.path), err)
+		}
+	}
+
+	if _, err := u.addPartRelationship("document.xml", OfficeDocumentNS+"/customXml", "../customXml/"+item, false); err != nil {
+		return "", nil, fmt.Errorf("add custom XML relationship: %w", err)
+	}
+	if err := u.addCustomXMLContentTypes(props); err != nil {
+		return "", nil, err
+	}
+	return filepath.Join(dir, item), content, nil
+}
+
+// addCustomXMLContentTypes registers a custom XML properties part and the
+// xml extension in [Content_Types].xml
+func (u *Updater) addCustomXMLContentTypes(propsName string) error {
+	if err := u.addImageContentType("xml", "application/xml"); err != nil {
+		return err
+	}
+
+	contentTypesPath := filepath.Join(u.tempDir, "[Content_Types].xml")
+	raw, err := os.ReadFile(contentTypesPath)
+	if err != nil {
+		return fmt.Errorf("read content types: %w", err)
+	}
+	content := string(raw)
+	if strings.Contains(content, "/customXml/"+propsName) {
+		return nil
+	}
+
+	override := fmt.Sprintf(`<Override PartName="/customXml/%s" ContentType="application/vnd.openxmlformats-officedocument.customXmlProperties+xml"/>`, propsName)
+	content = strings.Replace(content, "</Types>", override+"</Types>", 1)
+
+	if err := atomicWriteFile(contentTypesPath, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("write content types: %w", err)
+	}
+	return nil
+}
+
+// sourceLibrary is the parsed bibliography custom XML part
+type sourceLibrary struct {
+	style   CitationStyle
+	sources []Source
+}
+
+// xmlSources mirrors the b:Sources element for decoding
+type xmlSources struct {
+	StyleName string      `xml:"StyleName,attr"`
+	Sources   []xmlSource `xml:"Source"`
+}
+
+type xmlSource struct {
+	Tag               string      `xml:"Tag"`
+	SourceType        string      `xml:"SourceType"`
+	Persons           []xmlPerson `xml:"Author>Author>NameList>Person"`
+	Corporate         string      `xml:"Author>Author>Corporate"`
+	Title             string      `xml:"Title"`
+	Year              string      `xml:"Year"`
+	City              string      `xml:"City"`
+	Publisher         string      `xml:"Publisher"`
+	Edition           string      `xml:"Edition"`
+	JournalName       string      `xml:"JournalName"`
+	Volume            string      `xml:"Volume"`
+	Issue             string      `xml:"Issue"`
+	Pages             string      `xml:"Pages"`
+	InternetSiteTitle string      `xml:"InternetSiteTitle"`
+	URL               string      `xml:"URL"`
+	YearAccessed      string      `xml:"YearAccessed"`
+	MonthAccessed     string      `xml:"MonthAccessed"`
+	DayAccessed       string      `xml:"DayAccessed"`
+}
+
+type xmlPerson struct {
+	Last   string `xml:"Last"`
+	First  string `xml:"First"`
+	Middle string `xml:"Middle"`
+}
+
+// readSourceLibrary parses the bibliography custom XML part. It returns nil
+// when the document has no sources part.
+func (u *Updater) readSourceLibrary() (*sourceLibrary, error) {
+	path := u.sourcesPartPath()
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, NewXMLParseError(filepath.Base(path), err)
+	}
+	var parsed xmlSources
+	if err := xml.Unmarshal(raw, &parsed); err != nil {
+		return nil, NewXMLParseError(filepath.Base(path), err)
+	}
+
+	lib := &sourceLibrary{style: CitationStyleAPA}
+	if strings.EqualFold(parsed.StyleName, string(CitationStyleIEEE)) {
+		lib.style = CitationStyleIEEE
+	}
+	for _, s := range parsed.Sources {
+		src := Source{
+			Tag:         s.Tag,
+			Type:        SourceType(s.SourceType),
+			Title:       s.Title,
+			Year:        s.Year,
+			City:        s.City,
+			Publisher:   s.Publisher,
+			Edition:     s.Edition,
+			JournalName: s.JournalName,
+			Volume:      s.Volume,
+			Issue:       s.Issue,
+			Pages:       s.Pages,
+			SiteName:    s.InternetSiteTitle,
+			URL:         s.URL,
+		}
+		for _, p := range s.Persons {
+			src.Authors = append(src.Authors, SourceAuthor{First: p.First, Middle: p.Middle, Last: p.Last})
+		}
+		if s.Corporate != "" {
+			src.Authors = append(src.Authors, SourceAuthor{Corporate: s.Corporate})
+		}
+		if year := atoiOrZero(s.YearAccessed); year > 0 {
+			month := time.January
+			if m, err := time.Parse("January", s.MonthAccessed); err == nil {
+				month = m.Month()
+			} else if n := atoiOrZero(s.MonthAccessed); n >= 1 && n <= 12 {
+				month = time.Month(n)
+			}
+			day := max(atoiOrZero(s.DayAccessed), 1)
+			src.Accessed = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		}
+		lib.sources = append(lib.sources, src)
+	}
+	return lib, nil
+}
+
+// source returns the source with the given tag, or nil
+func (lib *sourceLibrary) source(tag string) *Source {
+	for i := range lib.sources {
+		if strings.EqualFold(lib.sources[i].Tag, tag) {
+			return &lib.sources[i]
+		}
+	}
+	return nil
+}
+
+// numbers assigns IEEE reference numbers: cited sources in the order of
+// their first citation in the body, then the uncited ones
+func (lib *sourceLibrary) numbers(body []byte) map[string]int {
+	numbers := make(map[string]int)
+	assign := func(tag string) {
+		if src := lib.source(tag); src != nil {
+			if _, ok := numbers[src.Tag]; !ok {
+				numbers[src.Tag] = len(numbers) + 1
+			}
+		}
+	}
+	for _, f := range parseDocumentFields(body) {
+		if code := parseFieldCode(f.instr.String()); code.name == "CITATION" && len(code.args) > 0 {
+			assign(code.args[0])
+		}
+	}
+	for _, src := range lib.sources {
+		assign(src.Tag)
+	}
+	return numbers
+}
+
+// citationText formats the result of a CITATION field
+func (lib *sourceLibrary) citationText(code fieldCode, numbers map[string]int) string {
+	if len(code.args) == 0 {
+		return ""
+	}
+	src := lib.source(code.args[0])
+	if src == nil {
+		return " Invalid source specified."
+	}
+	pages := ""
+	if p := code.switches["p"]; p != "" {
+		prefix := "p. "
+		if strings.ContainsAny(p, "-–,") {
+			prefix = "pp. "
+		}
+		pages = ", " + prefix + p
+	}
+
+	if lib.style == CitationStyleIEEE {
+		return fmt.Sprintf("[%d%s]", numbers[src.Tag], pages)
+	}
+	return fmt.Sprintf("(%s, %s%s)", apaCitationAuthors(*src), sourceYear(*src), pages)
+}
+
+// refreshCitations rewrites the results of the CITATION and BIBLIOGRAPHY
+// fields of a document body
+func refreshCitations(raw []byte, lib *sourceLibrary) []byte {
+	if lib == nil {
+		lib = &sourceLibrary{style: CitationStyleAPA}
+	}
+	numbers := lib.numbers(raw)
+
+	fields := parseDocumentFields(raw)
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		code := parseFieldCode(f.instr.String())
+		if !f.valid || f.simple || code.name != "CITATION" {
+			continue
+		}
+		result := lib.citationText(code, numbers)
+		if extractVisibleText(raw[f.resultStart:f.resultEnd]) == result {
+			continue
+		}
+		var run bytes.Buffer
+		run.WriteString("<w:r>")
+		run.Write(fieldResultRunProperties(raw, f))
+		writeRunTextWithControls(&run, result)
+		run.WriteString("</w:r>")
+		raw = spliceBytes(raw, f.resultStart, f.resultEnd, run.Bytes())
+	}
+
+	for i := range namedFields(raw, "BIBLIOGRAPHY") {
+		f, start, end, err := fieldParagraphs(raw, "BIBLIOGRAPHY", i)
+		if err != nil {
+			break
+		}
+		raw = spliceBytes(raw, start, end, generateBibliographyXML(f.instr.String(), lib.sources, lib.style, numbers))
+	}
+	return raw
+}
+
+// bibliographyRun is a piece of a bibliography entry
+type bibliographyRun struct {
+	text   string
+	italic bool
+}
+
+// generateBibliographyXML writes the paragraphs of a BIBLIOGRAPHY field.
+// APA entries are sorted by author with a hanging indent; IEEE entries are
+// listed by reference number.
+func generateBibliographyXML(instr string, sources []Source, style CitationStyle, numbers map[string]int) []byte {
+	var buf bytes.Buffer
+	if len(sources) == 0 {
+		buf.WriteString("<w:p>")
+		writeFieldBeginXML(&buf, instr)
+		buf.WriteString("<w:r><w:t>There are no sources in the current document.</w:t></w:r>")
+		buf.WriteString(fieldEndRunXML)
+		buf.WriteString("</w:p>")
+		return buf.Bytes()
+	}
+
+	sorted := append([]Source{}, sources...)
+	if style == CitationStyleIEEE {
+		sort.SliceStable(sorted, func(i, j int) bool { return numbers[sorted[i].Tag] < numbers[sorted[j].Tag] })
+	} else {
+		sort.SliceStable(sorted, func(i, j int) bool { return apaSortKey(sorted[i]) < apaSortKey(sorted[j]) })
+	}
+
+	for i, src := range sorted {
+		buf.WriteString(`<w:p><w:pPr><w:pStyle w:val="Bibliography"/>`)
+		var runs []bibliographyRun
+		if style == CitationStyleIEEE {
+			buf.WriteString(`<w:tabs><w:tab w:val="left" w:pos="720"/></w:tabs>`)
+			runs = append([]bibliographyRun{{text: fmt.Sprintf("[%d]\t", numbers[src.Tag])}}, ieeeReference(src)...)
+		} else {
+			runs = apaReference(src)
+		}
+		buf.WriteString(`<w:ind w:left="720" w:hanging="720"/></w:pPr>`)
+
+		if i == 0 {
+			writeFieldBeginXML(&buf, instr)
+		}
+		for _, r := range runs {
+			if r.text == "" {
+				continue
+			}
+			buf.WriteString("<w:r>")
+			writeRunPropertiesXML(&buf, RunOptions{Italic: r.italic})
+			writeRunTextWithControls(&buf, r.text)
+			buf.WriteString("</w:r>")
+		}
+		if i == len(sorted)-1 {
+			buf.WriteString(fieldEndRunXML)
+		}
+		buf.WriteString("</w:p>")
+	}
+	return buf.Bytes()
+}
+
+// sourceYear returns the year of a source, or "n.d." when it has none
+func sourceYear(src Source) string {
+	if src.Year == "" {
+		return "n.d."
+	}
+	return src.Year
+}
+
+// authorInitials returns "J. A." for a person's first and middle names
+func authorInitials(a SourceAuthor) string {
+	var initials []string
+	for _, name := range strings.Fields(a.First + " " + a.Middle) {
+		r := []rune(name)
+		initials = append(initials, string(r[0])+".")
+	}
+	return strings.Join(initials, " ")
+}
+
+// apaCitationAuthors returns the author part of an APA citation:
+// "Smith", "Smith & Jones" or "Smith et al."
+func apaCitationAuthors(src Source) string {
+	var names []string
+	for _, a := range src.Authors {
+		if a.Corporate != "" {
+			names = append(names, a.Corporate)
+		} else {
+			names = append(names, a.Last)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return src.Title
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " & " + names[1]
+	default:
+		return names[0] + " et al."
+	}
+}
+
+// apaSortKey orders APA entries by first author, then year and title
+func apaSortKey(src Source) string {
+	key := src.Title
+	if len(src.Authors) > 0 {
+		key = src.Authors[0].Corporate + src.Authors[0].Last + " " + src.Authors[0].First
+	}
+	return strings.ToLower(key + "\x00" + src.Year + "\x00" + src.Title)
+}
+
+// joinAuthors joins author names with commas and conjunction before the last
+// one, e.g. "A, B, & C"
+func joinAuthors(names []string, conjunction string, serialComma bool) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		if serialComma {
+			return names[0] + ", " + conjunction + " " + names[1]
+		}
+		return names[0] + " " + conjunction + " " + names[1]
+	}
+	last := len(names) - 1
+	return strings.Join(names[:last], ", ") + ", " + conjunction + " " + names[last]
+}
+
+// apaReference formats a bibliography entry in APA style
+func apaReference(src Source) []bibliographyRun {
+	var names []string
+	for _, a := range src.Authors {
+		if a.Corporate != "" {
+			names = append(names, a.Corporate)
+			continue
+		}
+		name := a.Last
+		if initials := authorInitials(a); initials != "" {
+			name += ", " + initials
+		}
+		names = append(names, name)
+	}
+
+	var runs []bibliographyRun
+	year := "(" + sourceYear(src) + "). "
+	titleItalic := src.Type != SourceJournalArticle
+	if len(names) > 0 {
+		authors := joinAuthors(names, "&", true)
+		if !strings.HasSuffix(authors, ".") {
+			authors += "."
+		}
+		runs = append(runs,
+			bibliographyRun{text: authors + " " + year},
+			bibliographyRun{text: src.Title, italic: titleItalic},
+			bibliographyRun{text: ". "})
+	} else {
+		runs = append(runs,
+			bibliographyRun{text: src.Title, italic: titleItalic},
+			bibliographyRun{text: ". " + year})
+	}
+
+	switch src.Type {
+	case SourceBook:
+		if src.Edition != "" {
+			runs[len(runs)-1].text = strings.TrimSuffix(runs[len(runs)-1].text, ". ") + " (" + src.Edition + " ed.). "
+		}
+		switch {
+		case src.City != "" && src.Publisher != "":
+			runs = append(runs, bibliographyRun{text: src.City + ": " + src.Publisher + "."})
+		case src.Publisher != "":
+			runs = append(runs, bibliographyRun{text: src.Publisher + "."})
+		}
+	case SourceJournalArticle:
+		runs = append(runs, bibliographyRun{text: src.JournalName, italic: true})
+		if src.Volume != "" {
+			runs = append(runs, bibliographyRun{text: ", "}, bibliographyRun{text: src.Volume, italic: true})
+		}
+		if src.Issue != "" {
+			runs = append(runs, bibliographyRun{text: "(" + src.Issue + ")"})
+		}
+		if src.Pages != "" {
+			runs = append(runs, bibliographyRun{text: ", " + src.Pages})
+		}
+		runs = append(runs, bibliographyRun{text: "."})
+	case SourceWebsite:
+		if src.URL != "" {
+			retrieved := "Retrieved from "
+			if !src.Accessed.IsZero() {
+				retrieved = "Retrieved " + src.Accessed.Format("January 2, 2006") + ", from "
+			}
+			runs = append(runs, bibliographyRun{text: retrieved + src.URL})
+		}
+	}
+	trimBibliographyRuns(runs)
+	return runs
+}
+
+// ieeeReference formats a bibliography entry in IEEE style, without its
+// number
+func ieeeReference(src Source) []bibliographyRun {
+	var names []string
+	for _, a := range src.Authors {
+		if a.Corporate != "" {
+			names = append(names, a.Corporate)
+			continue
+		}
+		name := a.Last
+		if initials := authorInitials(a); initials != "" {
+			name = initials + " " + a.Last
+		}
+		names = append(names, name)
+	}
+
+	var runs []bibliographyRun
+	if len(names) > 0 {
+		runs = append(runs, bibliographyRun{text: joinAuthors(names, "and", false) + ", "})
+	}
+	var details []string
+	switch src.Type {
+	case SourceBook:
+		runs = append(runs, bibliographyRun{text: src.Title, italic: true})
+		if src.Edition != "" {
+			details = append(details, src.Edition+" ed.")
+		}
+		switch {
+		case src.City != "" && src.Publisher != "":
+			details = append(details, src.City+": "+src.Publisher)
+		case src.Publisher != "":
+			details = append(details, src.Publisher)
+		}
+		details = append(details, sourceYear(src))
+		runs = append(runs, bibliographyRun{text: ", " + strings.Join(details, ", ") + "."})
+	case SourceJournalArticle:
+		runs = append(runs,
+			bibliographyRun{text: `"` + src.Title + `," `},
+			bibliographyRun{text: src.JournalName, italic: true})
+		if src.Volume != "" {
+			details = append(details, "vol. "+src.Volume)
+		}
+		if src.Issue != "" {
+			details = append(details, "no. "+src.Issue)
+		}
+		if src.Pages != "" {
+			details = append(details, "pp. "+src.Pages)
+		}
+		details = append(details, sourceYear(src))
+		runs = append(runs, bibliographyRun{text: ", " + strings.Join(details, ", ") + "."})
+	case SourceWebsite:
+		text := `"` + src.Title + `,"`
+		if src.SiteName != "" {
+			text += " " + src.SiteName + ","
+		}
+		text += " " + sourceYear(src)
+		if !strings.HasSuffix(text, ".") {
+			text += "."
+		}
+		if src.URL != "" {
+			text += " [Online]. Available: " + src.URL + "."
+		}
+		if !src.Accessed.IsZero() {
+			text += " [Accessed " + src.Accessed.Format("2 Jan. 2006") + "]."
+		}
+		runs = append(runs, bibliographyRun{text: text})
+	}
+	return runs
+}
+
+// trimBibliographyRuns removes the trailing space of an entry
+func trimBibliographyRuns(runs []bibliographyRun) {
+	if n := len(runs); n > 0 {
+		runs[n-1].text = strings.TrimRight(runs[n-1].text, " ")
+	}
+}
@@ -0,0 +1,221 @@
+package godocx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func citationFixtureSources() []Source {
+	return []Source{
+		{
+			Tag:       "Knu97",
+			Type:      SourceBook,
+			Authors:   []SourceAuthor{{First: "Donald", Middle: "Ervin", Last: "Knuth"}},
+			Title:     "The Art of Computer Programming",
+			Year:      "1997",
+			City:      "Reading",
+			Publisher: "Addison-Wesley",
+			Edition:   "3rd",
+		},
+		{
+			Tag:         "Dij68",
+			Type:        SourceJournalArticle,
+			Authors:     []SourceAuthor{{First: "Edsger", Last: "Dijkstra"}},
+			Title:       "Go To Statement Considered Harmful",
+			Year:        "1968",
+			JournalName: "Communications of the ACM",
+			Volume:      "11",
+			Issue:       "3",
+			Pages:       "147-148",
+		},
+		{
+			Tag:      "W3C",
+			Type:     SourceWebsite,
+			Authors:  []SourceAuthor{{Corporate: "W3C"}},
+			Title:    "Extensible Markup Language (XML) 1.0",
+			SiteName: "W3C",
+			URL:      "https://www.w3.org/TR/xml/",
+			Accessed: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestAddSource(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Text.</w:t></w:r></w:p>`))
+
+	for _, src := range citationFixtureSources() {
+		if err := u.AddSource(src); err != nil {
+			t.Fatalf("AddSource(%s): %v", src.Tag, err)
+		}
+	}
+	updated := citationFixtureSources()[0]
+	updated.Year = "1998"
+	if err := u.AddSource(updated); err != nil {
+		t.Fatalf("AddSource replace: %v", err)
+	}
+	if err := u.AddSource(Source{Tag: "bad tag", Type: SourceBook, Title: "X"}); err == nil {
+		t.Error("expected a validation error for a tag with a space")
+	}
+	if err := u.AddSource(Source{Tag: "NoTitle", Type: SourceBook}); err == nil {
+		t.Error("expected a validation error for a source without title")
+	}
+
+	sources, err := u.GetSources()
+	if err != nil {
+		t.Fatalf("GetSources: %v", err)
+	}
+	if len(sources) != 3 {
+		t.Fatalf("expected 3 sources, got %d", len(sources))
+	}
+	if sources[0].Year != "1998" || sources[0].Authors[0].Middle != "Ervin" || sources[0].Edition != "3rd" {
+		t.Errorf("unexpected first source %+v", sources[0])
+	}
+	if sources[1].Issue != "3" || sources[1].JournalName != "Communications of the ACM" {
+		t.Errorf("unexpected journal article %+v", sources[1])
+	}
+	if sources[2].Authors[0].Corporate != "W3C" || !sources[2].Accessed.Equal(time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected website %+v", sources[2])
+	}
+
+	item, err := os.ReadFile(filepath.Join(u.TempDir(), "customXml", "item1.xml"))
+	if err != nil {
+		t.Fatalf("read custom XML part: %v", err)
+	}
+	if !strings.Contains(string(item), `StyleName="APA"`) || strings.Count(string(item), "<b:Source>") != 3 {
+		t.Errorf("unexpected sources part %s", item)
+	}
+	if _, err := os.Stat(filepath.Join(u.TempDir(), "customXml", "_rels", "item1.xml.rels")); err != nil {
+		t.Errorf("expected custom XML part relationships: %v", err)
+	}
+	rels := readWordPart(t, u, filepath.Join("_rels", "document.xml.rels"))
+	if !strings.Contains(rels, `Target="../customXml/item1.xml"`) {
+		t.Errorf("expected customXml relationship, got %s", rels)
+	}
+	types, _ := os.ReadFile(filepath.Join(u.TempDir(), "[Content_Types].xml"))
+	if !strings.Contains(string(types), `/customXml/itemProps1.xml`) || !strings.Contains(string(types), `Extension="xml"`) {
+		t.Errorf("expected custom XML content types, got %s", types)
+	}
+}
+
+func TestInsertCitationAndBibliography(t *testing.T) {
+	body := `<w:p><w:r><w:t>Structured programming matters.</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Algorithms are analysed in depth.</w:t></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+	for _, src := range citationFixtureSources() {
+		if err := u.AddSource(src); err != nil {
+			t.Fatalf("AddSource(%s): %v", src.Tag, err)
+		}
+	}
+
+	if err := u.InsertCitation("Dij68", CitationOptions{Anchor: "programming matters"}); err != nil {
+		t.Fatalf("InsertCitation: %v", err)
+	}
+	if err := u.InsertCitation("Knu97", CitationOptions{Anchor: "in depth", Pages: "12-15"}); err != nil {
+		t.Fatalf("InsertCitation with pages: %v", err)
+	}
+	if err := u.InsertCitation("Missing", CitationOptions{Anchor: "in depth"}); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+	if err := u.InsertBibliography(DefaultBibliographyOptions()); err != nil {
+		t.Fatalf("InsertBibliography: %v", err)
+	}
+
+	texts := bodyParagraphTexts(t, u)
+	want := []string{
+		"Structured programming matters (Dijkstra, 1968).",
+		"Algorithms are analysed in depth (Knuth, 1997, pp. 12-15).",
+		"References",
+		"Dijkstra, E. (1968). Go To Statement Considered Harmful. Communications of the ACM, 11(3), 147-148.",
+		"Knuth, D. E. (1997). The Art of Computer Programming (3rd ed.). Reading: Addison-Wesley.",
+		"W3C. (n.d.). Extensible Markup Language (XML) 1.0. Retrieved March 5, 2024, from https://www.w3.org/TR/xml/",
+	}
+	if strings.Join(texts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected APA text:\n%s\nwant:\n%s", strings.Join(texts, "\n"), strings.Join(want, "\n"))
+	}
+	doc := readDocXML(t, u)
+	for _, wantXML := range []string{
+		`> CITATION Knu97 \l 1033 </w:instrText></w:r><w:r><w:instrText xml:space="preserve">\p &quot;12-15&quot; </w:instrText>`,
+		`> BIBLIOGRAPHY </w:instrText>`,
+		`<w:pStyle w:val="Bibliography"/>`,
+		`<w:r><w:rPr><w:i/></w:rPr><w:t>The Art of Computer Programming</w:t></w:r>`,
+	} {
+		if !strings.Contains(doc, wantXML) {
+			t.Errorf("expected %s in document, got %s", wantXML, doc)
+		}
+	}
+	// The fixture has no styles part, so the entry and title styles are created.
+	styles := readWordPart(t, u, "styles.xml")
+	for _, wantXML := range []string{
+		`w:styleId="Bibliography"><w:name w:val="Bibliography"/>`,
+		`w:styleId="Heading1"><w:name w:val="heading 1"/>`,
+	} {
+		if !strings.Contains(styles, wantXML) {
+			t.Errorf("expected %s in styles, got %s", wantXML, styles)
+		}
+	}
+
+	if err := u.SetCitationStyle(CitationStyleIEEE); err != nil {
+		t.Fatalf("SetCitationStyle: %v", err)
+	}
+	texts = bodyParagraphTexts(t, u)
+	want = []string{
+		"Structured programming matters [1].",
+		"Algorithms are analysed in depth [2, pp. 12-15].",
+		"References",
+		`[1]E. Dijkstra, "Go To Statement Considered Harmful," Communications of the ACM, vol. 11, no. 3, pp. 147-148, 1968.`,
+		"[2]D. E. Knuth, The Art of Computer Programming, 3rd ed., Reading: Addison-Wesley, 1997.",
+		`[3]W3C, "Extensible Markup Language (XML) 1.0," W3C, n.d. [Online]. Available: https://www.w3.org/TR/xml/. [Accessed 5 Mar. 2024].`,
+	}
+	if strings.Join(texts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected IEEE text:\n%s\nwant:\n%s", strings.Join(texts, "\n"), strings.Join(want, "\n"))
+	}
+
+	// UpdateFields agrees with the cached citation results.
+	before := readDocXML(t, u)
+	if err := u.UpdateFields(); err != nil {
+		t.Fatalf("UpdateFields: %v", err)
+	}
+	if after := readDocXML(t, u); after != before {
+		t.Errorf("UpdateFields changed citation results:\n%s", after)
+	}
+	if err := u.SetCitationStyle("MLA"); err == nil {
+		t.Error("expected an error for an unsupported style")
+	}
+}
+
+func TestInsertBibliography_NoSources(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Text.</w:t></w:r></w:p>`))
+
+	if err := u.InsertBibliography(BibliographyOptions{Position: PositionEnd}); err != nil {
+		t.Fatalf("InsertBibliography: %v", err)
+	}
+	texts := bodyParagraphTexts(t, u)
+	if got := texts[len(texts)-1]; got != "There are no sources in the current document." {
+		t.Errorf("unexpected placeholder %q", got)
+	}
+}
+
+func TestInsertCitation_EscapesPages(t *testing.T) {
+	body := `<w:p><w:r><w:t>Algorithms are analysed in depth.</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+	for _, src := range citationFixtureSources() {
+		if err := u.AddSource(src); err != nil {
+			t.Fatalf("AddSource(%s): %v", src.Tag, err)
+		}
+	}
+
+	if err := u.InsertCitation("Knu97", CitationOptions{Anchor: "in depth", Pages: `12 "note" \`}); err != nil {
+		t.Fatalf("InsertCitation: %v", err)
+	}
+	doc := readDocXML(t, u)
+	if want := `\p &quot;12 \&quot;note\&quot; \\&quot; </w:instrText>`; !strings.Contains(doc, want) {
+		t.Errorf("expected %s in document, got %s", want, doc)
+	}
+	if texts := bodyParagraphTexts(t, u); texts[0] != `Algorithms are analysed in depth (Knuth, 1997, p. 12 "note" \).` {
+		t.Errorf("unexpected citation text %q", texts[0])
+	}
+}
@@ -13,18 +13,45 @@ import (
 
 // CommentOptions defines options for inserting a comment
 type CommentOptions struct {
-	// Text is the comment content
+	// Text is the comment content. Ignored when Paragraphs is non-empty.
 	Text string
 
+	// Paragraphs is a rich comment body. Each entry becomes one paragraph of
+	// independently formatted runs. Runs with a URL become hyperlinks; an
+	// @mention is a run whose URL is a "mailto:" address.
+	Paragraphs [][]RunOptions
+
 	// Author is the comment author name
 	Author string
 
 	// Initials is the author's initials (derived from Author if empty)
 	Initials string
 
-	// Anchor is the text in the document to attach the comment to.
-	// The comment range will span the paragraph containing this text.
+	// Anchor is the text in the document to attach the comment to. The
+	// comment range covers exactly this text, even when it spans several
+	// runs. If the text only matches after whitespace normalisation, the
+	// range falls back to the whole paragraph containing it.
+	// Ignored by ReplyToComment, which reuses the parent comment's range.
 	Anchor string
+
+	// AnchorEnd optionally extends the range from the start of Anchor to
+	// the end of the first following occurrence of this text, which may be
+	// in a later paragraph.
+	AnchorEnd string
+
+	// Cell attaches the comment to the full contents of a table cell
+	// instead of Anchor.
+	Cell *TableCellRef
+
+	// Date is the comment timestamp (default: current time)
+	Date time.Time
+}
+
+// TableCellRef addresses a table cell. Table, Row and Col are all 1-based.
+type TableCellRef struct {
+	Table int
+	Row   int
+	Col   int
 }
 
 // Comment represents an existing comment in the document
@@ -33,7 +60,28 @@ type Comment struct {
 	Author   string
 	Initials string
 	Date     string
-	Text     string
+
+	// Text is the plain text of the comment body; paragraphs are separated
+	// by newlines.
+	Text string
+
+	// ParaID is the w14:paraId of the comment's last paragraph. Word uses it
+	// to link replies and resolved state in commentsExtended.xml.
+	ParaID string
+
+	// ParentID is the ID of the comment this one replies to, or 0 for a
+	// top-level comment.
+	ParentID int
+
+	// Resolved reports whether the comment has been marked as done.
+	Resolved bool
+
+	// AnchorText is the document text enclosed by the comment's range markers.
+	AnchorText string
+
+	// Replies holds the replies to this comment in document order.
+	// Only populated on top-level comments returned by GetComments.
+	Replies []Comment
 }
 
 var (
@@ -43,26 +91,33 @@ var (
 	commentDatePattern   = regexp.MustCompile(`w:date="([^"]*)"`)
 	commentBlockPattern  = regexp.MustCompile(`(?s)<w:comment\s+[^>]*>.*?</w:comment>`)
 	commentTextPattern   = regexp.MustCompile(`<w:t[^>]*>([^<]*)</w:t>`)
+	commentParaIDPattern = regexp.MustCompile(`<w:p\s[^>]*w14:paraId="([0-9A-Fa-f]{8})"`)
 )
 
 // InsertComment adds a comment to the document.
-// The comment range spans the paragraph containing the anchor text.
+// The comment range spans the anchor text, or the table cell given by Cell.
 func (u *Updater) InsertComment(opts CommentOptions) error {
 	if u == nil {
 		return fmt.Errorf("updater is nil")
 	}
-	if opts.Text == "" {
+	if opts.Text == "" && len(opts.Paragraphs) == 0 {
 		return fmt.Errorf("comment text cannot be empty")
 	}
-	if opts.Anchor == "" {
+	if opts.Anchor == "" && opts.Cell == nil {
 		return fmt.Errorf("anchor text cannot be empty")
 	}
+	if err := validateEquationParagraphs(opts.Paragraphs); err != nil {
+		return err
+	}
 	if opts.Author == "" {
 		opts.Author = "Author"
 	}
 	if opts.Initials == "" && len(opts.Author) > 0 {
 		opts.Initials = string(opts.Author[0])
 	}
+	if opts.Date.IsZero() {
+		opts.Date = time.Now()
+	}
 
 	commentID, err := u.ensureCommentsXML()
 	if err != nil {
@@ -73,7 +128,11 @@ func (u *Updater) InsertComment(opts CommentOptions) error {
 		return fmt.Errorf("add comment content: %w", err)
 	}
 
-	if err := u.insertCommentMarkers(opts.Anchor, commentID); err != nil {
+	if err := u.addCommentExtendedEntries(commentID, "", opts.Date); err != nil {
+		return fmt.Errorf("add comment extended entries: %w", err)
+	}
+
+	if err := u.insertCommentMarkers(opts, commentID); err != nil {
 		return fmt.Errorf("insert comment markers: %w", err)
 	}
 
@@ -81,22 +140,32 @@ func (u *Updater) InsertComment(opts CommentOptions) error {
 }
 
 // GetComments reads all comments from the document.
-// Returns nil if the document has no comments.
+// Replies are nested under their parent comment's Replies field rather than
+// returned at the top level. Returns nil if the document has no comments.
 func (u *Updater) GetComments() ([]Comment, error) {
 	if u == nil {
 		return nil, fmt.Errorf("updater is nil")
 	}
 
 	commentsPath := filepath.Join(u.tempDir, "word", "comments.xml")
-	raw, err := os.ReadFile(commentsPath)
+	if _, err := os.Stat(commentsPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	comments, err := u.loadComments()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("read comments.xml: %w", err)
+		return nil, err
+	}
+
+	docRaw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "document.xml"))
+	if err != nil {
+		return nil, fmt.Errorf("read document.xml: %w", err)
+	}
+	for i := range comments {
+		comments[i].AnchorText = extractCommentAnchorText(docRaw, comments[i].ID)
 	}
 
-	return parseComments(raw), nil
+	return nestCommentReplies(comments), nil
 }
 
 // ensureCommentsXML creates comments.xml if it doesn't exist and returns the next available ID.
@@ -135,7 +204,9 @@ func generateInitialCommentsXML() []byte {
 	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
 	buf.WriteString("\n")
 	buf.WriteString(`<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" `)
-	buf.WriteString(`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	buf.WriteString(`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" `)
+	buf.WriteString(`xmlns:mc="` + MarkupCompatibilityNS + `" `)
+	buf.WriteString(`xmlns:w14="` + Word2010NS + `" mc:Ignorable="w14">`)
 	buf.WriteString("\n")
 	buf.WriteString(`</w:comments>`)
 
@@ -150,7 +221,27 @@ func (u *Updater) addCommentContent(id int, opts CommentOptions) error {
 		return fmt.Errorf("read comments.xml: %w", err)
 	}
 
-	commentXML := generateCommentEntry(id, opts)
+	urlRelIDs := make(map[string]string)
+	for _, para := range opts.Paragraphs {
+		for _, run := range para {
+			if run.URL == "" {
+				continue
+			}
+			if _, seen := urlRelIDs[run.URL]; !seen {
+				rID, err := u.addPartHyperlinkRelationship("comments.xml", run.URL)
+				if err != nil {
+					return fmt.Errorf("register hyperlink for %q: %w", run.URL, err)
+				}
+				urlRelIDs[run.URL] = rID
+			}
+		}
+	}
+
+	raw = ensureCommentsW14Namespace(raw)
+	if hasEquationParagraphs(opts.Paragraphs) {
+		raw = ensureMathNamespace(raw)
+	}
+	commentXML := generateCommentEntry(id, opts, urlRelIDs)
 
 	closeTag := []byte("</w:comments>")
 	closeIdx := bytes.LastIndex(raw, closeTag)
@@ -167,64 +258,61 @@ func (u *Updater) addCommentContent(id int, opts CommentOptions) error {
 	return atomicWriteFile(commentsPath, result, 0o644)
 }
 
-// generateCommentEntry creates the XML for a single comment
-func generateCommentEntry(id int, opts CommentOptions) []byte {
+// generateCommentEntry creates the XML for a single comment.
+// urlRelIDs maps hyperlink URLs in opts.Paragraphs to relationship IDs in
+// comments.xml.rels. The last paragraph carries the comment's w14:paraId.
+func generateCommentEntry(id int, opts CommentOptions, urlRelIDs map[string]string) []byte {
 	var buf bytes.Buffer
 
-	dateStr := time.Now().UTC().Format(time.RFC3339)
+	date := opts.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	dateStr := date.UTC().Format(time.RFC3339)
+
+	paragraphs := opts.Paragraphs
+	if len(paragraphs) == 0 {
+		paragraphs = [][]RunOptions{{{Text: " " + opts.Text}}}
+	}
 
 	buf.WriteString(fmt.Sprintf(
 		`<w:comment w:id="%d" w:author="%s" w:date="%s" w:initials="%s">`,
 		id, xmlEscape(opts.Author), dateStr, xmlEscape(opts.Initials)))
-	buf.WriteString("<w:p>")
-	buf.WriteString(`<w:pPr><w:pStyle w:val="CommentText"/></w:pPr>`)
 
-	buf.WriteString("<w:r>")
-	buf.WriteString(`<w:rPr><w:rStyle w:val="CommentReference"/></w:rPr>`)
-	buf.WriteString("<w:annotationRef/>")
-	buf.WriteString("</w:r>")
+	for i, runs := range paragraphs {
+		if i == len(paragraphs)-1 {
+			fmt.Fprintf(&buf, `<w:p w14:paraId="%s" w14:textId="77777777">`, commentParaID(id))
+		} else {
+			buf.WriteString("<w:p>")
+		}
+		buf.WriteString(`<w:pPr><w:pStyle w:val="CommentText"/></w:pPr>`)
+
+		// The annotation mark opens the first paragraph of every comment.
+		if i == 0 {
+			buf.WriteString("<w:r>")
+			buf.WriteString(`<w:rPr><w:rStyle w:val="CommentReference"/></w:rPr>`)
+			buf.WriteString("<w:annotationRef/>")
+			buf.WriteString("</w:r>")
+		}
 
-	buf.WriteString("<w:r>")
-	buf.WriteString(fmt.Sprintf(`<w:t xml:space="preserve"> %s</w:t>`, xmlEscape(opts.Text)))
-	buf.WriteString("</w:r>")
+		writeRunsXML(&buf, runs, urlRelIDs)
+		buf.WriteString("</w:p>")
+	}
 
-	buf.WriteString("</w:p>")
 	buf.WriteString("</w:comment>")
 
 	return buf.Bytes()
 }
 
 // insertCommentMarkers inserts commentRangeStart, commentRangeEnd, and commentReference
-// into document.xml around the paragraph containing the anchor text.
-func (u *Updater) insertCommentMarkers(anchor string, commentID int) error {
+// into document.xml around the anchor text or table cell selected by opts.
+func (u *Updater) insertCommentMarkers(opts CommentOptions, commentID int) error {
 	docPath := filepath.Join(u.tempDir, "word", "document.xml")
 	raw, err := os.ReadFile(docPath)
 	if err != nil {
 		return fmt.Errorf("read document.xml: %w", err)
 	}
 
-	paraStart, paraEnd, err := findParagraphRangeByAnchor(raw, anchor)
-	if err != nil {
-		return fmt.Errorf("find anchor: %w", err)
-	}
-
-	pContent := string(raw[paraStart:paraEnd])
-
-	// Insert commentRangeStart after <w:pPr>...</w:pPr> if present,
-	// otherwise after the opening <w:p> tag
-	var insertStartOffset int
-	if pprEnd := strings.Index(pContent, "</w:pPr>"); pprEnd >= 0 {
-		insertStartOffset = pprEnd + len("</w:pPr>")
-	} else {
-		pOpenEnd := strings.Index(pContent, ">")
-		if pOpenEnd < 0 {
-			return fmt.Errorf("invalid paragraph XML")
-		}
-		insertStartOffset = pOpenEnd + 1
-	}
-	insertStartPos := paraStart + insertStartOffset
-	insertEndPos := paraEnd - len("</w:p>")
-
 	rangeStartXML := fmt.Sprintf(`<w:commentRangeStart w:id="%d"/>`, commentID)
 	rangeEndXML := fmt.Sprintf(
 		`<w:commentRangeEnd w:id="%d"/>`+
@@ -232,16 +320,114 @@ func (u *Updater) insertCommentMarkers(anchor string, commentID int) error {
 			`<w:commentReference w:id="%d"/></w:r>`,
 		commentID, commentID)
 
-	result := make([]byte, 0, len(raw)+len(rangeStartXML)+len(rangeEndXML))
-	result = append(result, raw[:insertStartPos]...)
-	result = append(result, []byte(rangeStartXML)...)
-	result = append(result, raw[insertStartPos:insertEndPos]...)
-	result = append(result, []byte(rangeEndXML)...)
-	result = append(result, raw[insertEndPos:]...)
+	var result []byte
+	switch {
+	case opts.Cell != nil:
+		result, err = wrapTableCellContent(raw, *opts.Cell, rangeStartXML, rangeEndXML)
+		if err != nil {
+			return fmt.Errorf("find cell: %w", err)
+		}
+	default:
+		result, err = wrapDocumentText(raw, opts.Anchor, opts.AnchorEnd, rangeStartXML, rangeEndXML)
+		if err != nil && opts.AnchorEnd == "" {
+			// The anchor may only match with normalised whitespace or across
+			// tabs and breaks; fall back to covering the whole paragraph.
+			result, err = wrapParagraphByAnchor(raw, opts.Anchor, rangeStartXML, rangeEndXML)
+		}
+		if err != nil {
+			return fmt.Errorf("find anchor: %w", err)
+		}
+	}
 
 	return atomicWriteFile(docPath, result, 0o644)
 }
 
+// wrapParagraphByAnchor inserts before at the start of the content of the
+// paragraph containing anchor and after at its end.
+func wrapParagraphByAnchor(docXML []byte, anchor, before, after string) ([]byte, error) {
+	paraStart, paraEnd, err := findParagraphRangeByAnchor(docXML, anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	contentStart, err := paragraphContentStart(docXML[paraStart:paraEnd])
+	if err != nil {
+		return nil, err
+	}
+	insertStartPos := paraStart + contentStart
+	insertEndPos := paraEnd - len("</w:p>")
+
+	result := make([]byte, 0, len(docXML)+len(before)+len(after))
+	result = append(result, docXML[:insertStartPos]...)
+	result = append(result, before...)
+	result = append(result, docXML[insertStartPos:insertEndPos]...)
+	result = append(result, after...)
+	result = append(result, docXML[insertEndPos:]...)
+	return result, nil
+}
+
+// paragraphContentStart returns the offset just after <w:pPr>...</w:pPr> if
+// present, otherwise just after the opening <w:p> tag.
+func paragraphContentStart(paraXML []byte) (int, error) {
+	if pprEnd := bytes.Index(paraXML, []byte("</w:pPr>")); pprEnd >= 0 {
+		return pprEnd + len("</w:pPr>"), nil
+	}
+	pOpenEnd := bytes.IndexByte(paraXML, '>')
+	if pOpenEnd < 0 {
+		return 0, fmt.Errorf("invalid paragraph XML")
+	}
+	return pOpenEnd + 1, nil
+}
+
+// wrapTableCellContent inserts before at the start of the first paragraph's
+// content in the addressed cell and after at the end of its last paragraph.
+func wrapTableCellContent(docXML []byte, cell TableCellRef, before, after string) ([]byte, error) {
+	if cell.Table < 1 || cell.Row < 1 || cell.Col < 1 {
+		return nil, fmt.Errorf("table, row and col must be >= 1")
+	}
+	content := string(docXML)
+
+	tblStart, tblEnd, err := findNthXMLBlock(content, "w:tbl", cell.Table)
+	if err != nil {
+		return nil, fmt.Errorf("table %d not found: %w", cell.Table, err)
+	}
+	trStart, trEnd, err := findNthXMLBlock(content[tblStart:tblEnd], "w:tr", cell.Row)
+	if err != nil {
+		return nil, fmt.Errorf("table %d row %d not found: %w", cell.Table, cell.Row, err)
+	}
+	trStart += tblStart
+	tcStart, tcEnd, err := findNthXMLBlock(content[trStart:tblStart+trEnd], "w:tc", cell.Col)
+	if err != nil {
+		return nil, fmt.Errorf("table %d row %d col %d not found: %w", cell.Table, cell.Row, cell.Col, err)
+	}
+	tcStart += trStart
+	tcEnd += trStart
+
+	firstPara := findNextParagraphStart(docXML[:tcEnd], tcStart)
+	lastParaEnd := bytes.LastIndex(docXML[tcStart:tcEnd], []byte("</w:p>"))
+	if firstPara == -1 || lastParaEnd == -1 {
+		return nil, fmt.Errorf("cell has no paragraph")
+	}
+	firstParaEndRel := bytes.Index(docXML[firstPara:tcEnd], []byte("</w:p>"))
+	if firstParaEndRel == -1 {
+		return nil, fmt.Errorf("cell has no paragraph")
+	}
+	contentStart, err := paragraphContentStart(docXML[firstPara : firstPara+firstParaEndRel])
+	if err != nil {
+		return nil, err
+	}
+	insertStartPos := firstPara + contentStart
+	insertEndPos := tcStart + lastParaEnd
+
+	result := make([]byte, 0, len(docXML)+len(before)+len(after))
+	result = append(result, docXML[:insertStartPos]...)
+	result = append(result, before...)
+	result = append(result, docXML[insertStartPos:insertEndPos]...)
+	result = append(result, after...)
+	result = append(result, docXML[insertEndPos:]...)
+	return result, nil
+}
+
 // getNextCommentID finds the next available comment ID in comments.xml
 func getNextCommentID(raw []byte) int {
 	matches := commentIDPattern.FindAllSubmatch(raw, -1)
@@ -272,8 +458,11 @@ func parseComments(raw []byte) []Comment {
 	for _, block := range blocks {
 		c := Comment{}
 
+		hasID := false
 		if m := commentIDPattern.FindStringSubmatch(block); len(m) > 1 {
-			c.ID, _ = strconv.Atoi(m[1])
+			if id, err := strconv.Atoi(m[1]); err == nil {
+				c.ID, hasID = id, true
+			}
 		}
 		if m := commentAuthorPattern.FindStringSubmatch(block); len(m) > 1 {
 			c.Author = m[1]
@@ -284,17 +473,26 @@ func parseComments(raw []byte) []Comment {
 		if m := commentDatePattern.FindStringSubmatch(block); len(m) > 1 {
 			c.Date = m[1]
 		}
+		// The last paragraph's paraId identifies the comment in commentsExtended.xml.
+		if ms := commentParaIDPattern.FindAllStringSubmatch(block, -1); len(ms) > 0 {
+			c.ParaID = strings.ToUpper(ms[len(ms)-1][1])
+		}
 
-		var texts []string
-		textMatches := commentTextPattern.FindAllStringSubmatch(block, -1)
-		for _, tm := range textMatches {
-			if len(tm) > 1 {
-				texts = append(texts, tm[1])
+		var paras []string
+		for _, para := range extractParaPattern.FindAllString(block, -1) {
+			var texts []string
+			textMatches := commentTextPattern.FindAllStringSubmatch(para, -1)
+			for _, tm := range textMatches {
+				if len(tm) > 1 {
+					texts = append(texts, xmlUnescape(tm[1]))
+				}
 			}
+			paras = append(paras, strings.Join(texts, ""))
 		}
-		c.Text = strings.Join(texts, "")
+		c.Text = strings.Join(paras, "\n")
 
-		if c.ID > 0 {
+		// Word numbers comments from 0, so any parsed ID is valid.
+		if hasID {
 			comments = append(comments, c)
 		}
 	}
@@ -24,7 +24,7 @@ func TestGenerateCommentEntry(t *testing.T) {
 		Anchor:   "test",
 	}
 
-	result := string(generateCommentEntry(1, opts))
+	result := string(generateCommentEntry(1, opts, nil))
 
 	if !strings.Contains(result, `w:id="1"`) {
 		t.Error("expected comment ID 1")
@@ -104,3 +104,114 @@ func TestInsertComment_Validation(t *testing.T) {
 		t.Error("expected error for nil updater")
 	}
 }
+
+func TestInsertComment_ExactRangeAcrossRuns(t *testing.T) {
+	body := `<w:p><w:r><w:t>Revenue </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>grew 15%</w:t></w:r>` +
+		`<w:r><w:t> this quarter.</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.InsertComment(CommentOptions{Text: "check", Anchor: "nue grew"}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if comments[0].AnchorText != "nue grew" {
+		t.Errorf("expected exact anchor range, got %q", comments[0].AnchorText)
+	}
+	if text, _ := u.GetText(); !strings.Contains(text, "Revenue grew 15% this quarter.") {
+		t.Errorf("document text altered: %q", text)
+	}
+}
+
+func TestInsertComment_AnchorEndSpansParagraphs(t *testing.T) {
+	body := `<w:p><w:r><w:t>Clause one begins.</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Clause two ends.</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	err := u.InsertComment(CommentOptions{Text: "both clauses", Anchor: "one", AnchorEnd: "two"})
+	if err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if want := "one begins.\nClause two"; comments[0].AnchorText != want {
+		t.Errorf("expected %q, got %q", want, comments[0].AnchorText)
+	}
+}
+
+func TestInsertComment_TableCell(t *testing.T) {
+	body := `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>A1</w:t></w:r></w:p></w:tc>` +
+		`<w:tc><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>B1</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.InsertComment(CommentOptions{Text: "cell note", Cell: &TableCellRef{Table: 1, Row: 1, Col: 2}}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+
+	docXML := readDocXML(t, u)
+	if !strings.Contains(docXML, `</w:pPr><w:commentRangeStart w:id="1"/><w:r><w:t>B1</w:t></w:r><w:commentRangeEnd w:id="1"/>`) {
+		t.Errorf("expected cell B1 wrapped by comment range, got %s", docXML)
+	}
+
+	if err := u.InsertComment(CommentOptions{Text: "x", Cell: &TableCellRef{Table: 1, Row: 2, Col: 1}}); err == nil {
+		t.Error("expected error for missing row")
+	}
+}
+
+func TestInsertComment_WhitespaceFallbackWrapsParagraph(t *testing.T) {
+	body := `<w:p><w:r><w:t>alpha</w:t><w:tab/><w:t>beta</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.InsertComment(CommentOptions{Text: "note", Anchor: "alpha beta"}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+	docXML := readDocXML(t, u)
+	if !strings.Contains(docXML, `<w:p><w:commentRangeStart w:id="1"/><w:r>`) {
+		t.Errorf("expected paragraph-wide range fallback, got %s", docXML)
+	}
+}
+
+func TestInsertComment_RichBody(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>text</w:t></w:r></w:p>`))
+
+	err := u.InsertComment(CommentOptions{
+		Anchor: "text",
+		Paragraphs: [][]RunOptions{
+			{{Text: "Important: ", Bold: true}, {Text: "see policy", URL: "https://example.com/policy"}},
+			{{Text: "@Alice", URL: "mailto:alice@example.com"}, {Text: " please confirm"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+
+	comments := readWordPart(t, u, "comments.xml")
+	if strings.Count(comments, "<w:annotationRef/>") != 1 {
+		t.Error("expected a single annotation mark in the first paragraph")
+	}
+	if strings.Count(comments, `<w:pStyle w:val="CommentText"/>`) != 2 {
+		t.Error("expected two comment paragraphs")
+	}
+	if !strings.Contains(comments, "<w:b/>") || !strings.Contains(comments, "<w:hyperlink r:id=") {
+		t.Error("expected bold run and hyperlink in comment body")
+	}
+
+	rels := readWordPart(t, u, "_rels/comments.xml.rels")
+	if !strings.Contains(rels, "https://example.com/policy") || !strings.Contains(rels, "mailto:alice@example.com") {
+		t.Errorf("expected hyperlink relationships in comments.xml.rels, got %s", rels)
+	}
+
+	parsed, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if want := "Important: see policy\n@Alice please confirm"; parsed[0].Text != want {
+		t.Errorf("expected text %q, got %q", want, parsed[0].Text)
+	}
+}
@@ -0,0 +1,773 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Word 2013+ comment extension parts. commentsExtended.xml carries reply
+// threading (w15:paraIdParent) and resolved state (w15:done); commentsIds.xml
+// and commentsExtensible.xml give each comment a durable ID and UTC timestamp
+// used by modern Word and Microsoft 365.
+const (
+	commentsExtendedFile        = "commentsExtended.xml"
+	commentsExtendedRelType     = "http://schemas.microsoft.com/office/2011/relationships/commentsExtended"
+	commentsExtendedContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.commentsExtended+xml"
+
+	commentsIdsFile        = "commentsIds.xml"
+	commentsIdsRelType     = "http://schemas.microsoft.com/office/2016/09/relationships/commentsIds"
+	commentsIdsContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.commentsIds+xml"
+
+	commentsExtensibleFile        = "commentsExtensible.xml"
+	commentsExtensibleRelType     = "http://schemas.microsoft.com/office/2018/08/relationships/commentsExtensible"
+	commentsExtensibleContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.commentsExtensible+xml"
+)
+
+// OpenXML constants for comment identifiers
+const (
+	// CommentParaIDBase is the base value for w14:paraId attributes on comment paragraphs.
+	// Values must stay below 0x80000000 per the Word 2010 schema.
+	CommentParaIDBase = 0x1C000000
+
+	// CommentDurableIDBase is the base value for w16cid:durableId attributes.
+	CommentDurableIDBase = 0x2C000000
+)
+
+var (
+	commentExPattern       = regexp.MustCompile(`<w15:commentEx\s[^>]*/>`)
+	commentExParaIDPattern = regexp.MustCompile(`w15:paraId="([0-9A-Fa-f]+)"`)
+	commentExParentPattern = regexp.MustCompile(`w15:paraIdParent="([0-9A-Fa-f]+)"`)
+	commentExDonePattern   = regexp.MustCompile(`w15:done="([^"]*)"`)
+	commentDurableIDAttr   = regexp.MustCompile(`w16cid:durableId="([0-9A-Fa-f]+)"`)
+)
+
+// commentExtended holds the threading state of one comment in commentsExtended.xml.
+type commentExtended struct {
+	ParaID       string
+	ParentParaID string
+	Done         bool
+}
+
+// ReplyToComment adds a reply to an existing comment. The reply shares the
+// parent comment's range in the document and appears threaded under it in
+// Word. Replying to a reply attaches the new comment to the thread's root,
+// since Word threads are only one level deep. opts.Anchor is ignored.
+func (u *Updater) ReplyToComment(parentID int, opts CommentOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if opts.Text == "" && len(opts.Paragraphs) == 0 {
+		return fmt.Errorf("comment text cannot be empty")
+	}
+	if err := validateEquationParagraphs(opts.Paragraphs); err != nil {
+		return err
+	}
+	if opts.Author == "" {
+		opts.Author = "Author"
+	}
+	if opts.Initials == "" && len(opts.Author) > 0 {
+		opts.Initials = string(opts.Author[0])
+	}
+	if opts.Date.IsZero() {
+		opts.Date = time.Now()
+	}
+
+	comments, err := u.loadComments()
+	if err != nil {
+		return err
+	}
+	root, ok := findCommentThreadRoot(comments, parentID)
+	if !ok {
+		return fmt.Errorf("comment %d not found", parentID)
+	}
+
+	rootParaID, err := u.ensureCommentParaID(root)
+	if err != nil {
+		return fmt.Errorf("assign paraId to comment %d: %w", root.ID, err)
+	}
+
+	replyID, err := u.ensureCommentsXML()
+	if err != nil {
+		return fmt.Errorf("ensure comments.xml: %w", err)
+	}
+
+	if err := u.addCommentContent(replyID, opts); err != nil {
+		return fmt.Errorf("add comment content: %w", err)
+	}
+
+	if err := u.addCommentExtendedEntries(replyID, rootParaID, opts.Date); err != nil {
+		return fmt.Errorf("add comment extended entries: %w", err)
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+	threadIDs := []int{root.ID}
+	for _, c := range comments {
+		if r, _ := findCommentThreadRoot(comments, c.ID); r.ID == root.ID && c.ID != root.ID {
+			threadIDs = append(threadIDs, c.ID)
+		}
+	}
+	updated, err := insertReplyMarkers(raw, threadIDs, replyID)
+	if err != nil {
+		return fmt.Errorf("insert reply markers: %w", err)
+	}
+
+	return atomicWriteFile(docPath, updated, 0o644)
+}
+
+// ResolveComment marks the thread containing the given comment as resolved
+// (w15:done="1"). Resolving a reply resolves its whole thread, matching Word.
+func (u *Updater) ResolveComment(id int) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	comments, err := u.loadComments()
+	if err != nil {
+		return err
+	}
+	root, ok := findCommentThreadRoot(comments, id)
+	if !ok {
+		return fmt.Errorf("comment %d not found", id)
+	}
+
+	extPath, err := u.ensureCommentPart(commentsExtendedFile, commentsExtendedRelType,
+		commentsExtendedContentType, generateInitialCommentsExtendedXML)
+	if err != nil {
+		return fmt.Errorf("ensure %s: %w", commentsExtendedFile, err)
+	}
+	extRaw, err := os.ReadFile(extPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", commentsExtendedFile, err)
+	}
+
+	rootParaID, err := u.ensureCommentParaID(root)
+	if err != nil {
+		return fmt.Errorf("assign paraId to comment %d: %w", root.ID, err)
+	}
+	extRaw = upsertCommentEx(extRaw, commentExtended{ParaID: rootParaID, Done: true})
+
+	for _, c := range comments {
+		if c.ParentID != root.ID {
+			continue
+		}
+		paraID, err := u.ensureCommentParaID(c)
+		if err != nil {
+			return fmt.Errorf("assign paraId to comment %d: %w", c.ID, err)
+		}
+		extRaw = upsertCommentEx(extRaw, commentExtended{ParaID: paraID, ParentParaID: rootParaID, Done: true})
+	}
+
+	return atomicWriteFile(extPath, extRaw, 0o644)
+}
+
+// DeleteComment removes a comment from the document, including its range
+// markers and reference run in document.xml and its entries in the comment
+// extension parts. Deleting a top-level comment also deletes its replies.
+func (u *Updater) DeleteComment(id int) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	comments, err := u.loadComments()
+	if err != nil {
+		return err
+	}
+
+	var targets []Comment
+	found := false
+	for _, c := range comments {
+		switch {
+		case c.ID == id:
+			found = true
+			targets = append(targets, c)
+		case c.ParentID == id:
+			targets = append(targets, c)
+		}
+	}
+	if !found {
+		return fmt.Errorf("comment %d not found", id)
+	}
+
+	commentsPath := filepath.Join(u.tempDir, "word", "comments.xml")
+	raw, err := os.ReadFile(commentsPath)
+	if err != nil {
+		return fmt.Errorf("read comments.xml: %w", err)
+	}
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	docRaw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	var paraIDs []string
+	for _, c := range targets {
+		raw = removeCommentBlock(raw, c.ID)
+		docRaw = removeCommentMarkers(docRaw, c.ID)
+		if c.ParaID != "" {
+			paraIDs = append(paraIDs, c.ParaID)
+		}
+	}
+
+	if err := atomicWriteFile(commentsPath, raw, 0o644); err != nil {
+		return fmt.Errorf("write comments.xml: %w", err)
+	}
+	if err := atomicWriteFile(docPath, docRaw, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+
+	return u.removeCommentExtendedEntries(paraIDs)
+}
+
+// loadComments returns the flat list of comments with threading state applied.
+// Returns an error if the document has no comments part.
+func (u *Updater) loadComments() ([]Comment, error) {
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "comments.xml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("document has no comments")
+		}
+		return nil, fmt.Errorf("read comments.xml: %w", err)
+	}
+
+	comments := parseComments(raw)
+	extended, err := u.readCommentsExtended()
+	if err != nil {
+		return nil, err
+	}
+	applyCommentsExtended(comments, extended)
+	return comments, nil
+}
+
+// findCommentThreadRoot returns the top-level comment of the thread containing id.
+func findCommentThreadRoot(comments []Comment, id int) (Comment, bool) {
+	byID := make(map[int]Comment, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+
+	c, ok := byID[id]
+	if !ok {
+		return Comment{}, false
+	}
+	// Bound the walk by the number of comments to guard against cyclic parents.
+	for range comments {
+		parent, ok := byID[c.ParentID]
+		if c.ParentID == 0 || !ok || parent.ID == c.ID {
+			break
+		}
+		c = parent
+	}
+	return c, true
+}
+
+// commentParaID returns the w14:paraId assigned to the paragraph of a new comment.
+func commentParaID(id int) string {
+	return fmt.Sprintf("%08X", CommentParaIDBase+id)
+}
+
+// commentDurableID returns the w16cid:durableId assigned to a new comment.
+func commentDurableID(id int) string {
+	return fmt.Sprintf("%08X", CommentDurableIDBase+id)
+}
+
+// ensureCommentsW14Namespace declares the w14 namespace on the <w:comments>
+// root so that w14:paraId attributes can be written to comment paragraphs.
+func ensureCommentsW14Namespace(raw []byte) []byte {
+	if bytes.Contains(raw, []byte(`xmlns:w14=`)) {
+		return raw
+	}
+	return bytes.Replace(raw, []byte(`<w:comments `),
+		[]byte(`<w:comments xmlns:w14="`+Word2010NS+`" `), 1)
+}
+
+// ensureCommentParaID returns the comment's paraId, assigning one to its last
+// paragraph in comments.xml when the comment predates threading support.
+func (u *Updater) ensureCommentParaID(c Comment) (string, error) {
+	if c.ParaID != "" {
+		return c.ParaID, nil
+	}
+
+	commentsPath := filepath.Join(u.tempDir, "word", "comments.xml")
+	raw, err := os.ReadFile(commentsPath)
+	if err != nil {
+		return "", fmt.Errorf("read comments.xml: %w", err)
+	}
+
+	loc := commentBlockLocation(raw, c.ID)
+	if loc == nil {
+		return "", fmt.Errorf("comment %d not found", c.ID)
+	}
+	block := raw[loc[0]:loc[1]]
+
+	pIdx := -1
+	for pos := 0; ; {
+		next := findNextParagraphStart(block, pos)
+		if next == -1 {
+			break
+		}
+		pIdx = next
+		pos = next + len("<w:p")
+	}
+	if pIdx == -1 {
+		return "", fmt.Errorf("comment %d has no paragraph", c.ID)
+	}
+
+	paraID := commentParaID(c.ID)
+	insertPos := loc[0] + pIdx + len("<w:p")
+	attr := fmt.Sprintf(` w14:paraId="%s" w14:textId="77777777"`, paraID)
+
+	result := make([]byte, 0, len(raw)+len(attr))
+	result = append(result, raw[:insertPos]...)
+	result = append(result, attr...)
+	result = append(result, raw[insertPos:]...)
+
+	if err := atomicWriteFile(commentsPath, ensureCommentsW14Namespace(result), 0o644); err != nil {
+		return "", fmt.Errorf("write comments.xml: %w", err)
+	}
+	return paraID, nil
+}
+
+// commentBlockLocation returns the byte range of the <w:comment> element with
+// the given ID, or nil if it does not exist.
+func commentBlockLocation(raw []byte, id int) []int {
+	pattern := regexp.MustCompile(fmt.Sprintf(`(?s)<w:comment\s+[^>]*w:id="%d"[^>]*>.*?</w:comment>`, id))
+	return pattern.FindIndex(raw)
+}
+
+// removeCommentBlock removes the <w:comment> element with the given ID.
+func removeCommentBlock(raw []byte, id int) []byte {
+	loc := commentBlockLocation(raw, id)
+	if loc == nil {
+		return raw
+	}
+	end := loc[1]
+	if end < len(raw) && raw[end] == '\n' {
+		end++
+	}
+	return append(raw[:loc[0]:loc[0]], raw[end:]...)
+}
+
+// addCommentExtendedEntries registers a new comment in commentsExtended.xml,
+// commentsIds.xml and commentsExtensible.xml, creating the parts if needed.
+// parentParaID is empty for top-level comments.
+func (u *Updater) addCommentExtendedEntries(id int, parentParaID string, date time.Time) error {
+	paraID := commentParaID(id)
+	durableID := commentDurableID(id)
+
+	entries := []struct {
+		file, relType, contentType, closeTag, entry string
+		initial                                     func() []byte
+	}{
+		{
+			commentsExtendedFile, commentsExtendedRelType, commentsExtendedContentType,
+			"</w15:commentsEx>",
+			generateCommentExEntry(commentExtended{ParaID: paraID, ParentParaID: parentParaID}),
+			generateInitialCommentsExtendedXML,
+		},
+		{
+			commentsIdsFile, commentsIdsRelType, commentsIdsContentType,
+			"</w16cid:commentsIds>",
+			fmt.Sprintf(`<w16cid:commentId w16cid:paraId="%s" w16cid:durableId="%s"/>`, paraID, durableID),
+			generateInitialCommentsIdsXML,
+		},
+		{
+			commentsExtensibleFile, commentsExtensibleRelType, commentsExtensibleContentType,
+			"</w16cex:commentsExtensible>",
+			fmt.Sprintf(`<w16cex:commentExtensible w16cex:durableId="%s" w16cex:dateUtc="%s"/>`,
+				durableID, date.UTC().Format(time.RFC3339)),
+			generateInitialCommentsExtensibleXML,
+		},
+	}
+
+	for _, e := range entries {
+		path, err := u.ensureCommentPart(e.file, e.relType, e.contentType, e.initial)
+		if err != nil {
+			return fmt.Errorf("ensure %s: %w", e.file, err)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", e.file, err)
+		}
+
+		closeIdx := bytes.LastIndex(raw, []byte(e.closeTag))
+		if closeIdx == -1 {
+			return fmt.Errorf("could not find %s tag in %s", e.closeTag, e.file)
+		}
+
+		result := make([]byte, 0, len(raw)+len(e.entry)+1)
+		result = append(result, raw[:closeIdx]...)
+		result = append(result, e.entry...)
+		result = append(result, '\n')
+		result = append(result, raw[closeIdx:]...)
+
+		if err := atomicWriteFile(path, result, 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", e.file, err)
+		}
+	}
+
+	return nil
+}
+
+// removeCommentExtendedEntries removes the entries for the given paraIds from
+// all comment extension parts that exist in the document.
+func (u *Updater) removeCommentExtendedEntries(paraIDs []string) error {
+	if len(paraIDs) == 0 {
+		return nil
+	}
+	wordDir := filepath.Join(u.tempDir, "word")
+
+	extPath := filepath.Join(wordDir, commentsExtendedFile)
+	if raw, err := os.ReadFile(extPath); err == nil {
+		for _, p := range paraIDs {
+			raw = removeElementsWithAttr(raw, "w15:commentEx", "w15:paraId", p)
+		}
+		if err := atomicWriteFile(extPath, raw, 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", commentsExtendedFile, err)
+		}
+	}
+
+	var durableIDs []string
+	idsPath := filepath.Join(wordDir, commentsIdsFile)
+	if raw, err := os.ReadFile(idsPath); err == nil {
+		for _, p := range paraIDs {
+			pattern := elementWithAttrPattern("w16cid:commentId", "w16cid:paraId", p)
+			for _, m := range pattern.FindAll(raw, -1) {
+				if dm := commentDurableIDAttr.FindSubmatch(m); dm != nil {
+					durableIDs = append(durableIDs, string(dm[1]))
+				}
+			}
+			raw = pattern.ReplaceAll(raw, nil)
+		}
+		if err := atomicWriteFile(idsPath, raw, 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", commentsIdsFile, err)
+		}
+	}
+
+	cexPath := filepath.Join(wordDir, commentsExtensibleFile)
+	if raw, err := os.ReadFile(cexPath); err == nil {
+		for _, d := range durableIDs {
+			raw = removeElementsWithAttr(raw, "w16cex:commentExtensible", "w16cex:durableId", d)
+		}
+		if err := atomicWriteFile(cexPath, raw, 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", commentsExtensibleFile, err)
+		}
+	}
+
+	return nil
+}
+
+// elementWithAttrPattern matches a self-closing or empty-bodied element whose
+// attribute attr has the given value (case-insensitive for hex IDs).
+func elementWithAttrPattern(tag, attr, value string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?si)<%s\s[^>]*%s="%s"[^>]*(?:/>|>.*?</%s>)\n?`,
+		regexp.QuoteMeta(tag), regexp.QuoteMeta(attr), regexp.QuoteMeta(value), regexp.QuoteMeta(tag)))
+}
+
+// removeElementsWithAttr removes every tag element whose attr equals value.
+func removeElementsWithAttr(raw []byte, tag, attr, value string) []byte {
+	return elementWithAttrPattern(tag, attr, value).ReplaceAll(raw, nil)
+}
+
+// ensureCommentPart creates word/<file> with the given initial content if it
+// does not exist and registers its relationship and content type. Returns the
+// absolute path of the part.
+func (u *Updater) ensureCommentPart(file, relType, contentType string, initial func() []byte) (string, error) {
+	path := filepath.Join(u.tempDir, "word", file)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := atomicWriteFile(path, initial(), 0o644); err != nil {
+			return "", fmt.Errorf("write %s: %w", file, err)
+		}
+	}
+
+	relsPath := filepath.Join(u.tempDir, "word", "_rels", "document.xml.rels")
+	rels, err := os.ReadFile(relsPath)
+	if err != nil {
+		return "", fmt.Errorf("read rels: %w", err)
+	}
+	if !bytes.Contains(rels, []byte(`Target="`+file+`"`)) {
+		if _, err := u.addDocumentRelationship(relType, file); err != nil {
+			return "", fmt.Errorf("add %s relationship: %w", file, err)
+		}
+	}
+
+	ctPath := filepath.Join(u.tempDir, "[Content_Types].xml")
+	ct, err := os.ReadFile(ctPath)
+	if err != nil {
+		return "", fmt.Errorf("read content types: %w", err)
+	}
+	partName := "/word/" + file
+	if !bytes.Contains(ct, []byte(`PartName="`+partName+`"`)) {
+		override := fmt.Sprintf(`<Override PartName="%s" ContentType="%s"/>`, partName, contentType)
+		ct = bytes.Replace(ct, []byte("</Types>"), []byte(override+"</Types>"), 1)
+		if err := atomicWriteFile(ctPath, ct, 0o644); err != nil {
+			return "", fmt.Errorf("write content types: %w", err)
+		}
+	}
+
+	return path, nil
+}
+
+// generateInitialCommentsExtendedXML creates a new empty commentsExtended.xml
+func generateInitialCommentsExtendedXML() []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<w15:commentsEx xmlns:mc="` + MarkupCompatibilityNS + `" ` +
+		`xmlns:w15="` + Word2012NS + `" mc:Ignorable="w15">` + "\n" +
+		`</w15:commentsEx>`)
+}
+
+// generateInitialCommentsIdsXML creates a new empty commentsIds.xml
+func generateInitialCommentsIdsXML() []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<w16cid:commentsIds xmlns:mc="` + MarkupCompatibilityNS + `" ` +
+		`xmlns:w16cid="` + Word2016CIDNS + `" mc:Ignorable="w16cid">` + "\n" +
+		`</w16cid:commentsIds>`)
+}
+
+// generateInitialCommentsExtensibleXML creates a new empty commentsExtensible.xml
+func generateInitialCommentsExtensibleXML() []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<w16cex:commentsExtensible xmlns:mc="` + MarkupCompatibilityNS + `" ` +
+		`xmlns:w16cex="` + Word2018CEXNS + `" mc:Ignorable="w16cex">` + "\n" +
+		`</w16cex:commentsExtensible>`)
+}
+
+// generateCommentExEntry creates the XML for a single w15:commentEx element
+func generateCommentExEntry(ext commentExtended) string {
+	done := "0"
+	if ext.Done {
+		done = "1"
+	}
+	if ext.ParentParaID != "" {
+		return fmt.Sprintf(`<w15:commentEx w15:paraId="%s" w15:paraIdParent="%s" w15:done="%s"/>`,
+			ext.ParaID, ext.ParentParaID, done)
+	}
+	return fmt.Sprintf(`<w15:commentEx w15:paraId="%s" w15:done="%s"/>`, ext.ParaID, done)
+}
+
+// upsertCommentEx sets the done flag of the commentEx entry for ext.ParaID,
+// appending a new entry when none exists. An existing parent link is kept.
+func upsertCommentEx(raw []byte, ext commentExtended) []byte {
+	pattern := elementWithAttrPattern("w15:commentEx", "w15:paraId", ext.ParaID)
+	if existing := pattern.Find(raw); existing != nil {
+		if m := commentExParentPattern.FindSubmatch(existing); m != nil {
+			ext.ParentParaID = string(m[1])
+		}
+		entry := generateCommentExEntry(ext)
+		if bytes.HasSuffix(existing, []byte("\n")) {
+			entry += "\n"
+		}
+		loc := pattern.FindIndex(raw)
+		result := make([]byte, 0, len(raw)+len(entry))
+		result = append(result, raw[:loc[0]]...)
+		result = append(result, entry...)
+		return append(result, raw[loc[1]:]...)
+	}
+
+	closeTag := []byte("</w15:commentsEx>")
+	closeIdx := bytes.LastIndex(raw, closeTag)
+	if closeIdx == -1 {
+		return raw
+	}
+	entry := generateCommentExEntry(ext) + "\n"
+	result := make([]byte, 0, len(raw)+len(entry))
+	result = append(result, raw[:closeIdx]...)
+	result = append(result, entry...)
+	return append(result, raw[closeIdx:]...)
+}
+
+// readCommentsExtended parses commentsExtended.xml keyed by upper-case paraId.
+// Returns an empty map when the part does not exist.
+func (u *Updater) readCommentsExtended() (map[string]commentExtended, error) {
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", commentsExtendedFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]commentExtended{}, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", commentsExtendedFile, err)
+	}
+	return parseCommentsExtended(raw), nil
+}
+
+// parseCommentsExtended extracts commentEx entries keyed by upper-case paraId.
+func parseCommentsExtended(raw []byte) map[string]commentExtended {
+	result := make(map[string]commentExtended)
+	for _, el := range commentExPattern.FindAll(raw, -1) {
+		m := commentExParaIDPattern.FindSubmatch(el)
+		if m == nil {
+			continue
+		}
+		ext := commentExtended{ParaID: strings.ToUpper(string(m[1]))}
+		if pm := commentExParentPattern.FindSubmatch(el); pm != nil {
+			ext.ParentParaID = strings.ToUpper(string(pm[1]))
+		}
+		if dm := commentExDonePattern.FindSubmatch(el); dm != nil {
+			ext.Done = string(dm[1]) == "1" || string(dm[1]) == "true"
+		}
+		result[ext.ParaID] = ext
+	}
+	return result
+}
+
+// applyCommentsExtended fills ParentID and Resolved from commentsExtended entries.
+func applyCommentsExtended(comments []Comment, extended map[string]commentExtended) {
+	paraToID := make(map[string]int, len(comments))
+	for _, c := range comments {
+		if c.ParaID != "" {
+			paraToID[c.ParaID] = c.ID
+		}
+	}
+	for i := range comments {
+		ext, ok := extended[comments[i].ParaID]
+		if !ok {
+			continue
+		}
+		comments[i].Resolved = ext.Done
+		if parentID, ok := paraToID[ext.ParentParaID]; ok && parentID != comments[i].ID {
+			comments[i].ParentID = parentID
+		}
+	}
+}
+
+// nestCommentReplies groups replies under their thread's top-level comment.
+func nestCommentReplies(comments []Comment) []Comment {
+	if comments == nil {
+		return nil
+	}
+
+	var topLevel []Comment
+	index := make(map[int]int)
+	for _, c := range comments {
+		root, _ := findCommentThreadRoot(comments, c.ID)
+		if root.ID == c.ID {
+			index[c.ID] = len(topLevel)
+			topLevel = append(topLevel, c)
+		}
+	}
+	for _, c := range comments {
+		root, _ := findCommentThreadRoot(comments, c.ID)
+		if root.ID == c.ID {
+			continue
+		}
+		if pos, ok := index[root.ID]; ok {
+			topLevel[pos].Replies = append(topLevel[pos].Replies, c)
+		}
+	}
+	return topLevel
+}
+
+// commentMarkerPattern matches a commentRangeStart/commentRangeEnd marker for one comment.
+func commentMarkerPattern(marker string, id int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`<w:%s\s+w:id="%d"\s*/>`, marker, id))
+}
+
+// extractCommentAnchorText returns the plain text between a comment's range
+// markers. Paragraph boundaries inside the range become newlines.
+func extractCommentAnchorText(docXML []byte, id int) string {
+	startLoc := commentMarkerPattern("commentRangeStart", id).FindIndex(docXML)
+	endLoc := commentMarkerPattern("commentRangeEnd", id).FindIndex(docXML)
+	if startLoc == nil || endLoc == nil || endLoc[0] < startLoc[1] {
+		return ""
+	}
+
+	segments := bytes.Split(docXML[startLoc[1]:endLoc[0]], []byte("</w:p>"))
+	texts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		texts = append(texts, extractParagraphPlainText(seg))
+	}
+	return strings.Join(texts, "\n")
+}
+
+// insertReplyMarkers places the reply's range markers next to those of the
+// thread and its reference run after the thread's reference runs. threadIDs
+// holds the root comment followed by its existing replies; the new reply
+// follows the last of them so that replies keep their document order.
+func insertReplyMarkers(docXML []byte, threadIDs []int, replyID int) ([]byte, error) {
+	parentID := threadIDs[0]
+	startLoc := commentMarkerPattern("commentRangeStart", parentID).FindIndex(docXML)
+	endLoc := commentMarkerPattern("commentRangeEnd", parentID).FindIndex(docXML)
+	if startLoc == nil || endLoc == nil {
+		return nil, fmt.Errorf("range markers for comment %d not found", parentID)
+	}
+
+	rangeStartXML := fmt.Sprintf(`<w:commentRangeStart w:id="%d"/>`, replyID)
+	rangeEndXML := fmt.Sprintf(`<w:commentRangeEnd w:id="%d"/>`, replyID)
+	refXML := fmt.Sprintf(
+		`<w:r><w:rPr><w:rStyle w:val="CommentReference"/></w:rPr>`+
+			`<w:commentReference w:id="%d"/></w:r>`, replyID)
+
+	// Each marker goes after the last matching marker of the thread. The
+	// reference run follows the last reference run when present, otherwise
+	// the reply's range end marker.
+	startPos, endPos, refPos := startLoc[1], endLoc[1], -1
+	for _, id := range threadIDs {
+		if loc := commentMarkerPattern("commentRangeStart", id).FindIndex(docXML); loc != nil && loc[1] > startPos {
+			startPos = loc[1]
+		}
+		if loc := commentMarkerPattern("commentRangeEnd", id).FindIndex(docXML); loc != nil && loc[1] > endPos {
+			endPos = loc[1]
+		}
+		if refLoc := commentReferencePattern(id).FindIndex(docXML); refLoc != nil {
+			if closeRel := bytes.Index(docXML[refLoc[1]:], []byte("</w:r>")); closeRel != -1 {
+				if pos := refLoc[1] + closeRel + len("</w:r>"); pos > refPos {
+					refPos = pos
+				}
+			}
+		}
+	}
+	if refPos == -1 {
+		refPos = endPos
+	}
+
+	type insertion struct {
+		pos  int
+		text string
+	}
+	inserts := []insertion{
+		{startPos, rangeStartXML},
+		{endPos, rangeEndXML},
+		{refPos, refXML},
+	}
+	if refPos == endPos {
+		inserts[1].text = rangeEndXML + refXML
+		inserts = inserts[:2]
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(docXML) + len(rangeStartXML) + len(rangeEndXML) + len(refXML))
+	last := 0
+	for _, ins := range inserts {
+		if ins.pos < last {
+			return nil, fmt.Errorf("invalid marker order for comment %d", parentID)
+		}
+		buf.Write(docXML[last:ins.pos])
+		buf.WriteString(ins.text)
+		last = ins.pos
+	}
+	buf.Write(docXML[last:])
+
+	return buf.Bytes(), nil
+}
+
+// commentReferencePattern matches the commentReference element for one comment.
+func commentReferencePattern(id int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`<w:commentReference\s+w:id="%d"\s*/>`, id))
+}
+
+// removeCommentMarkers removes a comment's range markers and reference from
+// document.xml. The run holding the reference is dropped when it has no
+// other content.
+func removeCommentMarkers(docXML []byte, id int) []byte {
+	docXML = commentMarkerPattern("commentRangeStart", id).ReplaceAll(docXML, nil)
+	docXML = commentMarkerPattern("commentRangeEnd", id).ReplaceAll(docXML, nil)
+
+	return removeReferenceRuns(docXML, commentReferencePattern(id))
+}
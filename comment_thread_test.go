@@ -0,0 +1,327 @@
+package godocx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readWordPart(t *testing.T, u *Updater, name string) string {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(u.TempDir(), "word", name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(raw)
+}
+
+func TestReplyToComment_Integration(t *testing.T) {
+	body := `<w:p><w:r><w:t>Revenue grew 15% this quarter.</w:t></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`
+
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.InsertComment(CommentOptions{Text: "Verify figure", Author: "Alice", Anchor: "grew 15%"}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+	if err := u.ReplyToComment(1, CommentOptions{Text: "Confirmed with accounting", Author: "Bob"}); err != nil {
+		t.Fatalf("ReplyToComment: %v", err)
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if len(comments) != 1 {
+		t.Fatalf("expected 1 top-level comment, got %d", len(comments))
+	}
+	root := comments[0]
+	if root.AnchorText != "grew 15%" {
+		t.Errorf("unexpected anchor text %q", root.AnchorText)
+	}
+	if len(root.Replies) != 1 {
+		t.Fatalf("expected 1 reply, got %d", len(root.Replies))
+	}
+	reply := root.Replies[0]
+	if reply.ID != 2 || reply.ParentID != 1 || reply.Author != "Bob" {
+		t.Errorf("unexpected reply %+v", reply)
+	}
+	if reply.AnchorText != root.AnchorText {
+		t.Errorf("expected reply to share parent range, got %q", reply.AnchorText)
+	}
+
+	ext := readWordPart(t, u, commentsExtendedFile)
+	if !strings.Contains(ext, `w15:paraIdParent="`+commentParaID(1)+`"`) {
+		t.Errorf("expected reply linked to parent paraId, got %s", ext)
+	}
+	if !strings.Contains(readWordPart(t, u, commentsIdsFile), commentDurableID(2)) {
+		t.Error("expected durable ID for reply in commentsIds.xml")
+	}
+	if !strings.Contains(readWordPart(t, u, commentsExtensibleFile), commentDurableID(2)) {
+		t.Error("expected reply entry in commentsExtensible.xml")
+	}
+
+	rels := readWordPart(t, u, filepath.Join("_rels", "document.xml.rels"))
+	for _, rt := range []string{commentsExtendedRelType, commentsIdsRelType, commentsExtensibleRelType} {
+		if strings.Count(rels, rt) != 1 {
+			t.Errorf("expected exactly one relationship of type %s", rt)
+		}
+	}
+
+	docXML := readDocXML(t, u)
+	if strings.Count(docXML, "<w:commentReference") != 2 {
+		t.Errorf("expected 2 comment references, got %d", strings.Count(docXML, "<w:commentReference"))
+	}
+}
+
+func TestReplyToComment_ReplyToReplyAttachesToRoot(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>text</w:t></w:r></w:p>`))
+
+	if err := u.InsertComment(CommentOptions{Text: "root", Anchor: "text"}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+	if err := u.ReplyToComment(1, CommentOptions{Text: "first"}); err != nil {
+		t.Fatalf("ReplyToComment 1: %v", err)
+	}
+	if err := u.ReplyToComment(2, CommentOptions{Text: "second"}); err != nil {
+		t.Fatalf("ReplyToComment 2: %v", err)
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if len(comments) != 1 || len(comments[0].Replies) != 2 {
+		t.Fatalf("expected one thread with 2 replies, got %+v", comments)
+	}
+	if comments[0].Replies[1].ParentID != 1 {
+		t.Errorf("expected reply to reply to attach to root, got parent %d", comments[0].Replies[1].ParentID)
+	}
+}
+
+func TestReplyToComment_ParagraphsOnly(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>text</w:t></w:r></w:p>`))
+
+	if err := u.InsertComment(CommentOptions{Text: "root", Anchor: "text"}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+	err := u.ReplyToComment(1, CommentOptions{Paragraphs: [][]RunOptions{
+		{{Text: "Agreed, "}, {Text: "see the spec", Bold: true}},
+		{{Text: "Second paragraph"}},
+	}})
+	if err != nil {
+		t.Fatalf("ReplyToComment: %v", err)
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if len(comments) != 1 || len(comments[0].Replies) != 1 {
+		t.Fatalf("expected one thread with 1 reply, got %+v", comments)
+	}
+	if got := comments[0].Replies[0].Text; got != "Agreed, see the spec\nSecond paragraph" {
+		t.Errorf("unexpected reply text %q", got)
+	}
+	if err := u.ReplyToComment(1, CommentOptions{}); err == nil {
+		t.Error("expected an error for a reply without text or paragraphs")
+	}
+}
+
+func TestReplyToComment_KeepsReplyOrder(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>text</w:t></w:r></w:p>`))
+
+	if err := u.InsertComment(CommentOptions{Text: "root", Anchor: "text"}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+	for _, text := range []string{"first", "second"} {
+		if err := u.ReplyToComment(1, CommentOptions{Text: text}); err != nil {
+			t.Fatalf("ReplyToComment %s: %v", text, err)
+		}
+	}
+
+	docXML := readDocXML(t, u)
+	for _, marker := range []string{`<w:commentRangeStart w:id="%d"/>`, `<w:commentRangeEnd w:id="%d"/>`, `<w:commentReference w:id="%d"/>`} {
+		last := -1
+		for id := 1; id <= 3; id++ {
+			pos := strings.Index(docXML, fmt.Sprintf(marker, id))
+			if pos <= last {
+				t.Fatalf("expected %s markers in comment order, got %s", marker, docXML)
+			}
+			last = pos
+		}
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if len(comments) != 1 || len(comments[0].Replies) != 2 ||
+		strings.TrimSpace(comments[0].Replies[0].Text) != "first" || strings.TrimSpace(comments[0].Replies[1].Text) != "second" {
+		t.Errorf("unexpected thread %+v", comments)
+	}
+}
+
+func TestReplyToComment_NotFound(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>text</w:t></w:r></w:p>`))
+
+	if err := u.ReplyToComment(1, CommentOptions{Text: "reply"}); err == nil {
+		t.Error("expected error when document has no comments")
+	}
+	if err := u.InsertComment(CommentOptions{Text: "root", Anchor: "text"}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+	if err := u.ReplyToComment(7, CommentOptions{Text: "reply"}); err == nil {
+		t.Error("expected error for unknown comment ID")
+	}
+}
+
+func TestResolveComment_Integration(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>text</w:t></w:r></w:p>`))
+
+	if err := u.InsertComment(CommentOptions{Text: "root", Anchor: "text"}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+	if err := u.ReplyToComment(1, CommentOptions{Text: "reply"}); err != nil {
+		t.Fatalf("ReplyToComment: %v", err)
+	}
+	if err := u.ResolveComment(2); err != nil {
+		t.Fatalf("ResolveComment: %v", err)
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if !comments[0].Resolved || !comments[0].Replies[0].Resolved {
+		t.Errorf("expected whole thread resolved, got %+v", comments[0])
+	}
+	if comments[0].Replies[0].ParentID != 1 {
+		t.Error("resolving must keep the reply's parent link")
+	}
+}
+
+func TestResolveComment_LegacyCommentWithoutParaID(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t,
+		`<w:p><w:commentRangeStart w:id="0"/><w:r><w:t>text</w:t></w:r><w:commentRangeEnd w:id="0"/>`+
+			`<w:r><w:commentReference w:id="0"/></w:r></w:p>`))
+
+	legacy := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:comment w:id="0" w:author="Old"><w:p><w:r><w:t>old note</w:t></w:r></w:p></w:comment>` +
+		`</w:comments>`
+	if err := os.WriteFile(filepath.Join(u.TempDir(), "word", "comments.xml"), []byte(legacy), 0o644); err != nil {
+		t.Fatalf("write comments.xml: %v", err)
+	}
+
+	if err := u.ResolveComment(0); err != nil {
+		t.Fatalf("ResolveComment: %v", err)
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != 0 || !comments[0].Resolved {
+		t.Fatalf("expected comment 0 resolved, got %+v", comments)
+	}
+	if !strings.Contains(readWordPart(t, u, "comments.xml"), `xmlns:w14=`) {
+		t.Error("expected w14 namespace declared when assigning paraId")
+	}
+}
+
+func TestDeleteComment_Integration(t *testing.T) {
+	body := `<w:p><w:r><w:t>first</w:t></w:r></w:p><w:p><w:r><w:t>second</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.InsertComment(CommentOptions{Text: "on first", Anchor: "first"}); err != nil {
+		t.Fatalf("InsertComment 1: %v", err)
+	}
+	if err := u.ReplyToComment(1, CommentOptions{Text: "reply"}); err != nil {
+		t.Fatalf("ReplyToComment: %v", err)
+	}
+	if err := u.InsertComment(CommentOptions{Text: "on second", Anchor: "second"}); err != nil {
+		t.Fatalf("InsertComment 2: %v", err)
+	}
+
+	if err := u.DeleteComment(1); err != nil {
+		t.Fatalf("DeleteComment: %v", err)
+	}
+
+	comments, err := u.GetComments()
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != 3 {
+		t.Fatalf("expected only comment 3 to remain, got %+v", comments)
+	}
+
+	docXML := readDocXML(t, u)
+	for _, id := range []string{`w:id="1"`, `w:id="2"`} {
+		if strings.Contains(docXML, id) {
+			t.Errorf("expected markers for %s removed", id)
+		}
+	}
+	if !strings.Contains(docXML, `<w:commentReference w:id="3"/>`) {
+		t.Error("unrelated comment reference must be kept")
+	}
+	if !strings.Contains(docXML, "first") {
+		t.Error("anchored text must be kept")
+	}
+
+	ext := readWordPart(t, u, commentsExtendedFile)
+	if strings.Contains(ext, commentParaID(1)) || strings.Contains(ext, commentParaID(2)) {
+		t.Error("expected extended entries for deleted thread removed")
+	}
+	if strings.Contains(readWordPart(t, u, commentsExtensibleFile), commentDurableID(2)) {
+		t.Error("expected commentsExtensible entry for reply removed")
+	}
+
+	if err := u.DeleteComment(1); err == nil {
+		t.Error("expected error deleting a missing comment")
+	}
+}
+
+func TestRemoveCommentMarkers_KeepsRunWithOtherContent(t *testing.T) {
+	doc := []byte(`<w:p><w:commentRangeStart w:id="4"/><w:r><w:t>a</w:t></w:r><w:commentRangeEnd w:id="4"/>` +
+		`<w:r><w:rPr><w:rStyle w:val="CommentReference"/></w:rPr><w:commentReference w:id="4"/></w:r>` +
+		`<w:r><w:t>b</w:t><w:commentReference w:id="4"/></w:r></w:p>`)
+
+	got := string(removeCommentMarkers(doc, 4))
+	want := `<w:p><w:r><w:t>a</w:t></w:r><w:r><w:t>b</w:t></w:r></w:p>`
+	if got != want {
+		t.Errorf("removeCommentMarkers:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestParseCommentsExtended(t *testing.T) {
+	raw := []byte(`<w15:commentsEx>` +
+		`<w15:commentEx w15:paraId="0a1b2c3d" w15:done="1"/>` +
+		`<w15:commentEx w15:paraId="1A2B3C4D" w15:paraIdParent="0A1B2C3D" w15:done="0"/>` +
+		`</w15:commentsEx>`)
+
+	ext := parseCommentsExtended(raw)
+	if len(ext) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(ext))
+	}
+	if !ext["0A1B2C3D"].Done {
+		t.Error("expected first entry done")
+	}
+	if ext["1A2B3C4D"].ParentParaID != "0A1B2C3D" || ext["1A2B3C4D"].Done {
+		t.Errorf("unexpected reply entry %+v", ext["1A2B3C4D"])
+	}
+}
+
+func TestExtractCommentAnchorText_MultiParagraph(t *testing.T) {
+	doc := []byte(`<w:p><w:r><w:t>before </w:t></w:r><w:commentRangeStart w:id="2"/><w:r><w:t>one</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>two</w:t></w:r><w:commentRangeEnd w:id="2"/></w:p>`)
+
+	if got := extractCommentAnchorText(doc, 2); got != "one\ntwo" {
+		t.Errorf("expected %q, got %q", "one\ntwo", got)
+	}
+	if got := extractCommentAnchorText(doc, 9); got != "" {
+		t.Errorf("expected empty anchor for missing comment, got %q", got)
+	}
+}
@@ -43,6 +43,9 @@ var (
 	// chartFilePattern matches chart XML filenames (e.g., chart1.xml, chart2.xml)
 	chartFilePattern = regexp.MustCompile(`^chart(\d+)\.xml$`)
 
+	// chartExFilePattern matches chartex XML filenames (e.g., chartEx1.xml)
+	chartExFilePattern = regexp.MustCompile(`^chartEx(\d+)\.xml$`)
+
 	// imageFilePattern matches image filenames in media folder (e.g., image1.png, image2.jpg)
 	imageFilePattern = regexp.MustCompile(`^image(\d+)\.\w+$`)
 
@@ -96,6 +99,18 @@ const (
 	DrawingMLNS      = "http://schemas.openxmlformats.org/drawingml/2006/main"
 	ChartNS          = "http://schemas.openxmlformats.org/drawingml/2006/chart"
 	SpreadsheetMLNS  = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	BibliographyNS   = "http://schemas.openxmlformats.org/officeDocument/2006/bibliography"
+	CustomXMLNS      = "http://schemas.openxmlformats.org/officeDocument/2006/customXml"
+	MathNS           = "http://schemas.openxmlformats.org/officeDocument/2006/math"
+)
+
+// Markup-compatibility and Word extension namespace URIs (used for threaded comments)
+const (
+	MarkupCompatibilityNS = "http://schemas.openxmlformats.org/markup-compatibility/2006"
+	Word2010NS            = "http://schemas.microsoft.com/office/word/2010/wordml"
+	Word2012NS            = "http://schemas.microsoft.com/office/word/2012/wordml"
+	Word2016CIDNS         = "http://schemas.microsoft.com/office/word/2016/wordml/cid"
+	Word2018CEXNS         = "http://schemas.microsoft.com/office/word/2018/wordml/cex"
 )
 
 // VML and Office namespace URIs (used for OLE embedded objects)
@@ -130,6 +145,9 @@ var embeddingFilePattern = regexp.MustCompile(`^embedding(\d+)\.xlsx$`)
 const (
 	ChartContentType = "application/vnd.openxmlformats-officedocument.drawingml.chart+xml"
 
+	// ChartExContentType is the content type of Office 2016 chartex parts.
+	ChartExContentType = "application/vnd.ms-office.chartex+xml"
+
 	// DocxMainContentType is the document body content type for .docx files.
 	DocxMainContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"
 
@@ -143,3 +161,15 @@ const (
 	ImageBMPType        = "image/bmp"
 	ImageTIFFType       = "image/tiff"
 )
+
+// Office 2016 chartex constants (waterfall, histogram, treemap and the other
+// chart kinds written as cx:chartSpace parts)
+const (
+	ChartExNS      = "http://schemas.microsoft.com/office/drawing/2014/chartex"
+	ChartExRelType = "http://schemas.microsoft.com/office/2014/relationships/chartEx"
+
+	// chartEx1NS and chartEx2NS name the Office versions that render the
+	// chartex kinds in mc:Choice Requires; funnel charts need the later one
+	chartEx1NS = "http://schemas.microsoft.com/office/drawing/2015/9/8/chartex"
+	chartEx2NS = "http://schemas.microsoft.com/office/drawing/2015/10/21/chartex"
+)
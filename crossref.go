@@ -0,0 +1,460 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CrossReferenceKind defines what a cross-reference shows
+type CrossReferenceKind string
+
+const (
+	// CrossRefText shows the text of the bookmark or heading, or the entire caption
+	CrossRefText CrossReferenceKind = "text"
+	// CrossRefPage shows the page number of the target
+	CrossRefPage CrossReferenceKind = "page"
+	// CrossRefLabelNumber shows a caption's label and number, e.g. "Figure 3"
+	CrossRefLabelNumber CrossReferenceKind = "labelNumber"
+	// CrossRefCaptionText shows a caption's description only
+	CrossRefCaptionText CrossReferenceKind = "captionText"
+)
+
+// CrossReferenceTarget identifies what a cross-reference points to. Set
+// exactly one of Bookmark, Heading or CaptionType/CaptionNumber.
+type CrossReferenceTarget struct {
+	// Bookmark is the name of an existing bookmark
+	Bookmark string
+
+	// Heading is the text of a heading paragraph (Heading1-9 style or an
+	// outline level); a hidden _Ref bookmark is added around it
+	Heading string
+
+	// CaptionType and CaptionNumber select a numbered caption, e.g. Figure 3
+	CaptionType   CaptionType
+	CaptionNumber int
+}
+
+// BookmarkTarget returns a cross-reference target for a bookmark
+func BookmarkTarget(name string) CrossReferenceTarget {
+	return CrossReferenceTarget{Bookmark: name}
+}
+
+// HeadingTarget returns a cross-reference target for the heading with the given text
+func HeadingTarget(text string) CrossReferenceTarget {
+	return CrossReferenceTarget{Heading: text}
+}
+
+// CaptionTarget returns a cross-reference target for a numbered caption
+func CaptionTarget(captionType CaptionType, number int) CrossReferenceTarget {
+	return CrossReferenceTarget{CaptionType: captionType, CaptionNumber: number}
+}
+
+// CrossReferenceOptions defines how InsertCrossReference writes its paragraph
+type CrossReferenceOptions struct {
+	// Prefix and Suffix are literal text around the reference, e.g. "see " and "."
+	Prefix string
+	Suffix string
+
+	// Format is the character formatting of the reference; Text is ignored
+	Format RunOptions
+
+	// Style of the paragraph (default: Normal)
+	Style ParagraphStyle
+
+	// Position and Anchor place the paragraph as in InsertParagraph
+	Position InsertPosition
+	Anchor   string
+}
+
+// InsertCrossReference inserts a paragraph containing a cross-reference to a
+// bookmark, heading or caption. Use CrossReferenceField to place references
+// inside other paragraphs, e.g. "see Figure 3 on page 12".
+func (u *Updater) InsertCrossReference(target CrossReferenceTarget, kind CrossReferenceKind, opts CrossReferenceOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	field, err := u.CrossReferenceField(target, kind)
+	if err != nil {
+		return err
+	}
+
+	var runs []RunOptions
+	if opts.Prefix != "" {
+		prefix := opts.Format
+		prefix.Text = opts.Prefix
+		runs = append(runs, prefix)
+	}
+	ref := opts.Format
+	ref.Field = field
+	runs = append(runs, ref)
+	if opts.Suffix != "" {
+		suffix := opts.Format
+		suffix.Text = opts.Suffix
+		runs = append(runs, suffix)
+	}
+
+	return u.InsertParagraph(ParagraphOptions{
+		Runs:     runs,
+		Style:    opts.Style,
+		Position: opts.Position,
+		Anchor:   opts.Anchor,
+	})
+}
+
+// CrossReferenceField returns a REF or PAGEREF field (with \h, so it links to
+// its target) for use in RunOptions.Field. Headings and captions get a hidden
+// _Ref bookmark on first use. The cached result is computed from the
+// document so the reference reads correctly before fields are updated; page
+// numbers are estimated from explicit page and section breaks.
+func (u *Updater) CrossReferenceField(target CrossReferenceTarget, kind CrossReferenceKind) (*Field, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+	if err := validateCrossReference(target, kind); err != nil {
+		return nil, err
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return nil, NewXMLParseError("document.xml", err)
+	}
+
+	var span crossRefSpan
+	switch {
+	case target.Bookmark != "":
+		span, err = findBookmarkSpan(raw, target.Bookmark)
+	case target.Heading != "":
+		span, err = findHeadingSpan(raw, target.Heading)
+	default:
+		span, err = findCaptionSpan(raw, target.CaptionType, target.CaptionNumber, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := span.bookmark
+	if name == "" {
+		bookmarkID, err := u.getNextBookmarkID()
+		if err != nil {
+			return nil, fmt.Errorf("get next bookmark ID: %w", err)
+		}
+		name = uniqueHiddenBookmarkName(raw, "_Ref", bookmarkID)
+		raw = insertBookmarkAround(raw, span.start, span.end, name, bookmarkID)
+		if err := atomicWriteFile(docPath, raw, 0o644); err != nil {
+			return nil, NewXMLWriteError("document.xml", err)
+		}
+	}
+
+	if kind == CrossRefPage {
+		return &Field{
+			Instruction: "PAGEREF " + name,
+			Switches:    []string{`\h`},
+			Result:      fmt.Sprintf("%d", estimatePageNumber(raw, span.start)),
+			Dirty:       true,
+		}, nil
+	}
+	return &Field{
+		Instruction: "REF " + name,
+		Switches:    []string{`\h`},
+		Result:      span.text,
+		Dirty:       true,
+	}, nil
+}
+
+// validateCrossReference checks that exactly one target is set and that the
+// kind suits it
+func validateCrossReference(target CrossReferenceTarget, kind CrossReferenceKind) error {
+	isCaption := target.CaptionType != "" || target.CaptionNumber != 0
+	set := 0
+	for _, ok := range []bool{target.Bookmark != "", target.Heading != "", isCaption} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return NewValidationError("target", "set exactly one of Bookmark, Heading or CaptionType/CaptionNumber")
+	}
+	if isCaption {
+		if target.CaptionType == "" || target.CaptionNumber < 1 {
+			return NewValidationError("target", "caption targets need a CaptionType and a CaptionNumber >= 1")
+		}
+	}
+
+	switch kind {
+	case CrossRefText, CrossRefPage:
+	case CrossRefLabelNumber, CrossRefCaptionText:
+		if !isCaption {
+			return NewValidationError("kind", fmt.Sprintf("%s references require a caption target", kind))
+		}
+	default:
+		return NewValidationError("kind", fmt.Sprintf("invalid cross-reference kind: %s", kind))
+	}
+	return nil
+}
+
+// crossRefSpan is the part of document.xml a cross-reference points to.
+// bookmark is the name of an existing bookmark covering it, if any.
+type crossRefSpan struct {
+	start, end int
+	text       string
+	bookmark   string
+}
+
+// bookmarkStartPattern matches a bookmark start with its ID and name
+var bookmarkStartPattern = regexp.MustCompile(`<w:bookmarkStart\s[^>]*?w:id="(\d+)"[^>]*?w:name="([^"]*)"[^>]*/>|<w:bookmarkStart\s[^>]*?w:name="([^"]*)"[^>]*?w:id="(\d+)"[^>]*/>`)
+
+// bookmarkRange finds the offsets of the named bookmark: the start of its
+// bookmarkStart, the start of its bookmarkEnd and the end of the bookmarkEnd
+func bookmarkRange(raw []byte, name string) (start, endTag, end int, ok bool) {
+	for _, m := range bookmarkStartPattern.FindAllSubmatchIndex(raw, -1) {
+		id, bmName := "", ""
+		if m[2] != -1 {
+			id, bmName = string(raw[m[2]:m[3]]), string(raw[m[4]:m[5]])
+		} else {
+			bmName, id = string(raw[m[6]:m[7]]), string(raw[m[8]:m[9]])
+		}
+		if xmlUnescape(bmName) != name {
+			continue
+		}
+		endTag := regexp.MustCompile(`<w:bookmarkEnd\s[^>]*w:id="` + id + `"[^>]*/>`)
+		loc := endTag.FindIndex(raw[m[1]:])
+		if loc == nil {
+			return 0, 0, 0, false
+		}
+		return m[0], m[1] + loc[0], m[1] + loc[1], true
+	}
+	return 0, 0, 0, false
+}
+
+// findBookmarkSpan locates an existing bookmark
+func findBookmarkSpan(raw []byte, name string) (crossRefSpan, error) {
+	start, _, end, ok := bookmarkRange(raw, name)
+	if !ok {
+		return crossRefSpan{}, NewValidationError("target", fmt.Sprintf("bookmark not found: %s", name))
+	}
+	return crossRefSpan{start: start, end: end, text: extractVisibleText(raw[start:end]), bookmark: name}, nil
+}
+
+// headingStylePattern matches the pStyle of a built-in heading
+var headingStylePattern = regexp.MustCompile(`<w:pStyle w:val="(?i:heading) ?[1-9]"`)
+
+// findHeadingSpan locates the contents of the first heading paragraph whose
+// text equals heading
+func findHeadingSpan(raw []byte, heading string) (crossRefSpan, error) {
+	want := strings.TrimSpace(heading)
+	for _, para := range bodyParagraphs(raw) {
+		element := raw[para.start:para.end]
+		if !headingStylePattern.Match(element) && !bytes.Contains(element, []byte("<w:outlineLvl ")) {
+			continue
+		}
+		text := extractVisibleText(element)
+		if strings.TrimSpace(text) != want {
+			continue
+		}
+		start, end := paragraphContentRange(raw, para)
+		return crossRefSpan{start: start, end: end, text: text, bookmark: hiddenBookmarkAt(raw, "_Ref", start, end)}, nil
+	}
+	return crossRefSpan{}, NewTextNotFoundError(heading)
+}
+
+// findCaptionSpan locates the numbered caption and the part of it that the
+// kind of reference shows
+func findCaptionSpan(raw []byte, captionType CaptionType, number int, kind CrossReferenceKind) (crossRefSpan, error) {
+	seq := "SEQ " + string(captionType)
+	count := 0
+	for _, para := range bodyParagraphs(raw) {
+		element := raw[para.start:para.end]
+		isCaption := false
+		for _, instr := range fieldInstructions(element) {
+			if instr == seq || strings.HasPrefix(instr, seq+" ") {
+				isCaption = true
+				break
+			}
+		}
+		if !isCaption {
+			continue
+		}
+		if count++; count != number {
+			continue
+		}
+
+		start, end := paragraphContentRange(raw, para)
+		seqStart, seqEnd := captionNumberRange(raw, start, end, seq)
+		if seqEnd == -1 {
+			return crossRefSpan{}, fmt.Errorf("malformed %s caption %d", captionType, number)
+		}
+
+		// The SEQ field's cached result may be stale, so the number is taken
+		// from the caption's position instead.
+		label := extractVisibleText(raw[start:seqStart]) + strconv.Itoa(number)
+		span := crossRefSpan{start: start, end: end, text: label + extractVisibleText(raw[seqEnd:end])}
+		switch kind {
+		case CrossRefLabelNumber:
+			span.end, span.text = seqEnd, label
+		case CrossRefCaptionText:
+			span.start = captionTextStart(raw, seqEnd, end)
+			if span.start == end {
+				return crossRefSpan{}, NewValidationError("target", fmt.Sprintf("%s %d has no caption text", captionType, number))
+			}
+			span.text = extractVisibleText(raw[span.start:end])
+		}
+		span.bookmark = hiddenBookmarkAt(raw, "_Ref", span.start, span.end)
+		return span, nil
+	}
+	return crossRefSpan{}, NewValidationError("target", fmt.Sprintf("%s %d not found", captionType, number))
+}
+
+// captionNumberRange returns the offset of the SEQ field's instruction and
+// the offset just after the run that ends the field in raw[start:end], or -1
+func captionNumberRange(raw []byte, start, end int, seq string) (int, int) {
+	instr := bytes.Index(raw[start:end], []byte(seq))
+	if instr == -1 {
+		return -1, -1
+	}
+	instr += start
+	fldEnd := bytes.Index(raw[instr:end], []byte(`w:fldCharType="end"`))
+	if fldEnd == -1 {
+		return -1, -1
+	}
+	runEnd := bytes.Index(raw[instr+fldEnd:end], []byte("</w:r>"))
+	if runEnd == -1 {
+		return -1, -1
+	}
+	return instr, instr + fldEnd + runEnd + len("</w:r>")
+}
+
+// captionTextStart skips the separator runs (": ", " - ", ...) after a caption
+// number and returns the offset of the first run of description text
+func captionTextStart(raw []byte, pos, end int) int {
+	for _, loc := range runElementPattern.FindAllSubmatchIndex(raw[pos:end], -1) {
+		run, ok := parseRun(raw[pos+loc[2] : pos+loc[3]])
+		if ok && strings.Trim(run.Text, " :.-–—\t") != "" {
+			return pos + loc[0]
+		}
+	}
+	return end
+}
+
+// bodyParagraphs returns the offsets of every paragraph in document order
+func bodyParagraphs(raw []byte) []crossRefSpan {
+	var paras []crossRefSpan
+	for pos := 0; ; {
+		start := findNextWordTagStart(raw, pos, "p")
+		if start == -1 {
+			return paras
+		}
+		end := wordElementEnd(raw, start, "p")
+		if end == -1 {
+			return paras
+		}
+		paras = append(paras, crossRefSpan{start: start, end: end})
+		pos = end
+	}
+}
+
+// paragraphContentRange returns the offsets of a paragraph's content after
+// its properties and before </w:p>
+func paragraphContentRange(raw []byte, para crossRefSpan) (int, int) {
+	element := raw[para.start:para.end]
+	start := para.start + bytes.IndexByte(element, '>') + 1
+	if idx := bytes.Index(element, []byte("</w:pPr>")); idx != -1 {
+		start = para.start + idx + len("</w:pPr>")
+	}
+	end := para.end - len("</w:p>")
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// bookmarkMarkupPattern matches bookmark start and end markers
+var bookmarkMarkupPattern = regexp.MustCompile(`<w:bookmark(?:Start|End)\s[^>]*/>`)
+
+// onlyBookmarkMarkup reports whether data holds nothing but bookmark markers
+func onlyBookmarkMarkup(data []byte) bool {
+	return len(bookmarkMarkupPattern.ReplaceAll(data, nil)) == 0
+}
+
+// hiddenBookmarkAt returns the name of a hidden bookmark with the given prefix
+// (_Ref, _Toc) that already spans raw[start:end], ignoring neighbouring
+// bookmark markers, or ""
+func hiddenBookmarkAt(raw []byte, prefix string, start, end int) string {
+	for _, m := range bookmarkStartPattern.FindAllSubmatch(raw, -1) {
+		name := string(m[2])
+		if name == "" {
+			name = string(m[3])
+		}
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		bmStart, bmEndTag, bmEnd, ok := bookmarkRange(raw, name)
+		if !ok {
+			continue
+		}
+		startOK := onlyBookmarkMarkup(raw[min(start, bmStart):max(start, bmStart)])
+		endOK := onlyBookmarkMarkup(raw[min(end, bmEndTag):max(end, bmEndTag)]) ||
+			onlyBookmarkMarkup(raw[min(end, bmEnd):max(end, bmEnd)])
+		if startOK && endOK {
+			return name
+		}
+	}
+	return ""
+}
+
+// uniqueHiddenBookmarkName returns a hidden bookmark name with the given
+// prefix (_Ref, _Toc) not yet used in raw
+func uniqueHiddenBookmarkName(raw []byte, prefix string, id int) string {
+	for n := id; ; n++ {
+		name := fmt.Sprintf("%s%09d", prefix, n)
+		if !bytes.Contains(raw, []byte(`w:name="`+name+`"`)) {
+			return name
+		}
+	}
+}
+
+// insertBookmarkAround wraps raw[start:end] in a bookmark
+func insertBookmarkAround(raw []byte, start, end int, name string, id int) []byte {
+	raw = spliceBytes(raw, end, end, []byte(fmt.Sprintf(`<w:bookmarkEnd w:id="%d"/>`, id)))
+	return spliceBytes(raw, start, start, []byte(fmt.Sprintf(`<w:bookmarkStart w:id="%d" w:name="%s"/>`, id, xmlEscape(name))))
+}
+
+// extractVisibleText returns the text of the runs in data, leaving out field
+// instructions
+func extractVisibleText(data []byte) string {
+	var text strings.Builder
+	for _, m := range extractTextPattern.FindAllSubmatch(data, -1) {
+		text.WriteString(xmlUnescape(string(m[1])))
+	}
+	return text.String()
+}
+
+// pageBreakPattern matches explicit page breaks
+var pageBreakPattern = regexp.MustCompile(`<w:br\s[^>]*w:type="page"[^>]*/>|<w:pageBreakBefore/>`)
+
+// estimatePageNumber estimates the page at offset pos. Documents last saved
+// by Word carry rendered page breaks, which are used when present; otherwise
+// explicit page breaks and section breaks that start a new page are counted.
+func estimatePageNumber(raw []byte, pos int) int {
+	if bytes.Contains(raw, []byte("<w:lastRenderedPageBreak/>")) {
+		return bytes.Count(raw[:pos], []byte("<w:lastRenderedPageBreak/>")) + 1
+	}
+
+	page := 1 + len(pageBreakPattern.FindAllIndex(raw[:pos], -1))
+	// A section's w:type says how it starts, so each break before pos is
+	// governed by the section that follows it.
+	ranges := findSectionRanges(raw)
+	for i := 0; i+1 < len(ranges) && ranges[i].end <= pos; i++ {
+		next := raw[ranges[i+1].start:ranges[i+1].end]
+		if tag := sectPrChildTag(next, "type"); tag == nil || xmlAttrValue(tag, "w:val") != string(SectionBreakContinuous) {
+			page++
+		}
+	}
+	return page
+}
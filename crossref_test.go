@@ -0,0 +1,97 @@
+package godocx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func crossRefFixtureBody() string {
+	return `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Results</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Intro</w:t></w:r><w:r><w:br w:type="page"/></w:r></w:p>` +
+		string(generateCaptionXML(CaptionOptions{Type: CaptionFigure, AutoNumber: true, Description: "Sales by region"})) +
+		`<w:p><w:bookmarkStart w:id="1" w:name="summary"/><w:r><w:t>Key findings</w:t></w:r><w:bookmarkEnd w:id="1"/></w:p>` +
+		`<w:p><w:r><w:br w:type="page"/></w:r></w:p>` +
+		string(generateCaptionXML(CaptionOptions{Type: CaptionFigure, AutoNumber: true, Description: "Costs"}))
+}
+
+func TestCrossReferenceField_Targets(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, crossRefFixtureBody()))
+
+	tests := []struct {
+		target      CrossReferenceTarget
+		kind        CrossReferenceKind
+		instruction string
+		result      string
+	}{
+		{BookmarkTarget("summary"), CrossRefText, "REF summary", "Key findings"},
+		{BookmarkTarget("summary"), CrossRefPage, "PAGEREF summary", "2"},
+		{HeadingTarget("Results"), CrossRefText, "REF _Ref", "Results"},
+		{CaptionTarget(CaptionFigure, 2), CrossRefLabelNumber, "REF _Ref", "Figure 2"},
+		{CaptionTarget(CaptionFigure, 2), CrossRefCaptionText, "REF _Ref", "Costs"},
+		{CaptionTarget(CaptionFigure, 2), CrossRefPage, "PAGEREF _Ref", "3"},
+		{CaptionTarget(CaptionFigure, 1), CrossRefText, "REF _Ref", "Figure 1: Sales by region"},
+	}
+	for _, tt := range tests {
+		field, err := u.CrossReferenceField(tt.target, tt.kind)
+		if err != nil {
+			t.Fatalf("%+v %s: %v", tt.target, tt.kind, err)
+		}
+		if !strings.HasPrefix(field.Instruction, tt.instruction) || !reflect.DeepEqual(field.Switches, []string{`\h`}) {
+			t.Errorf("%+v %s: got instruction %q %v", tt.target, tt.kind, field.Instruction, field.Switches)
+		}
+		if field.Result != tt.result {
+			t.Errorf("%+v %s: got result %q, want %q", tt.target, tt.kind, field.Result, tt.result)
+		}
+	}
+
+	// Referencing the same heading and caption part again reuses their bookmarks.
+	doc := readDocXML(t, u)
+	before := strings.Count(doc, `w:name="_Ref`)
+	if before != 5 {
+		t.Errorf("expected 5 hidden bookmarks (heading, 2x whole caption, label, caption text), got %d", before)
+	}
+	if _, err := u.CrossReferenceField(HeadingTarget("Results"), CrossRefPage); err != nil {
+		t.Fatalf("CrossReferenceField: %v", err)
+	}
+	if _, err := u.CrossReferenceField(CaptionTarget(CaptionFigure, 2), CrossRefLabelNumber); err != nil {
+		t.Fatalf("CrossReferenceField: %v", err)
+	}
+	if after := strings.Count(readDocXML(t, u), `w:name="_Ref`); after != before {
+		t.Errorf("expected bookmarks to be reused, got %d then %d", before, after)
+	}
+}
+
+func TestInsertCrossReference(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, crossRefFixtureBody()))
+
+	err := u.InsertCrossReference(CaptionTarget(CaptionFigure, 1), CrossRefLabelNumber, CrossReferenceOptions{
+		Prefix: "See ", Suffix: " for details.", Position: PositionEnd,
+	})
+	if err != nil {
+		t.Fatalf("InsertCrossReference: %v", err)
+	}
+	paragraphs, err := u.GetParagraphText()
+	if err != nil {
+		t.Fatalf("GetParagraphText: %v", err)
+	}
+	if last := paragraphs[len(paragraphs)-1]; last != "See Figure 1 for details." {
+		t.Errorf("expected cached reference text, got %q", last)
+	}
+
+	bad := []struct {
+		target CrossReferenceTarget
+		kind   CrossReferenceKind
+	}{
+		{BookmarkTarget("missing"), CrossRefText},
+		{HeadingTarget("Nope"), CrossRefText},
+		{CaptionTarget(CaptionTable, 1), CrossRefLabelNumber},
+		{BookmarkTarget("summary"), CrossRefLabelNumber},
+		{CrossReferenceTarget{Bookmark: "summary", Heading: "Results"}, CrossRefText},
+	}
+	for i, tt := range bad {
+		if err := u.InsertCrossReference(tt.target, tt.kind, CrossReferenceOptions{}); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package godocx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// EquationOptions defines options for inserting an equation
+type EquationOptions struct {
+	// Inline places the equation in running text directly after Anchor,
+	// instead of in a display paragraph of its own
+	Inline bool
+
+	// Position where to insert a display equation
+	Position InsertPosition
+
+	// Anchor text for position-based or inline insertion
+	Anchor string
+
+	// Alignment justifies a display equation (default: centered)
+	Alignment ParagraphAlignment
+}
+
+// Equation is an Office Math equation read from the document
+type Equation struct {
+	// Text is the equation in Word's linear format, e.g. "x=(-b±√(b^2-4ac))/(2a)"
+	Text string
+
+	// Display reports whether the equation is in a display (m:oMathPara)
+	// block rather than inline
+	Display bool
+}
+
+// InsertEquation converts a LaTeX math expression into an Office Math
+// equation and inserts it, either as a display equation in its own paragraph
+// or inline after opts.Anchor. Surrounding $, $$, \( \) or \[ \] are
+// optional.
+//
+// The supported subset covers \frac, \binom, sub- and superscripts, \sqrt
+// with an optional degree, \sum, \prod and \int with limits, matrix
+// environments (matrix, pmatrix, bmatrix, vmatrix, cases), Greek letters,
+// operators and relations, \left...\right delimiters, functions such as
+// \sin and \lim, accents, \text and font commands. The operand of a sum,
+// integral or limit runs up to the next relation such as "=". To place an
+// equation among other runs, use RunOptions.Equation.
+func (u *Updater) InsertEquation(latex string, opts EquationOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	nodes, err := parseLaTeX(latex)
+	if err != nil {
+		return NewValidationError("latex", err.Error())
+	}
+	if opts.Inline && opts.Anchor == "" {
+		return NewValidationError("anchor", "anchor text is required for an inline equation")
+	}
+	jc, ok := paragraphAlignmentValue(opts.Alignment)
+	if !ok {
+		jc = "center"
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if opts.Inline {
+		writeOMathXML(&buf, nodes, RunOptions{})
+		raw, err = insertAtDocumentText(raw, 0, opts.Anchor, true, buf.String())
+	} else {
+		fmt.Fprintf(&buf, `<w:p><m:oMathPara><m:oMathParaPr><m:jc m:val="%s"/></m:oMathParaPr>`, mathJustification(jc))
+		writeOMathXML(&buf, nodes, RunOptions{})
+		buf.WriteString("</m:oMathPara></w:p>")
+		raw, err = insertParagraphAtPosition(raw, buf.Bytes(), ParagraphOptions{Position: opts.Position, Anchor: opts.Anchor})
+	}
+	if err != nil {
+		return fmt.Errorf("insert equation: %w", err)
+	}
+
+	raw = ensureMathNamespace(raw)
+	if err := atomicWriteFile(docPath, raw, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+	return nil
+}
+
+// GetEquations returns the equations of the document body in order, in
+// Word's linear format
+func (u *Updater) GetEquations() ([]Equation, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "document.xml"))
+	if err != nil {
+		return nil, fmt.Errorf("read document.xml: %w", err)
+	}
+	return readEquations(raw)
+}
+
+// mathJustification maps a paragraph alignment value to an m:jc value
+func mathJustification(jc string) string {
+	switch jc {
+	case "left", "start":
+		return "left"
+	case "right", "end":
+		return "right"
+	}
+	return "center"
+}
+
+// partRootPattern matches the start of the root element of a part that can
+// hold equation runs
+var partRootPattern = regexp.MustCompile(`<w:(?:document|hdr|ftr|footnotes|endnotes|comments)[\s>]`)
+
+// ensureMathNamespace declares the math namespace on the root element of a
+// document, header, footer, notes or comments part
+func ensureMathNamespace(raw []byte) []byte {
+	if bytes.Contains(raw, []byte(`xmlns:m=`)) {
+		return raw
+	}
+	loc := partRootPattern.FindIndex(raw)
+	if loc == nil {
+		return raw
+	}
+	return spliceBytes(raw, loc[1]-1, loc[1]-1, []byte(` xmlns:m="`+MathNS+`"`))
+}
+
+// hasEquationRuns reports whether any run is an inline equation
+func hasEquationRuns(runs []RunOptions) bool {
+	for _, run := range runs {
+		if run.Equation != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEquationParagraphs reports whether any paragraph has an equation run
+func hasEquationParagraphs(paragraphs [][]RunOptions) bool {
+	for _, runs := range paragraphs {
+		if hasEquationRuns(runs) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEquationRuns checks the LaTeX source of every equation run, since
+// writeRunsXML leaves out equations it cannot convert
+func validateEquationRuns(runs []RunOptions) error {
+	for i, run := range runs {
+		if run.Equation == "" {
+			continue
+		}
+		if _, err := parseLaTeX(run.Equation); err != nil {
+			return fmt.Errorf("run %d: %w", i+1, NewValidationError("Equation", err.Error()))
+		}
+	}
+	return nil
+}
+
+// validateEquationParagraphs checks the equation runs of every paragraph
+func validateEquationParagraphs(paragraphs [][]RunOptions) error {
+	for i, runs := range paragraphs {
+		if err := validateEquationRuns(runs); err != nil {
+			return fmt.Errorf("paragraph %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// mathElement is an Office Math element read from the document
+type mathElement struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*mathElement
+}
+
+// child returns the first child element with the given name
+func (e *mathElement) child(name string) *mathElement {
+	for _, c := range e.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// property returns the m:val of a property element such as m:chr inside
+// the element's properties, e.g. m:naryPr
+func (e *mathElement) property(props, name string) (string, bool) {
+	if p := e.child(props); p != nil {
+		if v := p.child(name); v != nil {
+			val, ok := v.attrs["val"]
+			if !ok {
+				val = "1"
+			}
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// readEquations parses every m:oMath element of a part
+func readEquations(raw []byte) ([]Equation, error) {
+	dec := xml.NewDecoder(bytes.NewReader(raw))
+	var equations []Equation
+	paraDepth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewXMLParseError("document.xml", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !isMathName(t.Name) {
+				continue
+			}
+			switch t.Name.Local {
+			case "oMathPara":
+				paraDepth++
+			case "oMath":
+				root, err := readMathElement(dec, t)
+				if err != nil {
+					return nil, NewXMLParseError("document.xml", err)
+				}
+				equations = append(equations, Equation{Text: linearMath(root), Display: paraDepth > 0})
+			}
+		case xml.EndElement:
+			if isMathName(t.Name) && t.Name.Local == "oMathPara" {
+				paraDepth--
+			}
+		}
+	}
+	return equations, nil
+}
+
+// isMathName reports whether name is in the math namespace. Parts that use
+// the m prefix without declaring it are tolerated.
+func isMathName(name xml.Name) bool {
+	return name.Space == MathNS || name.Space == "m"
+}
+
+// readMathElement reads the element started by start and its math
+// descendants. Elements outside the math namespace, such as run
+// properties, are skipped.
+func readMathElement(dec *xml.Decoder, start xml.StartElement) (*mathElement, error) {
+	e := &mathElement{name: start.Name.Local, attrs: make(map[string]string)}
+	for _, a := range start.Attr {
+		e.attrs[a.Name.Local] = a.Value
+	}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !isMathName(t.Name) {
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			child, err := readMathElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			e.children = append(e.children, child)
+		case xml.CharData:
+			if e.name == "t" {
+				e.text += string(t)
+			}
+		case xml.EndElement:
+			return e, nil
+		}
+	}
+}
+
+// linearMath returns the linear format of the children of e
+func linearMath(e *mathElement) string {
+	if e == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, c := range e.children {
+		sb.WriteString(linearMathElement(c))
+	}
+	return sb.String()
+}
+
+// linearArg returns the linear format of an argument, parenthesised unless
+// it is a single character, a single word or number, or already bracketed
+func linearArg(e *mathElement) string {
+	text := linearMath(e)
+	if len([]rune(text)) <= 1 || len(e.children) != 1 {
+		if len([]rune(text)) > 1 {
+			return "(" + text + ")"
+		}
+		return text
+	}
+	switch e.children[0].name {
+	case "d":
+		return text
+	case "r":
+		isWord := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) == -1
+		isNumber := strings.Trim(text, "0123456789.") == ""
+		if isWord || isNumber {
+			return text
+		}
+	}
+	return "(" + text + ")"
+}
+
+// linearMathElement returns the linear format of one math element
+func linearMathElement(e *mathElement) string {
+	switch e.name {
+	case "r":
+		if t := e.child("t"); t != nil {
+			return t.text
+		}
+		return ""
+	case "f":
+		num, den := e.child("num"), e.child("den")
+		switch typ, _ := e.property("fPr", "type"); typ {
+		case "noBar":
+			return linearMath(num) + "¦" + linearMath(den)
+		case "lin":
+			return linearMath(num) + "/" + linearMath(den)
+		}
+		return linearArg(num) + "/" + linearArg(den)
+	case "sSub":
+		return linearArg(e.child("e")) + "_" + linearArg(e.child("sub"))
+	case "sSup":
+		return linearArg(e.child("e")) + "^" + linearArg(e.child("sup"))
+	case "sSubSup":
+		return linearArg(e.child("e")) + "_" + linearArg(e.child("sub")) + "^" + linearArg(e.child("sup"))
+	case "sPre":
+		return "_" + linearArg(e.child("sub")) + "^" + linearArg(e.child("sup")) + linearArg(e.child("e"))
+	case "rad":
+		if hide, _ := e.property("radPr", "degHide"); hide == "1" || hide == "on" || hide == "true" || linearMath(e.child("deg")) == "" {
+			return "√" + linearArg(e.child("e"))
+		}
+		return "√(" + linearMath(e.child("deg")) + "&" + linearMath(e.child("e")) + ")"
+	case "nary":
+		chr, ok := e.property("naryPr", "chr")
+		if !ok {
+			chr = "∫"
+		}
+		text := chr
+		if sub := linearMath(e.child("sub")); sub != "" {
+			text += "_" + linearArg(e.child("sub"))
+		}
+		if sup := linearMath(e.child("sup")); sup != "" {
+			text += "^" + linearArg(e.child("sup"))
+		}
+		return text + "▒" + linearArg(e.child("e"))
+	case "d":
+		beg, ok := e.property("dPr", "begChr")
+		if !ok {
+			beg = "("
+		}
+		end, ok := e.property("dPr", "endChr")
+		if !ok {
+			end = ")"
+		}
+		sep, ok := e.property("dPr", "sepChr")
+		if !ok {
+			sep = "|"
+		}
+		var parts []string
+		for _, c := range e.children {
+			if c.name == "e" {
+				parts = append(parts, linearMath(c))
+			}
+		}
+		return beg + strings.Join(parts, sep) + end
+	case "m", "eqArr":
+		var rows []string
+		for _, r := range e.children {
+			switch r.name {
+			case "mr":
+				var cells []string
+				for _, c := range r.children {
+					if c.name == "e" {
+						cells = append(cells, linearMath(c))
+					}
+				}
+				rows = append(rows, strings.Join(cells, "&"))
+			case "e":
+				rows = append(rows, linearMath(r))
+			}
+		}
+		prefix := "■("
+		if e.name == "eqArr" {
+			prefix = "█("
+		}
+		return prefix + strings.Join(rows, "@") + ")"
+	case "func":
+		return linearMath(e.child("fName")) + " " + linearMath(e.child("e"))
+	case "limLow":
+		return linearMath(e.child("e")) + "┬" + linearArg(e.child("lim"))
+	case "limUpp":
+		return linearMath(e.child("e")) + "┴" + linearArg(e.child("lim"))
+	case "acc":
+		chr, ok := e.property("accPr", "chr")
+		if !ok {
+			chr = "\u0302"
+		}
+		return linearArg(e.child("e")) + chr
+	case "bar":
+		if pos, _ := e.property("barPr", "pos"); pos == "top" {
+			return "¯" + linearArg(e.child("e"))
+		}
+		return "▁" + linearArg(e.child("e"))
+	case "rPr", "fPr", "naryPr", "dPr", "mPr", "accPr", "barPr", "radPr", "funcPr",
+		"limLowPr", "limUppPr", "sSubPr", "sSupPr", "sSubSupPr", "sPrePr", "eqArrPr",
+		"groupChrPr", "boxPr", "borderBoxPr", "phantPr", "ctrlPr", "oMathParaPr":
+		return ""
+	}
+	return linearMath(e)
+}
@@ -0,0 +1,809 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// mathKind identifies an Office Math element built from LaTeX
+type mathKind int
+
+const (
+	mathRun    mathKind = iota // m:r
+	mathFrac                   // m:f
+	mathSub                    // m:sSub
+	mathSup                    // m:sSup
+	mathSubSup                 // m:sSubSup
+	mathRad                    // m:rad
+	mathNary                   // m:nary
+	mathDelim                  // m:d
+	mathMatrix                 // m:m
+	mathFunc                   // m:func
+	mathLimLow                 // m:limLow
+	mathAccent                 // m:acc
+	mathBar                    // m:bar
+)
+
+// mathNode is an Office Math element. Only the fields of its kind are set.
+type mathNode struct {
+	kind mathKind
+
+	// text is the run text, n-ary operator, accent or opening delimiter
+	text string
+	// style is the run's m:sty value, or "nor" for normal (\text) text
+	style string
+	// end is the closing delimiter
+	end string
+	// flag is a binomial (fraction without bar), n-ary limits above and
+	// below, a bar below, or a matrix with left-aligned columns
+	flag bool
+
+	e, num, den, sub, sup, deg, lim []*mathNode
+	rows                            [][][]*mathNode
+	hasSub, hasSup                  bool
+}
+
+var latexSymbols = map[string]string{
+	// Greek letters
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ",
+	"varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ",
+	"varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+
+	// Operators
+	"pm": "±", "mp": "∓", "times": "×", "div": "÷", "cdot": "⋅", "ast": "∗", "star": "⋆",
+	"circ": "∘", "bullet": "∙", "oplus": "⊕", "otimes": "⊗", "cup": "∪", "cap": "∩",
+	"setminus": "∖", "wedge": "∧", "land": "∧", "vee": "∨", "lor": "∨", "neg": "¬", "lnot": "¬",
+
+	// Relations
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈",
+	"equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝", "ll": "≪", "gg": "≫",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "supset": "⊃", "subseteq": "⊆",
+	"supseteq": "⊇", "perp": "⊥", "parallel": "∥", "mid": "∣",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "leftrightarrow": "↔", "mapsto": "↦",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "implies": "⟹", "iff": "⟺",
+
+	// Other symbols
+	"infty": "∞", "partial": "∂", "nabla": "∇", "forall": "∀", "exists": "∃", "emptyset": "∅",
+	"varnothing": "∅", "ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"prime": "′", "degree": "°", "angle": "∠", "hbar": "ℏ", "ell": "ℓ", "Re": "ℜ", "Im": "ℑ",
+	"aleph": "ℵ", "langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈",
+	"rceil": "⌉", "vert": "|", "Vert": "‖", "therefore": "∴", "because": "∵",
+
+	// Escaped characters
+	"{": "{", "}": "}", "|": "‖", "%": "%", "#": "#", "&": "&", "_": "_", "$": "$",
+
+	// Spacing
+	",": "\u2009", ":": "\u205F", ";": "\u2004", "!": "", " ": " ", "quad": "\u2003", "qquad": "\u2003\u2003",
+}
+
+// latexRelations end the operand of a sum, integral or limit
+var latexRelations = map[string]bool{
+	"=": true, "<": true, ">": true, `\leq`: true, `\le`: true, `\geq`: true, `\ge`: true,
+	`\neq`: true, `\ne`: true, `\approx`: true, `\equiv`: true, `\sim`: true, `\simeq`: true,
+	`\cong`: true, `\propto`: true, `\to`: true, `\rightarrow`: true, `\Rightarrow`: true,
+	`\implies`: true, `\iff`: true, `\Leftrightarrow`: true, `\ll`: true, `\gg`: true,
+}
+
+// latexNary maps n-ary commands to their operator; sums and products put
+// their limits above and below, integrals beside
+var latexNary = map[string]struct {
+	chr   string
+	under bool
+}{
+	"sum": {"∑", true}, "prod": {"∏", true}, "coprod": {"∐", true},
+	"bigcup": {"⋃", true}, "bigcap": {"⋂", true}, "bigoplus": {"⨁", true}, "bigotimes": {"⨂", true},
+	"int": {"∫", false}, "iint": {"∬", false}, "iiint": {"∭", false}, "oint": {"∮", false},
+}
+
+// latexFunctions are upright function names. Those marked true take their
+// subscript below the name, as in \lim_{x\to 0}.
+var latexFunctions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false, "csc": false,
+	"arcsin": false, "arccos": false, "arctan": false, "sinh": false, "cosh": false, "tanh": false,
+	"coth": false, "log": false, "ln": false, "lg": false, "exp": false, "deg": false, "arg": false,
+	"dim": false, "ker": false, "hom": false,
+	"lim": true, "liminf": true, "limsup": true, "max": true, "min": true, "sup": true, "inf": true,
+	"det": true, "gcd": true, "Pr": true, "argmax": true, "argmin": true,
+}
+
+// latexAccents maps accent commands to their combining character
+var latexAccents = map[string]string{
+	"hat": "\u0302", "widehat": "\u0302", "check": "\u030C", "tilde": "\u0303", "widetilde": "\u0303",
+	"bar": "\u0305", "vec": "\u20D7", "dot": "\u0307", "ddot": "\u0308", "acute": "\u0301",
+	"grave": "\u0300", "breve": "\u0306",
+}
+
+// latexStyles maps font commands to the m:sty of the runs they contain
+var latexStyles = map[string]string{
+	"mathrm": "p", "mathbf": "b", "mathit": "i", "boldsymbol": "bi", "mathsf": "p", "mathup": "p",
+}
+
+// latexMatrices maps matrix environments to their delimiters
+var latexMatrices = map[string][2]string{
+	"matrix": {"", ""}, "smallmatrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"},
+	"Bmatrix": {"{", "}"}, "vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"}, "cases": {"{", ""},
+}
+
+// latexDelimiters maps \left and \right arguments to delimiter characters
+var latexDelimiters = map[string]string{
+	".": "", "(": "(", ")": ")", "[": "[", "]": "]", "|": "|", "/": "/",
+	`\{`: "{", `\}`: "}", `\|`: "‖", `\langle`: "⟨", `\rangle`: "⟩", `\lfloor`: "⌊",
+	`\rfloor`: "⌋", `\lceil`: "⌈", `\rceil`: "⌉", `\vert`: "|", `\Vert`: "‖",
+}
+
+// latexParser converts a LaTeX math expression into Office Math elements
+type latexParser struct {
+	src []rune
+	pos int
+
+	// closer is the delimiter ending the innermost parenthesised expression,
+	// which also ends the operand of a sum inside it
+	closer string
+}
+
+// parseLaTeX parses a LaTeX math expression without surrounding $ signs
+func parseLaTeX(src string) ([]*mathNode, error) {
+	src = strings.TrimSpace(src)
+	for _, pair := range [][2]string{{"$$", "$$"}, {"$", "$"}, {`\[`, `\]`}, {`\(`, `\)`}} {
+		if len(src) > len(pair[0])+len(pair[1]) && strings.HasPrefix(src, pair[0]) && strings.HasSuffix(src, pair[1]) {
+			src = src[len(pair[0]) : len(src)-len(pair[1])]
+			break
+		}
+	}
+	if strings.TrimSpace(src) == "" {
+		return nil, fmt.Errorf("equation is empty")
+	}
+
+	p := &latexParser{src: []rune(src)}
+	nodes, stop, err := p.parseSequence("", false)
+	if err != nil {
+		return nil, err
+	}
+	switch stop {
+	case "":
+		return nodes, nil
+	case "}":
+		return nil, fmt.Errorf("unbalanced }")
+	case `\right`:
+		return nil, fmt.Errorf(`\right without \left`)
+	case `\end`:
+		return nil, fmt.Errorf(`\end without \begin`)
+	default:
+		return nil, fmt.Errorf("%s outside a matrix", stop)
+	}
+}
+
+// peek returns the next token without consuming it: a command such as
+// `\alpha` or `\{`, or a single character. It returns "" at the end.
+func (p *latexParser) peek() string {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	if p.src[p.pos] != '\\' || p.pos+1 >= len(p.src) {
+		return string(p.src[p.pos])
+	}
+	end := p.pos + 1
+	for end < len(p.src) && isASCIILetter(p.src[end]) {
+		end++
+	}
+	if end == p.pos+1 {
+		end++
+	}
+	return string(p.src[p.pos:end])
+}
+
+// next consumes and returns the next token
+func (p *latexParser) next() string {
+	tok := p.peek()
+	p.pos += len([]rune(tok))
+	return tok
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// isStop reports whether tok ends the current sequence
+func (p *latexParser) isStop(tok string) bool {
+	switch tok {
+	case "", "}", "&", `\\`, `\end`, `\right`:
+		return true
+	}
+	return tok == p.closer
+}
+
+// parseSequence parses atoms up to a stop token, which it returns without
+// consuming. closer is an extra stop token such as ")". With stopAtRelation
+// the sequence also ends before a relation such as "=", which delimits the
+// operand of a sum or integral.
+func (p *latexParser) parseSequence(closer string, stopAtRelation bool) ([]*mathNode, string, error) {
+	saved := p.closer
+	if closer != "" {
+		p.closer = closer
+	}
+	defer func() { p.closer = saved }()
+
+	var nodes []*mathNode
+	for {
+		tok := p.peek()
+		if p.isStop(tok) || (stopAtRelation && len(nodes) > 0 && latexRelations[tok]) {
+			return nodes, tok, nil
+		}
+		atom, err := p.parseAtom()
+		if err != nil {
+			return nil, "", err
+		}
+		if len(atom) == 1 && (atom[0].kind == mathNary || atom[0].kind == mathFunc) {
+			nodes = append(nodes, atom...)
+			continue
+		}
+		scripted, err := p.parseScripts(atom)
+		if err != nil {
+			return nil, "", err
+		}
+		nodes = append(nodes, scripted...)
+	}
+}
+
+// parseScripts attaches any following subscript, superscript or primes to
+// base
+func (p *latexParser) parseScripts(base []*mathNode) ([]*mathNode, error) {
+	var sub, sup []*mathNode
+	hasSub, hasSup := false, false
+	for {
+		switch tok := p.peek(); tok {
+		case "_", "^":
+			p.next()
+			if (tok == "_" && hasSub) || (tok == "^" && hasSup) {
+				return nil, fmt.Errorf("double %s", tok)
+			}
+			arg, err := p.parseArgument()
+			if err != nil {
+				return nil, err
+			}
+			if tok == "_" {
+				sub, hasSub = arg, true
+			} else {
+				sup, hasSup = append(sup, arg...), true
+			}
+			continue
+		case "'":
+			p.next()
+			if hasSup {
+				return nil, fmt.Errorf("prime after superscript")
+			}
+			if len(sup) > 0 {
+				sup[0].text += "′"
+			} else {
+				sup = []*mathNode{{kind: mathRun, text: "′"}}
+			}
+			continue
+		}
+		break
+	}
+	if len(sup) > 0 {
+		hasSup = true
+	}
+
+	switch {
+	case hasSub && hasSup:
+		return []*mathNode{{kind: mathSubSup, e: base, sub: sub, sup: sup}}, nil
+	case hasSub:
+		return []*mathNode{{kind: mathSub, e: base, sub: sub}}, nil
+	case hasSup:
+		return []*mathNode{{kind: mathSup, e: base, sup: sup}}, nil
+	}
+	return base, nil
+}
+
+// parseArgument parses a command argument or script: a braced group, a
+// command or a single character
+func (p *latexParser) parseArgument() ([]*mathNode, error) {
+	switch tok := p.peek(); {
+	case tok == "{":
+		return p.parseAtom()
+	case p.isStop(tok) || tok == "_" || tok == "^":
+		if tok == "" {
+			return nil, fmt.Errorf("missing argument at end of equation")
+		}
+		return nil, fmt.Errorf("missing argument before %s", tok)
+	case strings.HasPrefix(tok, `\`):
+		return p.parseAtom()
+	default:
+		p.next()
+		return []*mathNode{{kind: mathRun, text: tok}}, nil
+	}
+}
+
+// parseGroupText reads the raw text of a braced argument, as for \text
+func (p *latexParser) parseGroupText() (string, error) {
+	if p.next() != "{" {
+		return "", fmt.Errorf("expected {")
+	}
+	depth, start := 1, p.pos
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				text := string(p.src[start:p.pos])
+				p.pos++
+				return text, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("missing }")
+}
+
+// parseAtom parses one element: a group, a command, a parenthesised
+// expression, a number or a character
+func (p *latexParser) parseAtom() ([]*mathNode, error) {
+	p.peek() // skip white space
+	start := p.pos
+	tok := p.next()
+	switch {
+	case tok == "{":
+		nodes, stop, err := p.parseSequence("", false)
+		if err != nil {
+			return nil, err
+		}
+		if stop != "}" {
+			return nil, fmt.Errorf("missing }")
+		}
+		p.next()
+		if nodes == nil {
+			nodes = []*mathNode{}
+		}
+		return nodes, nil
+	case tok == "_" || tok == "^":
+		// A script without a base, as in ^{14}C
+		p.pos = start
+		return nil, nil
+	case tok == "(" || tok == "[":
+		closer := map[string]string{"(": ")", "[": "]"}[tok]
+		nodes, stop, err := p.parseSequence(closer, false)
+		if err != nil {
+			return nil, err
+		}
+		if stop != closer {
+			// Unbalanced: keep the bracket as a character.
+			p.pos = start + 1
+			return []*mathNode{{kind: mathRun, text: tok}}, nil
+		}
+		p.next()
+		return []*mathNode{{kind: mathDelim, text: tok, end: closer, e: nodes}}, nil
+	case tok == "~":
+		return []*mathNode{{kind: mathRun, text: " "}}, nil
+	case strings.HasPrefix(tok, `\`) && len(tok) > 1:
+		return p.parseCommand(tok[1:])
+	case tok >= "0" && tok <= "9" || tok == ".":
+		// Keep numbers such as 2.5 in one run.
+		for p.pos < len(p.src) && (unicode.IsDigit(p.src[p.pos]) || (p.src[p.pos] == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1]))) {
+			p.pos++
+		}
+		return []*mathNode{{kind: mathRun, text: string(p.src[start:p.pos])}}, nil
+	}
+	return []*mathNode{{kind: mathRun, text: tok}}, nil
+}
+
+// parseCommand parses the command `\name`, whose token was consumed
+func (p *latexParser) parseCommand(name string) ([]*mathNode, error) {
+	if sym, ok := latexSymbols[name]; ok {
+		if sym == "" {
+			return nil, nil
+		}
+		return []*mathNode{{kind: mathRun, text: sym}}, nil
+	}
+	if nary, ok := latexNary[name]; ok {
+		return p.parseNary(nary.chr, nary.under)
+	}
+	if limits, ok := latexFunctions[name]; ok {
+		return p.parseFunction([]*mathNode{{kind: mathRun, text: name, style: "p"}}, limits)
+	}
+	if chr, ok := latexAccents[name]; ok {
+		arg, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		return []*mathNode{{kind: mathAccent, text: chr, e: arg}}, nil
+	}
+	if style, ok := latexStyles[name]; ok {
+		arg, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		setMathStyle(arg, style)
+		return arg, nil
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac", "binom":
+		num, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		den, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		if name == "binom" {
+			frac := &mathNode{kind: mathFrac, num: num, den: den, flag: true}
+			return []*mathNode{{kind: mathDelim, text: "(", end: ")", e: []*mathNode{frac}}}, nil
+		}
+		return []*mathNode{{kind: mathFrac, num: num, den: den}}, nil
+	case "sqrt":
+		var deg []*mathNode
+		if p.peek() == "[" {
+			p.next()
+			var stop string
+			var err error
+			if deg, stop, err = p.parseSequence("]", false); err != nil {
+				return nil, err
+			}
+			if stop != "]" {
+				return nil, fmt.Errorf(`missing ] after \sqrt[`)
+			}
+			p.next()
+		}
+		arg, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		return []*mathNode{{kind: mathRad, deg: deg, e: arg}}, nil
+	case "overline", "underline":
+		arg, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		return []*mathNode{{kind: mathBar, e: arg, flag: name == "underline"}}, nil
+	case "text", "textrm", "mbox", "textit", "textbf":
+		text, err := p.parseGroupText()
+		if err != nil {
+			return nil, fmt.Errorf(`\%s: %w`, name, err)
+		}
+		return []*mathNode{{kind: mathRun, text: text, style: "nor"}}, nil
+	case "operatorname":
+		text, err := p.parseGroupText()
+		if err != nil {
+			return nil, fmt.Errorf(`\operatorname: %w`, err)
+		}
+		return p.parseFunction([]*mathNode{{kind: mathRun, text: text, style: "p"}}, false)
+	case "left":
+		return p.parseLeftRight()
+	case "begin":
+		return p.parseEnvironment()
+	case "limits", "nolimits", "displaystyle", "textstyle":
+		return nil, nil
+	}
+	return nil, fmt.Errorf(`unsupported LaTeX command \%s`, name)
+}
+
+// parseNary parses the limits and operand of a sum, product or integral
+func (p *latexParser) parseNary(chr string, under bool) ([]*mathNode, error) {
+	node := &mathNode{kind: mathNary, text: chr, flag: under}
+	for {
+		tok := p.peek()
+		if tok == `\limits` || tok == `\nolimits` {
+			p.next()
+			node.flag = tok == `\limits`
+			continue
+		}
+		if tok != "_" && tok != "^" {
+			break
+		}
+		p.next()
+		arg, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		if tok == "_" {
+			node.sub, node.hasSub = arg, true
+		} else {
+			node.sup, node.hasSup = arg, true
+		}
+	}
+	body, _, err := p.parseSequence("", true)
+	if err != nil {
+		return nil, err
+	}
+	node.e = body
+	return []*mathNode{node}, nil
+}
+
+// parseFunction parses the scripts and argument of a function such as \sin
+// or \lim. Limit-like functions take the rest of the expression up to a
+// relation as their argument; others take the next element.
+func (p *latexParser) parseFunction(name []*mathNode, limits bool) ([]*mathNode, error) {
+	if limits && p.peek() == "_" {
+		p.next()
+		lim, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		name = []*mathNode{{kind: mathLimLow, e: name, lim: lim}}
+	}
+	name, err := p.parseScripts(name)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &mathNode{kind: mathFunc, lim: name}
+	if limits {
+		node.e, _, err = p.parseSequence("", true)
+		if err != nil {
+			return nil, err
+		}
+	} else if tok := p.peek(); !p.isStop(tok) && !latexRelations[tok] {
+		atom, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		if node.e, err = p.parseScripts(atom); err != nil {
+			return nil, err
+		}
+	}
+	return []*mathNode{node}, nil
+}
+
+// parseLeftRight parses \left( ... \right) after its \left token
+func (p *latexParser) parseLeftRight() ([]*mathNode, error) {
+	open, ok := latexDelimiters[p.next()]
+	if !ok {
+		return nil, fmt.Errorf(`unsupported delimiter after \left`)
+	}
+	saved := p.closer
+	p.closer = ""
+	body, stop, err := p.parseSequence("", false)
+	p.closer = saved
+	if err != nil {
+		return nil, err
+	}
+	if stop != `\right` {
+		return nil, fmt.Errorf(`\left without \right`)
+	}
+	p.next()
+	closeChr, ok := latexDelimiters[p.next()]
+	if !ok {
+		return nil, fmt.Errorf(`unsupported delimiter after \right`)
+	}
+	return []*mathNode{{kind: mathDelim, text: open, end: closeChr, e: body}}, nil
+}
+
+// parseEnvironment parses a matrix environment after its \begin token
+func (p *latexParser) parseEnvironment() ([]*mathNode, error) {
+	env, err := p.parseGroupText()
+	if err != nil {
+		return nil, fmt.Errorf(`\begin: %w`, err)
+	}
+	delims, ok := latexMatrices[env]
+	if !ok {
+		return nil, fmt.Errorf("unsupported environment %s", env)
+	}
+
+	saved := p.closer
+	p.closer = ""
+	defer func() { p.closer = saved }()
+
+	var rows [][][]*mathNode
+	var row [][]*mathNode
+	for {
+		cell, stop, err := p.parseSequence("", false)
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, cell)
+		switch stop {
+		case "&":
+			p.next()
+			continue
+		case `\\`:
+			p.next()
+			rows = append(rows, row)
+			row = nil
+			continue
+		case `\end`:
+			p.next()
+			name, err := p.parseGroupText()
+			if err != nil || name != env {
+				return nil, fmt.Errorf(`\begin{%s} ended by \end{%s}`, env, name)
+			}
+		default:
+			return nil, fmt.Errorf(`missing \end{%s}`, env)
+		}
+		break
+	}
+	// A trailing \\ leaves an empty last row.
+	if len(row) > 1 || len(row[0]) > 0 {
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s has no rows", env)
+	}
+
+	matrix := &mathNode{kind: mathMatrix, rows: rows, flag: env == "cases"}
+	if delims[0] == "" && delims[1] == "" {
+		return []*mathNode{matrix}, nil
+	}
+	return []*mathNode{{kind: mathDelim, text: delims[0], end: delims[1], e: []*mathNode{matrix}}}, nil
+}
+
+// setMathStyle applies a font style to the runs of nodes
+func setMathStyle(nodes []*mathNode, style string) {
+	for _, n := range nodes {
+		if n.kind == mathRun && n.style != "nor" {
+			n.style = style
+		}
+		for _, children := range [][]*mathNode{n.e, n.num, n.den, n.sub, n.sup, n.deg, n.lim} {
+			setMathStyle(children, style)
+		}
+		for _, row := range n.rows {
+			for _, cell := range row {
+				setMathStyle(cell, style)
+			}
+		}
+	}
+}
+
+// writeOMathXML writes nodes as an m:oMath element. run supplies the
+// character formatting of the math runs.
+func writeOMathXML(buf *bytes.Buffer, nodes []*mathNode, run RunOptions) {
+	run.FontName = "Cambria Math"
+	var rPr bytes.Buffer
+	writeRunPropertiesXML(&rPr, run)
+
+	buf.WriteString("<m:oMath>")
+	writeMathNodesXML(buf, nodes, rPr.Bytes())
+	buf.WriteString("</m:oMath>")
+}
+
+// writeMathNodesXML writes nodes, merging adjacent runs of the same style
+func writeMathNodesXML(buf *bytes.Buffer, nodes []*mathNode, rPr []byte) {
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		if n.kind != mathRun {
+			writeMathNodeXML(buf, n, rPr)
+			continue
+		}
+		text := n.text
+		for i+1 < len(nodes) && nodes[i+1].kind == mathRun && nodes[i+1].style == n.style {
+			i++
+			text += nodes[i].text
+		}
+		buf.WriteString("<m:r>")
+		switch n.style {
+		case "":
+		case "nor":
+			buf.WriteString("<m:rPr><m:nor/></m:rPr>")
+		default:
+			fmt.Fprintf(buf, `<m:rPr><m:sty m:val="%s"/></m:rPr>`, n.style)
+		}
+		buf.Write(rPr)
+		if strings.TrimSpace(text) != text {
+			fmt.Fprintf(buf, `<m:t xml:space="preserve">%s</m:t>`, xmlEscape(text))
+		} else {
+			fmt.Fprintf(buf, "<m:t>%s</m:t>", xmlEscape(text))
+		}
+		buf.WriteString("</m:r>")
+	}
+}
+
+// writeMathArgXML writes nodes wrapped in the element tag, such as m:e
+func writeMathArgXML(buf *bytes.Buffer, tag string, nodes []*mathNode, rPr []byte) {
+	if len(nodes) == 0 {
+		fmt.Fprintf(buf, "<m:%s/>", tag)
+		return
+	}
+	fmt.Fprintf(buf, "<m:%s>", tag)
+	writeMathNodesXML(buf, nodes, rPr)
+	fmt.Fprintf(buf, "</m:%s>", tag)
+}
+
+// writeMathNodeXML writes one Office Math element other than a run
+func writeMathNodeXML(buf *bytes.Buffer, n *mathNode, rPr []byte) {
+	switch n.kind {
+	case mathFrac:
+		buf.WriteString("<m:f>")
+		if n.flag {
+			buf.WriteString(`<m:fPr><m:type m:val="noBar"/></m:fPr>`)
+		}
+		writeMathArgXML(buf, "num", n.num, rPr)
+		writeMathArgXML(buf, "den", n.den, rPr)
+		buf.WriteString("</m:f>")
+	case mathSub, mathSup, mathSubSup:
+		tag := map[mathKind]string{mathSub: "sSub", mathSup: "sSup", mathSubSup: "sSubSup"}[n.kind]
+		fmt.Fprintf(buf, "<m:%s>", tag)
+		writeMathArgXML(buf, "e", n.e, rPr)
+		if n.kind != mathSup {
+			writeMathArgXML(buf, "sub", n.sub, rPr)
+		}
+		if n.kind != mathSub {
+			writeMathArgXML(buf, "sup", n.sup, rPr)
+		}
+		fmt.Fprintf(buf, "</m:%s>", tag)
+	case mathRad:
+		buf.WriteString("<m:rad>")
+		if len(n.deg) == 0 {
+			buf.WriteString(`<m:radPr><m:degHide m:val="1"/></m:radPr>`)
+		}
+		writeMathArgXML(buf, "deg", n.deg, rPr)
+		writeMathArgXML(buf, "e", n.e, rPr)
+		buf.WriteString("</m:rad>")
+	case mathNary:
+		limLoc := "subSup"
+		if n.flag {
+			limLoc = "undOvr"
+		}
+		fmt.Fprintf(buf, `<m:nary><m:naryPr><m:chr m:val="%s"/><m:limLoc m:val="%s"/>`, n.text, limLoc)
+		if !n.hasSub {
+			buf.WriteString(`<m:subHide m:val="1"/>`)
+		}
+		if !n.hasSup {
+			buf.WriteString(`<m:supHide m:val="1"/>`)
+		}
+		buf.WriteString("</m:naryPr>")
+		writeMathArgXML(buf, "sub", n.sub, rPr)
+		writeMathArgXML(buf, "sup", n.sup, rPr)
+		writeMathArgXML(buf, "e", n.e, rPr)
+		buf.WriteString("</m:nary>")
+	case mathDelim:
+		fmt.Fprintf(buf, `<m:d><m:dPr><m:begChr m:val="%s"/><m:endChr m:val="%s"/></m:dPr>`, xmlEscape(n.text), xmlEscape(n.end))
+		writeMathArgXML(buf, "e", n.e, rPr)
+		buf.WriteString("</m:d>")
+	case mathMatrix:
+		cols := 0
+		for _, row := range n.rows {
+			cols = max(cols, len(row))
+		}
+		jc := "center"
+		if n.flag {
+			jc = "left"
+		}
+		fmt.Fprintf(buf, `<m:m><m:mPr><m:mcs><m:mc><m:mcPr><m:count m:val="%d"/><m:mcJc m:val="%s"/></m:mcPr></m:mc></m:mcs></m:mPr>`, cols, jc)
+		for _, row := range n.rows {
+			buf.WriteString("<m:mr>")
+			for c := 0; c < cols; c++ {
+				var cell []*mathNode
+				if c < len(row) {
+					cell = row[c]
+				}
+				writeMathArgXML(buf, "e", cell, rPr)
+			}
+			buf.WriteString("</m:mr>")
+		}
+		buf.WriteString("</m:m>")
+	case mathFunc:
+		buf.WriteString("<m:func>")
+		writeMathArgXML(buf, "fName", n.lim, rPr)
+		writeMathArgXML(buf, "e", n.e, rPr)
+		buf.WriteString("</m:func>")
+	case mathLimLow:
+		buf.WriteString("<m:limLow>")
+		writeMathArgXML(buf, "e", n.e, rPr)
+		writeMathArgXML(buf, "lim", n.lim, rPr)
+		buf.WriteString("</m:limLow>")
+	case mathAccent:
+		fmt.Fprintf(buf, `<m:acc><m:accPr><m:chr m:val="%s"/></m:accPr>`, n.text)
+		writeMathArgXML(buf, "e", n.e, rPr)
+		buf.WriteString("</m:acc>")
+	case mathBar:
+		pos := "top"
+		if n.flag {
+			pos = "bot"
+		}
+		fmt.Fprintf(buf, `<m:bar><m:barPr><m:pos m:val="%s"/></m:barPr>`, pos)
+		writeMathArgXML(buf, "e", n.e, rPr)
+		buf.WriteString("</m:bar>")
+	}
+}
@@ -0,0 +1,232 @@
+package godocx
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseLaTeX_Linear(t *testing.T) {
+	tests := []struct {
+		latex string
+		want  string
+	}{
+		{`x = \frac{-b \pm \sqrt{b^2 - 4ac}}{2a}`, "x=(-b±√(b^2-4ac))/(2a)"},
+		{`$E = mc^2$`, "E=mc^2"},
+		{`a_{i,j}^{2} + x_1'`, "a_(i,j)^2+x_1^′"},
+		{`\sqrt[3]{x+1}`, "√(3&x+1)"},
+		{`\sum_{i=1}^{n} i^2 = \frac{n(n+1)(2n+1)}{6}`, "∑_(i=1)^n▒(i^2)=(n(n+1)(2n+1))/6"},
+		{`\int_0^\infty e^{-x} \, dx`, "∫_0^∞▒(e^(-x)\u2009dx)"},
+		{`\left[ \begin{matrix} a & b \\ c & d \end{matrix} \right]`, "[■(a&b@c&d)]"},
+		{`\begin{pmatrix} 1 & 0 \\ 0 & 1 \\ \end{pmatrix}`, "(■(1&0@0&1))"},
+		{`|x| = \begin{cases} x & x \geq 0 \\ -x & x < 0 \end{cases}`, "|x|={■(x&x≥0@-x&x<0)"},
+		{`\lim_{x \to 0} \frac{\sin x}{x} = 1`, "lim┬(x→0) (sin x)/x=1"},
+		{`\alpha\beta + \Omega \leq \infty`, "αβ+Ω≤∞"},
+		{`\binom{n}{k}`, "(n¦k)"},
+		{`\hat{x} + \vec{v} + \overline{AB}`, "x̂+v⃗+¯AB"},
+		{`F = \text{ma and more}`, "F=ma and more"},
+		{`\mathbf{F}(t) = \sin^2(\omega t)`, "F(t)=sin^2 (ωt)"},
+		{`^{14}C`, "^14C"},
+	}
+	for _, tt := range tests {
+		nodes, err := parseLaTeX(tt.latex)
+		if err != nil {
+			t.Errorf("parseLaTeX(%q): %v", tt.latex, err)
+			continue
+		}
+		var buf bytes.Buffer
+		buf.WriteString(`<w:p xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:m="` + MathNS + `">`)
+		writeOMathXML(&buf, nodes, RunOptions{})
+		buf.WriteString("</w:p>")
+		equations, err := readEquations(buf.Bytes())
+		if err != nil || len(equations) != 1 {
+			t.Errorf("readEquations(%q): %v, %d equations", tt.latex, err, len(equations))
+			continue
+		}
+		if equations[0].Text != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.latex, equations[0].Text, tt.want)
+		}
+	}
+}
+
+func TestParseLaTeX_Errors(t *testing.T) {
+	for _, latex := range []string{
+		``,
+		`\frac{a}`,
+		`x^{2`,
+		`a}`,
+		`\left( x`,
+		`x \right)`,
+		`\begin{pmatrix} a & b \end{bmatrix}`,
+		`\foo{x}`,
+		`x^2^3`,
+		`a & b`,
+	} {
+		if _, err := parseLaTeX(latex); err == nil {
+			t.Errorf("parseLaTeX(%q): expected an error", latex)
+		}
+	}
+}
+
+func TestInsertEquation(t *testing.T) {
+	body := `<w:p><w:r><w:t>The energy is given by Einstein.</w:t></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.InsertEquation(`\sum_{k=0}^{n} \binom{n}{k} = 2^n`, EquationOptions{Position: PositionEnd}); err != nil {
+		t.Fatalf("InsertEquation display: %v", err)
+	}
+	if err := u.InsertEquation(`E = mc^2`, EquationOptions{Inline: true, Anchor: "given by"}); err != nil {
+		t.Fatalf("InsertEquation inline: %v", err)
+	}
+	if err := u.InsertParagraph(ParagraphOptions{
+		Runs: []RunOptions{
+			{Text: "where "},
+			{Equation: `\alpha \in [0, 1]`, Bold: true},
+			{Text: " is a weight."},
+		},
+		Position: PositionEnd,
+	}); err != nil {
+		t.Fatalf("InsertParagraph with equation run: %v", err)
+	}
+
+	if err := u.InsertEquation(`\frac{1}{`, EquationOptions{}); err == nil {
+		t.Error("expected a validation error for malformed LaTeX")
+	}
+	if err := u.InsertEquation(`x`, EquationOptions{Inline: true}); err == nil {
+		t.Error("expected a validation error for an inline equation without anchor")
+	}
+	if err := u.InsertParagraph(ParagraphOptions{Runs: []RunOptions{{Equation: `\unknown`}}}); err == nil {
+		t.Error("expected a validation error for an unsupported command in a run")
+	}
+
+	doc := readDocXML(t, u)
+	for _, want := range []string{
+		`xmlns:m="` + MathNS + `"`,
+		`<w:p><m:oMathPara><m:oMathParaPr><m:jc m:val="center"/></m:oMathParaPr><m:oMath><m:nary><m:naryPr><m:chr m:val="∑"/><m:limLoc m:val="undOvr"/></m:naryPr>`,
+		`<m:fPr><m:type m:val="noBar"/></m:fPr>`,
+		`<w:t xml:space="preserve">The energy is given by</w:t></w:r><m:oMath>`,
+		`<m:r><w:rPr><w:rFonts w:ascii="Cambria Math" w:hAnsi="Cambria Math"/><w:b/></w:rPr><m:t>α∈</m:t></m:r>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %s in document, got %s", want, doc)
+		}
+	}
+
+	equations, err := u.GetEquations()
+	if err != nil {
+		t.Fatalf("GetEquations: %v", err)
+	}
+	want := []Equation{
+		{Text: "E=mc^2"},
+		{Text: "∑_(k=0)^n▒(n¦k)=2^n", Display: true},
+		{Text: "α∈[0,1]"},
+	}
+	if len(equations) != len(want) {
+		t.Fatalf("expected %d equations, got %+v", len(want), equations)
+	}
+	for i := range want {
+		if equations[i] != want[i] {
+			t.Errorf("equation %d: got %+v, want %+v", i+1, equations[i], want[i])
+		}
+	}
+}
+
+func TestEquationRuns_DeclareMathNamespaceInParts(t *testing.T) {
+	body := `<w:p><w:r><w:t>First claim.</w:t></w:r></w:p><w:sectPr/>`
+	equation := []RunOptions{{Text: "where "}, {Equation: `x^2`}}
+
+	tests := []struct {
+		name   string
+		part   string
+		insert func(u *Updater) error
+	}{
+		{"header", "header3.xml", func(u *Updater) error {
+			return u.SetHeader(HeaderFooterContent{Blocks: []HeaderFooterBlock{
+				{Paragraph: &ParagraphOptions{Runs: equation}},
+			}}, DefaultHeaderOptions())
+		}},
+		{"footer", "footer3.xml", func(u *Updater) error {
+			return u.SetFooter(HeaderFooterContent{Blocks: []HeaderFooterBlock{
+				{Paragraph: &ParagraphOptions{Runs: equation}},
+			}}, DefaultFooterOptions())
+		}},
+		{"footnote", "footnotes.xml", func(u *Updater) error {
+			return u.InsertFootnote(FootnoteOptions{Anchor: "First claim", Paragraphs: [][]RunOptions{equation}})
+		}},
+		{"endnote", "endnotes.xml", func(u *Updater) error {
+			return u.InsertEndnote(EndnoteOptions{Anchor: "First claim", Paragraphs: [][]RunOptions{equation}})
+		}},
+		{"comment", "comments.xml", func(u *Updater) error {
+			return u.InsertComment(CommentOptions{Anchor: "First claim", Paragraphs: [][]RunOptions{equation}})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+			if err := tt.insert(u); err != nil {
+				t.Fatalf("insert: %v", err)
+			}
+			part := readWordPart(t, u, tt.part)
+			if !strings.Contains(part, "<m:oMath>") {
+				t.Fatalf("expected an equation in %s, got %s", tt.part, part)
+			}
+			root := partRootPattern.FindStringIndex(part)
+			if root == nil {
+				t.Fatalf("no root element in %s", part)
+			}
+			rootTag := part[root[0] : root[0]+strings.Index(part[root[0]:], ">")]
+			if !strings.Contains(rootTag, `xmlns:m="`+MathNS+`"`) {
+				t.Errorf("expected the math namespace on the root of %s, got %s", tt.part, rootTag)
+			}
+		})
+	}
+}
+
+func TestEquationRuns_RejectMalformedLaTeXInParts(t *testing.T) {
+	body := `<w:p><w:r><w:t>First claim.</w:t></w:r></w:p><w:sectPr/>`
+	bad := []RunOptions{{Text: "where "}, {Equation: `\frac{1}{`}}
+
+	tests := []struct {
+		name   string
+		insert func(u *Updater) error
+	}{
+		{"header", func(u *Updater) error {
+			return u.SetHeader(HeaderFooterContent{Blocks: []HeaderFooterBlock{
+				{Paragraph: &ParagraphOptions{Runs: bad}},
+			}}, DefaultHeaderOptions())
+		}},
+		{"footnote", func(u *Updater) error {
+			return u.InsertFootnote(FootnoteOptions{Anchor: "First claim", Paragraphs: [][]RunOptions{bad}})
+		}},
+		{"endnote", func(u *Updater) error {
+			return u.InsertEndnote(EndnoteOptions{Anchor: "First claim", Paragraphs: [][]RunOptions{{{Text: "ok"}}, bad}})
+		}},
+		{"update footnote", func(u *Updater) error {
+			return u.UpdateFootnote(1, FootnoteOptions{Paragraphs: [][]RunOptions{bad}})
+		}},
+		{"footnote separator", func(u *Updater) error {
+			return u.SetFootnoteSeparators(NoteSeparatorOptions{ContinuationNotice: bad})
+		}},
+		{"comment", func(u *Updater) error {
+			return u.InsertComment(CommentOptions{Anchor: "First claim", Paragraphs: [][]RunOptions{bad}})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+			err := tt.insert(u)
+			var docxErr *DocxError
+			if !errors.As(err, &docxErr) || docxErr.Code != ErrCodeValidation {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "run 2") {
+				t.Errorf("expected the error to name the equation run, got %v", err)
+			}
+			if strings.Contains(readDocXML(t, u), "w:footnoteReference") {
+				t.Error("a rejected note must not leave a reference in the document")
+			}
+		})
+	}
+}
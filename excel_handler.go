@@ -168,6 +168,9 @@ func updateWorksheetXML(existing []byte, data ChartData, useSharedStrings bool,
 	updated = reSheetData.ReplaceAllString(updated, newSheetData)
 
 	lastCol := columnLetters(len(data.Series) + 1)
+	if hasBubbleSizes(data) {
+		lastCol = columnLetters(bubbleSizeColumn(len(data.Series)-1, len(data.Series), 2))
+	}
 	lastRow := len(data.Categories) + 1
 	newDimension := `<dimension ref="A1:` + lastCol + strconv.Itoa(lastRow) + `"/>`
 
@@ -189,6 +192,13 @@ func buildSheetDataXML(data ChartData, useSharedStrings bool, stringIndexes map[
 	for i, s := range data.Series {
 		b.WriteString(stringCell(cellRef(i+2, 1), s.Name, useSharedStrings, stringIndexes))
 	}
+	bubbles := hasBubbleSizes(data)
+	if bubbles {
+		// Bubble sizes follow the value columns of all series.
+		for i, s := range data.Series {
+			b.WriteString(stringCell(cellRef(bubbleSizeColumn(i, len(data.Series), 2), 1), bubbleSizeHeader(s.Name), useSharedStrings, stringIndexes))
+		}
+	}
 	b.WriteString(`</row>`)
 
 	for rowIdx, cat := range data.Categories {
@@ -202,6 +212,15 @@ func buildSheetDataXML(data ChartData, useSharedStrings bool, stringIndexes map[
 			}
 			b.WriteString(numberCell(cellRef(sIdx+2, r), value))
 		}
+		if bubbles {
+			for sIdx, s := range data.Series {
+				size := 0.0
+				if rowIdx < len(s.BubbleSizes) {
+					size = s.BubbleSizes[rowIdx]
+				}
+				b.WriteString(numberCell(cellRef(bubbleSizeColumn(sIdx, len(data.Series), 2), r), size))
+			}
+		}
 		b.WriteString(`</row>`)
 	}
 
@@ -209,6 +228,16 @@ func buildSheetDataXML(data ChartData, useSharedStrings bool, stringIndexes map[
 	return b.String()
 }
 
+// hasBubbleSizes reports whether any series carries bubble sizes.
+func hasBubbleSizes(data ChartData) bool {
+	for _, s := range data.Series {
+		if len(s.BubbleSizes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func stringCell(ref, value string, useSharedStrings bool, stringIndexes map[string]int) string {
 	if useSharedStrings {
 		if idx, ok := stringIndexes[value]; ok {
@@ -273,6 +302,11 @@ func updateSharedStringsXML(existing []byte, data ChartData) ([]byte, map[string
 	for _, s := range data.Series {
 		appendIfMissing(s.Name)
 	}
+	if hasBubbleSizes(data) {
+		for _, s := range data.Series {
+			appendIfMissing(bubbleSizeHeader(s.Name))
+		}
+	}
 	for _, c := range data.Categories {
 		appendIfMissing(c)
 	}
@@ -0,0 +1,285 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field is a Word field placed in a run, such as a page number, date or
+// cross-reference. Set RunOptions.Field to use it in any paragraph; the run's
+// character formatting applies to the whole field.
+type Field struct {
+	// Instruction is the field type and its arguments, e.g. "PAGE" or `REF intro`
+	Instruction string
+
+	// Parts follow Instruction in the field code. A part is literal text or a
+	// nested field, as in { IF { PAGE } = { NUMPAGES } "Last" "" }.
+	Parts []FieldPart
+
+	// Switches are appended to the field code, e.g. `\* ROMAN` or `\h`
+	Switches []string
+
+	// Result is the cached result shown until Word updates the field
+	Result string
+
+	// Dirty asks Word to recalculate the field when the document opens
+	Dirty bool
+}
+
+// FieldPart is literal text or a nested field inside a field code
+type FieldPart struct {
+	Text  string
+	Field *Field
+}
+
+// PageField returns a field showing the current page number
+func PageField() *Field {
+	return &Field{Instruction: "PAGE", Result: "1"}
+}
+
+// NumPagesField returns a field showing the number of pages in the document
+func NumPagesField() *Field {
+	return &Field{Instruction: "NUMPAGES", Result: "1"}
+}
+
+// SectionPagesField returns a field showing the number of pages in the current section
+func SectionPagesField() *Field {
+	return &Field{Instruction: "SECTIONPAGES", Result: "1"}
+}
+
+// DateField returns a field showing the current date. picture is a Word
+// date-time picture such as "d MMMM yyyy"; empty uses Word's default format.
+func DateField(picture string) *Field {
+	return dateTimeField("DATE", picture, "M/d/yyyy")
+}
+
+// TimeField returns a field showing the current time. picture is a Word
+// date-time picture such as "HH:mm"; empty uses Word's default format.
+func TimeField(picture string) *Field {
+	return dateTimeField("TIME", picture, "h:mm am/pm")
+}
+
+// dateTimeField builds a DATE or TIME field whose cached result is the
+// current time formatted with the picture.
+func dateTimeField(instruction, picture, defaultPicture string) *Field {
+	field := &Field{Instruction: instruction, Dirty: true}
+	if picture != "" {
+		field.Switches = []string{fmt.Sprintf(`\@ "%s"`, picture)}
+	} else {
+		picture = defaultPicture
+	}
+	field.Result = formatFieldDateTime(time.Now(), picture)
+	return field
+}
+
+// DocumentPropertyField returns a field showing a built-in or custom document
+// property such as "Company" or "Title". Quotes and backslashes in the name
+// are escaped.
+func DocumentPropertyField(name string) *Field {
+	return &Field{Instruction: fmt.Sprintf(`DOCPROPERTY "%s"`, fieldQuoteEscaper.Replace(name)), Result: name, Dirty: true}
+}
+
+// fieldQuoteEscaper escapes text placed inside a quoted field argument
+var fieldQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// RefField returns a field showing the text of a bookmark, linked to it
+func RefField(bookmark string) *Field {
+	return &Field{Instruction: "REF " + bookmark, Switches: []string{`\h`}, Result: bookmark, Dirty: true}
+}
+
+// PageRefField returns a field showing the page number of a bookmark, linked to it
+func PageRefField(bookmark string) *Field {
+	return &Field{Instruction: "PAGEREF " + bookmark, Switches: []string{`\h`}, Result: "1", Dirty: true}
+}
+
+// FileNameField returns a field showing the document's file name, with its
+// full path when withPath is true
+func FileNameField(withPath bool) *Field {
+	field := &Field{Instruction: "FILENAME", Dirty: true}
+	if withPath {
+		field.Switches = []string{`\p`}
+	}
+	return field
+}
+
+// AuthorField returns a field showing the document author
+func AuthorField() *Field {
+	return &Field{Instruction: "AUTHOR", Dirty: true}
+}
+
+// IfField returns a field that compares two operands and shows trueText or
+// falseText. Operands are literal text (quote strings yourself) or nested
+// fields, e.g. IfField(FieldPart{Field: PageField()}, "=", FieldPart{Field: NumPagesField()}, "End", "").
+func IfField(left FieldPart, operator string, right FieldPart, trueText, falseText string) *Field {
+	return &Field{
+		Instruction: "IF",
+		Parts: []FieldPart{
+			left,
+			{Text: " " + operator + " "},
+			right,
+			{Text: fmt.Sprintf(` "%s" "%s"`, strings.ReplaceAll(trueText, `"`, `\"`), strings.ReplaceAll(falseText, `"`, `\"`))},
+		},
+		Dirty: true,
+	}
+}
+
+// validateField checks that a field has an instruction and that its nested
+// fields are valid
+func validateField(field *Field) error {
+	if strings.TrimSpace(field.Instruction) == "" {
+		return NewValidationError("Field", "field instruction cannot be empty")
+	}
+	for _, part := range field.Parts {
+		if part.Field != nil {
+			if err := validateField(part.Field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeComplexFieldXML emits a complex field (OOXML §17.16.18) as the run
+// sequence begin → instrText → separate → cached result → end. The begin
+// character is marked dirty so Word recalculates the field on open, while the
+// cached result keeps renderers that never update fields readable. Every run
+// carries the character formatting of format.
+func writeComplexFieldXML(buf *bytes.Buffer, instruction, result string, format RunOptions) {
+	writeFieldXML(buf, Field{Instruction: instruction, Result: result, Dirty: true}, format)
+}
+
+// writeFieldXML emits field as a complex field with the character formatting
+// of format. Nested fields are written inside the instruction runs.
+func writeFieldXML(buf *bytes.Buffer, field Field, format RunOptions) {
+	fieldRun := func(inner string) {
+		buf.WriteString("<w:r>")
+		writeRunPropertiesXML(buf, format)
+		buf.WriteString(inner)
+		buf.WriteString("</w:r>")
+	}
+	instrRun := func(text string) {
+		if text != "" {
+			fieldRun(`<w:instrText xml:space="preserve">` + xmlEscape(text) + `</w:instrText>`)
+		}
+	}
+
+	if field.Dirty {
+		fieldRun(`<w:fldChar w:fldCharType="begin" w:dirty="true"/>`)
+	} else {
+		fieldRun(`<w:fldChar w:fldCharType="begin"/>`)
+	}
+	instrRun(" " + strings.TrimSpace(field.Instruction) + " ")
+	for _, part := range field.Parts {
+		if part.Field != nil {
+			writeFieldXML(buf, *part.Field, format)
+			continue
+		}
+		instrRun(part.Text)
+	}
+	if len(field.Switches) > 0 {
+		instrRun(strings.Join(field.Switches, " ") + " ")
+	}
+	fieldRun(`<w:fldChar w:fldCharType="separate"/>`)
+	if field.Result != "" {
+		buf.WriteString("<w:r>")
+		writeRunPropertiesXML(buf, format)
+		writeRunTextWithControls(buf, field.Result)
+		buf.WriteString("</w:r>")
+	}
+	fieldRun(`<w:fldChar w:fldCharType="end"/>`)
+}
+
+// fieldDateTimeTokens maps Word date-time picture tokens to Go layouts,
+// longest first so that "MMMM" wins over "MM". Go has no layout for the
+// unpadded 24-hour clock, so "H" maps to an empty layout that
+// formatFieldDateTime writes itself.
+var fieldDateTimeTokens = []struct{ word, layout string }{
+	{"yyyy", "2006"}, {"yy", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dddd", "Monday"}, {"ddd", "Mon"}, {"dd", "02"}, {"d", "2"},
+	{"HH", "15"}, {"H", ""}, {"hh", "03"}, {"h", "3"},
+	{"mm", "04"}, {"m", "4"}, {"ss", "05"}, {"s", "5"},
+	{"AM/PM", "PM"}, {"am/pm", "pm"},
+}
+
+// formatFieldDateTime formats t with a Word date-time picture, for use as a
+// DATE or TIME field's cached result. Quoted text is copied literally.
+func formatFieldDateTime(t time.Time, picture string) string {
+	var out strings.Builder
+	for i := 0; i < len(picture); {
+		if picture[i] == '\'' {
+			end := strings.IndexByte(picture[i+1:], '\'')
+			if end == -1 {
+				end = len(picture) - i - 1
+			}
+			out.WriteString(picture[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		matched := false
+		for _, tok := range fieldDateTimeTokens {
+			if strings.HasPrefix(picture[i:], tok.word) {
+				if tok.layout == "" {
+					out.WriteString(strconv.Itoa(t.Hour()))
+				} else {
+					out.WriteString(t.Format(tok.layout))
+				}
+				i += len(tok.word)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			out.WriteByte(picture[i])
+			i++
+		}
+	}
+	return out.String()
+}
+
+// fieldTokenPattern matches the parts of complex and simple fields that carry
+// their instructions, in document order.
+var fieldTokenPattern = regexp.MustCompile(`(?s)<w:fldChar\s[^>]*w:fldCharType="(begin|separate|end)"[^>]*>|<w:instrText(?:\s[^>]*)?>(.*?)</w:instrText>|<w:fldSimple\s[^>]*w:instr="([^"]*)"`)
+
+// fieldInstructions returns the trimmed instruction of every field in data.
+// Nested complex fields are reported before the field that contains them.
+func fieldInstructions(data []byte) []string {
+	type openField struct {
+		instr     strings.Builder
+		separated bool
+	}
+	var stack []*openField
+	var instructions []string
+
+	for _, m := range fieldTokenPattern.FindAllSubmatch(data, -1) {
+		switch {
+		case m[1] != nil:
+			switch string(m[1]) {
+			case "begin":
+				stack = append(stack, &openField{})
+			case "separate":
+				if len(stack) > 0 {
+					stack[len(stack)-1].separated = true
+				}
+			case "end":
+				if len(stack) == 0 {
+					continue
+				}
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				instructions = append(instructions, strings.TrimSpace(top.instr.String()))
+			}
+		case m[3] != nil:
+			instructions = append(instructions, strings.TrimSpace(xmlUnescape(string(m[3]))))
+		default:
+			if len(stack) > 0 && !stack[len(stack)-1].separated {
+				stack[len(stack)-1].instr.WriteString(xmlUnescape(string(m[2])))
+			}
+		}
+	}
+	return instructions
+}
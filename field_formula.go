@@ -0,0 +1,612 @@
+package godocx
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// fieldNumberPattern matches the first number in a table cell or bookmark,
+	// with optional thousands separators
+	fieldNumberPattern = regexp.MustCompile(`-?(?:\d[\d,]*(?:\.\d+)?|\.\d+)`)
+
+	// cellReferencePattern matches an A1-style table cell reference
+	cellReferencePattern = regexp.MustCompile(`^([A-Za-z]{1,2})([0-9]+)$`)
+)
+
+// Word's cached results for formulas that cannot be evaluated
+const (
+	formulaSyntaxError = "!Syntax Error"
+	formulaZeroDivide  = "!Zero Divide"
+)
+
+// fieldTableCell is one cell of the table that holds a formula field
+type fieldTableCell struct {
+	col, span int
+	text      string
+}
+
+// fieldTable is the grid of the innermost table around a formula field
+type fieldTable struct {
+	rows [][]fieldTableCell
+}
+
+// formulaContext supplies the values a formula can refer to
+type formulaContext struct {
+	table    *fieldTable
+	row, col int // position of the field's cell, or -1 outside tables
+	bookmark func(name string) (string, bool)
+}
+
+// evaluateFormula evaluates the expression of an = field. The second result
+// is false when the expression is malformed, and the string is Word's error
+// text for the failure.
+func evaluateFormula(expr string, ctx formulaContext) (float64, string, bool) {
+	p := &formulaParser{src: expr, ctx: ctx}
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return 0, formulaSyntaxError, false
+	}
+	v, err := p.parseComparison()
+	if err == nil {
+		p.skipSpace()
+		if p.pos < len(p.src) {
+			err = fmt.Errorf("%s, %s", formulaSyntaxError, p.src[p.pos:])
+		}
+	}
+	if err != nil {
+		return 0, err.Error(), false
+	}
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, formulaZeroDivide, false
+	}
+	return v, "", true
+}
+
+// formatFormulaNumber formats a formula result the way Word does without a
+// numeric picture: whole numbers have no decimals.
+func formatFormulaNumber(v float64) string {
+	v = math.Round(v*1e10) / 1e10
+	if v == 0 {
+		v = 0 // drop the sign of negative zero
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formulaParser is a recursive-descent parser over Word's formula syntax
+type formulaParser struct {
+	src string
+	pos int
+	ctx formulaContext
+}
+
+func (p *formulaParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept consumes tok if it comes next
+func (p *formulaParser) accept(tok string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+func (p *formulaParser) syntaxError() error {
+	if p.pos >= len(p.src) {
+		return fmt.Errorf("%s", formulaSyntaxError)
+	}
+	return fmt.Errorf("%s, %s", formulaSyntaxError, p.src[p.pos:])
+}
+
+func (p *formulaParser) parseComparison() (float64, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return 0, err
+	}
+	for _, op := range []string{"<=", ">=", "<>", "=", "<", ">"} {
+		if !p.accept(op) {
+			continue
+		}
+		right, err := p.parseAdditive()
+		if err != nil {
+			return 0, err
+		}
+		return boolToFloat(compareNumbers(left, op, right)), nil
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseAdditive() (float64, error) {
+	v, err := p.parseTerm()
+	for err == nil {
+		switch {
+		case p.accept("+"):
+			var r float64
+			r, err = p.parseTerm()
+			v += r
+		case p.accept("-"):
+			var r float64
+			r, err = p.parseTerm()
+			v -= r
+		default:
+			return v, nil
+		}
+	}
+	return 0, err
+}
+
+func (p *formulaParser) parseTerm() (float64, error) {
+	v, err := p.parsePower()
+	for err == nil {
+		switch {
+		case p.accept("*"):
+			var r float64
+			r, err = p.parsePower()
+			v *= r
+		case p.accept("/"):
+			var r float64
+			if r, err = p.parsePower(); err == nil && r == 0 {
+				return 0, fmt.Errorf("%s", formulaZeroDivide)
+			}
+			v /= r
+		default:
+			return v, nil
+		}
+	}
+	return 0, err
+}
+
+func (p *formulaParser) parsePower() (float64, error) {
+	v, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	if p.accept("^") {
+		exp, err := p.parsePower()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(v, exp), nil
+	}
+	return v, nil
+}
+
+func (p *formulaParser) parseUnary() (float64, error) {
+	if p.accept("-") {
+		v, err := p.parseUnary()
+		return -v, err
+	}
+	if p.accept("+") {
+		return p.parseUnary()
+	}
+	v, err := p.parsePrimary()
+	if err == nil && p.accept("%") {
+		v /= 100
+	}
+	return v, err
+}
+
+func (p *formulaParser) parsePrimary() (float64, error) {
+	p.skipSpace()
+	if p.accept("(") {
+		v, err := p.parseComparison()
+		if err != nil {
+			return 0, err
+		}
+		if !p.accept(")") {
+			return 0, p.syntaxError()
+		}
+		return v, nil
+	}
+
+	if p.pos < len(p.src) && (p.src[p.pos] == '.' || unicode.IsDigit(rune(p.src[p.pos]))) {
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			p.pos = start
+			return 0, p.syntaxError()
+		}
+		return v, nil
+	}
+
+	name := p.identifier()
+	if name == "" {
+		return 0, p.syntaxError()
+	}
+	if p.accept("(") {
+		return p.parseFunction(strings.ToUpper(name))
+	}
+
+	switch strings.ToUpper(name) {
+	case "TRUE":
+		return 1, nil
+	case "FALSE":
+		return 0, nil
+	}
+	if values, ok := p.ctx.cellRange(name); ok {
+		if len(values) != 1 {
+			return 0, fmt.Errorf("%s, %s", formulaSyntaxError, name)
+		}
+		return values[0], nil
+	}
+	if text, ok := p.ctx.bookmark(name); ok {
+		return parseFieldNumber(text), nil
+	}
+	return 0, fmt.Errorf("!Undefined Bookmark, %s", name)
+}
+
+// identifier reads a function, bookmark or cell name, including the colon of
+// an A1:B2 range
+func (p *formulaParser) identifier() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := rune(p.src[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != ':' {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parseFunction evaluates a function call after its opening parenthesis
+func (p *formulaParser) parseFunction(name string) (float64, error) {
+	var args []float64
+	var counts []int // values contributed by each argument
+	if !p.accept(")") {
+		for {
+			values, err := p.parseArgument()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, values...)
+			counts = append(counts, len(values))
+			if p.accept(",") || p.accept(";") {
+				continue
+			}
+			if !p.accept(")") {
+				return 0, p.syntaxError()
+			}
+			break
+		}
+	}
+
+	want := func(n int) error {
+		if len(counts) != n || len(args) != n {
+			return fmt.Errorf("%s, %s", formulaSyntaxError, name)
+		}
+		return nil
+	}
+
+	switch name {
+	case "SUM":
+		total := 0.0
+		for _, v := range args {
+			total += v
+		}
+		return total, nil
+	case "PRODUCT":
+		if len(args) == 0 {
+			return 0, nil
+		}
+		total := 1.0
+		for _, v := range args {
+			total *= v
+		}
+		return total, nil
+	case "AVERAGE":
+		if len(args) == 0 {
+			return 0, fmt.Errorf("%s", formulaZeroDivide)
+		}
+		total := 0.0
+		for _, v := range args {
+			total += v
+		}
+		return total / float64(len(args)), nil
+	case "COUNT":
+		return float64(len(args)), nil
+	case "MIN", "MAX":
+		if len(args) == 0 {
+			return 0, nil
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			if (name == "MIN") == (a < v) {
+				v = a
+			}
+		}
+		return v, nil
+	case "ABS", "INT", "SIGN", "NOT":
+		if err := want(1); err != nil {
+			return 0, err
+		}
+		switch name {
+		case "ABS":
+			return math.Abs(args[0]), nil
+		case "INT":
+			return math.Trunc(args[0]), nil
+		case "SIGN":
+			if args[0] == 0 {
+				return 0, nil
+			}
+			return math.Copysign(1, args[0]), nil
+		default:
+			return boolToFloat(args[0] == 0), nil
+		}
+	case "ROUND", "MOD", "AND", "OR":
+		if err := want(2); err != nil {
+			return 0, err
+		}
+		a, b := args[0], args[1]
+		switch name {
+		case "ROUND":
+			scale := math.Pow(10, math.Trunc(b))
+			return math.Round(a*scale) / scale, nil
+		case "MOD":
+			if b == 0 {
+				return 0, fmt.Errorf("%s", formulaZeroDivide)
+			}
+			return math.Mod(a, b), nil
+		case "AND":
+			return boolToFloat(a != 0 && b != 0), nil
+		default:
+			return boolToFloat(a != 0 || b != 0), nil
+		}
+	case "IF":
+		if err := want(3); err != nil {
+			return 0, err
+		}
+		if args[0] != 0 {
+			return args[1], nil
+		}
+		return args[2], nil
+	}
+	return 0, fmt.Errorf("%s, %s", formulaSyntaxError, name)
+}
+
+// parseArgument reads one function argument: a table range or an expression
+func (p *formulaParser) parseArgument() ([]float64, error) {
+	save := p.pos
+	name := p.identifier()
+	p.skipSpace()
+	if name != "" && (p.pos == len(p.src) || strings.ContainsRune(",;)", rune(p.src[p.pos]))) {
+		if values, ok := p.ctx.cellRange(name); ok {
+			return values, nil
+		}
+	}
+	p.pos = save
+	v, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	return []float64{v}, nil
+}
+
+// cellRange resolves ABOVE, BELOW, LEFT, RIGHT and A1-style references to
+// the numbers in the referenced cells. It reports false for other names.
+func (ctx formulaContext) cellRange(name string) ([]float64, bool) {
+	t := ctx.table
+	if t == nil {
+		return nil, false
+	}
+
+	// Directional ranges run from the field's cell to the first cell that
+	// holds no number.
+	collect := func(cells []fieldTableCell) []float64 {
+		var values []float64
+		for _, cell := range cells {
+			if !fieldNumberPattern.MatchString(cell.text) {
+				break
+			}
+			values = append(values, parseFieldNumber(cell.text))
+		}
+		return values
+	}
+
+	switch strings.ToUpper(name) {
+	case "ABOVE", "BELOW":
+		var cells []fieldTableCell
+		step := -1
+		if strings.EqualFold(name, "BELOW") {
+			step = 1
+		}
+		for r := ctx.row + step; r >= 0 && r < len(t.rows); r += step {
+			if cell, ok := t.cellAt(r, ctx.col); ok {
+				cells = append(cells, cell)
+			}
+		}
+		return collect(cells), true
+	case "LEFT", "RIGHT":
+		if ctx.row < 0 || ctx.row >= len(t.rows) {
+			return nil, true
+		}
+		row := t.rows[ctx.row]
+		var cells []fieldTableCell
+		for i := range row {
+			if row[i].col != ctx.col {
+				continue
+			}
+			if strings.EqualFold(name, "LEFT") {
+				for j := i - 1; j >= 0; j-- {
+					cells = append(cells, row[j])
+				}
+			} else {
+				cells = append(cells, row[i+1:]...)
+			}
+		}
+		return collect(cells), true
+	}
+
+	from, to, isRange := strings.Cut(name, ":")
+	r1, c1, ok := parseCellReference(from)
+	if !ok {
+		return nil, false
+	}
+	r2, c2 := r1, c1
+	if isRange {
+		if r2, c2, ok = parseCellReference(to); !ok {
+			return nil, false
+		}
+	}
+	if r1 > r2 {
+		r1, r2 = r2, r1
+	}
+	if c1 > c2 {
+		c1, c2 = c2, c1
+	}
+
+	var values []float64
+	for r := r1; r <= r2 && r < len(t.rows); r++ {
+		for _, cell := range t.rows[r] {
+			if cell.col >= c1 && cell.col <= c2 && fieldNumberPattern.MatchString(cell.text) {
+				values = append(values, parseFieldNumber(cell.text))
+			}
+		}
+	}
+	return values, true
+}
+
+// cellAt returns the cell of row r that covers grid column col
+func (t *fieldTable) cellAt(r, col int) (fieldTableCell, bool) {
+	for _, cell := range t.rows[r] {
+		if col >= cell.col && col < cell.col+cell.span {
+			return cell, true
+		}
+	}
+	return fieldTableCell{}, false
+}
+
+// parseCellReference converts an A1-style reference to 0-based row and
+// column indexes
+func parseCellReference(ref string) (int, int, bool) {
+	m := cellReferencePattern.FindStringSubmatch(ref)
+	if m == nil {
+		return 0, 0, false
+	}
+	col := 0
+	for _, c := range strings.ToUpper(m[1]) {
+		col = col*26 + int(c-'A'+1)
+	}
+	row, _ := strconv.Atoi(m[2])
+	if row < 1 {
+		return 0, 0, false
+	}
+	return row - 1, col - 1, true
+}
+
+// parseFieldNumber returns the first number in text, or 0
+func parseFieldNumber(text string) float64 {
+	v, _ := strconv.ParseFloat(strings.ReplaceAll(fieldNumberPattern.FindString(text), ",", ""), 64)
+	return v
+}
+
+// compareNumbers applies a field comparison operator
+func compareNumbers(a float64, op string, b float64) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "<>":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	default:
+		return a >= b
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// findFieldTable returns the grid of the innermost table around offset pos
+// and the row and grid column of the cell holding pos
+func findFieldTable(raw []byte, pos int) (*fieldTable, int, int) {
+	tblStart, tblEnd := -1, -1
+	for from := 0; ; {
+		start := findNextWordTagStart(raw, from, "tbl")
+		if start == -1 || start > pos {
+			break
+		}
+		end := wordElementEnd(raw, start, "tbl")
+		if end == -1 {
+			break
+		}
+		if pos < end {
+			// pos is inside this table; keep looking for a nested one
+			tblStart, tblEnd = start, end
+			from = start + len("<w:tbl")
+			continue
+		}
+		from = end
+	}
+	if tblStart == -1 {
+		return nil, -1, -1
+	}
+
+	table := &fieldTable{}
+	row, col := -1, -1
+	for _, tr := range childWordElements(raw, tblStart+len("<w:tbl"), tblEnd, "tr") {
+		var cells []fieldTableCell
+		gridCol := 0
+		for _, tc := range childWordElements(raw, tr[0]+len("<w:tr"), tr[1], "tc") {
+			span := 1
+			if m := gridSpanPattern.FindSubmatch(raw[tc[0]:tc[1]]); m != nil {
+				if n := atoiOrZero(string(m[1])); n > 1 {
+					span = n
+				}
+			}
+			if pos >= tc[0] && pos < tc[1] {
+				row, col = len(table.rows), gridCol
+			}
+			cells = append(cells, fieldTableCell{
+				col:  gridCol,
+				span: span,
+				text: extractVisibleText(raw[tc[0]:tc[1]]),
+			})
+			gridCol += span
+		}
+		table.rows = append(table.rows, cells)
+	}
+	return table, row, col
+}
+
+// gridSpanPattern matches a table cell's horizontal span
+var gridSpanPattern = regexp.MustCompile(`<w:gridSpan w:val="(\d+)"`)
+
+// childWordElements returns the start and end offsets of the <w:tag>
+// elements in raw[from:to], skipping elements nested inside them
+func childWordElements(raw []byte, from, to int, tag string) [][2]int {
+	var elements [][2]int
+	for {
+		start := findNextWordTagStart(raw, from, tag)
+		if start == -1 || start >= to {
+			return elements
+		}
+		end := wordElementEnd(raw, start, tag)
+		if end == -1 || end > to {
+			return elements
+		}
+		elements = append(elements, [2]int{start, end})
+		from = end
+	}
+}
@@ -0,0 +1,107 @@
+package godocx
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteFieldXML_NestedIf(t *testing.T) {
+	field := IfField(FieldPart{Field: PageField()}, "=", FieldPart{Field: NumPagesField()}, "Last page", "Continued")
+	field.Result = "Continued"
+
+	var buf bytes.Buffer
+	writeFieldXML(&buf, *field, RunOptions{Italic: true})
+	got := buf.String()
+
+	if n := strings.Count(got, `w:fldCharType="begin"`); n != 3 {
+		t.Fatalf("expected outer and two nested fields, got %d begins in %s", n, got)
+	}
+	// The nested fields sit between the outer begin and separate.
+	outerSeparate := strings.LastIndex(got, `w:fldCharType="separate"`)
+	if idx := strings.Index(got, ` NUMPAGES `); idx == -1 || idx > outerSeparate {
+		t.Errorf("nested NUMPAGES must be inside the IF instruction, got %s", got)
+	}
+	if !strings.Contains(got, `<w:instrText xml:space="preserve"> &quot;Last page&quot; &quot;Continued&quot;</w:instrText>`) {
+		t.Errorf("expected quoted IF results, got %s", got)
+	}
+	if strings.Count(got, "<w:i/>") != strings.Count(got, "<w:r>") {
+		t.Errorf("every field run must carry the run formatting, got %s", got)
+	}
+
+	if want := []string{"PAGE", "NUMPAGES", `IF  =  "Last page" "Continued"`}; !reflect.DeepEqual(fieldInstructions(buf.Bytes()), want) {
+		t.Errorf("got instructions %q, want %q", fieldInstructions(buf.Bytes()), want)
+	}
+}
+
+func TestInsertParagraph_FieldRuns(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Body</w:t></w:r></w:p>`))
+
+	err := u.InsertParagraph(ParagraphOptions{
+		Runs: []RunOptions{
+			{Text: "See page "},
+			{Field: PageRefField("results"), Bold: true},
+			{Text: " of "},
+			{Field: SectionPagesField()},
+			{Text: ", printed "},
+			{Field: DateField("d MMMM yyyy")},
+		},
+		Position: PositionEnd,
+	})
+	if err != nil {
+		t.Fatalf("InsertParagraph: %v", err)
+	}
+
+	doc := readDocXML(t, u)
+	want := []string{`PAGEREF results \h`, "SECTIONPAGES", `DATE \@ "d MMMM yyyy"`}
+	if got := fieldInstructions([]byte(doc)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got instructions %q, want %q", got, want)
+	}
+	if !strings.Contains(doc, `<w:r><w:rPr><w:b/></w:rPr><w:fldChar w:fldCharType="begin" w:dirty="true"/></w:r>`) {
+		t.Errorf("expected a dirty, bold PAGEREF field, got %s", doc)
+	}
+	if !strings.Contains(doc, `<w:fldChar w:fldCharType="begin"/>`) {
+		t.Errorf("expected SECTIONPAGES without the dirty flag, got %s", doc)
+	}
+
+	if err := u.InsertParagraph(ParagraphOptions{Runs: []RunOptions{{Field: &Field{}}}}); err == nil {
+		t.Error("expected validation error for empty field instruction")
+	}
+}
+
+func TestFormatFieldDateTime(t *testing.T) {
+	ts := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+	tests := map[string]string{
+		"d MMMM yyyy":          "5 March 2024",
+		"dd/MM/yy":             "05/03/24",
+		"dddd, MMM d":          "Tuesday, Mar 5",
+		"HH:mm:ss":             "14:07:09",
+		"H:mm":                 "14:07",
+		"h:mm am/pm":           "2:07 pm",
+		"'Week of' d MMM yyyy": "Week of 5 Mar 2024",
+	}
+	for picture, want := range tests {
+		if got := formatFieldDateTime(ts, picture); got != want {
+			t.Errorf("%q: got %q, want %q", picture, got, want)
+		}
+	}
+}
+
+func TestFormatFieldDateTime_UnpaddedHour(t *testing.T) {
+	ts := time.Date(2024, time.March, 5, 9, 7, 0, 0, time.UTC)
+	if got := formatFieldDateTime(ts, "H:mm"); got != "9:07" {
+		t.Errorf("H: got %q, want %q", got, "9:07")
+	}
+	if got := formatFieldDateTime(ts, "HH:mm"); got != "09:07" {
+		t.Errorf("HH: got %q, want %q", got, "09:07")
+	}
+}
+
+func TestDocumentPropertyField_EscapesQuotes(t *testing.T) {
+	field := DocumentPropertyField(`Client "A"`)
+	if want := `DOCPROPERTY "Client \"A\""`; field.Instruction != want {
+		t.Errorf("got instruction %s, want %s", field.Instruction, want)
+	}
+}
@@ -0,0 +1,1007 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxFieldUpdatePasses bounds how often a part is re-evaluated so that fields
+// depending on other fields' results (a REF to a caption, an IF around a
+// MERGEFIELD) settle without looping forever on circular references
+const maxFieldUpdatePasses = 5
+
+// Word's cached results for references that cannot be resolved
+const (
+	fieldErrorReference = "Error! Reference source not found."
+	fieldErrorProperty  = "Error! Unknown document property name."
+	fieldErrorStyle     = "Error! No text of specified style in document."
+)
+
+var (
+	// fldSimplePattern matches a simple field with or without a result
+	fldSimplePattern = regexp.MustCompile(`(?s)<w:fldSimple\s([^>]*?)(?:/>|>(.*?)</w:fldSimple>)`)
+
+	// fieldDirtyAttrPattern matches the dirty flag of a field
+	fieldDirtyAttrPattern = regexp.MustCompile(`\s+w:dirty="[^"]*"`)
+
+	// paragraphStylePattern matches a paragraph's style reference
+	paragraphStylePattern = regexp.MustCompile(`<w:pStyle w:val="([^"]*)"`)
+
+	// fieldComparisonPattern splits an IF condition written without spaces
+	fieldComparisonPattern = regexp.MustCompile(`^(.*?)(<=|>=|<>|=|<|>)(.*)$`)
+)
+
+// UpdateFields evaluates the fields whose results do not depend on page
+// layout and writes their cached results, so that the document reads
+// correctly in viewers and converters that do not recalculate fields.
+//
+// Supported fields:
+//   - SEQ: caption and list numbering, including \r, \c, \h and \s
+//   - REF and bare bookmark references: the bookmarked text, or "above" /
+//     "below" with \p
+//   - DOCPROPERTY, AUTHOR, TITLE, SUBJECT, KEYWORDS, COMMENTS and
+//     LASTSAVEDBY: core, app and custom document properties
+//   - IF: comparisons of numbers or text, with ? and * wildcards
+//   - = formulas: arithmetic, comparisons, bookmarks, SUM/AVERAGE/COUNT/MIN/
+//     MAX/PRODUCT/ABS/INT/ROUND/MOD/SIGN/AND/OR/NOT/IF and table references
+//     (ABOVE, BELOW, LEFT, RIGHT, A1, A1:B3)
+//   - STYLEREF: the text of the nearest paragraph with the style (body only)
+//   - MERGEFIELD: see UpdateFieldsWithMergeData
+//   - CITATION: the citation of a bibliography source in the document's
+//     citation style
+//
+// The \* (case and number format) and \# (numeric picture) switches are
+// honoured. Page-dependent fields such as PAGE, NUMPAGES, PAGEREF and TOC are
+// left untouched; use ForceFieldUpdateOnOpen for those. Updated fields lose
+// their dirty flag. The body, headers, footers, footnotes and endnotes are
+// updated; SEQ and STYLEREF are evaluated in the body only.
+func (u *Updater) UpdateFields() error {
+	return u.UpdateFieldsWithMergeData(nil)
+}
+
+// UpdateFieldsWithMergeData works like UpdateFields and also fills MERGEFIELD
+// results from data, matched case-insensitively by field name. The \b and \f
+// switches add their text before and after non-empty values. Merge fields
+// without a value keep their current result.
+func (u *Updater) UpdateFieldsWithMergeData(data map[string]string) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	ctx := u.newFieldUpdateContext(data)
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+	updated := ctx.updatePart(raw, true)
+	if !bytes.Equal(updated, raw) {
+		if err := atomicWriteFile(docPath, updated, 0o644); err != nil {
+			return fmt.Errorf("write document.xml: %w", err)
+		}
+	}
+	ctx.body = updated
+
+	entries, err := os.ReadDir(filepath.Join(u.tempDir, "word"))
+	if err != nil {
+		return fmt.Errorf("read word dir: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		isHeaderFooter := (strings.HasPrefix(name, "header") || strings.HasPrefix(name, "footer")) && strings.HasSuffix(name, ".xml")
+		if entry.IsDir() || (!isHeaderFooter && name != "footnotes.xml" && name != "endnotes.xml") {
+			continue
+		}
+		partPath := filepath.Join(u.tempDir, "word", name)
+		raw, err := os.ReadFile(partPath)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+		if updated := ctx.updatePart(raw, false); !bytes.Equal(updated, raw) {
+			if err := atomicWriteFile(partPath, updated, 0o644); err != nil {
+				return fmt.Errorf("write %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// fieldUpdateContext holds the document-wide data fields are evaluated from
+type fieldUpdateContext struct {
+	body       []byte
+	properties map[string]any    // lower-cased property name → value
+	mergeData  map[string]string // lower-cased field name → value
+	styleNames map[string]string // style ID → display name
+	sources    *sourceLibrary    // nil without a bibliography part
+}
+
+func (u *Updater) newFieldUpdateContext(data map[string]string) *fieldUpdateContext {
+	ctx := &fieldUpdateContext{
+		properties: make(map[string]any),
+		mergeData:  make(map[string]string, len(data)),
+	}
+	for name, value := range data {
+		ctx.mergeData[strings.ToLower(name)] = value
+	}
+
+	if core, err := u.GetCoreProperties(); err == nil {
+		for name, value := range map[string]any{
+			"title":          core.Title,
+			"subject":        core.Subject,
+			"author":         core.Creator,
+			"keywords":       core.Keywords,
+			"comments":       core.Description,
+			"category":       core.Category,
+			"lastsavedby":    core.LastModifiedBy,
+			"revisionnumber": core.Revision,
+			"createtime":     core.Created,
+			"lastsavedtime":  core.Modified,
+		} {
+			ctx.properties[name] = value
+		}
+	}
+	if app, err := u.GetAppProperties(); err == nil {
+		for name, value := range map[string]any{
+			"company":       app.Company,
+			"manager":       app.Manager,
+			"template":      app.Template,
+			"hyperlinkbase": app.HyperlinkBase,
+		} {
+			ctx.properties[name] = value
+		}
+	}
+	if custom, err := u.GetCustomProperties(); err == nil {
+		for _, prop := range custom {
+			if _, builtIn := ctx.properties[strings.ToLower(prop.Name)]; !builtIn {
+				ctx.properties[strings.ToLower(prop.Name)] = prop.Value
+			}
+		}
+	}
+
+	ctx.styleNames = u.readStyleNames()
+	ctx.sources, _ = u.readSourceLibrary()
+	return ctx
+}
+
+// updatePart re-evaluates the fields of one part until their results settle
+func (ctx *fieldUpdateContext) updatePart(raw []byte, isBody bool) []byte {
+	for pass := 0; pass < maxFieldUpdatePasses; pass++ {
+		if isBody {
+			ctx.body = raw
+		}
+		updated, changed := ctx.updateFieldsPass(raw, isBody)
+		if !changed {
+			break
+		}
+		raw = updated
+	}
+	return raw
+}
+
+// docField is a complex or simple field located in a part
+type docField struct {
+	instr     strings.Builder // instruction, with nested fields' results inlined
+	separated bool
+
+	start            int // begin run, or the <w:fldSimple> element
+	end              int // end of the <w:fldSimple> element
+	tagStart, tagEnd int // begin <w:fldChar> or <w:fldSimple> opening tag
+	resultStart      int
+	resultEnd        int
+	simple           bool
+	valid            bool
+}
+
+// fieldEdit replaces raw[start:end] with data
+type fieldEdit struct {
+	start, end int
+	data       []byte
+}
+
+// fieldPassState tracks document-order state while a part is evaluated
+type fieldPassState struct {
+	isBody     bool
+	seq        map[string]int
+	seqLastPos map[string]int
+	seqValues  map[string][]seqValue
+	paragraphs []fieldParagraph
+}
+
+// seqValue records the number a SEQ field produced at an offset
+type seqValue struct {
+	pos, value int
+}
+
+// fieldParagraph is a body paragraph with its style
+type fieldParagraph struct {
+	start, end int
+	style      string
+}
+
+// updateFieldsPass evaluates every field of a part once and writes the
+// results that changed
+func (ctx *fieldUpdateContext) updateFieldsPass(raw []byte, isBody bool) ([]byte, bool) {
+	state := &fieldPassState{
+		isBody:     isBody,
+		seq:        make(map[string]int),
+		seqLastPos: make(map[string]int),
+		seqValues:  make(map[string][]seqValue),
+	}
+	if isBody {
+		for _, para := range bodyParagraphs(raw) {
+			p := fieldParagraph{start: para.start, end: para.end}
+			element := raw[para.start:para.end]
+			if pPrEnd := bytes.Index(element, []byte("</w:pPr>")); pPrEnd != -1 {
+				if m := paragraphStylePattern.FindSubmatch(element[:pPrEnd]); m != nil {
+					p.style = xmlUnescape(string(m[1]))
+				}
+			}
+			state.paragraphs = append(state.paragraphs, p)
+		}
+	}
+
+	var edits []fieldEdit
+	for _, f := range parseDocumentFields(raw) {
+		if !f.valid {
+			continue
+		}
+		result, ok := ctx.evaluateField(raw, f, state)
+		if !ok {
+			continue
+		}
+
+		dirty := fieldDirtyAttrPattern.Match(raw[f.tagStart:f.tagEnd])
+		if extractVisibleText(raw[f.resultStart:f.resultEnd]) == result && !dirty {
+			continue
+		}
+
+		var run bytes.Buffer
+		if result != "" {
+			run.WriteString("<w:r>")
+			run.Write(fieldResultRunProperties(raw, f))
+			writeRunTextWithControls(&run, result)
+			run.WriteString("</w:r>")
+		}
+
+		if f.simple {
+			var element bytes.Buffer
+			element.WriteString("<w:fldSimple ")
+			attrs := bytes.TrimSuffix(bytes.TrimSpace(raw[f.tagStart+len("<w:fldSimple "):f.tagEnd-1]), []byte("/"))
+			element.Write(fieldDirtyAttrPattern.ReplaceAll(attrs, nil))
+			element.WriteString(">")
+			element.Write(run.Bytes())
+			element.WriteString("</w:fldSimple>")
+			edits = append(edits, fieldEdit{f.start, f.end, element.Bytes()})
+			continue
+		}
+		if dirty {
+			edits = append(edits, fieldEdit{f.tagStart, f.tagEnd, fieldDirtyAttrPattern.ReplaceAll(raw[f.tagStart:f.tagEnd], nil)})
+		}
+		edits = append(edits, fieldEdit{f.resultStart, f.resultEnd, run.Bytes()})
+	}
+	if len(edits) == 0 {
+		return raw, false
+	}
+
+	// Drop edits inside the result of an outer field that is rewritten, then
+	// apply the rest back to front so that offsets stay valid.
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+	kept := edits[:0]
+	lastEnd := -1
+	for _, e := range edits {
+		if e.start < lastEnd {
+			continue
+		}
+		kept = append(kept, e)
+		lastEnd = e.end
+	}
+	updated := append([]byte{}, raw...)
+	for i := len(kept) - 1; i >= 0; i-- {
+		updated = spliceBytes(updated, kept[i].start, kept[i].end, kept[i].data)
+	}
+	return updated, true
+}
+
+// fieldResultRunProperties returns the <w:rPr> of the field's first result
+// run, or of its begin run when it has no result yet
+func fieldResultRunProperties(raw []byte, f *docField) []byte {
+	if run := runElementPattern.Find(raw[f.resultStart:f.resultEnd]); run != nil {
+		return runPropertiesPattern.Find(run)
+	}
+	if !f.simple {
+		return runPropertiesPattern.Find(raw[f.start:f.tagStart])
+	}
+	return nil
+}
+
+// parseDocumentFields locates the complex and simple fields of a part in
+// document order. A field is valid when its result can be rewritten in place,
+// that is when it does not span paragraphs.
+func parseDocumentFields(raw []byte) []*docField {
+	var fields []*docField
+	var stack []*docField
+
+	for _, m := range fieldTokenPattern.FindAllSubmatchIndex(raw, -1) {
+		switch {
+		case m[2] != -1:
+			switch string(raw[m[2]:m[3]]) {
+			case "begin":
+				stack = append(stack, &docField{
+					start:    lastWordTagStart(raw, m[0], "r"),
+					tagStart: m[0],
+					tagEnd:   m[1],
+				})
+			case "separate":
+				if len(stack) > 0 {
+					top := stack[len(stack)-1]
+					top.separated = true
+					top.resultStart = runEndAfter(raw, m[1])
+				}
+			case "end":
+				if len(stack) == 0 {
+					continue
+				}
+				f := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				runStart := lastWordTagStart(raw, m[0], "r")
+				if !f.separated {
+					f.resultStart = runStart
+				}
+				f.resultEnd = runStart
+				f.valid = f.start != -1 && runStart != -1 && f.resultStart != -1 &&
+					f.resultStart <= f.resultEnd && !bytes.Contains(raw[f.start:f.resultEnd], []byte("</w:p>"))
+				fields = append(fields, f)
+
+				// A nested field in the instruction contributes its result.
+				if len(stack) > 0 && !stack[len(stack)-1].separated && f.valid {
+					stack[len(stack)-1].instr.WriteString(extractVisibleText(raw[f.resultStart:f.resultEnd]))
+				}
+			}
+		case m[4] != -1:
+			if len(stack) > 0 && !stack[len(stack)-1].separated {
+				stack[len(stack)-1].instr.WriteString(xmlUnescape(string(raw[m[4]:m[5]])))
+			}
+		}
+	}
+
+	for _, m := range fldSimplePattern.FindAllSubmatchIndex(raw, -1) {
+		f := &docField{start: m[0], end: m[1], tagStart: m[0], simple: true, valid: true}
+		f.instr.WriteString(xmlAttrValue(raw[m[0]:m[3]], "w:instr"))
+		if m[4] != -1 {
+			f.tagEnd = m[4]
+			f.resultStart, f.resultEnd = m[4], m[5]
+		} else {
+			f.tagEnd = m[1]
+			f.resultStart, f.resultEnd = m[1], m[1]
+		}
+		fields = append(fields, f)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].tagStart < fields[j].tagStart })
+	return fields
+}
+
+// lastWordTagStart returns the offset of the last <w:tag> opening tag that
+// starts before pos, or -1
+func lastWordTagStart(raw []byte, pos int, tag string) int {
+	needle := []byte("<w:" + tag)
+	for end := pos; ; {
+		idx := bytes.LastIndex(raw[:end], needle)
+		if idx == -1 {
+			return -1
+		}
+		if next := idx + len(needle); next < len(raw) {
+			switch raw[next] {
+			case '>', ' ', '\t', '\n', '\r', '/':
+				return idx
+			}
+		}
+		end = idx
+	}
+}
+
+// runEndAfter returns the offset just past the </w:r> that follows pos, or -1
+func runEndAfter(raw []byte, pos int) int {
+	idx := bytes.Index(raw[pos:], []byte("</w:r>"))
+	if idx == -1 {
+		return -1
+	}
+	return pos + idx + len("</w:r>")
+}
+
+// fieldCode is a parsed field instruction
+type fieldCode struct {
+	name     string
+	args     []string
+	switches map[string]string // switch letter → argument ("" for flags)
+	formats  []string          // arguments of \* switches, in order
+	text     string            // instruction after the field name
+}
+
+// fieldSwitchesWithArgs lists the switches that may take an argument, including
+// TOC's \o, \c, \n, \l and \t, INDEX's \e and \h and CITATION's \p. A switch
+// followed by another switch is a flag, as in REF's \f and \r.
+const fieldSwitchesWithArgs = "*#@bcdefhlnoprst"
+
+// parseFieldCode splits a field instruction into its name, arguments and
+// switches. Quoted arguments may contain spaces.
+func parseFieldCode(instr string) fieldCode {
+	instr = strings.TrimSpace(instr)
+	code := fieldCode{switches: make(map[string]string)}
+	if strings.HasPrefix(instr, "=") {
+		code.name = "="
+		code.text = instr[1:]
+		if idx := strings.IndexByte(code.text, '\\'); idx != -1 {
+			code.text = code.text[:idx]
+		}
+		code.parseSwitches(fieldTokens(instr[1+len(code.text):]))
+		code.text = strings.TrimSpace(code.text)
+		return code
+	}
+
+	tokens := fieldTokens(instr)
+	if len(tokens) == 0 {
+		return code
+	}
+	code.name = strings.ToUpper(tokens[0].text)
+	code.text = strings.TrimSpace(instr[len(tokens[0].text):])
+	code.parseSwitches(tokens[1:])
+	return code
+}
+
+func (code *fieldCode) parseSwitches(tokens []fieldToken) {
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.quoted || !strings.HasPrefix(tok.text, `\`) || len(tok.text) < 2 {
+			code.args = append(code.args, tok.text)
+			continue
+		}
+		letter := strings.ToLower(tok.text[1:2])
+		value := ""
+		if strings.Contains(fieldSwitchesWithArgs, letter) && i+1 < len(tokens) &&
+			(tokens[i+1].quoted || !strings.HasPrefix(tokens[i+1].text, `\`)) {
+			i++
+			value = tokens[i].text
+		}
+		if letter == "*" {
+			code.formats = append(code.formats, value)
+			continue
+		}
+		code.switches[letter] = value
+	}
+}
+
+// fieldToken is one word of a field instruction
+type fieldToken struct {
+	text   string
+	quoted bool
+}
+
+// fieldTokens splits a field instruction on spaces, keeping quoted text
+// together and unescaping \" inside quotes
+func fieldTokens(instr string) []fieldToken {
+	var tokens []fieldToken
+	for i := 0; i < len(instr); {
+		switch {
+		case unicode.IsSpace(rune(instr[i])):
+			i++
+		case instr[i] == '"':
+			var text strings.Builder
+			i++
+			for i < len(instr) && instr[i] != '"' {
+				if instr[i] == '\\' && i+1 < len(instr) && (instr[i+1] == '"' || instr[i+1] == '\\') {
+					i++
+				}
+				text.WriteByte(instr[i])
+				i++
+			}
+			i++
+			tokens = append(tokens, fieldToken{text: text.String(), quoted: true})
+		default:
+			start := i
+			for i < len(instr) && !unicode.IsSpace(rune(instr[i])) && instr[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, fieldToken{text: instr[start:i]})
+		}
+	}
+	return tokens
+}
+
+// evaluateField computes the result of a supported field. It reports false
+// for fields that are left as they are.
+func (ctx *fieldUpdateContext) evaluateField(raw []byte, f *docField, state *fieldPassState) (string, bool) {
+	code := parseFieldCode(f.instr.String())
+	arg := func(i int) string {
+		if i < len(code.args) {
+			return code.args[i]
+		}
+		return ""
+	}
+
+	var result string
+	switch code.name {
+	case "SEQ":
+		if !state.isBody || arg(0) == "" {
+			return "", false
+		}
+		result = state.nextSequence(raw, f.tagStart, code, ctx)
+	case "REF":
+		var ok bool
+		if result, ok = ctx.bookmarkReference(raw, f, arg(0), code, state.isBody); !ok {
+			return "", false
+		}
+	case "DOCPROPERTY":
+		result = ctx.documentProperty(arg(0), code)
+	case "AUTHOR", "TITLE", "SUBJECT", "KEYWORDS", "COMMENTS", "LASTSAVEDBY":
+		result = ctx.documentProperty(code.name, code)
+	case "MERGEFIELD":
+		value, ok := ctx.mergeData[strings.ToLower(arg(0))]
+		if !ok {
+			return "", false
+		}
+		result = value
+		if value != "" {
+			result = code.switches["b"] + value + code.switches["f"]
+		}
+	case "STYLEREF":
+		if !state.isBody {
+			return "", false
+		}
+		var ok bool
+		if result, ok = ctx.styleReference(raw, f, arg(0), code, state); !ok {
+			return "", false
+		}
+	case "CITATION":
+		if ctx.sources == nil {
+			return "", false
+		}
+		result = ctx.sources.citationText(code, ctx.sources.numbers(ctx.body))
+	case "IF":
+		result = evaluateIfField(code.args)
+	case "=":
+		table, row, col := findFieldTable(raw, f.tagStart)
+		value, errText, ok := evaluateFormula(code.text, formulaContext{
+			table:    table,
+			row:      row,
+			col:      col,
+			bookmark: ctx.bookmarkText,
+		})
+		if !ok {
+			return errText, true
+		}
+		if picture, ok := code.switches["#"]; ok {
+			result = formatFieldNumber(value, picture)
+		} else {
+			result = formatFormulaNumber(value)
+		}
+	default:
+		// A field consisting of a bookmark name is an implicit REF.
+		if code.name == "" || strings.ContainsAny(code.name, `\"`) {
+			return "", false
+		}
+		name := fieldTokens(f.instr.String())[0].text
+		if _, ok := ctx.bookmarkText(name); !ok {
+			return "", false
+		}
+		var ok bool
+		if result, ok = ctx.bookmarkReference(raw, f, name, code, state.isBody); !ok {
+			return "", false
+		}
+	}
+
+	if picture, ok := code.switches["#"]; ok && code.name != "=" && fieldNumberPattern.MatchString(result) {
+		result = formatFieldNumber(parseFieldNumber(result), picture)
+	}
+	for _, format := range code.formats {
+		result = applyFieldFormat(result, format)
+	}
+	return result, true
+}
+
+// nextSequence advances the SEQ counter of the field's identifier
+func (state *fieldPassState) nextSequence(raw []byte, pos int, code fieldCode, ctx *fieldUpdateContext) string {
+	id := code.args[0]
+
+	// SEQ id bookmark repeats the number at the bookmark.
+	if len(code.args) > 1 {
+		_, _, end, ok := bookmarkRange(raw, code.args[1])
+		if !ok {
+			return fieldErrorReference
+		}
+		value := 0
+		for _, v := range state.seqValues[id] {
+			if v.pos < end {
+				value = v.value
+			}
+		}
+		return strconv.Itoa(value)
+	}
+
+	if level, ok := code.switches["s"]; ok {
+		if n := atoiOrZero(level); n > 0 && state.headingBetween(state.seqLastPos[id], pos, n, ctx) {
+			state.seq[id] = 0
+		}
+	}
+	switch {
+	case hasSwitch(code, "r"):
+		state.seq[id] = atoiOrZero(code.switches["r"])
+	case hasSwitch(code, "c"):
+	default:
+		state.seq[id]++
+	}
+	state.seqLastPos[id] = pos
+	state.seqValues[id] = append(state.seqValues[id], seqValue{pos, state.seq[id]})
+
+	if hasSwitch(code, "h") && len(code.formats) == 0 {
+		return ""
+	}
+	return strconv.Itoa(state.seq[id])
+}
+
+func hasSwitch(code fieldCode, letter string) bool {
+	_, ok := code.switches[letter]
+	return ok
+}
+
+// headingBetween reports whether a heading of the given level or higher
+// starts between the offsets from and to
+func (state *fieldPassState) headingBetween(from, to, level int, ctx *fieldUpdateContext) bool {
+	for _, para := range state.paragraphs {
+		if para.start < from || para.start >= to {
+			continue
+		}
+		if n := styleHeadingLevel(ctx.styleNames, para.style); n > 0 && n <= level {
+			return true
+		}
+	}
+	return false
+}
+
+// bookmarkText returns the visible text of a bookmark in the body
+func (ctx *fieldUpdateContext) bookmarkText(name string) (string, bool) {
+	start, endTag, _, ok := bookmarkRange(ctx.body, name)
+	if !ok {
+		return "", false
+	}
+	return extractVisibleText(ctx.body[start:endTag]), true
+}
+
+// bookmarkReference computes the result of a REF field. Paragraph-number
+// switches (\n, \r, \w) depend on list rendering and are not evaluated.
+func (ctx *fieldUpdateContext) bookmarkReference(raw []byte, f *docField, name string, code fieldCode, isBody bool) (string, bool) {
+	if hasSwitch(code, "n") || hasSwitch(code, "r") || hasSwitch(code, "w") {
+		return "", false
+	}
+	if hasSwitch(code, "p") {
+		if !isBody {
+			return "", false
+		}
+		start, _, _, ok := bookmarkRange(raw, name)
+		if !ok {
+			return fieldErrorReference, true
+		}
+		if start < f.tagStart {
+			return "above", true
+		}
+		return "below", true
+	}
+	text, ok := ctx.bookmarkText(name)
+	if !ok {
+		return fieldErrorReference, true
+	}
+	return text, true
+}
+
+// documentProperty returns the display text of a document property
+func (ctx *fieldUpdateContext) documentProperty(name string, code fieldCode) string {
+	value, ok := ctx.properties[strings.ToLower(name)]
+	if !ok {
+		return fieldErrorProperty
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "Y"
+		}
+		return "N"
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		picture := code.switches["@"]
+		if picture == "" {
+			picture = "M/d/yyyy"
+		}
+		return formatFieldDateTime(v, picture)
+	}
+	return fmt.Sprint(value)
+}
+
+// styleReference finds the text of the nearest paragraph with the style named
+// by the STYLEREF field: before the field first, then after it. Paragraph
+// numbers (\n, \r, \w) are not evaluated.
+func (ctx *fieldUpdateContext) styleReference(raw []byte, f *docField, style string, code fieldCode, state *fieldPassState) (string, bool) {
+	if hasSwitch(code, "n") || hasSwitch(code, "r") || hasSwitch(code, "w") {
+		return "", false
+	}
+	if n := atoiOrZero(style); n >= 1 && n <= 9 && strconv.Itoa(n) == style {
+		style = "heading " + style
+	}
+	matches := func(id string) bool {
+		if id == "" {
+			return false
+		}
+		return strings.EqualFold(id, style) || strings.EqualFold(ctx.styleNames[id], style) ||
+			strings.EqualFold(id, strings.ReplaceAll(style, " ", ""))
+	}
+
+	found := -1
+	for i, para := range state.paragraphs {
+		if para.end <= f.tagStart && matches(para.style) {
+			found = i
+		}
+	}
+	if found == -1 {
+		for i, para := range state.paragraphs {
+			if para.start > f.tagStart && matches(para.style) {
+				found = i
+				break
+			}
+		}
+	}
+	if found == -1 {
+		return fieldErrorStyle, true
+	}
+
+	para := state.paragraphs[found]
+	if hasSwitch(code, "p") {
+		if para.end <= f.tagStart {
+			return "above", true
+		}
+		return "below", true
+	}
+	return strings.TrimSpace(extractVisibleText(raw[para.start:para.end])), true
+}
+
+// evaluateIfField evaluates IF left operator right "true text" "false text".
+// Numbers are compared numerically, other values as text; with = and <> the
+// right-hand text may contain ? and * wildcards.
+func evaluateIfField(args []string) string {
+	isOperator := func(s string) bool {
+		switch s {
+		case "=", "<>", "<", "<=", ">", ">=":
+			return true
+		}
+		return false
+	}
+	// Empty nested results leave the left operand out; a condition written
+	// without spaces arrives as one word.
+	if len(args) > 0 && isOperator(args[0]) {
+		args = append([]string{""}, args...)
+	} else if len(args) > 0 && (len(args) < 2 || !isOperator(args[1])) {
+		if m := fieldComparisonPattern.FindStringSubmatch(args[0]); m != nil {
+			args = append([]string{m[1], m[2], m[3]}, args[1:]...)
+		}
+	}
+	for len(args) < 5 {
+		args = append(args, "")
+	}
+
+	left, op, right := args[0], args[1], args[2]
+	var holds bool
+	l, lErr := strconv.ParseFloat(strings.ReplaceAll(left, ",", ""), 64)
+	r, rErr := strconv.ParseFloat(strings.ReplaceAll(right, ",", ""), 64)
+	switch {
+	case lErr == nil && rErr == nil:
+		holds = compareNumbers(l, op, r)
+	case op == "=":
+		holds = matchFieldWildcard(right, left)
+	case op == "<>":
+		holds = !matchFieldWildcard(right, left)
+	default:
+		cmp := strings.Compare(left, right)
+		holds = compareNumbers(float64(cmp), op, 0)
+	}
+	if holds {
+		return args[3]
+	}
+	return args[4]
+}
+
+// matchFieldWildcard matches text against a pattern where ? matches one
+// character and * any run of characters
+func matchFieldWildcard(pattern, text string) bool {
+	p, t := []rune(pattern), []rune(text)
+	star, match := -1, 0
+	for i, j := 0, 0; j < len(t); {
+		switch {
+		case i < len(p) && (p[i] == '?' || p[i] == t[j]):
+			i++
+			j++
+		case i < len(p) && p[i] == '*':
+			star, match = i, j
+			i++
+		case star != -1:
+			i = star + 1
+			match++
+			j = match
+		default:
+			return false
+		}
+		if j == len(t) {
+			for i < len(p) && p[i] == '*' {
+				i++
+			}
+			return i == len(p)
+		}
+	}
+	for _, c := range p {
+		if c != '*' {
+			return false
+		}
+	}
+	return true
+}
+
+// applyFieldFormat applies one \* general formatting switch
+func applyFieldFormat(result, format string) string {
+	upper := format != "" && unicode.IsUpper(rune(format[0]))
+	switch strings.ToLower(format) {
+	case "upper":
+		return strings.ToUpper(result)
+	case "lower":
+		return strings.ToLower(result)
+	case "firstcap":
+		r := []rune(result)
+		if len(r) > 0 {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		return string(r)
+	case "caps":
+		words := strings.Fields(result)
+		for i, w := range words {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			words[i] = string(r)
+		}
+		return strings.Join(words, " ")
+	case "arabic":
+		return strconv.Itoa(int(parseFieldNumber(result)))
+	case "roman":
+		n := int(parseFieldNumber(result))
+		if upper {
+			return toRomanNumeral(n)
+		}
+		return strings.ToLower(toRomanNumeral(n))
+	case "alphabetic":
+		n := int(parseFieldNumber(result))
+		if upper {
+			return toAlphabeticNumber(n)
+		}
+		return strings.ToLower(toAlphabeticNumber(n))
+	case "ordinal":
+		n := int(parseFieldNumber(result))
+		suffix := "th"
+		if n%100 < 11 || n%100 > 13 {
+			switch n % 10 {
+			case 1:
+				suffix = "st"
+			case 2:
+				suffix = "nd"
+			case 3:
+				suffix = "rd"
+			}
+		}
+		return strconv.Itoa(n) + suffix
+	}
+	// MERGEFORMAT, CHARFORMAT and unsupported formats keep the result.
+	return result
+}
+
+// toRomanNumeral formats n (1-3999) as an upper-case Roman numeral
+func toRomanNumeral(n int) string {
+	if n <= 0 || n >= 4000 {
+		return strconv.Itoa(n)
+	}
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	var out strings.Builder
+	for i, v := range values {
+		for n >= v {
+			out.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return out.String()
+}
+
+// toAlphabeticNumber formats n the way Word letters lists: A-Z, then AA-ZZ,
+// AAA-ZZZ and so on
+func toAlphabeticNumber(n int) string {
+	if n <= 0 {
+		return strconv.Itoa(n)
+	}
+	letter := string(rune('A' + (n-1)%26))
+	return strings.Repeat(letter, (n-1)/26+1)
+}
+
+// formatFieldNumber formats v with a Word numeric picture such as "0.00",
+// "#,##0" or "$#,##0.00;($#,##0.00)". Text around the digit placeholders is
+// kept; a second section is used for negative numbers.
+func formatFieldNumber(v float64, picture string) string {
+	sections := strings.Split(picture, ";")
+	pic := sections[0]
+	negative := v < 0
+	if negative && len(sections) > 1 && sections[1] != "" {
+		pic = sections[1]
+	}
+	if v == 0 && len(sections) > 2 && sections[2] != "" {
+		pic = sections[2]
+	}
+
+	first := strings.IndexAny(pic, "0#x.,")
+	if first == -1 {
+		return pic
+	}
+	last := strings.LastIndexAny(pic, "0#x.,")
+	prefix, core, suffix := pic[:first], pic[first:last+1], pic[last+1:]
+	prefix = strings.ReplaceAll(prefix, "'", "")
+	suffix = strings.ReplaceAll(suffix, "'", "")
+
+	intPart, fracPart, _ := strings.Cut(core, ".")
+	decimals := strings.Count(fracPart, "0") + strings.Count(fracPart, "#")
+	minInt := strings.Count(intPart, "0")
+
+	abs := v
+	if abs < 0 {
+		abs = -abs
+	}
+	digits := strconv.FormatFloat(abs, 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(digits, ".")
+	for len(whole) < minInt {
+		whole = "0" + whole
+	}
+	if minInt == 0 && whole == "0" && decimals > 0 {
+		whole = ""
+	}
+	if strings.Contains(intPart, ",") {
+		var grouped strings.Builder
+		for i, c := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				grouped.WriteByte(',')
+			}
+			grouped.WriteRune(c)
+		}
+		whole = grouped.String()
+	}
+
+	out := whole
+	if decimals > 0 {
+		out += "." + frac
+	}
+	if negative && (len(sections) < 2 || sections[1] == "") && strings.Trim(out, "0.,") != "" {
+		return "-" + prefix + out + suffix
+	}
+	return prefix + out + suffix
+}
@@ -0,0 +1,217 @@
+package godocx
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bodyParagraphTexts returns the visible text of each body paragraph
+func bodyParagraphTexts(t *testing.T, u *Updater) []string {
+	t.Helper()
+	raw := []byte(readDocXML(t, u))
+	var texts []string
+	for _, para := range bodyParagraphs(raw) {
+		texts = append(texts, extractVisibleText(raw[para.start:para.end]))
+	}
+	return texts
+}
+
+func fieldXML(field *Field) string {
+	var buf bytes.Buffer
+	writeFieldXML(&buf, *field, RunOptions{})
+	return buf.String()
+}
+
+func TestUpdateFields_CaptionsAndReferences(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, crossRefFixtureBody()))
+
+	err := u.InsertCrossReference(CaptionTarget(CaptionFigure, 2), CrossRefLabelNumber, CrossReferenceOptions{
+		Prefix:   "See ",
+		Position: PositionEnd,
+	})
+	if err != nil {
+		t.Fatalf("InsertCrossReference: %v", err)
+	}
+	// Make the cached result stale, as after the caption above it was deleted.
+	doc := strings.Replace(readDocXML(t, u), `<w:t>Figure 2</w:t>`, `<w:t>Figure 7</w:t>`, 1)
+	if err := os.WriteFile(filepath.Join(u.tempDir, "word", "document.xml"), []byte(doc), 0o644); err != nil {
+		t.Fatalf("write document.xml: %v", err)
+	}
+
+	if err := u.UpdateFields(); err != nil {
+		t.Fatalf("UpdateFields: %v", err)
+	}
+
+	texts := bodyParagraphTexts(t, u)
+	for _, want := range []string{"Figure 1: Sales by region", "Figure 2: Costs", "See Figure 2"} {
+		found := false
+		for _, text := range texts {
+			found = found || text == want
+		}
+		if !found {
+			t.Errorf("expected paragraph %q, got %q", want, texts)
+		}
+	}
+	if doc := readDocXML(t, u); strings.Contains(doc, "w:dirty") {
+		t.Errorf("updated fields must not stay dirty, got %s", doc)
+	}
+}
+
+func TestUpdateFields_PropertiesMergeAndIf(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Quote</w:t></w:r></w:p>`))
+	for _, dir := range []string{"docProps", "_rels"} {
+		if err := os.MkdirAll(filepath.Join(u.tempDir, dir), 0o755); err != nil {
+			t.Fatalf("create %s: %v", dir, err)
+		}
+	}
+	rels := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`
+	if err := os.WriteFile(filepath.Join(u.tempDir, "_rels", ".rels"), []byte(rels), 0o644); err != nil {
+		t.Fatalf("write .rels: %v", err)
+	}
+
+	if err := u.SetCoreProperties(CoreProperties{Title: "Quote", Creator: "Ada Lovelace"}); err != nil {
+		t.Fatalf("SetCoreProperties: %v", err)
+	}
+	if err := u.SetCustomProperties([]CustomProperty{{Name: "Client", Value: "Acme"}, {Name: "Approved", Value: true}}); err != nil {
+		t.Fatalf("SetCustomProperties: %v", err)
+	}
+
+	tier := &Field{Instruction: "MERGEFIELD Tier", Result: "«Tier»"}
+	err := u.InsertParagraphs([]ParagraphOptions{
+		{Runs: []RunOptions{{Text: "Client: "}, {Field: DocumentPropertyField("Client"), Bold: true}}, Position: PositionEnd},
+		{Runs: []RunOptions{{Text: "By "}, {Field: &Field{Instruction: "AUTHOR", Switches: []string{`\* Upper`}}}}, Position: PositionEnd},
+		{Runs: []RunOptions{{Field: DocumentPropertyField("Approved")}, {Text: "/"}, {Field: DocumentPropertyField("Missing")}}, Position: PositionEnd},
+		{Runs: []RunOptions{{Field: IfField(FieldPart{Field: tier}, "=", FieldPart{Text: `"Gold"`}, "Priority", "Standard")}}, Position: PositionEnd},
+		{Runs: []RunOptions{{Field: &Field{Instruction: "MERGEFIELD Contact", Switches: []string{`\b "Attn: "`}}}}, Position: PositionEnd},
+	})
+	if err != nil {
+		t.Fatalf("InsertParagraphs: %v", err)
+	}
+
+	if err := u.UpdateFieldsWithMergeData(map[string]string{"tier": "Gold", "CONTACT": "Grace"}); err != nil {
+		t.Fatalf("UpdateFieldsWithMergeData: %v", err)
+	}
+
+	// The nested MERGEFIELD result is part of the IF field code, so the
+	// paragraph's text shows it before the IF result.
+	want := []string{"Quote", "Client: Acme", "By ADA LOVELACE", "Y/" + fieldErrorProperty, "GoldPriority", "Attn: Grace"}
+	if got := bodyParagraphTexts(t, u); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got paragraphs %q, want %q", got, want)
+	}
+	if doc := readDocXML(t, u); !strings.Contains(doc, `<w:r><w:rPr><w:b/></w:rPr><w:t>Acme</w:t></w:r>`) {
+		t.Errorf("expected the result to keep the field's formatting, got %s", doc)
+	}
+
+	// Without merge data, merge fields keep their results.
+	if err := u.UpdateFields(); err != nil {
+		t.Fatalf("UpdateFields: %v", err)
+	}
+	if got := bodyParagraphTexts(t, u); got[4] != "GoldPriority" || got[5] != "Attn: Grace" {
+		t.Errorf("merge results must be kept, got %q", got)
+	}
+}
+
+func TestUpdateFields_TableFormulas(t *testing.T) {
+	cell := func(content string) string {
+		return `<w:tc><w:p>` + content + `</w:p></w:tc>`
+	}
+	text := func(s string) string { return `<w:r><w:t>` + s + `</w:t></w:r>` }
+	body := `<w:tbl>` +
+		`<w:tr>` + cell(text("Item")) + cell(text("Amount")) + `</w:tr>` +
+		`<w:tr>` + cell(text("Beans")) + cell(text("1,200.50")) + `</w:tr>` +
+		`<w:tr>` + cell(text("Milk")) + cell(text("$300")) + `</w:tr>` +
+		`<w:tr>` + cell(text("Total")) + cell(fieldXML(&Field{Instruction: "= SUM(ABOVE)", Switches: []string{`\# "#,##0.00"`}, Result: "0"})) + `</w:tr>` +
+		`<w:tr>` + cell(text("Average")) + cell(`<w:fldSimple w:instr=" =AVERAGE(B2:B3) "/>`) + `</w:tr>` +
+		`</w:tbl>` +
+		`<w:p>` + fieldXML(&Field{Instruction: "= 10 / (2 - 2)"}) + `</w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.UpdateFields(); err != nil {
+		t.Fatalf("UpdateFields: %v", err)
+	}
+
+	texts := bodyParagraphTexts(t, u)
+	want := []string{"Item", "Amount", "Beans", "1,200.50", "Milk", "$300", "Total", "1,500.50", "Average", "750.25", formulaZeroDivide}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("got cells %q, want %q", texts, want)
+	}
+}
+
+func TestUpdateFields_SequenceSwitchesAndStyleRef(t *testing.T) {
+	heading := func(text string) string {
+		return `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>` + text + `</w:t></w:r></w:p>`
+	}
+	seq := func(instr string) string {
+		return `<w:p>` + fieldXML(&Field{Instruction: instr, Result: "1"}) + `</w:p>`
+	}
+	body := heading("Overview") +
+		seq(`SEQ Table \s 1`) + seq(`SEQ Table \s 1`) + seq(`SEQ Table \c \* ROMAN`) +
+		`<w:p>` + fieldXML(&Field{Instruction: `STYLEREF "heading 1"`, Result: "?"}) + `</w:p>` +
+		heading("Details") +
+		seq(`SEQ Table \s 1 \* alphabetic`) + seq(`SEQ Note \r 5`) + seq(`SEQ Note \h`)
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.UpdateFields(); err != nil {
+		t.Fatalf("UpdateFields: %v", err)
+	}
+
+	want := []string{"Overview", "1", "2", "II", "Overview", "Details", "a", "5", ""}
+	if got := bodyParagraphTexts(t, u); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got paragraphs %q, want %q", got, want)
+	}
+}
+
+func TestFieldExpressions(t *testing.T) {
+	formulas := map[string]string{
+		"1 + 2 * 3":         "7",
+		"(1 + 2) * 3 ^ 2":   "27",
+		"ROUND(10 / 3, 2)":  "3.33",
+		"MAX(4, 9, 2) - 1":  "8",
+		"IF(5 > 3, 10, 20)": "10",
+		"AND(1, 0) + 50%":   "0.5",
+		"SUM(":              formulaSyntaxError,
+		"Unknown * 2":       "!Undefined Bookmark, Unknown",
+	}
+	ctx := formulaContext{row: -1, col: -1, bookmark: func(string) (string, bool) { return "", false }}
+	for expr, want := range formulas {
+		v, errText, ok := evaluateFormula(expr, ctx)
+		got := errText
+		if ok {
+			got = formatFormulaNumber(v)
+		}
+		if got != want {
+			t.Errorf("%q: got %q, want %q", expr, got, want)
+		}
+	}
+
+	pictures := map[string]string{
+		"0.00":                  "-1234.57",
+		"#,##0":                 "-1,235",
+		"$#,##0.00;($#,##0.00)": "($1,234.57)",
+	}
+	for picture, want := range pictures {
+		if got := formatFieldNumber(-1234.567, picture); got != want {
+			t.Errorf("%q: got %q, want %q", picture, got, want)
+		}
+	}
+
+	conditions := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"10", ">", "9", "big", "small"}, "big"},
+		{[]string{"Smith", "=", "S*h", "yes", "no"}, "yes"},
+		{[]string{"Smith", "<>", "J?nes", "yes", "no"}, "yes"},
+		{[]string{"=", "", "empty", "filled"}, "empty"},
+		{[]string{"3<2", "yes", "no"}, "no"},
+	}
+	for _, c := range conditions {
+		if got := evaluateIfField(c.args); got != c.want {
+			t.Errorf("%q: got %q, want %q", c.args, got, c.want)
+		}
+	}
+}
@@ -12,35 +12,83 @@ import (
 
 // FootnoteOptions defines options for inserting a footnote
 type FootnoteOptions struct {
-	// Text is the footnote content
+	// Text is the footnote content. Ignored when Paragraphs is non-empty.
 	Text string
 
-	// Anchor is the text in the document after which the footnote reference is placed
+	// Paragraphs is a rich footnote body. Each entry becomes one paragraph of
+	// independently formatted runs; runs with a URL become hyperlinks.
+	Paragraphs [][]RunOptions
+
+	// Anchor is the text in the document immediately after which the footnote
+	// reference is placed. Ignored by UpdateFootnote.
 	Anchor string
+
+	// CustomMark replaces the automatic footnote number with a custom
+	// reference mark such as "*" or "†" (w:customMarkFollows).
+	CustomMark string
 }
 
 // EndnoteOptions defines options for inserting an endnote
 type EndnoteOptions struct {
-	// Text is the endnote content
+	// Text is the endnote content. Ignored when Paragraphs is non-empty.
 	Text string
 
-	// Anchor is the text in the document after which the endnote reference is placed
+	// Paragraphs is a rich endnote body. Each entry becomes one paragraph of
+	// independently formatted runs; runs with a URL become hyperlinks.
+	Paragraphs [][]RunOptions
+
+	// Anchor is the text in the document immediately after which the endnote
+	// reference is placed. Ignored by UpdateEndnote.
 	Anchor string
+
+	// CustomMark replaces the automatic endnote number with a custom
+	// reference mark such as "*" or "†" (w:customMarkFollows).
+	CustomMark string
 }
 
+// Note represents an existing footnote or endnote in the document
+type Note struct {
+	ID int
+
+	// Text is the plain text of the note body; paragraphs are separated by newlines.
+	Text string
+
+	// CustomMark is the custom reference mark, or empty for automatic numbering.
+	CustomMark string
+
+	// ParagraphText is the text of the document paragraph holding the reference mark.
+	ParagraphText string
+}
+
+// noteContent is the body of a footnote or endnote shared by both option types.
+type noteContent struct {
+	Text       string
+	Paragraphs [][]RunOptions
+	CustomMark string
+}
+
+var (
+	noteRunStylePattern = regexp.MustCompile(`<w:rStyle w:val="(?:Footnote|Endnote)Reference"/>`)
+	noteTypeAttrPattern = regexp.MustCompile(`^<w:(?:footnote|endnote)\s[^>]*w:type="`)
+)
+
 // InsertFootnote adds a footnote to the document.
-// The footnote reference marker is placed at the end of the paragraph
-// containing the anchor text.
+// The footnote reference marker is placed immediately after the anchor text,
+// or at the end of its paragraph if the text only matches after whitespace
+// normalisation.
 func (u *Updater) InsertFootnote(opts FootnoteOptions) error {
 	if u == nil {
 		return fmt.Errorf("updater is nil")
 	}
-	if opts.Text == "" {
+	if opts.Text == "" && len(opts.Paragraphs) == 0 {
 		return fmt.Errorf("footnote text cannot be empty")
 	}
 	if opts.Anchor == "" {
 		return fmt.Errorf("anchor text cannot be empty")
 	}
+	if err := validateEquationParagraphs(opts.Paragraphs); err != nil {
+		return err
+	}
 
 	// Ensure footnotes.xml exists and get next footnote ID
 	footnoteID, err := u.ensureFootnotesXML()
@@ -53,12 +101,13 @@ func (u *Updater) InsertFootnote(opts FootnoteOptions) error {
 	}
 
 	// Add the footnote content to footnotes.xml
-	if err := u.addFootnoteContent(footnoteID, opts.Text); err != nil {
+	content := noteContent{Text: opts.Text, Paragraphs: opts.Paragraphs, CustomMark: opts.CustomMark}
+	if err := u.addNoteContent("footnote", footnoteID, content); err != nil {
 		return fmt.Errorf("add footnote content: %w", err)
 	}
 
 	// Insert footnote reference in document.xml
-	if err := u.insertNoteReference(opts.Anchor, footnoteID, "footnote"); err != nil {
+	if err := u.insertNoteReference(opts.Anchor, footnoteID, "footnote", opts.CustomMark); err != nil {
 		return fmt.Errorf("insert footnote reference: %w", err)
 	}
 
@@ -66,18 +115,22 @@ func (u *Updater) InsertFootnote(opts FootnoteOptions) error {
 }
 
 // InsertEndnote adds an endnote to the document.
-// The endnote reference marker is placed at the end of the paragraph
-// containing the anchor text.
+// The endnote reference marker is placed immediately after the anchor text,
+// or at the end of its paragraph if the text only matches after whitespace
+// normalisation.
 func (u *Updater) InsertEndnote(opts EndnoteOptions) error {
 	if u == nil {
 		return fmt.Errorf("updater is nil")
 	}
-	if opts.Text == "" {
+	if opts.Text == "" && len(opts.Paragraphs) == 0 {
 		return fmt.Errorf("endnote text cannot be empty")
 	}
 	if opts.Anchor == "" {
 		return fmt.Errorf("anchor text cannot be empty")
 	}
+	if err := validateEquationParagraphs(opts.Paragraphs); err != nil {
+		return err
+	}
 
 	// Ensure endnotes.xml exists and get next endnote ID
 	endnoteID, err := u.ensureEndnotesXML()
@@ -90,18 +143,85 @@ func (u *Updater) InsertEndnote(opts EndnoteOptions) error {
 	}
 
 	// Add the endnote content to endnotes.xml
-	if err := u.addEndnoteContent(endnoteID, opts.Text); err != nil {
+	content := noteContent{Text: opts.Text, Paragraphs: opts.Paragraphs, CustomMark: opts.CustomMark}
+	if err := u.addNoteContent("endnote", endnoteID, content); err != nil {
 		return fmt.Errorf("add endnote content: %w", err)
 	}
 
 	// Insert endnote reference in document.xml
-	if err := u.insertNoteReference(opts.Anchor, endnoteID, "endnote"); err != nil {
+	if err := u.insertNoteReference(opts.Anchor, endnoteID, "endnote", opts.CustomMark); err != nil {
 		return fmt.Errorf("insert endnote reference: %w", err)
 	}
 
 	return nil
 }
 
+// GetFootnotes reads all footnotes from the document, excluding separators.
+// Returns nil if the document has no footnotes.
+func (u *Updater) GetFootnotes() ([]Note, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+	return u.getNotes("footnote")
+}
+
+// GetEndnotes reads all endnotes from the document, excluding separators.
+// Returns nil if the document has no endnotes.
+func (u *Updater) GetEndnotes() ([]Note, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+	return u.getNotes("endnote")
+}
+
+// UpdateFootnote replaces the content of an existing footnote.
+// opts.Anchor is ignored. When opts.CustomMark is set it replaces the mark in
+// both the note and the document reference; otherwise the existing mark is kept.
+func (u *Updater) UpdateFootnote(id int, opts FootnoteOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if opts.Text == "" && len(opts.Paragraphs) == 0 {
+		return fmt.Errorf("footnote text cannot be empty")
+	}
+	if err := validateEquationParagraphs(opts.Paragraphs); err != nil {
+		return err
+	}
+	return u.updateNote("footnote", id, noteContent{Text: opts.Text, Paragraphs: opts.Paragraphs, CustomMark: opts.CustomMark})
+}
+
+// UpdateEndnote replaces the content of an existing endnote.
+// opts.Anchor is ignored. When opts.CustomMark is set it replaces the mark in
+// both the note and the document reference; otherwise the existing mark is kept.
+func (u *Updater) UpdateEndnote(id int, opts EndnoteOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if opts.Text == "" && len(opts.Paragraphs) == 0 {
+		return fmt.Errorf("endnote text cannot be empty")
+	}
+	if err := validateEquationParagraphs(opts.Paragraphs); err != nil {
+		return err
+	}
+	return u.updateNote("endnote", id, noteContent{Text: opts.Text, Paragraphs: opts.Paragraphs, CustomMark: opts.CustomMark})
+}
+
+// DeleteFootnote removes a footnote and the run holding its reference mark.
+func (u *Updater) DeleteFootnote(id int) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	return u.deleteNote("footnote", id)
+}
+
+// DeleteEndnote removes an endnote and the run holding its reference mark.
+func (u *Updater) DeleteEndnote(id int) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	return u.deleteNote("endnote", id)
+}
+
 // ensureFootnotesXML creates footnotes.xml if it doesn't exist and returns the next available ID.
 func (u *Updater) ensureFootnotesXML() (int, error) {
 	fnPath := filepath.Join(u.tempDir, "word", "footnotes.xml")
@@ -174,18 +294,10 @@ func generateInitialFootnotesXML() []byte {
 	buf.WriteString(`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
 	buf.WriteString("\n")
 
-	// Separator footnote (required, id=-1)
-	buf.WriteString(`<w:footnote w:type="separator" w:id="-1">`)
-	buf.WriteString(`<w:p><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>`)
-	buf.WriteString(`<w:r><w:separator/></w:r></w:p>`)
-	buf.WriteString(`</w:footnote>`)
+	// Separator (id=-1) and continuation separator (id=0) are required
+	buf.Write(generateNoteSeparatorEntry("footnote", "separator", -1, nil))
 	buf.WriteString("\n")
-
-	// Continuation separator footnote (required, id=0)
-	buf.WriteString(`<w:footnote w:type="continuationSeparator" w:id="0">`)
-	buf.WriteString(`<w:p><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>`)
-	buf.WriteString(`<w:r><w:continuationSeparator/></w:r></w:p>`)
-	buf.WriteString(`</w:footnote>`)
+	buf.Write(generateNoteSeparatorEntry("footnote", "continuationSeparator", 0, nil))
 	buf.WriteString("\n")
 
 	buf.WriteString(`</w:footnotes>`)
@@ -203,16 +315,10 @@ func generateInitialEndnotesXML() []byte {
 	buf.WriteString(`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
 	buf.WriteString("\n")
 
-	buf.WriteString(`<w:endnote w:type="separator" w:id="-1">`)
-	buf.WriteString(`<w:p><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>`)
-	buf.WriteString(`<w:r><w:separator/></w:r></w:p>`)
-	buf.WriteString(`</w:endnote>`)
+	// Separator (id=-1) and continuation separator (id=0) are required
+	buf.Write(generateNoteSeparatorEntry("endnote", "separator", -1, nil))
 	buf.WriteString("\n")
-
-	buf.WriteString(`<w:endnote w:type="continuationSeparator" w:id="0">`)
-	buf.WriteString(`<w:p><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>`)
-	buf.WriteString(`<w:r><w:continuationSeparator/></w:r></w:p>`)
-	buf.WriteString(`</w:endnote>`)
+	buf.Write(generateNoteSeparatorEntry("endnote", "continuationSeparator", 0, nil))
 	buf.WriteString("\n")
 
 	buf.WriteString(`</w:endnotes>`)
@@ -220,154 +326,368 @@ func generateInitialEndnotesXML() []byte {
 	return buf.Bytes()
 }
 
-// addFootnoteContent adds a footnote entry to footnotes.xml
-func (u *Updater) addFootnoteContent(id int, text string) error {
-	fnPath := filepath.Join(u.tempDir, "word", "footnotes.xml")
-	raw, err := os.ReadFile(fnPath)
+// addNoteContent adds a footnote or endnote entry to footnotes.xml/endnotes.xml.
+// Hyperlink runs are registered in the part's own relationships file.
+func (u *Updater) addNoteContent(noteType string, id int, content noteContent) error {
+	fileName := noteType + "s.xml"
+	notePath := filepath.Join(u.tempDir, "word", fileName)
+	raw, err := os.ReadFile(notePath)
 	if err != nil {
-		return fmt.Errorf("read footnotes.xml: %w", err)
+		return fmt.Errorf("read %s: %w", fileName, err)
 	}
 
-	footnoteXML := generateFootnoteEntry(id, text)
+	urlRelIDs, err := u.registerNoteHyperlinks(fileName, content)
+	if err != nil {
+		return err
+	}
+	noteXML := generateNoteEntry(noteType, id, content, urlRelIDs)
+	if hasEquationParagraphs(content.Paragraphs) {
+		raw = ensureMathNamespace(raw)
+	}
 
-	// Insert before </w:footnotes>
-	closeTag := []byte("</w:footnotes>")
+	// Insert before the closing root tag
+	closeTag := []byte("</w:" + noteType + "s>")
 	closeIdx := bytes.LastIndex(raw, closeTag)
 	if closeIdx == -1 {
-		return fmt.Errorf("could not find </w:footnotes> tag")
+		return fmt.Errorf("could not find %s tag", closeTag)
 	}
 
-	result := make([]byte, 0, len(raw)+len(footnoteXML)+1)
+	result := make([]byte, 0, len(raw)+len(noteXML)+1)
 	result = append(result, raw[:closeIdx]...)
-	result = append(result, footnoteXML...)
+	result = append(result, noteXML...)
 	result = append(result, '\n')
 	result = append(result, raw[closeIdx:]...)
 
-	if err := os.WriteFile(fnPath, result, 0o644); err != nil {
-		return fmt.Errorf("write footnotes.xml: %w", err)
+	if err := atomicWriteFile(notePath, result, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", fileName, err)
 	}
 
 	return nil
 }
 
-// addEndnoteContent adds an endnote entry to endnotes.xml
-func (u *Updater) addEndnoteContent(id int, text string) error {
-	enPath := filepath.Join(u.tempDir, "word", "endnotes.xml")
-	raw, err := os.ReadFile(enPath)
-	if err != nil {
-		return fmt.Errorf("read endnotes.xml: %w", err)
+// registerNoteHyperlinks adds a relationship to the note part for every
+// distinct URL used in content and returns the URL→rId map.
+func (u *Updater) registerNoteHyperlinks(partName string, content noteContent) (map[string]string, error) {
+	urlRelIDs := make(map[string]string)
+	for _, para := range content.Paragraphs {
+		for _, run := range para {
+			if run.URL == "" {
+				continue
+			}
+			if _, seen := urlRelIDs[run.URL]; !seen {
+				rID, err := u.addPartHyperlinkRelationship(partName, run.URL)
+				if err != nil {
+					return nil, fmt.Errorf("register hyperlink for %q: %w", run.URL, err)
+				}
+				urlRelIDs[run.URL] = rID
+			}
+		}
 	}
+	return urlRelIDs, nil
+}
 
-	endnoteXML := generateEndnoteEntry(id, text)
+// generateFootnoteEntry creates the XML for a single plain-text footnote
+func generateFootnoteEntry(id int, text string) []byte {
+	return generateNoteEntry("footnote", id, noteContent{Text: text}, nil)
+}
 
-	closeTag := []byte("</w:endnotes>")
-	closeIdx := bytes.LastIndex(raw, closeTag)
-	if closeIdx == -1 {
-		return fmt.Errorf("could not find </w:endnotes> tag")
+// generateEndnoteEntry creates the XML for a single plain-text endnote
+func generateEndnoteEntry(id int, text string) []byte {
+	return generateNoteEntry("endnote", id, noteContent{Text: text}, nil)
+}
+
+// generateNoteEntry creates the XML for a single footnote or endnote.
+// The first paragraph opens with the note reference marker (the superscript
+// number in the note area), or with the custom mark when one is set.
+func generateNoteEntry(noteType string, id int, content noteContent, urlRelIDs map[string]string) []byte {
+	var buf bytes.Buffer
+
+	styleBase := noteStyleBase(noteType)
+	paragraphs := content.Paragraphs
+	if len(paragraphs) == 0 {
+		// Like Word, separate plain text from the mark with a run holding a
+		// single space, which parseNotes drops again.
+		paragraphs = [][]RunOptions{{{Text: " "}, {Text: content.Text}}}
+	}
+
+	buf.WriteString(fmt.Sprintf(`<w:%s w:id="%d">`, noteType, id))
+	for i, runs := range paragraphs {
+		buf.WriteString("<w:p>")
+		fmt.Fprintf(&buf, `<w:pPr><w:pStyle w:val="%sText"/></w:pPr>`, styleBase)
+
+		if i == 0 {
+			buf.WriteString("<w:r>")
+			fmt.Fprintf(&buf, `<w:rPr><w:rStyle w:val="%sReference"/></w:rPr>`, styleBase)
+			if content.CustomMark != "" {
+				writeRunTextWithControls(&buf, content.CustomMark)
+			} else {
+				fmt.Fprintf(&buf, "<w:%sRef/>", noteType)
+			}
+			buf.WriteString("</w:r>")
+		}
+
+		writeRunsXML(&buf, runs, urlRelIDs)
+		buf.WriteString("</w:p>")
 	}
+	buf.WriteString(fmt.Sprintf("</w:%s>", noteType))
 
-	result := make([]byte, 0, len(raw)+len(endnoteXML)+1)
-	result = append(result, raw[:closeIdx]...)
-	result = append(result, endnoteXML...)
-	result = append(result, '\n')
-	result = append(result, raw[closeIdx:]...)
+	return buf.Bytes()
+}
 
-	if err := os.WriteFile(enPath, result, 0o644); err != nil {
-		return fmt.Errorf("write endnotes.xml: %w", err)
+// noteStyleBase returns the style name prefix for a note type ("Footnote" or "Endnote").
+func noteStyleBase(noteType string) string {
+	if noteType == "footnote" {
+		return "Footnote"
 	}
+	return "Endnote"
+}
 
-	return nil
+// generateNoteReferenceRun builds the document run that references a note.
+func generateNoteReferenceRun(noteType string, noteID int, customMark string) string {
+	styleBase := noteStyleBase(noteType)
+	if customMark != "" {
+		var buf bytes.Buffer
+		writeRunTextWithControls(&buf, customMark)
+		return fmt.Sprintf(
+			`<w:r><w:rPr><w:rStyle w:val="%sReference"/></w:rPr>`+
+				`<w:%sReference w:customMarkFollows="1" w:id="%d"/>%s</w:r>`,
+			styleBase, noteType, noteID, buf.String())
+	}
+	return fmt.Sprintf(
+		`<w:r><w:rPr><w:rStyle w:val="%sReference"/></w:rPr>`+
+			`<w:%sReference w:id="%d"/></w:r>`, styleBase, noteType, noteID)
 }
 
-// generateFootnoteEntry creates the XML for a single footnote
-func generateFootnoteEntry(id int, text string) []byte {
-	var buf bytes.Buffer
+// insertNoteReference inserts a footnote or endnote reference into document.xml
+// immediately after the anchor text. If the anchor only matches after
+// whitespace normalisation, the reference goes at the end of its paragraph.
+func (u *Updater) insertNoteReference(anchor string, noteID int, noteType, customMark string) error {
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
 
-	buf.WriteString(fmt.Sprintf(`<w:footnote w:id="%d">`, id))
-	buf.WriteString("<w:p>")
-	buf.WriteString(`<w:pPr><w:pStyle w:val="FootnoteText"/></w:pPr>`)
+	refXML := generateNoteReferenceRun(noteType, noteID, customMark)
 
-	// Footnote reference marker (the superscript number in the footnote area)
-	buf.WriteString("<w:r>")
-	buf.WriteString(`<w:rPr><w:rStyle w:val="FootnoteReference"/></w:rPr>`)
-	buf.WriteString("<w:footnoteRef/>")
-	buf.WriteString("</w:r>")
+	result, err := insertAtDocumentText(raw, 0, anchor, true, refXML)
+	if err != nil {
+		// Fall back to the end of the paragraph containing the anchor text.
+		_, paraEnd, findErr := findParagraphRangeByAnchor(raw, anchor)
+		if findErr != nil {
+			return fmt.Errorf("find anchor: %w", findErr)
+		}
 
-	// Space + text
-	buf.WriteString("<w:r>")
-	buf.WriteString(fmt.Sprintf(`<w:t xml:space="preserve"> %s</w:t>`, xmlEscape(text)))
-	buf.WriteString("</w:r>")
+		// paraEnd points to the end of "</w:p>", so we insert before that tag
+		insertPos := paraEnd - len("</w:p>")
 
-	buf.WriteString("</w:p>")
-	buf.WriteString("</w:footnote>")
+		result = make([]byte, 0, len(raw)+len(refXML))
+		result = append(result, raw[:insertPos]...)
+		result = append(result, []byte(refXML)...)
+		result = append(result, raw[insertPos:]...)
+	}
 
-	return buf.Bytes()
+	if err := atomicWriteFile(docPath, result, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+
+	return nil
 }
 
-// generateEndnoteEntry creates the XML for a single endnote
-func generateEndnoteEntry(id int, text string) []byte {
-	var buf bytes.Buffer
+// noteBlockPattern matches a complete <w:footnote>/<w:endnote> element with the given ID.
+func noteBlockPattern(noteType string, id int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?s)<w:%s\s[^>]*w:id="%d"[^>]*>.*?</w:%s>`, noteType, id, noteType))
+}
 
-	buf.WriteString(fmt.Sprintf(`<w:endnote w:id="%d">`, id))
-	buf.WriteString("<w:p>")
-	buf.WriteString(`<w:pPr><w:pStyle w:val="EndnoteText"/></w:pPr>`)
+// noteReferencePattern matches the document reference element for a note.
+func noteReferencePattern(noteType string, id int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`<w:%sReference\s[^>]*w:id="%d"[^>]*/>`, noteType, id))
+}
+
+// getNotes parses all regular (non-separator) notes of the given type.
+func (u *Updater) getNotes(noteType string) ([]Note, error) {
+	fileName := noteType + "s.xml"
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", fileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", fileName, err)
+	}
 
-	buf.WriteString("<w:r>")
-	buf.WriteString(`<w:rPr><w:rStyle w:val="EndnoteReference"/></w:rPr>`)
-	buf.WriteString("<w:endnoteRef/>")
-	buf.WriteString("</w:r>")
+	docRaw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "document.xml"))
+	if err != nil {
+		return nil, fmt.Errorf("read document.xml: %w", err)
+	}
+
+	notes := parseNotes(raw, noteType)
+	for i := range notes {
+		if loc := noteReferencePattern(noteType, notes[i].ID).FindIndex(docRaw); loc != nil {
+			if start, end, ok := enclosingParagraph(docRaw, loc[0]); ok {
+				notes[i].ParagraphText = extractParagraphPlainText(docRaw[start:end])
+			}
+		}
+	}
+	return notes, nil
+}
 
-	buf.WriteString("<w:r>")
-	buf.WriteString(fmt.Sprintf(`<w:t xml:space="preserve"> %s</w:t>`, xmlEscape(text)))
-	buf.WriteString("</w:r>")
+// parseNotes extracts regular notes from footnotes.xml/endnotes.xml content.
+// Separator and continuation notes (any w:type) are skipped.
+func parseNotes(raw []byte, noteType string) []Note {
+	blockPattern := regexp.MustCompile(fmt.Sprintf(`(?s)<w:%s\s[^>]*>.*?</w:%s>`, noteType, noteType))
+	idPattern := regexp.MustCompile(fmt.Sprintf(`^<w:%s\s[^>]*w:id="(-?\d+)"`, noteType))
 
-	buf.WriteString("</w:p>")
-	buf.WriteString("</w:endnote>")
+	var notes []Note
+	for _, block := range blockPattern.FindAll(raw, -1) {
+		if noteTypeAttrPattern.Match(block) {
+			continue
+		}
+		m := idPattern.FindSubmatch(block)
+		if m == nil {
+			continue
+		}
+		id, err := strconv.Atoi(string(m[1]))
+		if err != nil {
+			continue
+		}
 
-	return buf.Bytes()
+		n := Note{ID: id}
+		var paras []string
+		for _, para := range extractParaPattern.FindAll(block, -1) {
+			var text strings.Builder
+			bodyRuns := 0
+			for _, run := range runBlockPattern.FindAll(para, -1) {
+				// The leading reference-styled run holds the note mark, not body text.
+				if noteRunStylePattern.Match(run) {
+					if n.CustomMark == "" && len(paras) == 0 {
+						n.CustomMark = extractParagraphPlainText(run)
+					}
+					continue
+				}
+				runText := extractParagraphPlainText(run)
+				bodyRuns++
+				// Neither is the space-only run Word puts after the mark.
+				if len(paras) == 0 && bodyRuns == 1 && runText == " " {
+					continue
+				}
+				text.WriteString(runText)
+			}
+			paras = append(paras, text.String())
+		}
+		n.Text = strings.Join(paras, "\n")
+		notes = append(notes, n)
+	}
+	return notes
 }
 
-// insertNoteReference inserts a footnote or endnote reference into document.xml
-// at the end of the paragraph containing the anchor text.
-func (u *Updater) insertNoteReference(anchor string, noteID int, noteType string) error {
-	docPath := filepath.Join(u.tempDir, "word", "document.xml")
-	raw, err := os.ReadFile(docPath)
+// updateNote replaces a note's body and, if content.CustomMark is set, the
+// mark shown by its document reference. Without a new mark the existing one is kept.
+func (u *Updater) updateNote(noteType string, id int, content noteContent) error {
+	// IDs below 1 belong to the separator notes Word requires.
+	if id < 1 {
+		return NewValidationError("ID", fmt.Sprintf("%s id must be 1 or greater", noteType))
+	}
+	fileName := noteType + "s.xml"
+	notePath := filepath.Join(u.tempDir, "word", fileName)
+	raw, err := os.ReadFile(notePath)
 	if err != nil {
-		return fmt.Errorf("read document.xml: %w", err)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s %d not found", noteType, id)
+		}
+		return fmt.Errorf("read %s: %w", fileName, err)
+	}
+
+	pattern := noteBlockPattern(noteType, id)
+	loc := pattern.FindIndex(raw)
+	if loc == nil {
+		return fmt.Errorf("%s %d not found", noteType, id)
 	}
 
-	// Find the paragraph containing the anchor text
-	_, paraEnd, err := findParagraphRangeByAnchor(raw, anchor)
+	markChanged := content.CustomMark != ""
+	if !markChanged {
+		for _, n := range parseNotes(raw[loc[0]:loc[1]], noteType) {
+			content.CustomMark = n.CustomMark
+		}
+	}
+
+	urlRelIDs, err := u.registerNoteHyperlinks(fileName, content)
 	if err != nil {
-		return fmt.Errorf("find anchor: %w", err)
+		return err
+	}
+	noteXML := generateNoteEntry(noteType, id, content, urlRelIDs)
+	if hasEquationParagraphs(content.Paragraphs) {
+		raw = ensureMathNamespace(raw)
 	}
 
-	// Build the reference run XML
-	var refXML string
-	if noteType == "footnote" {
-		refXML = fmt.Sprintf(
-			`<w:r><w:rPr><w:rStyle w:val="FootnoteReference"/></w:rPr>`+
-				`<w:footnoteReference w:id="%d"/></w:r>`, noteID)
-	} else {
-		refXML = fmt.Sprintf(
-			`<w:r><w:rPr><w:rStyle w:val="EndnoteReference"/></w:rPr>`+
-				`<w:endnoteReference w:id="%d"/></w:r>`, noteID)
+	result := make([]byte, 0, len(raw)+len(noteXML))
+	result = append(result, raw[:loc[0]]...)
+	result = append(result, noteXML...)
+	result = append(result, raw[loc[1]:]...)
+	if err := atomicWriteFile(notePath, result, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", fileName, err)
 	}
 
-	// Insert the reference run before </w:p>
-	// paraEnd points to the end of "</w:p>", so we insert before that tag
-	insertPos := paraEnd - len("</w:p>")
+	if !markChanged {
+		return nil
+	}
 
-	result := make([]byte, 0, len(raw)+len(refXML))
-	result = append(result, raw[:insertPos]...)
-	result = append(result, []byte(refXML)...)
-	result = append(result, raw[insertPos:]...)
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	docRaw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+	refLoc := noteReferencePattern(noteType, id).FindIndex(docRaw)
+	if refLoc == nil {
+		return nil
+	}
+	runStart, runEnd, ok := enclosingRun(docRaw, refLoc[0])
+	if !ok {
+		return nil
+	}
+	refXML := generateNoteReferenceRun(noteType, id, content.CustomMark)
+	updated := make([]byte, 0, len(docRaw)+len(refXML))
+	updated = append(updated, docRaw[:runStart]...)
+	updated = append(updated, refXML...)
+	updated = append(updated, docRaw[runEnd:]...)
+	return atomicWriteFile(docPath, updated, 0o644)
+}
 
-	if err := os.WriteFile(docPath, result, 0o644); err != nil {
-		return fmt.Errorf("write document.xml: %w", err)
+// deleteNote removes a note from its part and the reference run from document.xml.
+func (u *Updater) deleteNote(noteType string, id int) error {
+	// IDs below 1 belong to the separator notes Word requires.
+	if id < 1 {
+		return NewValidationError("ID", fmt.Sprintf("%s id must be 1 or greater", noteType))
+	}
+	fileName := noteType + "s.xml"
+	notePath := filepath.Join(u.tempDir, "word", fileName)
+	raw, err := os.ReadFile(notePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s %d not found", noteType, id)
+		}
+		return fmt.Errorf("read %s: %w", fileName, err)
 	}
 
-	return nil
+	loc := noteBlockPattern(noteType, id).FindIndex(raw)
+	if loc == nil {
+		return fmt.Errorf("%s %d not found", noteType, id)
+	}
+	end := loc[1]
+	if end < len(raw) && raw[end] == '\n' {
+		end++
+	}
+	raw = append(raw[:loc[0]:loc[0]], raw[end:]...)
+	if err := atomicWriteFile(notePath, raw, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", fileName, err)
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	docRaw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+	docRaw = removeReferenceRuns(docRaw, noteReferencePattern(noteType, id))
+	return atomicWriteFile(docPath, docRaw, 0o644)
 }
 
 // getNextNoteID finds the next available note ID in a footnotes/endnotes XML file
@@ -0,0 +1,411 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// NoteNumberFormat defines the numbering format of footnote/endnote reference marks
+type NoteNumberFormat string
+
+const (
+	// NoteNumberDecimal numbers notes 1, 2, 3
+	NoteNumberDecimal NoteNumberFormat = "decimal"
+	// NoteNumberLowerRoman numbers notes i, ii, iii
+	NoteNumberLowerRoman NoteNumberFormat = "lowerRoman"
+	// NoteNumberUpperRoman numbers notes I, II, III
+	NoteNumberUpperRoman NoteNumberFormat = "upperRoman"
+	// NoteNumberLowerLetter numbers notes a, b, c
+	NoteNumberLowerLetter NoteNumberFormat = "lowerLetter"
+	// NoteNumberUpperLetter numbers notes A, B, C
+	NoteNumberUpperLetter NoteNumberFormat = "upperLetter"
+	// NoteNumberSymbols numbers notes *, †, ‡, §
+	NoteNumberSymbols NoteNumberFormat = "chicago"
+)
+
+// NoteRestart defines when note numbering restarts
+type NoteRestart string
+
+const (
+	// NoteRestartContinuous numbers notes continuously through the document
+	NoteRestartContinuous NoteRestart = "continuous"
+	// NoteRestartEachSection restarts numbering in every section
+	NoteRestartEachSection NoteRestart = "eachSect"
+	// NoteRestartEachPage restarts numbering on every page (footnotes only)
+	NoteRestartEachPage NoteRestart = "eachPage"
+)
+
+// NotePosition defines where notes are placed
+type NotePosition string
+
+const (
+	// NotePositionPageBottom places footnotes at the bottom of the page
+	NotePositionPageBottom NotePosition = "pageBottom"
+	// NotePositionBeneathText places footnotes directly below the last line of text
+	NotePositionBeneathText NotePosition = "beneathText"
+	// NotePositionSectionEnd places notes at the end of each section
+	NotePositionSectionEnd NotePosition = "sectEnd"
+	// NotePositionDocumentEnd places notes at the end of the document
+	NotePositionDocumentEnd NotePosition = "docEnd"
+)
+
+// NoteProperties defines numbering and placement of footnotes or endnotes.
+// Zero-valued fields leave the current setting unchanged.
+type NoteProperties struct {
+	Position     NotePosition
+	NumberFormat NoteNumberFormat
+	StartAt      int // First note number (1 or greater, 0 keeps the current start)
+	Restart      NoteRestart
+}
+
+// NoteSeparatorOptions defines custom content for the special separator notes.
+// Nil fields leave the current content unchanged.
+type NoteSeparatorOptions struct {
+	// Separator replaces the short line between body text and notes.
+	Separator []RunOptions
+
+	// ContinuationSeparator replaces the full-width line shown above notes
+	// continued from the previous page.
+	ContinuationSeparator []RunOptions
+
+	// ContinuationNotice is shown below a note that continues on the next page.
+	ContinuationNotice []RunOptions
+}
+
+var (
+	notePrPosPattern     = regexp.MustCompile(`<w:pos\s+w:val="([^"]*)"\s*/>`)
+	notePrNumFmtPattern  = regexp.MustCompile(`<w:numFmt\s+w:val="([^"]*)"\s*/>`)
+	notePrStartPattern   = regexp.MustCompile(`<w:numStart\s+w:val="([^"]*)"\s*/>`)
+	notePrRestartPattern = regexp.MustCompile(`<w:numRestart\s+w:val="([^"]*)"\s*/>`)
+	notePrRefPattern     = regexp.MustCompile(`<w:(?:footnote|endnote)\s[^>]*/>`)
+)
+
+// settingsNotePrSuccessors lists the settings.xml elements that follow
+// footnotePr/endnotePr in schema order.
+var settingsNotePrSuccessors = []string{
+	"<w:compat>", "<w:compat/>", "<w:docVars", "<w:rsids", "<m:mathPr",
+	"<w:attachedSchema", "<w:themeFontLang", "<w:clrSchemeMapping",
+	"<w:doNotIncludeSubdocsInStats", "<w:doNotAutoCompressPictures",
+	"<w:forceUpgrade", "<w:captions", "<w:readModeInkLockDown",
+	"<w:smartTagType", "<w:shapeDefaults", "<w:decimalSymbol", "<w:listSeparator",
+}
+
+// SetFootnoteProperties sets the document-wide footnote numbering and placement
+// in settings.xml. Sections without their own footnote properties inherit these.
+func (u *Updater) SetFootnoteProperties(props NoteProperties) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if err := validateNoteProperties("footnote", props); err != nil {
+		return err
+	}
+	return u.setSettingsNoteProperties("footnote", props)
+}
+
+// SetEndnoteProperties sets the document-wide endnote numbering and placement
+// in settings.xml. Sections without their own endnote properties inherit these.
+func (u *Updater) SetEndnoteProperties(props NoteProperties) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if err := validateNoteProperties("endnote", props); err != nil {
+		return err
+	}
+	return u.setSettingsNoteProperties("endnote", props)
+}
+
+// SetSectionFootnoteProperties overrides footnote numbering for one section (1-based).
+func (u *Updater) SetSectionFootnoteProperties(section int, props NoteProperties) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if err := validateNoteProperties("footnote", props); err != nil {
+		return err
+	}
+	return u.updateSectionProperties(section, func(sectPr []byte) ([]byte, error) {
+		return setSectPrNoteProperties(sectPr, "footnote", props), nil
+	})
+}
+
+// SetSectionEndnoteProperties overrides endnote numbering for one section (1-based).
+func (u *Updater) SetSectionEndnoteProperties(section int, props NoteProperties) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if err := validateNoteProperties("endnote", props); err != nil {
+		return err
+	}
+	return u.updateSectionProperties(section, func(sectPr []byte) ([]byte, error) {
+		return setSectPrNoteProperties(sectPr, "endnote", props), nil
+	})
+}
+
+// SetFootnoteSeparators replaces the content of the footnote separator,
+// continuation separator and continuation notice.
+func (u *Updater) SetFootnoteSeparators(opts NoteSeparatorOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if _, err := u.ensureFootnotesXML(); err != nil {
+		return fmt.Errorf("ensure footnotes.xml: %w", err)
+	}
+	return u.setNoteSeparators("footnote", opts)
+}
+
+// SetEndnoteSeparators replaces the content of the endnote separator,
+// continuation separator and continuation notice.
+func (u *Updater) SetEndnoteSeparators(opts NoteSeparatorOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if _, err := u.ensureEndnotesXML(); err != nil {
+		return fmt.Errorf("ensure endnotes.xml: %w", err)
+	}
+	return u.setNoteSeparators("endnote", opts)
+}
+
+// validateNoteProperties checks props against the values allowed for the note type.
+func validateNoteProperties(noteType string, props NoteProperties) error {
+	switch props.NumberFormat {
+	case "", NoteNumberDecimal, NoteNumberLowerRoman, NoteNumberUpperRoman,
+		NoteNumberLowerLetter, NoteNumberUpperLetter, NoteNumberSymbols:
+	default:
+		return NewValidationError("NumberFormat", fmt.Sprintf("invalid note number format: %s", props.NumberFormat))
+	}
+
+	if props.StartAt < 0 {
+		return NewValidationError("StartAt", "note start number cannot be negative")
+	}
+
+	switch props.Restart {
+	case "", NoteRestartContinuous, NoteRestartEachSection:
+	case NoteRestartEachPage:
+		if noteType == "endnote" {
+			return NewValidationError("Restart", "endnote numbering cannot restart on each page")
+		}
+	default:
+		return NewValidationError("Restart", fmt.Sprintf("invalid note restart: %s", props.Restart))
+	}
+
+	switch props.Position {
+	case "", NotePositionSectionEnd, NotePositionDocumentEnd:
+	case NotePositionPageBottom, NotePositionBeneathText:
+		if noteType == "endnote" {
+			return NewValidationError("Position", fmt.Sprintf("endnotes cannot be placed at %s", props.Position))
+		}
+	default:
+		return NewValidationError("Position", fmt.Sprintf("invalid note position: %s", props.Position))
+	}
+
+	return nil
+}
+
+// buildNotePr merges props into an existing <w:footnotePr>/<w:endnotePr>
+// element (which may be empty) and returns the new element. Separator
+// references kept in settings.xml are preserved.
+func buildNotePr(existing []byte, noteType string, props NoteProperties) []byte {
+	pos := string(props.Position)
+	if pos == "" {
+		pos = firstSubmatch(notePrPosPattern, existing)
+	}
+	numFmt := string(props.NumberFormat)
+	if numFmt == "" {
+		numFmt = firstSubmatch(notePrNumFmtPattern, existing)
+	}
+	start := ""
+	if props.StartAt > 0 {
+		start = strconv.Itoa(props.StartAt)
+	} else {
+		start = firstSubmatch(notePrStartPattern, existing)
+	}
+	restart := string(props.Restart)
+	if restart == "" {
+		restart = firstSubmatch(notePrRestartPattern, existing)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<w:%sPr>", noteType)
+	if pos != "" {
+		fmt.Fprintf(&buf, `<w:pos w:val="%s"/>`, pos)
+	}
+	if numFmt != "" {
+		fmt.Fprintf(&buf, `<w:numFmt w:val="%s"/>`, numFmt)
+	}
+	if start != "" {
+		fmt.Fprintf(&buf, `<w:numStart w:val="%s"/>`, start)
+	}
+	if restart != "" {
+		fmt.Fprintf(&buf, `<w:numRestart w:val="%s"/>`, restart)
+	}
+	for _, ref := range notePrRefPattern.FindAll(existing, -1) {
+		buf.Write(ref)
+	}
+	fmt.Fprintf(&buf, "</w:%sPr>", noteType)
+	return buf.Bytes()
+}
+
+// firstSubmatch returns the first capture group of pattern in data, or "".
+func firstSubmatch(pattern *regexp.Regexp, data []byte) string {
+	if m := pattern.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// notePrPattern matches a <w:footnotePr>/<w:endnotePr> element in either form.
+func notePrPattern(noteType string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?s)<w:%sPr(?:\s[^>]*)?(?:/>|>.*?</w:%sPr>)`, noteType, noteType))
+}
+
+// setSectPrNoteProperties merges props into the note properties of one sectPr.
+func setSectPrNoteProperties(sectPr []byte, noteType string, props NoteProperties) []byte {
+	sectPr = expandSelfClosingSectPr(sectPr)
+	var existing []byte
+	if start, end, ok := sectPrChildRange(sectPr, noteType+"Pr"); ok {
+		existing = sectPr[start:end]
+	}
+	return setSectPrChild(sectPr, noteType+"Pr", string(buildNotePr(existing, noteType, props)))
+}
+
+// expandSelfClosingSectPr turns <w:sectPr .../> into an open/close pair.
+func expandSelfClosingSectPr(sectPr []byte) []byte {
+	if !bytes.HasSuffix(sectPr, []byte("/>")) || bytes.HasSuffix(sectPr, []byte("</w:sectPr>")) {
+		return sectPr
+	}
+	expanded := make([]byte, 0, len(sectPr)+len("</w:sectPr>"))
+	expanded = append(expanded, bytes.TrimRight(sectPr[:len(sectPr)-2], " ")...)
+	expanded = append(expanded, '>')
+	return append(expanded, "</w:sectPr>"...)
+}
+
+// spliceBytes returns data with data[start:end] replaced by insert.
+func spliceBytes(data []byte, start, end int, insert []byte) []byte {
+	result := make([]byte, 0, len(data)-(end-start)+len(insert))
+	result = append(result, data[:start]...)
+	result = append(result, insert...)
+	return append(result, data[end:]...)
+}
+
+// setSettingsNoteProperties merges props into settings.xml, creating the
+// settings part if the document has none.
+func (u *Updater) setSettingsNoteProperties(noteType string, props NoteProperties) error {
+	settingsPath, err := u.ensureSettingsXML()
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return fmt.Errorf("read settings.xml: %w", err)
+	}
+
+	var existing []byte
+	if loc := notePrPattern(noteType).FindIndex(raw); loc != nil {
+		existing = raw[loc[0]:loc[1]]
+	}
+
+	successors := settingsNotePrSuccessors
+	if noteType == "footnote" {
+		successors = append([]string{"<w:endnotePr"}, successors...)
+	}
+	return u.setSettingsElement(noteType+"Pr", string(buildNotePr(existing, noteType, props)), successors)
+}
+
+// validate checks the equation runs of each separator note
+func (opts NoteSeparatorOptions) validate() error {
+	for _, sep := range []struct {
+		name string
+		runs []RunOptions
+	}{
+		{"separator", opts.Separator},
+		{"continuation separator", opts.ContinuationSeparator},
+		{"continuation notice", opts.ContinuationNotice},
+	} {
+		if err := validateEquationRuns(sep.runs); err != nil {
+			return fmt.Errorf("%s: %w", sep.name, err)
+		}
+	}
+	return nil
+}
+
+// setNoteSeparators rewrites the special separator notes in footnotes.xml or
+// endnotes.xml. A continuation notice is added with the next free ID if the
+// part does not have one yet.
+func (u *Updater) setNoteSeparators(noteType string, opts NoteSeparatorOptions) error {
+	fileName := noteType + "s.xml"
+	notePath := filepath.Join(u.tempDir, "word", fileName)
+	raw, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", fileName, err)
+	}
+
+	separators := []struct {
+		sepType string
+		runs    []RunOptions
+	}{
+		{"separator", opts.Separator},
+		{"continuationSeparator", opts.ContinuationSeparator},
+		{"continuationNotice", opts.ContinuationNotice},
+	}
+	for _, sep := range separators {
+		if sep.runs == nil {
+			continue
+		}
+		pattern := regexp.MustCompile(fmt.Sprintf(
+			`(?s)<w:%s\s[^>]*w:type="%s"[^>]*>.*?</w:%s>`, noteType, sep.sepType, noteType))
+		idPattern := regexp.MustCompile(`w:id="(-?\d+)"`)
+
+		if loc := pattern.FindIndex(raw); loc != nil {
+			id, _ := strconv.Atoi(firstSubmatch(idPattern, raw[loc[0]:loc[1]]))
+			entry := generateNoteSeparatorEntry(noteType, sep.sepType, id, sep.runs)
+			raw = spliceBytes(raw, loc[0], loc[1], entry)
+			continue
+		}
+
+		closeIdx := bytes.LastIndex(raw, []byte("</w:"+noteType+"s>"))
+		if closeIdx == -1 {
+			return fmt.Errorf("could not find </w:%ss> tag", noteType)
+		}
+		entry := generateNoteSeparatorEntry(noteType, sep.sepType, getNextNoteID(raw, noteType), sep.runs)
+		entry = append(entry, '\n')
+		raw = spliceBytes(raw, closeIdx, closeIdx, entry)
+	}
+
+	if hasEquationParagraphs([][]RunOptions{opts.Separator, opts.ContinuationSeparator, opts.ContinuationNotice}) {
+		raw = ensureMathNamespace(raw)
+	}
+
+	if err := atomicWriteFile(notePath, raw, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// generateNoteSeparatorEntry creates a special note of the given w:type.
+// When runs is nil the standard separator line (or an empty continuation
+// notice) is generated.
+func generateNoteSeparatorEntry(noteType, sepType string, id int, runs []RunOptions) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `<w:%s w:type="%s" w:id="%d">`, noteType, sepType, id)
+	buf.WriteString(`<w:p><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>`)
+	switch {
+	case runs != nil:
+		writeRunsXML(&buf, runs, nil)
+	case sepType != "continuationNotice":
+		fmt.Fprintf(&buf, `<w:r><w:%s/></w:r>`, sepType)
+	}
+	buf.WriteString(`</w:p>`)
+	fmt.Fprintf(&buf, `</w:%s>`, noteType)
+
+	return buf.Bytes()
+}
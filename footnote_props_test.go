@@ -0,0 +1,143 @@
+package godocx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetFootnoteProperties_Settings(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>text</w:t></w:r></w:p>`))
+
+	if err := u.SetFootnoteProperties(NoteProperties{NumberFormat: NoteNumberSymbols, Restart: NoteRestartEachPage}); err != nil {
+		t.Fatalf("SetFootnoteProperties: %v", err)
+	}
+	if err := u.SetEndnoteProperties(NoteProperties{Position: NotePositionSectionEnd, NumberFormat: NoteNumberLowerRoman}); err != nil {
+		t.Fatalf("SetEndnoteProperties: %v", err)
+	}
+	// A second call merges with the existing properties.
+	if err := u.SetFootnoteProperties(NoteProperties{StartAt: 5}); err != nil {
+		t.Fatalf("SetFootnoteProperties: %v", err)
+	}
+	// A zero StartAt keeps the start number set above.
+	if err := u.SetFootnoteProperties(NoteProperties{StartAt: 0}); err != nil {
+		t.Fatalf("SetFootnoteProperties: %v", err)
+	}
+
+	settings := readWordPart(t, u, "settings.xml")
+	wantFn := `<w:footnotePr><w:numFmt w:val="chicago"/><w:numStart w:val="5"/><w:numRestart w:val="eachPage"/></w:footnotePr>`
+	if !strings.Contains(settings, wantFn) {
+		t.Errorf("expected %s in settings, got %s", wantFn, settings)
+	}
+	if strings.Index(settings, "<w:footnotePr>") > strings.Index(settings, "<w:endnotePr>") {
+		t.Error("footnotePr must precede endnotePr")
+	}
+	if !strings.Contains(settings, `<w:endnotePr><w:pos w:val="sectEnd"/><w:numFmt w:val="lowerRoman"/></w:endnotePr>`) {
+		t.Errorf("unexpected endnote properties in %s", settings)
+	}
+	if !strings.Contains(readWordPart(t, u, "_rels/document.xml.rels"), settingsRelType) {
+		t.Error("expected settings relationship")
+	}
+}
+
+func TestSetSectionFootnoteProperties(t *testing.T) {
+	body := `<w:p><w:pPr><w:sectPr><w:type w:val="nextPage"/><w:pgSz w:w="12240" w:h="15840"/></w:sectPr></w:pPr></w:p>` +
+		`<w:p><w:r><w:t>text</w:t></w:r></w:p>` +
+		`<w:sectPr><w:headerReference w:type="default" r:id="rId9"/><w:pgSz w:w="15840" w:h="12240"/></w:sectPr>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.SetSectionFootnoteProperties(2, NoteProperties{NumberFormat: NoteNumberUpperLetter, Restart: NoteRestartEachSection}); err != nil {
+		t.Fatalf("SetSectionFootnoteProperties: %v", err)
+	}
+	if err := u.SetSectionEndnoteProperties(2, NoteProperties{StartAt: 3}); err != nil {
+		t.Fatalf("SetSectionEndnoteProperties: %v", err)
+	}
+
+	docXML := readDocXML(t, u)
+	want := `<w:headerReference w:type="default" r:id="rId9"/>` +
+		`<w:footnotePr><w:numFmt w:val="upperLetter"/><w:numRestart w:val="eachSect"/></w:footnotePr>` +
+		`<w:endnotePr><w:numStart w:val="3"/></w:endnotePr><w:pgSz`
+	if !strings.Contains(docXML, want) {
+		t.Errorf("expected note properties after header reference, got %s", docXML)
+	}
+	if strings.Count(docXML, "<w:footnotePr>") != 1 {
+		t.Error("first section must not be changed")
+	}
+
+	if err := u.SetSectionFootnoteProperties(3, NoteProperties{StartAt: 1}); err == nil {
+		t.Error("expected error for out-of-range section")
+	}
+}
+
+func TestValidateNoteProperties(t *testing.T) {
+	tests := []struct {
+		name     string
+		noteType string
+		props    NoteProperties
+	}{
+		{"bad format", "footnote", NoteProperties{NumberFormat: "bogus"}},
+		{"negative start", "footnote", NoteProperties{StartAt: -1}},
+		{"endnote each page", "endnote", NoteProperties{Restart: NoteRestartEachPage}},
+		{"endnote page bottom", "endnote", NoteProperties{Position: NotePositionPageBottom}},
+		{"bad position", "footnote", NoteProperties{Position: "top"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateNoteProperties(tt.noteType, tt.props); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+	if err := validateNoteProperties("footnote", NoteProperties{Position: NotePositionBeneathText, Restart: NoteRestartEachPage}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSetFootnoteSeparators(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Claim.</w:t></w:r></w:p>`))
+
+	err := u.SetFootnoteSeparators(NoteSeparatorOptions{
+		Separator:          []RunOptions{{Text: "———"}},
+		ContinuationNotice: []RunOptions{{Text: "(continued)", Italic: true}},
+	})
+	if err != nil {
+		t.Fatalf("SetFootnoteSeparators: %v", err)
+	}
+	if err := u.InsertFootnote(FootnoteOptions{Text: "Note.", Anchor: "Claim"}); err != nil {
+		t.Fatalf("InsertFootnote: %v", err)
+	}
+
+	fn := readWordPart(t, u, "footnotes.xml")
+	if !strings.Contains(fn, `<w:footnote w:type="separator" w:id="-1">`) || !strings.Contains(fn, "———") {
+		t.Errorf("expected custom separator keeping id -1, got %s", fn)
+	}
+	if !strings.Contains(fn, "<w:continuationSeparator/>") {
+		t.Error("continuation separator must be unchanged")
+	}
+	if !strings.Contains(fn, `<w:footnote w:type="continuationNotice" w:id="1">`) {
+		t.Errorf("expected continuation notice added, got %s", fn)
+	}
+
+	notes, err := u.GetFootnotes()
+	if err != nil {
+		t.Fatalf("GetFootnotes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].ID != 2 {
+		t.Errorf("expected one regular footnote with id 2, got %+v", notes)
+	}
+}
+
+func TestFindSectionRanges_NestedAndSelfClosing(t *testing.T) {
+	doc := []byte(`<w:body><w:p><w:pPr><w:sectPr/></w:pPr></w:p>` +
+		`<w:sectPr><w:pgSz w:w="1"/><w:sectPrChange w:id="1"><w:sectPr><w:pgSz w:w="2"/></w:sectPr></w:sectPrChange></w:sectPr></w:body>`)
+
+	ranges := findSectionRanges(doc)
+	if len(ranges) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(ranges))
+	}
+	if got := string(doc[ranges[0].start:ranges[0].end]); got != "<w:sectPr/>" {
+		t.Errorf("unexpected first section %q", got)
+	}
+	if got := string(doc[ranges[1].start:ranges[1].end]); !strings.HasSuffix(got, "</w:sectPrChange></w:sectPr>") {
+		t.Errorf("expected nested sectPr included in body section, got %q", got)
+	}
+}
@@ -2,6 +2,8 @@ package godocx
 
 import (
 	"bytes"
+	"errors"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -135,3 +137,178 @@ func TestFootnoteEntryXMLEscaping(t *testing.T) {
 		t.Error("expected escaped quotes")
 	}
 }
+
+func TestInsertFootnote_PlacedAfterAnchorText(t *testing.T) {
+	body := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Revenue grew 15% this quarter.</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.InsertFootnote(FootnoteOptions{Text: "Unaudited.", Anchor: "grew 15%"}); err != nil {
+		t.Fatalf("InsertFootnote: %v", err)
+	}
+
+	docXML := readDocXML(t, u)
+	refIdx := strings.Index(docXML, `<w:footnoteReference w:id="1"/>`)
+	tailIdx := strings.Index(docXML, " this quarter.")
+	if refIdx == -1 || tailIdx == -1 || refIdx > tailIdx {
+		t.Fatalf("expected reference directly after anchor text, got %s", docXML)
+	}
+	if strings.Count(docXML, "<w:b/>") != 2 {
+		t.Error("expected run formatting preserved on both halves of the split run")
+	}
+}
+
+func TestFootnotes_GetUpdateDelete(t *testing.T) {
+	body := `<w:p><w:r><w:t>First claim.</w:t></w:r></w:p><w:p><w:r><w:t>Second claim.</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	if err := u.InsertFootnote(FootnoteOptions{Text: "Source A.", Anchor: "First claim"}); err != nil {
+		t.Fatalf("InsertFootnote 1: %v", err)
+	}
+	err := u.InsertFootnote(FootnoteOptions{
+		Anchor: "Second claim",
+		Paragraphs: [][]RunOptions{
+			{{Text: "See "}, {Text: "the report", URL: "https://example.com/report"}},
+			{{Text: "Second paragraph", Italic: true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("InsertFootnote 2: %v", err)
+	}
+
+	notes, err := u.GetFootnotes()
+	if err != nil {
+		t.Fatalf("GetFootnotes: %v", err)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 footnotes, got %+v", notes)
+	}
+	if notes[0].ID != 1 || notes[0].Text != "Source A." || notes[0].ParagraphText != "First claim." {
+		t.Errorf("unexpected first note %+v", notes[0])
+	}
+	if notes[1].Text != "See the report\nSecond paragraph" || notes[1].ParagraphText != "Second claim." {
+		t.Errorf("unexpected second note %+v", notes[1])
+	}
+	if !strings.Contains(readWordPart(t, u, filepath.Join("_rels", "footnotes.xml.rels")), "https://example.com/report") {
+		t.Error("expected hyperlink relationship in footnotes.xml.rels")
+	}
+
+	if err := u.UpdateFootnote(1, FootnoteOptions{Text: "Source B."}); err != nil {
+		t.Fatalf("UpdateFootnote: %v", err)
+	}
+	if err := u.DeleteFootnote(2); err != nil {
+		t.Fatalf("DeleteFootnote: %v", err)
+	}
+
+	notes, err = u.GetFootnotes()
+	if err != nil {
+		t.Fatalf("GetFootnotes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Text != "Source B." {
+		t.Fatalf("expected only updated footnote 1, got %+v", notes)
+	}
+
+	docXML := readDocXML(t, u)
+	if strings.Contains(docXML, `<w:footnoteReference w:id="2"/>`) {
+		t.Error("expected reference run for deleted footnote removed")
+	}
+	if !strings.Contains(docXML, ">Second claim<") {
+		t.Error("anchor text must be kept")
+	}
+
+	if err := u.DeleteFootnote(2); err == nil {
+		t.Error("expected error deleting a missing footnote")
+	}
+	if err := u.UpdateFootnote(9, FootnoteOptions{Text: "x"}); err == nil {
+		t.Error("expected error updating a missing footnote")
+	}
+}
+
+func TestEndnotes_CustomMark(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Claim here.</w:t></w:r></w:p>`))
+
+	if err := u.InsertEndnote(EndnoteOptions{Text: "Note.", Anchor: "Claim", CustomMark: "*"}); err != nil {
+		t.Fatalf("InsertEndnote: %v", err)
+	}
+
+	docXML := readDocXML(t, u)
+	if !strings.Contains(docXML, `<w:endnoteReference w:customMarkFollows="1" w:id="1"/><w:t>*</w:t>`) {
+		t.Fatalf("expected custom mark reference, got %s", docXML)
+	}
+
+	if err := u.UpdateEndnote(1, EndnoteOptions{Text: "Revised.", CustomMark: "†"}); err != nil {
+		t.Fatalf("UpdateEndnote: %v", err)
+	}
+	notes, err := u.GetEndnotes()
+	if err != nil {
+		t.Fatalf("GetEndnotes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].CustomMark != "†" || notes[0].Text != "Revised." {
+		t.Fatalf("unexpected endnotes %+v", notes)
+	}
+	if !strings.Contains(readDocXML(t, u), `<w:t>†</w:t>`) {
+		t.Error("expected document reference mark updated")
+	}
+
+	if err := u.DeleteEndnote(1); err != nil {
+		t.Fatalf("DeleteEndnote: %v", err)
+	}
+	docXML = readDocXML(t, u)
+	if strings.Contains(docXML, "endnoteReference") || strings.Contains(docXML, "†") {
+		t.Errorf("expected custom mark run removed, got %s", docXML)
+	}
+}
+
+func TestParseNotes_SkipsSeparators(t *testing.T) {
+	notes := parseNotes(generateInitialFootnotesXML(), "footnote")
+	if len(notes) != 0 {
+		t.Errorf("expected separators skipped, got %+v", notes)
+	}
+}
+
+func TestNotes_SeparatorIDsRejected(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Claim here.</w:t></w:r></w:p>`))
+	if err := u.InsertFootnote(FootnoteOptions{Text: "Foot.", Anchor: "Claim"}); err != nil {
+		t.Fatalf("InsertFootnote: %v", err)
+	}
+	if err := u.InsertEndnote(EndnoteOptions{Text: "End.", Anchor: "Claim"}); err != nil {
+		t.Fatalf("InsertEndnote: %v", err)
+	}
+
+	for _, id := range []int{0, -1} {
+		for name, err := range map[string]error{
+			"DeleteFootnote": u.DeleteFootnote(id),
+			"UpdateFootnote": u.UpdateFootnote(id, FootnoteOptions{Text: "x"}),
+			"DeleteEndnote":  u.DeleteEndnote(id),
+			"UpdateEndnote":  u.UpdateEndnote(id, EndnoteOptions{Text: "x"}),
+		} {
+			var docxErr *DocxError
+			if !errors.As(err, &docxErr) || docxErr.Code != ErrCodeValidation {
+				t.Errorf("%s(%d): expected a validation error, got %v", name, id, err)
+			}
+		}
+	}
+
+	for _, part := range []string{"footnotes.xml", "endnotes.xml"} {
+		notes := readWordPart(t, u, part)
+		for _, sep := range []string{`w:type="separator" w:id="-1"`, `w:type="continuationSeparator" w:id="0"`} {
+			if !strings.Contains(notes, sep) {
+				t.Errorf("expected %s kept in %s, got %s", sep, part, notes)
+			}
+		}
+	}
+}
+
+func TestParseNotes_KeepsLeadingSpaceOfRichBody(t *testing.T) {
+	raw := append(generateFootnoteEntry(1, "Plain."),
+		generateNoteEntry("footnote", 2, noteContent{Paragraphs: [][]RunOptions{{{Text: " lead space"}}}}, nil)...)
+	notes := parseNotes(raw, "footnote")
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 notes, got %+v", notes)
+	}
+	if notes[0].Text != "Plain." {
+		t.Errorf("expected the separating space dropped, got %q", notes[0].Text)
+	}
+	if notes[1].Text != " lead space" {
+		t.Errorf("expected the leading space of a rich body kept, got %q", notes[1].Text)
+	}
+}
@@ -273,7 +273,7 @@ func TestGolden_GenerateCommentEntry(t *testing.T) {
 		Initials: "R",
 	}
 
-	result := string(generateCommentEntry(42, opts))
+	result := string(generateCommentEntry(42, opts, nil))
 
 	assertContains(t, result, `w:id="42"`)
 	assertContains(t, result, `w:author="Reviewer"`)
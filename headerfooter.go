@@ -1,6 +1,7 @@
 package godocx
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -54,6 +55,10 @@ type HeaderFooterContent struct {
 
 	// DateFormat defines date format
 	DateFormat string
+
+	// Blocks holds rich content (paragraphs, tables, images, horizontal rules
+	// and fields) written after the simple content above
+	Blocks []HeaderFooterBlock
 }
 
 // HeaderOptions defines options for header creation
@@ -66,6 +71,11 @@ type HeaderOptions struct {
 
 	// DifferentOddEven enables different headers for odd/even pages
 	DifferentOddEven bool
+
+	// Section is the 1-based section that receives the header. The section
+	// gets its own reference (unlinked from the previous section). Zero sets
+	// the header on every section.
+	Section int
 }
 
 // FooterOptions defines options for footer creation
@@ -78,6 +88,11 @@ type FooterOptions struct {
 
 	// DifferentOddEven enables different footers for odd/even pages
 	DifferentOddEven bool
+
+	// Section is the 1-based section that receives the footer. The section
+	// gets its own reference (unlinked from the previous section). Zero sets
+	// the footer on every section.
+	Section int
 }
 
 // DefaultHeaderOptions returns header options with sensible defaults
@@ -103,6 +118,9 @@ func (u *Updater) SetHeader(content HeaderFooterContent, opts HeaderOptions) err
 	if u == nil {
 		return fmt.Errorf("updater is nil")
 	}
+	if err := validateHeaderFooterBlocks(content.Blocks); err != nil {
+		return err
+	}
 
 	// Determine header filename based on type
 	var headerFile string
@@ -116,11 +134,27 @@ func (u *Updater) SetHeader(content HeaderFooterContent, opts HeaderOptions) err
 	default:
 		headerFile = "header.xml"
 	}
+	if opts.Section > 0 {
+		file, err := u.sectionHeaderFooterFile("header", opts.Section, headerFooterRefType(string(opts.Type)))
+		if err != nil {
+			return NewHeaderFooterError("failed to select header part", err)
+		}
+		headerFile = file
+	}
 
 	headerPath := filepath.Join(u.tempDir, "word", headerFile)
 
+	// The part is rewritten from scratch, so relationships of its previous
+	// content (images, hyperlinks) are stale.
+	if err := os.Remove(filepath.Join(u.tempDir, "word", "_rels", headerFile+".rels")); err != nil && !os.IsNotExist(err) {
+		return NewHeaderFooterError("failed to reset header relationships", err)
+	}
+
 	// Generate header XML
-	headerXML := u.generateHeaderFooterXML(content, true)
+	headerXML, err := u.generateHeaderFooterXML(content, true, headerFile)
+	if err != nil {
+		return NewHeaderFooterError("failed to generate header content", err)
+	}
 
 	// Write header file
 	if err := atomicWriteFile(headerPath, headerXML, 0o644); err != nil {
@@ -134,7 +168,7 @@ func (u *Updater) SetHeader(content HeaderFooterContent, opts HeaderOptions) err
 	}
 
 	// Update document.xml to reference header
-	if err := u.updateDocumentForHeaderFooter(string(opts.Type), "header", relID, opts.DifferentFirst, opts.DifferentOddEven); err != nil {
+	if err := u.updateDocumentForHeaderFooter(opts.Section, string(opts.Type), "header", relID, opts.DifferentFirst, opts.DifferentOddEven); err != nil {
 		return NewHeaderFooterError("failed to update document", err)
 	}
 
@@ -151,6 +185,9 @@ func (u *Updater) SetFooter(content HeaderFooterContent, opts FooterOptions) err
 	if u == nil {
 		return fmt.Errorf("updater is nil")
 	}
+	if err := validateHeaderFooterBlocks(content.Blocks); err != nil {
+		return err
+	}
 
 	// Determine footer filename based on type
 	var footerFile string
@@ -164,11 +201,27 @@ func (u *Updater) SetFooter(content HeaderFooterContent, opts FooterOptions) err
 	default:
 		footerFile = "footer.xml"
 	}
+	if opts.Section > 0 {
+		file, err := u.sectionHeaderFooterFile("footer", opts.Section, headerFooterRefType(string(opts.Type)))
+		if err != nil {
+			return NewHeaderFooterError("failed to select footer part", err)
+		}
+		footerFile = file
+	}
 
 	footerPath := filepath.Join(u.tempDir, "word", footerFile)
 
+	// The part is rewritten from scratch, so relationships of its previous
+	// content (images, hyperlinks) are stale.
+	if err := os.Remove(filepath.Join(u.tempDir, "word", "_rels", footerFile+".rels")); err != nil && !os.IsNotExist(err) {
+		return NewHeaderFooterError("failed to reset footer relationships", err)
+	}
+
 	// Generate footer XML
-	footerXML := u.generateHeaderFooterXML(content, false)
+	footerXML, err := u.generateHeaderFooterXML(content, false, footerFile)
+	if err != nil {
+		return NewHeaderFooterError("failed to generate footer content", err)
+	}
 
 	// Write footer file
 	if err := atomicWriteFile(footerPath, footerXML, 0o644); err != nil {
@@ -182,7 +235,7 @@ func (u *Updater) SetFooter(content HeaderFooterContent, opts FooterOptions) err
 	}
 
 	// Update document.xml to reference footer - use string type for flexibility
-	if err := u.updateDocumentForHeaderFooter(string(opts.Type), "footer", relID, opts.DifferentFirst, opts.DifferentOddEven); err != nil {
+	if err := u.updateDocumentForHeaderFooter(opts.Section, string(opts.Type), "footer", relID, opts.DifferentFirst, opts.DifferentOddEven); err != nil {
 		return NewHeaderFooterError("failed to update document", err)
 	}
 
@@ -194,8 +247,9 @@ func (u *Updater) SetFooter(content HeaderFooterContent, opts FooterOptions) err
 	return nil
 }
 
-// generateHeaderFooterXML creates the XML content for a header or footer
-func (u *Updater) generateHeaderFooterXML(content HeaderFooterContent, isHeader bool) []byte {
+// generateHeaderFooterXML creates the XML content for a header or footer.
+// Relationships needed by rich blocks are added to the .rels file of partName.
+func (u *Updater) generateHeaderFooterXML(content HeaderFooterContent, isHeader bool, partName string) ([]byte, error) {
 	var buf strings.Builder
 
 	rootElement := "w:hdr"
@@ -208,6 +262,7 @@ func (u *Updater) generateHeaderFooterXML(content HeaderFooterContent, isHeader
 	buf.WriteString(fmt.Sprintf(`<%s xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" `, rootElement))
 	buf.WriteString(`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
 	buf.WriteString("\n")
+	contentStart := buf.Len()
 
 	// Create table for left, center, right layout
 	if content.LeftText != "" || content.CenterText != "" || content.RightText != "" {
@@ -224,9 +279,23 @@ func (u *Updater) generateHeaderFooterXML(content HeaderFooterContent, isHeader
 		buf.WriteString(u.generateDateParagraph(content.DateFormat))
 	}
 
+	// Add rich content blocks
+	if err := u.writeHeaderFooterBlocks(&buf, content.Blocks, partName); err != nil {
+		return nil, err
+	}
+
+	// A header or footer needs at least one block-level element.
+	if buf.Len() == contentStart {
+		buf.WriteString("<w:p/>")
+	}
+
 	buf.WriteString(fmt.Sprintf("</%s>", rootElement))
 
-	return []byte(buf.String())
+	// Equation runs in paragraph or table blocks need the math namespace.
+	if strings.Contains(buf.String(), "<m:oMath") {
+		return ensureMathNamespace([]byte(buf.String())), nil
+	}
+	return []byte(buf.String()), nil
 }
 
 // generateThreeColumnTable creates a table with left, center, right columns
@@ -417,113 +486,170 @@ func (u *Updater) addHeaderFooterRelationship(filename, hdrFtrType string) (stri
 	return relID, nil
 }
 
-// updateDocumentForHeaderFooter updates document.xml to reference header/footer
-func (u *Updater) updateDocumentForHeaderFooter(hdrFtrType string, hdrFtr string, relID string, differentFirst, differentOddEven bool) error {
-	docPath := filepath.Join(u.tempDir, "word", "document.xml")
-
-	raw, err := os.ReadFile(docPath)
-	if err != nil {
-		return fmt.Errorf("read document: %w", err)
+// updateDocumentForHeaderFooter updates document.xml to reference header/footer.
+// section is 1-based; zero updates every section.
+func (u *Updater) updateDocumentForHeaderFooter(section int, hdrFtrType string, hdrFtr string, relID string, differentFirst, differentOddEven bool) error {
+	refType := headerFooterRefType(hdrFtrType)
+	apply := func(sectPr []byte) ([]byte, error) {
+		sectPr = setSectPrHeaderFooterReference(sectPr, hdrFtr, refType, relID)
+		if differentFirst {
+			sectPr = setSectPrChild(sectPr, "titlePg", "<w:titlePg/>")
+		}
+		return sectPr, nil
 	}
 
-	content := string(raw)
-
-	// Find or create <w:sectPr> section
-	sectPrPattern := `<w:sectPr[^>]*>.*?</w:sectPr>`
-	sectPrRegex := regexp.MustCompile(sectPrPattern)
-
-	if sectPrRegex.MatchString(content) {
-		// Update existing sectPr
-		content = sectPrRegex.ReplaceAllStringFunc(content, func(sectPr string) string {
-			return u.addHeaderFooterToSectPr(sectPr, hdrFtrType, hdrFtr, relID, differentFirst, differentOddEven)
-		})
-	} else {
-		// Create new sectPr before </w:body>
-		sectPr := u.createSectPrWithHeaderFooter(hdrFtrType, hdrFtr, relID, differentFirst, differentOddEven)
-		content = strings.Replace(content, "</w:body>", sectPr+"</w:body>", 1)
+	if section > 0 {
+		if err := u.updateSectionProperties(section, apply); err != nil {
+			return err
+		}
+	} else if err := u.updateAllSectionProperties(apply); err != nil {
+		return err
 	}
 
-	// Write updated document
-	if err := atomicWriteFile(docPath, []byte(content), 0o644); err != nil {
-		return fmt.Errorf("write document: %w", err)
+	// Odd/even headers are a document-wide setting (ECMA-376 §17.15.1.35).
+	if differentOddEven {
+		if err := u.setSettingsElement("evenAndOddHeaders", "<w:evenAndOddHeaders/>", evenAndOddHeadersSuccessors); err != nil {
+			return fmt.Errorf("set evenAndOddHeaders: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// addHeaderFooterToSectPr adds header/footer reference to existing sectPr
-func (u *Updater) addHeaderFooterToSectPr(sectPr string, hdrFtrType string, hdrFtr string, relID string, differentFirst, differentOddEven bool) string {
-	// Determine reference type
-	refType := "default"
-	if hdrFtrType == "first" {
-		refType = "first"
-	} else if hdrFtrType == "even" {
-		refType = "even"
-	}
+// evenAndOddHeadersSuccessors lists the settings.xml elements that follow
+// evenAndOddHeaders in schema order.
+var evenAndOddHeadersSuccessors = append([]string{
+	"<w:bookFoldRevPrinting", "<w:bookFoldPrinting", "<w:bookFoldPrintingSheets",
+	"<w:drawingGridHorizontalSpacing", "<w:drawingGridVerticalSpacing",
+	"<w:displayHorizontalDrawingGridEvery", "<w:displayVerticalDrawingGridEvery",
+	"<w:doNotUseMarginsForDrawingGridOrigin", "<w:drawingGridHorizontalOrigin",
+	"<w:drawingGridVerticalOrigin", "<w:doNotShadeFormData", "<w:noPunctuationKerning",
+	"<w:characterSpacingControl", "<w:printTwoOnOne", "<w:strictFirstAndLastChars",
+	"<w:noLineBreaksAfter", "<w:noLineBreaksBefore", "<w:savePreviewPicture",
+	"<w:doNotValidateAgainstSchema", "<w:saveInvalidXml", "<w:ignoreMixedContent",
+	"<w:alwaysShowPlaceholderText", "<w:doNotDemarcateInvalidXml", "<w:saveXmlDataOnly",
+	"<w:useXSLTWhenSaving", "<w:saveThroughXslt", "<w:showXMLTags",
+	"<w:alwaysMergeEmptyNamespace", "<w:updateFields", "<w:hdrShapeDefaults",
+	"<w:footnotePr", "<w:endnotePr",
+}, settingsNotePrSuccessors...)
+
+// headerFooterRefType maps a HeaderType/FooterType value to the w:type of a
+// header/footer reference; unknown or empty types map to "default".
+func headerFooterRefType(hdrFtrType string) string {
+	switch hdrFtrType {
+	case "first", "even":
+		return hdrFtrType
+	}
+	return "default"
+}
 
-	// Create reference element
-	var refElement string
-	if hdrFtr == "header" {
-		refElement = fmt.Sprintf(`<w:headerReference w:type="%s" r:id="%s"/>`, refType, relID)
-	} else {
-		refElement = fmt.Sprintf(`<w:footerReference w:type="%s" r:id="%s"/>`, refType, relID)
-	}
+// setSectPrHeaderFooterReference replaces the sectPr's header or footer
+// reference of refType, or adds one. References lead the CT_SectPr sequence
+// (ECMA-376 §17.6.17), so new ones go after any existing references.
+func setSectPrHeaderFooterReference(sectPr []byte, hdrFtr, refType, relID string) []byte {
+	sectPr = expandSelfClosingSectPr(sectPr)
+	refElement := fmt.Sprintf(`<w:%sReference w:type="%s" r:id="%s"/>`, hdrFtr, refType, relID)
 
-	// Check if reference already exists for this type
-	refPattern := fmt.Sprintf(`<w:%sReference w:type="%s"[^>]*/>`, hdrFtr, refType)
-	if matched, _ := regexp.MatchString(refPattern, sectPr); matched {
-		// Replace existing reference
-		sectPr = regexp.MustCompile(refPattern).ReplaceAllString(sectPr, refElement)
-	} else {
-		// ECMA-376 §17.17.18 CT_SectPr sequence: headerReference/footerReference must appear
-		// at the beginning of <w:sectPr> content, before type/pgSz/pgMar/cols/etc.
-		// Insert immediately after the opening <w:sectPr...> tag.
-		openTagEnd := strings.Index(sectPr, ">") + 1
-		sectPr = sectPr[:openTagEnd] + refElement + sectPr[openTagEnd:]
+	if start, end, ok := sectPrHeaderFooterReference(sectPr, hdrFtr, refType); ok {
+		return spliceBytes(sectPr, start, end, []byte(refElement))
 	}
-	if differentFirst && !strings.Contains(sectPr, "<w:titlePg") {
-		sectPr = strings.Replace(sectPr, "</w:sectPr>", "<w:titlePg/></w:sectPr>", 1)
-	}
-	if differentOddEven && !strings.Contains(sectPr, "<w:evenAndOddHeaders") {
-		sectPr = strings.Replace(sectPr, "</w:sectPr>", "<w:evenAndOddHeaders/></w:sectPr>", 1)
+
+	insertAt := bytes.IndexByte(sectPr, '>') + 1
+	for _, loc := range hdrFtrReferencePattern.FindAllIndex(sectPr[:sectPrChildLimit(sectPr)], -1) {
+		insertAt = max(insertAt, loc[1])
 	}
+	return spliceBytes(sectPr, insertAt, insertAt, []byte(refElement))
+}
 
-	return sectPr
+// sectPrHeaderFooterReference locates the sectPr's header or footer reference of refType.
+func sectPrHeaderFooterReference(sectPr []byte, hdrFtr, refType string) (start, end int, ok bool) {
+	limit := sectPrChildLimit(sectPr)
+	for _, loc := range hdrFtrReferencePattern.FindAllSubmatchIndex(sectPr[:limit], -1) {
+		if string(sectPr[loc[2]:loc[3]]) != hdrFtr {
+			continue
+		}
+		if xmlAttrValue(sectPr[loc[0]:loc[1]], "w:type") == refType {
+			return loc[0], loc[1], true
+		}
+	}
+	return 0, 0, false
 }
 
-// createSectPrWithHeaderFooter creates a new sectPr with header/footer
-func (u *Updater) createSectPrWithHeaderFooter(hdrFtrType string, hdrFtr string, relID string, differentFirst, differentOddEven bool) string {
-	var buf strings.Builder
+// sectionHeaderFooterFile picks the part that will hold a section's own
+// header or footer of refType. The section's current part is reused when no
+// other section references it; otherwise a new headerN.xml/footerN.xml name
+// is allocated so other sections are unaffected.
+func (u *Updater) sectionHeaderFooterFile(hdrFtr string, section int, refType string) (string, error) {
+	sections, err := u.Sections()
+	if err != nil {
+		return "", err
+	}
+	if section > len(sections) && !(section == 1 && len(sections) == 0) {
+		return "", NewValidationError("section", fmt.Sprintf("section %d out of range (document has %d)", section, len(sections)))
+	}
+
+	if section <= len(sections) {
+		if ref, ok := sections[section-1].headerFooterRef(hdrFtr, refType); ok && !ref.Inherited && ref.Target != "" {
+			shared := false
+			for _, other := range sections {
+				if other.Index == section {
+					continue
+				}
+				if o, ok := other.headerFooterRef(hdrFtr, refType); ok && !o.Inherited && o.RelID == ref.RelID {
+					shared = true
+					break
+				}
+			}
+			if !shared {
+				return ref.Target, nil
+			}
+		}
+	}
 
-	buf.WriteString("<w:sectPr>")
+	return u.nextHeaderFooterFileName(hdrFtr)
+}
 
-	// ECMA-376 §17.17.18 CT_SectPr sequence: headerReference/footerReference must appear
-	// before type/pgSz/pgMar/titlePg etc.
-	// Determine reference type
-	refType := "default"
-	if hdrFtrType == "first" {
-		refType = "first"
-	} else if hdrFtrType == "even" {
-		refType = "even"
+// nextHeaderFooterFileName returns the first headerN.xml/footerN.xml name
+// not yet present in the word directory.
+func (u *Updater) nextHeaderFooterFileName(hdrFtr string) (string, error) {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("%s%d.xml", hdrFtr, n)
+		if _, err := os.Stat(filepath.Join(u.tempDir, "word", name)); os.IsNotExist(err) {
+			return name, nil
+		} else if err != nil {
+			return "", fmt.Errorf("stat %s: %w", name, err)
+		}
 	}
+}
 
-	// Add header/footer reference first (before titlePg/evenAndOddHeaders)
-	if hdrFtr == "header" {
-		buf.WriteString(fmt.Sprintf(`<w:headerReference w:type="%s" r:id="%s"/>`, refType, relID))
-	} else {
-		buf.WriteString(fmt.Sprintf(`<w:footerReference w:type="%s" r:id="%s"/>`, refType, relID))
+// LinkHeaderToPrevious removes a section's own header of the given type so
+// that it inherits the previous section's header again. In the first section
+// this leaves the section without a header of that type.
+func (u *Updater) LinkHeaderToPrevious(section int, headerType HeaderType) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
 	}
+	return u.linkHeaderFooterToPrevious(section, "header", headerFooterRefType(string(headerType)))
+}
 
-	if differentFirst {
-		buf.WriteString("<w:titlePg/>")
-	}
-	if differentOddEven {
-		buf.WriteString("<w:evenAndOddHeaders/>")
+// LinkFooterToPrevious removes a section's own footer of the given type so
+// that it inherits the previous section's footer again. In the first section
+// this leaves the section without a footer of that type.
+func (u *Updater) LinkFooterToPrevious(section int, footerType FooterType) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
 	}
+	return u.linkHeaderFooterToPrevious(section, "footer", headerFooterRefType(string(footerType)))
+}
 
-	buf.WriteString("</w:sectPr>")
-
-	return buf.String()
+// linkHeaderFooterToPrevious drops one header/footer reference from a section.
+func (u *Updater) linkHeaderFooterToPrevious(section int, hdrFtr, refType string) error {
+	return u.updateSectionProperties(section, func(sectPr []byte) ([]byte, error) {
+		if start, end, ok := sectPrHeaderFooterReference(sectPr, hdrFtr, refType); ok {
+			return spliceBytes(sectPr, start, end, nil), nil
+		}
+		return sectPr, nil
+	})
 }
 
 // addHeaderFooterContentType adds content type for header/footer
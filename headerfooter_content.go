@@ -0,0 +1,324 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HeaderFooterBlock is one block-level element of rich header or footer
+// content. Exactly one of the fields must be set; blocks are written in order
+// after the simple Left/Center/Right, page number and date content.
+type HeaderFooterBlock struct {
+	// Paragraph is a formatted paragraph built like InsertParagraph's options.
+	// Position and Anchor are ignored; hyperlink runs get relationships in the
+	// header or footer part.
+	Paragraph *ParagraphOptions
+
+	// Table is a custom table built like InsertTable's options. Position,
+	// Anchor and Caption are ignored.
+	Table *TableOptions
+
+	// Image is a picture (e.g. a company logo) stored with its relationship in
+	// the header or footer part.
+	Image *HeaderFooterImage
+
+	// HorizontalRule is a full-width line drawn as a paragraph bottom border.
+	HorizontalRule *HorizontalRule
+
+	// Field is a paragraph holding a field such as STYLEREF or DOCPROPERTY.
+	Field *HeaderFooterField
+}
+
+// HeaderFooterImage defines a picture placed in a header or footer
+type HeaderFooterImage struct {
+	// Path to the image file (required)
+	Path string
+
+	// Width and Height in pixels; when only one is set the other is calculated
+	// proportionally
+	Width  int
+	Height int
+
+	// Alternative text for accessibility (optional)
+	AltText string
+
+	// Alignment of the paragraph holding the image
+	Alignment ParagraphAlignment
+}
+
+// HorizontalRule defines a horizontal line across the header or footer
+type HorizontalRule struct {
+	// Style of the line (default: single)
+	Style BorderStyle
+
+	// Size is the line width in eighths of a point (default: 6 = 0.75pt)
+	Size int
+
+	// Color is a hex color (default: "auto")
+	Color string
+}
+
+// HeaderFooterField defines a paragraph containing a single field
+type HeaderFooterField struct {
+	// Instruction is the field code, e.g. `STYLEREF "Heading 1"` or
+	// `DOCPROPERTY Company`
+	Instruction string
+
+	// Placeholder is the cached result shown until fields are updated
+	Placeholder string
+
+	// Prefix and Suffix are literal text written around the field
+	Prefix string
+	Suffix string
+
+	// Alignment of the paragraph
+	Alignment ParagraphAlignment
+
+	// Format is the character formatting of the field and its surrounding
+	// text; its Text, URL and BookmarkRef are ignored.
+	Format RunOptions
+}
+
+// StyleRefField returns a field showing the text of the nearest paragraph
+// with the given style, e.g. a running chapter title from "Heading 1".
+func StyleRefField(styleName string) HeaderFooterField {
+	return HeaderFooterField{
+		Instruction: fmt.Sprintf(`STYLEREF "%s"`, fieldQuoteEscaper.Replace(styleName)),
+		Placeholder: styleName,
+	}
+}
+
+// DocPropertyField returns a field showing a built-in or custom document
+// property such as "Company" or "Title". It is the header and footer form of
+// DocumentPropertyField.
+func DocPropertyField(name string) HeaderFooterField {
+	field := DocumentPropertyField(name)
+	return HeaderFooterField{
+		Instruction: field.Instruction,
+		Placeholder: field.Result,
+	}
+}
+
+// validateHeaderFooterBlocks checks that each block sets exactly one element
+func validateHeaderFooterBlocks(blocks []HeaderFooterBlock) error {
+	for i, block := range blocks {
+		set := 0
+		if block.Paragraph != nil {
+			set++
+		}
+		if block.Table != nil {
+			set++
+		}
+		if block.Image != nil {
+			set++
+		}
+		if block.HorizontalRule != nil {
+			set++
+		}
+		if block.Field != nil {
+			set++
+		}
+		if set != 1 {
+			return NewValidationError("Blocks", fmt.Sprintf("block %d must set exactly one of Paragraph, Table, Image, HorizontalRule or Field", i+1))
+		}
+
+		switch {
+		case block.Paragraph != nil:
+			if block.Paragraph.Text == "" && len(block.Paragraph.Runs) == 0 {
+				return NewValidationError("Blocks", fmt.Sprintf("block %d: paragraph needs Text or Runs", i+1))
+			}
+			if err := validateParagraphFormat(*block.Paragraph); err != nil {
+				return fmt.Errorf("block %d: %w", i+1, err)
+			}
+		case block.Table != nil:
+			if err := validateTableOptions(*block.Table); err != nil {
+				return fmt.Errorf("block %d: invalid table options: %w", i+1, err)
+			}
+		case block.Image != nil:
+			if block.Image.Path == "" {
+				return NewValidationError("Blocks", fmt.Sprintf("block %d: image path cannot be empty", i+1))
+			}
+			if _, err := os.Stat(block.Image.Path); err != nil {
+				return NewValidationError("Blocks", fmt.Sprintf("block %d: image file not found: %s", i+1, block.Image.Path))
+			}
+		case block.Field != nil:
+			if strings.TrimSpace(block.Field.Instruction) == "" {
+				return NewValidationError("Blocks", fmt.Sprintf("block %d: field instruction cannot be empty", i+1))
+			}
+		}
+	}
+	return nil
+}
+
+// writeHeaderFooterBlocks renders blocks into buf. Relationships needed by
+// images and hyperlinks are added to the .rels file of partName.
+func (u *Updater) writeHeaderFooterBlocks(buf *strings.Builder, blocks []HeaderFooterBlock, partName string) error {
+	lastWasTable := false
+	nextDocPrID := 0
+	for _, block := range blocks {
+		var blockXML []byte
+		var err error
+		switch {
+		case block.Paragraph != nil:
+			blockXML, err = u.headerFooterParagraphXML(*block.Paragraph, partName)
+		case block.Table != nil:
+			blockXML, err = u.headerFooterTableXML(*block.Table)
+		case block.Image != nil:
+			if nextDocPrID == 0 {
+				if nextDocPrID, err = u.getNextDocPrId(); err != nil {
+					return fmt.Errorf("get next docPr id: %w", err)
+				}
+			}
+			blockXML, err = u.headerFooterImageXML(*block.Image, partName, nextDocPrID)
+			nextDocPrID++
+		case block.HorizontalRule != nil:
+			blockXML = generateHorizontalRuleXML(*block.HorizontalRule)
+		case block.Field != nil:
+			blockXML = generateFieldParagraphXML(*block.Field)
+		}
+		if err != nil {
+			return err
+		}
+		buf.Write(blockXML)
+		lastWasTable = block.Table != nil
+	}
+
+	// A header or footer may not end with a table: Word requires a trailing
+	// paragraph after it.
+	if lastWasTable {
+		buf.WriteString("<w:p/>")
+	}
+	return nil
+}
+
+// headerFooterParagraphXML renders a paragraph block, registering hyperlink
+// relationships in the header or footer part.
+func (u *Updater) headerFooterParagraphXML(opts ParagraphOptions, partName string) ([]byte, error) {
+	if opts.Style == "" {
+		opts.Style = StyleNormal
+	}
+
+	listIDs := listNumberingIDs{bulletNumID: BulletListNumID, numberedNumID: NumberedListNumID}
+	if opts.ListType != "" {
+		if _, err := u.ensureNumberingXML(); err != nil {
+			return nil, fmt.Errorf("ensure numbering: %w", err)
+		}
+		listIDs = u.getListNumberingIDs()
+	}
+
+	urlRelIDs := make(map[string]string)
+	for _, run := range opts.Runs {
+		if run.URL == "" {
+			continue
+		}
+		if _, seen := urlRelIDs[run.URL]; seen {
+			continue
+		}
+		rID, err := u.addPartHyperlinkRelationship(partName, run.URL)
+		if err != nil {
+			return nil, fmt.Errorf("register hyperlink for %q: %w", run.URL, err)
+		}
+		urlRelIDs[run.URL] = rID
+	}
+
+	return generateParagraphXML(opts, listIDs, 0, urlRelIDs), nil
+}
+
+// headerFooterTableXML renders a table block
+func (u *Updater) headerFooterTableXML(opts TableOptions) ([]byte, error) {
+	opts = applyTableDefaults(opts)
+	if err := u.ensureTableCellStyles(opts.HeaderStyleName, opts.RowStyleName); err != nil {
+		return nil, fmt.Errorf("ensure table cell styles: %w", err)
+	}
+	return generateTableXML(opts), nil
+}
+
+// headerFooterImageXML copies the image into word/media, relates it from the
+// header or footer part and returns the drawing paragraph. docPrID is passed
+// in because earlier drawings of the same part are not on disk yet.
+func (u *Updater) headerFooterImageXML(img HeaderFooterImage, partName string, docPrID int) ([]byte, error) {
+	actualDims, err := getImageDimensions(img.Path)
+	if err != nil {
+		return nil, fmt.Errorf("get image dimensions: %w", err)
+	}
+	finalDims := calculateProportionalDimensions(actualDims, img.Width, img.Height)
+
+	imageIndex, err := u.getNextImageIndex()
+	if err != nil {
+		return nil, fmt.Errorf("get next image index: %w", err)
+	}
+
+	contentType := getImageContentType(img.Path)
+	ext := strings.ToLower(filepath.Ext(img.Path))
+
+	imageFileName := fmt.Sprintf("image%d%s", imageIndex, ext)
+	if err := u.copyImageToMedia(img.Path, imageFileName); err != nil {
+		return nil, fmt.Errorf("copy image to media: %w", err)
+	}
+
+	relID, err := u.addPartRelationship(partName, OfficeDocumentNS+"/image", "media/"+imageFileName, false)
+	if err != nil {
+		return nil, fmt.Errorf("add image relationship: %w", err)
+	}
+
+	if err := u.addImageContentType(ext, contentType); err != nil {
+		return nil, fmt.Errorf("add image content type: %w", err)
+	}
+
+	drawing := generateImageDrawingXMLWithID(docPrID, imageIndex, relID, finalDims, img.AltText)
+
+	if alignment, ok := paragraphAlignmentValue(img.Alignment); ok {
+		drawing = bytes.Replace(drawing, []byte("<w:p>"), fmt.Appendf(nil, `<w:p><w:pPr><w:jc w:val="%s"/></w:pPr>`, alignment), 1)
+	}
+	return drawing, nil
+}
+
+// generateHorizontalRuleXML creates an empty paragraph with a bottom border
+func generateHorizontalRuleXML(rule HorizontalRule) []byte {
+	style := rule.Style
+	if style == "" {
+		style = BorderSingle
+	}
+	size := rule.Size
+	if size <= 0 {
+		size = 6
+	}
+	color := normalizeHexColor(rule.Color)
+	if color == "" {
+		color = "auto"
+	}
+
+	return fmt.Appendf(nil,
+		`<w:p><w:pPr><w:pBdr><w:bottom w:val="%s" w:sz="%d" w:space="1" w:color="%s"/></w:pBdr></w:pPr></w:p>`,
+		xmlEscape(string(style)), size, color)
+}
+
+// generateFieldParagraphXML creates a paragraph holding a single field with
+// optional literal text around it
+func generateFieldParagraphXML(field HeaderFooterField) []byte {
+	var buf bytes.Buffer
+
+	format := field.Format
+	format.Text, format.URL, format.BookmarkRef = "", "", ""
+
+	buf.WriteString("<w:p>")
+	if alignment, ok := paragraphAlignmentValue(field.Alignment); ok {
+		fmt.Fprintf(&buf, `<w:pPr><w:jc w:val="%s"/></w:pPr>`, alignment)
+	}
+	if field.Prefix != "" {
+		format.Text = field.Prefix
+		writeRunXML(&buf, format)
+		format.Text = ""
+	}
+	writeComplexFieldXML(&buf, field.Instruction, field.Placeholder, format)
+	if field.Suffix != "" {
+		format.Text = field.Suffix
+		writeRunXML(&buf, format)
+	}
+	buf.WriteString("</w:p>")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,145 @@
+package godocx
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetHeader_RichBlocks(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Body</w:t></w:r></w:p><w:sectPr/>`))
+
+	logo := filepath.Join(t.TempDir(), "logo.png")
+	writeTestPNG(t, logo, 200, 50)
+
+	content := HeaderFooterContent{
+		Blocks: []HeaderFooterBlock{
+			{Image: &HeaderFooterImage{Path: logo, Width: 100, AltText: "Acme logo", Alignment: ParagraphAlignRight}},
+			{Paragraph: &ParagraphOptions{Runs: []RunOptions{
+				{Text: "Acme Corp", Bold: true, FontSize: 14},
+				{Text: " | "},
+				{Text: "acme.example", URL: "https://acme.example"},
+			}}},
+			{Table: &TableOptions{
+				Columns: []ColumnDefinition{{Title: "Ref"}, {Title: "Rev"}},
+				Rows:    [][]string{{"DOC-1", "B"}},
+			}},
+			{HorizontalRule: &HorizontalRule{Color: "#1F4E79", Size: 12}},
+		},
+	}
+	if err := u.SetHeader(content, HeaderOptions{Type: HeaderDefault}); err != nil {
+		t.Fatalf("SetHeader: %v", err)
+	}
+
+	header := readWordPart(t, u, "header3.xml")
+	for _, want := range []string{
+		`<w:jc w:val="right"/></w:pPr><w:r><w:drawing>`,
+		`descr="Acme logo"`,
+		`<w:b/>`,
+		`<w:hyperlink r:id="rId`,
+		`<w:t>DOC-1</w:t>`,
+		`<w:bottom w:val="single" w:sz="12" w:space="1" w:color="1F4E79"/>`,
+	} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header missing %q", want)
+		}
+	}
+
+	rels := readWordPart(t, u, "_rels/header3.xml.rels")
+	if !strings.Contains(rels, `/image" Target="media/image1.png"`) {
+		t.Errorf("image relationship must live in the header part rels, got %s", rels)
+	}
+	if !strings.Contains(rels, `Target="https://acme.example" TargetMode="External"`) {
+		t.Errorf("hyperlink relationship must live in the header part rels, got %s", rels)
+	}
+	if strings.Contains(readWordPart(t, u, "_rels/document.xml.rels"), "media/image1.png") {
+		t.Error("image must not be related from document.xml")
+	}
+}
+
+func TestSetFooter_FieldsAndTrailingParagraph(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Body</w:t></w:r></w:p><w:sectPr/>`))
+
+	chapter := StyleRefField("Heading 1")
+	chapter.Prefix = "Chapter: "
+	chapter.Format = RunOptions{Italic: true}
+	company := DocPropertyField("Company")
+	company.Alignment = ParagraphAlignCenter
+
+	content := HeaderFooterContent{
+		Blocks: []HeaderFooterBlock{
+			{Field: &chapter},
+			{Field: &company},
+			{Table: &TableOptions{Columns: []ColumnDefinition{{Title: "A"}}}},
+		},
+	}
+	if err := u.SetFooter(content, FooterOptions{Type: FooterDefault}); err != nil {
+		t.Fatalf("SetFooter: %v", err)
+	}
+
+	footer := readWordPart(t, u, "footer3.xml")
+	for _, want := range []string{
+		`<w:t xml:space="preserve">Chapter: </w:t>`,
+		`<w:instrText xml:space="preserve"> STYLEREF &quot;Heading 1&quot; </w:instrText>`,
+		`<w:instrText xml:space="preserve"> DOCPROPERTY &quot;Company&quot; </w:instrText>`,
+		`<w:rPr><w:i/></w:rPr><w:fldChar w:fldCharType="begin" w:dirty="true"/>`,
+		`<w:t>Company</w:t>`,
+	} {
+		if !strings.Contains(footer, want) {
+			t.Errorf("footer missing %q", want)
+		}
+	}
+	if !strings.HasSuffix(footer, "</w:tbl><w:p/></w:ftr>") {
+		t.Errorf("footer ending in a table needs a trailing paragraph, got %s", footer[len(footer)-40:])
+	}
+}
+
+func TestSetHeader_InvalidBlocks(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p/><w:sectPr/>`))
+
+	cases := []HeaderFooterContent{
+		{Blocks: []HeaderFooterBlock{{}}},
+		{Blocks: []HeaderFooterBlock{{HorizontalRule: &HorizontalRule{}, Field: &HeaderFooterField{Instruction: "PAGE"}}}},
+		{Blocks: []HeaderFooterBlock{{Image: &HeaderFooterImage{Path: "missing.png"}}}},
+		{Blocks: []HeaderFooterBlock{{Field: &HeaderFooterField{}}}},
+	}
+	for i, content := range cases {
+		err := u.SetHeader(content, DefaultHeaderOptions())
+		var docxErr *DocxError
+		if !errors.As(err, &docxErr) || docxErr.Code != ErrCodeValidation {
+			t.Errorf("case %d: expected validation error, got %v", i, err)
+		}
+	}
+}
+
+func writeTestPNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create image: %v", err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode PNG: %v", err)
+	}
+}
+
+func TestHeaderFooterFields_EscapeQuotedNames(t *testing.T) {
+	if got, want := StyleRefField(`Heading "1"`).Instruction, `STYLEREF "Heading \"1\""`; got != want {
+		t.Errorf("StyleRefField instruction = %s, want %s", got, want)
+	}
+	field := DocPropertyField(`Dept\"A"`)
+	if want := `DOCPROPERTY "Dept\\\"A\""`; field.Instruction != want {
+		t.Errorf("DocPropertyField instruction = %s, want %s", field.Instruction, want)
+	}
+	if field.Placeholder != `Dept\"A"` {
+		t.Errorf("placeholder must keep the plain name, got %s", field.Placeholder)
+	}
+	if code := parseFieldCode(field.Instruction); code.args[0] != `Dept\"A"` {
+		t.Errorf("expected the name to round-trip, got %q", code.args)
+	}
+}
@@ -0,0 +1,328 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// HeaderFooterBlockKind identifies the kind of a block read from a header or footer
+type HeaderFooterBlockKind string
+
+const (
+	// HeaderFooterBlockParagraph is a paragraph
+	HeaderFooterBlockParagraph HeaderFooterBlockKind = "paragraph"
+	// HeaderFooterBlockTable is a table
+	HeaderFooterBlockTable HeaderFooterBlockKind = "table"
+)
+
+// HeaderFooterInfo describes the header or footer a section shows for one
+// type (default, first or even)
+type HeaderFooterInfo struct {
+	// Section is the 1-based section index
+	Section int
+
+	// Type is "default", "first" or "even"
+	Type string
+
+	// Target is the part file name, e.g. "header1.xml"
+	Target string
+
+	// Inherited is true when the part comes from an earlier section
+	Inherited bool
+
+	// Text is the visible text, one line per non-empty paragraph
+	Text string
+
+	// Blocks lists the top-level paragraphs and tables in order
+	Blocks []HeaderFooterBlockInfo
+}
+
+// HeaderFooterBlockInfo describes one top-level block of a header or footer
+type HeaderFooterBlockInfo struct {
+	// Kind is paragraph or table
+	Kind HeaderFooterBlockKind
+
+	// Text is the visible text of a paragraph
+	Text string
+
+	// Rows holds the cell text of a table
+	Rows [][]string
+
+	// Fields lists the field instructions in the block, e.g. "PAGE"
+	Fields []string
+
+	// Drawings counts pictures, shapes and watermarks in the block
+	Drawings int
+}
+
+// drawingPattern matches DrawingML and VML graphics
+var drawingPattern = regexp.MustCompile(`<w:(?:drawing|pict)[\s>]`)
+
+// GetHeaders returns the headers of every section, including those inherited
+// from earlier sections, ordered by section and then default, first, even.
+func (u *Updater) GetHeaders() ([]HeaderFooterInfo, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+	return u.getHeaderFooters("header")
+}
+
+// GetFooters returns the footers of every section, including those inherited
+// from earlier sections, ordered by section and then default, first, even.
+func (u *Updater) GetFooters() ([]HeaderFooterInfo, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+	return u.getHeaderFooters("footer")
+}
+
+// getHeaderFooters reads the header or footer parts used by each section.
+func (u *Updater) getHeaderFooters(hdrFtr string) ([]HeaderFooterInfo, error) {
+	sections, err := u.Sections()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make(map[string][]HeaderFooterBlockInfo)
+	var infos []HeaderFooterInfo
+	for _, sec := range sections {
+		for _, refType := range []string{"default", "first", "even"} {
+			ref, ok := sec.headerFooterRef(hdrFtr, refType)
+			if !ok || ref.Target == "" {
+				continue
+			}
+
+			blocks, seen := parts[ref.Target]
+			if !seen {
+				raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", filepath.FromSlash(ref.Target)))
+				if err != nil {
+					return nil, NewXMLParseError(ref.Target, err)
+				}
+				blocks = u.parseHeaderFooterBlocks(raw)
+				parts[ref.Target] = blocks
+			}
+
+			var lines []string
+			for _, block := range blocks {
+				if block.Kind == HeaderFooterBlockTable {
+					for _, row := range block.Rows {
+						for _, cell := range row {
+							if cell != "" {
+								lines = append(lines, cell)
+							}
+						}
+					}
+				} else if block.Text != "" {
+					lines = append(lines, block.Text)
+				}
+			}
+
+			infos = append(infos, HeaderFooterInfo{
+				Section:   sec.Index,
+				Type:      refType,
+				Target:    ref.Target,
+				Inherited: ref.Inherited,
+				Text:      strings.Join(lines, "\n"),
+				Blocks:    blocks,
+			})
+		}
+	}
+	return infos, nil
+}
+
+// parseHeaderFooterBlocks splits a header or footer part into its top-level
+// paragraphs and tables. Wrappers such as content controls are looked through.
+func (u *Updater) parseHeaderFooterBlocks(raw []byte) []HeaderFooterBlockInfo {
+	var blocks []HeaderFooterBlockInfo
+
+	pos := bytes.Index(raw, []byte("<w:hdr"))
+	if pos == -1 {
+		pos = bytes.Index(raw, []byte("<w:ftr"))
+	}
+	if pos == -1 {
+		return nil
+	}
+
+	for {
+		paraStart := findNextWordTagStart(raw, pos, "p")
+		tableStart := findNextWordTagStart(raw, pos, "tbl")
+
+		start, tag := paraStart, "p"
+		if tableStart != -1 && (paraStart == -1 || tableStart < paraStart) {
+			start, tag = tableStart, "tbl"
+		}
+		if start == -1 {
+			return blocks
+		}
+		end := wordElementEnd(raw, start, tag)
+		if end == -1 {
+			return blocks
+		}
+
+		element := raw[start:end]
+		block := HeaderFooterBlockInfo{
+			Kind:     HeaderFooterBlockParagraph,
+			Fields:   fieldInstructions(element),
+			Drawings: len(drawingPattern.FindAllIndex(element, -1)),
+		}
+		if tag == "tbl" {
+			block.Kind = HeaderFooterBlockTable
+			block.Rows = u.extractTableData(element)
+		} else {
+			block.Text = u.extractTextFromXML(element)
+		}
+		blocks = append(blocks, block)
+		pos = end
+	}
+}
+
+// ClearHeader empties the header of the given type in a section (1-based;
+// zero clears it in every section). The section keeps its own, empty header
+// so that it no longer shows the previous section's header.
+func (u *Updater) ClearHeader(section int, headerType HeaderType) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	return u.SetHeader(HeaderFooterContent{}, HeaderOptions{Type: headerType, Section: section})
+}
+
+// ClearFooter empties the footer of the given type in a section (1-based;
+// zero clears it in every section). The section keeps its own, empty footer
+// so that it no longer shows the previous section's footer.
+func (u *Updater) ClearFooter(section int, footerType FooterType) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	return u.SetFooter(HeaderFooterContent{}, FooterOptions{Type: footerType, Section: section})
+}
+
+// headerFooterRelPattern matches header and footer relationships in
+// document.xml.rels
+var headerFooterRelPattern = regexp.MustCompile(`<Relationship\s[^>]*Type="[^"]*/(?:header|footer)"[^>]*/>`)
+
+// mediaRelPattern matches a relationship to a file in word/media
+var mediaRelPattern = regexp.MustCompile(`<Relationship\s[^>]*Target="(?:\.\./)?media/([^"/]+)"[^>]*/>`)
+
+// RemoveAllHeadersFooters removes every header and footer from the document:
+// the section references, the parts with their relationships and content
+// types, the titlePg flags and the document-wide evenAndOddHeaders setting.
+func (u *Updater) RemoveAllHeadersFooters() error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+	if len(findSectionRanges(raw)) > 0 {
+		err := u.updateAllSectionProperties(func(sectPr []byte) ([]byte, error) {
+			sectPr = hdrFtrReferencePattern.ReplaceAll(sectPr, nil)
+			return setSectPrChild(sectPr, "titlePg", ""), nil
+		})
+		if err != nil {
+			return NewHeaderFooterError("failed to remove references", err)
+		}
+	}
+
+	// Drop the relationships and remember which parts they pointed to.
+	relsPath := filepath.Join(u.tempDir, "word", "_rels", "document.xml.rels")
+	rels, err := os.ReadFile(relsPath)
+	if err != nil {
+		return NewHeaderFooterError("failed to read relationships", err)
+	}
+	var targets []string
+	for _, rel := range headerFooterRelPattern.FindAll(rels, -1) {
+		if target := xmlAttrValue(rel, "Target"); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) > 0 {
+		if err := atomicWriteFile(relsPath, headerFooterRelPattern.ReplaceAll(rels, nil), 0o644); err != nil {
+			return NewHeaderFooterError("failed to write relationships", err)
+		}
+	}
+
+	// Delete the parts, their own relationships and content type overrides.
+	ctPath := filepath.Join(u.tempDir, "[Content_Types].xml")
+	contentTypes, err := os.ReadFile(ctPath)
+	if err != nil {
+		return NewHeaderFooterError("failed to read content types", err)
+	}
+	var media []string
+	for _, target := range targets {
+		target = strings.TrimPrefix(target, "/word/")
+		partPath := filepath.Join(u.tempDir, "word", filepath.FromSlash(target))
+		relsDir, partFile := filepath.Split(partPath)
+		if partRels, err := os.ReadFile(filepath.Join(relsDir, "_rels", partFile+".rels")); err == nil {
+			for _, m := range mediaRelPattern.FindAllSubmatch(partRels, -1) {
+				media = append(media, string(m[1]))
+			}
+		}
+		for _, path := range []string{partPath, filepath.Join(relsDir, "_rels", partFile+".rels")} {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return NewHeaderFooterError("failed to delete part", err)
+			}
+		}
+
+		override := regexp.MustCompile(`<Override\s[^>]*PartName="/word/` + regexp.QuoteMeta(target) + `"[^>]*/>`)
+		contentTypes = override.ReplaceAll(contentTypes, nil)
+	}
+	if err := atomicWriteFile(ctPath, contentTypes, 0o644); err != nil {
+		return NewHeaderFooterError("failed to write content types", err)
+	}
+	if err := u.removeUnreferencedMedia(media); err != nil {
+		return NewHeaderFooterError("failed to delete media", err)
+	}
+
+	// Odd/even headers are a document-wide setting; only touch an existing settings part.
+	if _, err := os.Stat(filepath.Join(u.tempDir, "word", "settings.xml")); err == nil {
+		if err := u.setSettingsElement("evenAndOddHeaders", "", nil); err != nil {
+			return NewHeaderFooterError("failed to clear evenAndOddHeaders", err)
+		}
+	}
+
+	return nil
+}
+
+// removeUnreferencedMedia deletes the named files in word/media that no
+// remaining relationships part refers to
+func (u *Updater) removeUnreferencedMedia(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	referenced := make(map[string]bool)
+	err := filepath.WalkDir(u.tempDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".rels") {
+			return err
+		}
+		rels, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range mediaRelPattern.FindAllSubmatch(rels, -1) {
+			referenced[string(m[1])] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan relationships: %w", err)
+	}
+
+	for _, name := range names {
+		if referenced[name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(u.tempDir, "word", "media", name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package godocx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetHeadersAndFooters(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	if err := u.SetHeader(HeaderFooterContent{LeftText: "Acme", RightText: "Draft"}, HeaderOptions{Type: HeaderDefault, Section: 1}); err != nil {
+		t.Fatalf("SetHeader: %v", err)
+	}
+	if err := u.SetHeader(HeaderFooterContent{CenterText: "Title page"}, HeaderOptions{Type: HeaderFirst, Section: 1, DifferentFirst: true}); err != nil {
+		t.Fatalf("SetHeader first: %v", err)
+	}
+	if err := u.SetFooter(HeaderFooterContent{PageNumber: true, PageNumberFormat: "Page X of Y"}, FooterOptions{Type: FooterDefault, Section: 2}); err != nil {
+		t.Fatalf("SetFooter: %v", err)
+	}
+
+	headers, err := u.GetHeaders()
+	if err != nil {
+		t.Fatalf("GetHeaders: %v", err)
+	}
+	if len(headers) != 4 {
+		t.Fatalf("expected default+first headers for 2 sections, got %+v", headers)
+	}
+	first := headers[0]
+	if first.Section != 1 || first.Type != "default" || first.Inherited || first.Text != "Acme\nDraft" {
+		t.Errorf("unexpected section 1 default header %+v", first)
+	}
+	if len(first.Blocks) != 1 || first.Blocks[0].Kind != HeaderFooterBlockTable || first.Blocks[0].Rows[0][2] != "Draft" {
+		t.Errorf("expected the three-column table, got %+v", first.Blocks)
+	}
+	if headers[1].Type != "first" || headers[1].Text != "Title page" {
+		t.Errorf("unexpected first-page header %+v", headers[1])
+	}
+	if !headers[2].Inherited || headers[2].Section != 2 || headers[2].Target != first.Target {
+		t.Errorf("expected section 2 to inherit header, got %+v", headers[2])
+	}
+
+	footers, err := u.GetFooters()
+	if err != nil {
+		t.Fatalf("GetFooters: %v", err)
+	}
+	if len(footers) != 1 || footers[0].Section != 2 {
+		t.Fatalf("expected one footer in section 2, got %+v", footers)
+	}
+	if got := footers[0].Blocks[0].Fields; !reflect.DeepEqual(got, []string{"PAGE", "NUMPAGES"}) {
+		t.Errorf("expected PAGE and NUMPAGES fields, got %v", got)
+	}
+	if footers[0].Blocks[0].Kind != HeaderFooterBlockParagraph || footers[0].Text != "Page 1 of 1" {
+		t.Errorf("unexpected footer %+v", footers[0])
+	}
+}
+
+func TestClearHeader_StopsInheritance(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	logo := filepath.Join(t.TempDir(), "logo.png")
+	writeTestPNG(t, logo, 20, 20)
+	if err := u.SetHeader(HeaderFooterContent{Blocks: []HeaderFooterBlock{{Image: &HeaderFooterImage{Path: logo}}}}, HeaderOptions{Type: HeaderDefault, Section: 1}); err != nil {
+		t.Fatalf("SetHeader: %v", err)
+	}
+	if err := u.ClearHeader(2, HeaderDefault); err != nil {
+		t.Fatalf("ClearHeader: %v", err)
+	}
+
+	headers, err := u.GetHeaders()
+	if err != nil {
+		t.Fatalf("GetHeaders: %v", err)
+	}
+	if len(headers) != 2 || headers[1].Inherited || headers[1].Text != "" || headers[1].Target == headers[0].Target {
+		t.Fatalf("expected section 2 to own an empty header, got %+v", headers)
+	}
+	if headers[0].Blocks[0].Drawings != 1 {
+		t.Errorf("section 1 header must keep its logo, got %+v", headers[0].Blocks)
+	}
+
+	// Clearing section 1's own part also drops the logo's relationships.
+	if err := u.ClearHeader(1, HeaderDefault); err != nil {
+		t.Fatalf("ClearHeader: %v", err)
+	}
+	if part := readWordPart(t, u, headers[0].Target); !strings.Contains(part, "<w:p/></w:hdr>") {
+		t.Errorf("expected empty header part, got %s", part)
+	}
+	if _, err := os.Stat(filepath.Join(u.TempDir(), "word", "_rels", headers[0].Target+".rels")); !os.IsNotExist(err) {
+		t.Error("stale header relationships must be removed")
+	}
+}
+
+func TestRemoveAllHeadersFooters(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	if err := u.SetHeader(HeaderFooterContent{CenterText: "Odd"}, HeaderOptions{Type: HeaderDefault, DifferentOddEven: true}); err != nil {
+		t.Fatalf("SetHeader: %v", err)
+	}
+	if err := u.SetHeader(HeaderFooterContent{CenterText: "Cover"}, HeaderOptions{Type: HeaderFirst, Section: 1, DifferentFirst: true}); err != nil {
+		t.Fatalf("SetHeader first: %v", err)
+	}
+	if err := u.SetFooter(HeaderFooterContent{PageNumber: true}, FooterOptions{Type: FooterDefault, Section: 2}); err != nil {
+		t.Fatalf("SetFooter: %v", err)
+	}
+
+	if err := u.RemoveAllHeadersFooters(); err != nil {
+		t.Fatalf("RemoveAllHeadersFooters: %v", err)
+	}
+
+	doc := readDocXML(t, u)
+	for _, unwanted := range []string{"headerReference", "footerReference", "titlePg"} {
+		if strings.Contains(doc, unwanted) {
+			t.Errorf("document still contains %s", unwanted)
+		}
+	}
+	if rels := readWordPart(t, u, "_rels/document.xml.rels"); strings.Contains(rels, "/header\"") || strings.Contains(rels, "/footer\"") {
+		t.Errorf("header/footer relationships left behind: %s", rels)
+	}
+	ct, err := os.ReadFile(filepath.Join(u.TempDir(), "[Content_Types].xml"))
+	if err != nil {
+		t.Fatalf("read content types: %v", err)
+	}
+	if strings.Contains(string(ct), "/word/header") || strings.Contains(string(ct), "/word/footer") {
+		t.Errorf("header/footer content types left behind: %s", ct)
+	}
+	if parts, _ := filepath.Glob(filepath.Join(u.TempDir(), "word", "header*.xml")); len(parts) != 0 {
+		t.Errorf("header parts left behind: %v", parts)
+	}
+	if strings.Contains(readWordPart(t, u, "settings.xml"), "evenAndOddHeaders") {
+		t.Error("evenAndOddHeaders must be removed from settings.xml")
+	}
+
+	headers, err := u.GetHeaders()
+	if err != nil || len(headers) != 0 {
+		t.Errorf("expected no headers, got %+v (%v)", headers, err)
+	}
+}
+
+func TestRemoveAllHeadersFooters_RemovesUnreferencedMedia(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Body</w:t></w:r></w:p><w:sectPr/>`))
+
+	logo := filepath.Join(t.TempDir(), "logo.png")
+	writeTestPNG(t, logo, 200, 50)
+	if err := u.InsertImage(ImageOptions{Path: logo, Width: 100, Position: PositionEnd}); err != nil {
+		t.Fatalf("InsertImage: %v", err)
+	}
+	bodyMedia, _ := filepath.Glob(filepath.Join(u.TempDir(), "word", "media", "*"))
+	if len(bodyMedia) != 1 {
+		t.Fatalf("expected 1 media file for the body image, got %v", bodyMedia)
+	}
+	content := HeaderFooterContent{Blocks: []HeaderFooterBlock{{Image: &HeaderFooterImage{Path: logo, Width: 100}}}}
+	if err := u.SetHeader(content, DefaultHeaderOptions()); err != nil {
+		t.Fatalf("SetHeader: %v", err)
+	}
+	if media, _ := filepath.Glob(filepath.Join(u.TempDir(), "word", "media", "*")); len(media) != 2 {
+		t.Fatalf("expected a second media file for the header logo, got %v", media)
+	}
+
+	if err := u.RemoveAllHeadersFooters(); err != nil {
+		t.Fatalf("RemoveAllHeadersFooters: %v", err)
+	}
+	media, _ := filepath.Glob(filepath.Join(u.TempDir(), "word", "media", "*"))
+	if !reflect.DeepEqual(media, bodyMedia) {
+		t.Errorf("expected only the body image to be kept, got %v", media)
+	}
+}
@@ -0,0 +1,132 @@
+package godocx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const threeSectionBody = `<w:p><w:r><w:t>Cover</w:t></w:r></w:p>` +
+	`<w:p><w:pPr><w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr></w:pPr></w:p>` +
+	`<w:p><w:r><w:t>Preface</w:t></w:r></w:p>` +
+	`<w:p><w:pPr><w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr></w:pPr></w:p>` +
+	`<w:p><w:r><w:t>Chapter 1</w:t></w:r></w:p>` +
+	`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`
+
+func TestSetHeaderFooter_PerSection(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, threeSectionBody))
+
+	if err := u.UpdateSection(2, SectionOptions{PageNumber: &PageNumberOptions{Start: 1, Format: PageNumLowerRoman}}); err != nil {
+		t.Fatalf("UpdateSection: %v", err)
+	}
+	if err := u.SetFooter(HeaderFooterContent{PageNumber: true}, FooterOptions{Type: FooterDefault, Section: 2}); err != nil {
+		t.Fatalf("SetFooter: %v", err)
+	}
+	if err := u.SetHeader(HeaderFooterContent{CenterText: "Chapter 1"}, HeaderOptions{Type: HeaderDefault, Section: 3}); err != nil {
+		t.Fatalf("SetHeader: %v", err)
+	}
+
+	sections, err := u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	if len(sections[0].Headers) != 0 || len(sections[0].Footers) != 0 {
+		t.Errorf("cover section must have no header/footer, got %+v", sections[0])
+	}
+	if len(sections[1].Footers) != 1 || sections[1].Footers[0].Inherited || len(sections[1].Headers) != 0 {
+		t.Errorf("unexpected front matter parts %+v", sections[1])
+	}
+	body := sections[2]
+	if len(body.Headers) != 1 || body.Headers[0].Inherited {
+		t.Errorf("expected own body header, got %+v", body.Headers)
+	}
+	if len(body.Footers) != 1 || !body.Footers[0].Inherited || body.Footers[0].Target != sections[1].Footers[0].Target {
+		t.Errorf("expected body footer inherited from front matter, got %+v", body.Footers)
+	}
+
+	// Setting the same section again reuses its own part.
+	headerTarget := body.Headers[0].Target
+	if err := u.SetHeader(HeaderFooterContent{CenterText: "Chapter 1 (rev)"}, HeaderOptions{Type: HeaderDefault, Section: 3}); err != nil {
+		t.Fatalf("SetHeader again: %v", err)
+	}
+	sections, _ = u.Sections()
+	if sections[2].Headers[0].Target != headerTarget {
+		t.Errorf("expected part %s reused, got %s", headerTarget, sections[2].Headers[0].Target)
+	}
+	if _, err := os.Stat(filepath.Join(u.TempDir(), "word", "header2.xml")); !os.IsNotExist(err) {
+		t.Error("no extra header part expected")
+	}
+}
+
+func TestSetHeader_SharedPartNotOverwritten(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, threeSectionBody))
+
+	// Legacy call puts the same header on every section.
+	if err := u.SetHeader(HeaderFooterContent{CenterText: "All"}, HeaderOptions{Type: HeaderDefault}); err != nil {
+		t.Fatalf("SetHeader: %v", err)
+	}
+	if err := u.SetHeader(HeaderFooterContent{CenterText: "Only two"}, HeaderOptions{Type: HeaderDefault, Section: 2}); err != nil {
+		t.Fatalf("SetHeader section 2: %v", err)
+	}
+
+	sections, err := u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	if sections[1].Headers[0].Target == sections[0].Headers[0].Target {
+		t.Fatal("section 2 must get its own header part")
+	}
+	if sections[2].Headers[0].Target != sections[0].Headers[0].Target {
+		t.Error("section 3 must keep the shared header")
+	}
+	shared := readWordPart(t, u, sections[0].Headers[0].Target)
+	if !strings.Contains(shared, "All") {
+		t.Errorf("shared header overwritten: %s", shared)
+	}
+}
+
+func TestLinkHeaderFooterToPrevious(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, threeSectionBody))
+
+	if err := u.SetHeader(HeaderFooterContent{LeftText: "Front"}, HeaderOptions{Type: HeaderDefault, Section: 2}); err != nil {
+		t.Fatalf("SetHeader 2: %v", err)
+	}
+	if err := u.SetHeader(HeaderFooterContent{LeftText: "Body"}, HeaderOptions{Type: HeaderDefault, Section: 3}); err != nil {
+		t.Fatalf("SetHeader 3: %v", err)
+	}
+	if err := u.LinkHeaderToPrevious(3, HeaderDefault); err != nil {
+		t.Fatalf("LinkHeaderToPrevious: %v", err)
+	}
+
+	sections, err := u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	if len(sections[2].Headers) != 1 || !sections[2].Headers[0].Inherited || sections[2].Headers[0].RelID != sections[1].Headers[0].RelID {
+		t.Errorf("expected section 3 to inherit section 2 header, got %+v", sections[2].Headers)
+	}
+	if err := u.LinkFooterToPrevious(4, FooterDefault); err == nil {
+		t.Error("expected error for out-of-range section")
+	}
+}
+
+func TestSetHeader_DifferentOddEvenGoesToSettings(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, threeSectionBody))
+
+	opts := HeaderOptions{Type: HeaderEven, DifferentFirst: true, DifferentOddEven: true, Section: 3}
+	if err := u.SetHeader(HeaderFooterContent{CenterText: "Even"}, opts); err != nil {
+		t.Fatalf("SetHeader: %v", err)
+	}
+
+	if !strings.Contains(readWordPart(t, u, "settings.xml"), "<w:evenAndOddHeaders/>") {
+		t.Error("expected evenAndOddHeaders in settings.xml")
+	}
+	docXML := readDocXML(t, u)
+	if strings.Contains(docXML, "evenAndOddHeaders") {
+		t.Error("evenAndOddHeaders must not be written to sectPr")
+	}
+	if strings.Count(docXML, "<w:titlePg/>") != 1 {
+		t.Error("expected titlePg only on the targeted section")
+	}
+}
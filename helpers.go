@@ -80,7 +80,9 @@ func normalizeHexColor(color string) string {
 	return strings.ToUpper(c)
 }
 
-// getNextDocPrId finds the next available docPr ID in the document.
+// getNextDocPrId finds the next available docPr ID in the document. Header and
+// footer parts are scanned too, since drawing IDs must be unique across the
+// whole package.
 func (u *Updater) getNextDocPrId() (int, error) {
 	docPath := filepath.Join(u.tempDir, "word", "document.xml")
 	raw, err := os.ReadFile(docPath)
@@ -88,6 +90,14 @@ func (u *Updater) getNextDocPrId() (int, error) {
 		return 0, fmt.Errorf("read document: %w", err)
 	}
 
+	parts, _ := filepath.Glob(filepath.Join(u.tempDir, "word", "header*.xml"))
+	footers, _ := filepath.Glob(filepath.Join(u.tempDir, "word", "footer*.xml"))
+	for _, part := range append(parts, footers...) {
+		if data, err := os.ReadFile(part); err == nil {
+			raw = append(raw, data...)
+		}
+	}
+
 	matches := docPrIDPattern.FindAllStringSubmatch(string(raw), -1)
 
 	maxId := 0
@@ -143,9 +143,30 @@ func (u *Updater) InsertInternalLink(text, bookmarkName string, opts HyperlinkOp
 
 // addHyperlinkRelationship adds a hyperlink relationship to document.xml.rels
 func (u *Updater) addHyperlinkRelationship(urlStr string) (string, error) {
-	relsPath := filepath.Join(u.tempDir, "word", "_rels", "document.xml.rels")
+	return u.addPartHyperlinkRelationship("document.xml", urlStr)
+}
+
+// addPartHyperlinkRelationship adds a hyperlink relationship to the .rels file
+// of the given part under word/ (e.g. "comments.xml"), creating the .rels file
+// if the part has no relationships yet.
+func (u *Updater) addPartHyperlinkRelationship(partName, urlStr string) (string, error) {
+	return u.addPartRelationship(partName, OfficeDocumentNS+"/hyperlink", urlStr, true)
+}
+
+// addPartRelationship adds a relationship of relType to the .rels file of the
+// given part under word/, creating the .rels file if the part has no
+// relationships yet. External targets get TargetMode="External".
+func (u *Updater) addPartRelationship(partName, relType, target string, external bool) (string, error) {
+	relsPath := filepath.Join(u.tempDir, "word", "_rels", partName+".rels")
 
 	raw, err := os.ReadFile(relsPath)
+	if os.IsNotExist(err) && partName != "document.xml" {
+		raw = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<Relationships xmlns="` + RelationshipsNS + `"></Relationships>`)
+		if err = atomicWriteFile(relsPath, raw, 0o644); err != nil {
+			return "", fmt.Errorf("create relationships: %w", err)
+		}
+	}
 	if err != nil {
 		return "", fmt.Errorf("read relationships: %w", err)
 	}
@@ -158,11 +179,16 @@ func (u *Updater) addHyperlinkRelationship(urlStr string) (string, error) {
 		return "", fmt.Errorf("find next relationship id: %w", err)
 	}
 
-	// Create hyperlink relationship
+	targetMode := ""
+	if external {
+		targetMode = ` TargetMode="External"`
+	}
 	newRel := fmt.Sprintf(
-		`<Relationship Id="%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="%s" TargetMode="External"/>`,
+		`<Relationship Id="%s" Type="%s" Target="%s"%s/>`,
 		relID,
-		xmlEscape(urlStr),
+		relType,
+		xmlEscape(target),
+		targetMode,
 	)
 
 	// Insert before closing </Relationships>
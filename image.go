@@ -234,6 +234,12 @@ func (u *Updater) generateImageDrawingXML(imageIndex int, relId string, dims Ima
 		return nil, fmt.Errorf("get next docPr id: %w", err)
 	}
 
+	return generateImageDrawingXMLWithID(docPrId, imageIndex, relId, dims, altText), nil
+}
+
+// generateImageDrawingXMLWithID creates the inline drawing XML for an image
+// using the given docPr ID
+func generateImageDrawingXMLWithID(docPrId, imageIndex int, relId string, dims ImageDimensions, altText string) []byte {
 	// Convert dimensions to EMUs
 	widthEMU := convertPixelsToEMUs(dims.Width)
 	heightEMU := convertPixelsToEMUs(dims.Height)
@@ -254,7 +260,7 @@ func (u *Updater) generateImageDrawingXML(imageIndex int, relId string, dims Ima
 		docPrId, imageIndex, xmlEscape(altText),
 		docPrId, imageIndex, xmlEscape(altText),
 		relId,
-		widthEMU, heightEMU), nil
+		widthEMU, heightEMU)
 }
 
 // addImageRelationship adds a relationship for the image to document.xml.rels
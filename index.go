@@ -0,0 +1,608 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IndexEntryOptions defines options for marking index entries
+type IndexEntryOptions struct {
+	// AllOccurrences marks every occurrence of the text instead of the first
+	AllOccurrences bool
+
+	// Bold and Italic format the entry's page numbers
+	Bold   bool
+	Italic bool
+
+	// CrossReference replaces the page number with text such as
+	// "See Widgets"
+	CrossReference string
+
+	// PageRangeBookmark lists the pages spanned by this bookmark instead of
+	// the page of the marked text
+	PageRangeBookmark string
+}
+
+// IndexOptions defines options for a back-of-book index
+type IndexOptions struct {
+	// Title for the index. Empty means no title paragraph.
+	Title string
+
+	// Columns is the number of columns, 1-4 (default: 2)
+	Columns int
+
+	// LetterHeadings groups entries under their first letter
+	LetterHeadings bool
+
+	// RightAlignPageNumbers places page numbers at the right margin of the
+	// column after a tab, instead of after a comma
+	RightAlignPageNumbers bool
+
+	// Position where to insert the index
+	Position InsertPosition
+
+	// Anchor text for position-based insertion
+	Anchor string
+
+	// Prebuild fills in the index from the document's XE fields instead of
+	// placeholder text, so the index reads correctly without Word.
+	// See RebuildIndex.
+	Prebuild bool
+}
+
+// DefaultIndexOptions returns default index options: two columns with letter
+// headings and right-aligned page numbers at the end of the document
+func DefaultIndexOptions() IndexOptions {
+	return IndexOptions{
+		Title:                 "Index",
+		Columns:               2,
+		LetterHeadings:        true,
+		RightAlignPageNumbers: true,
+		Position:              PositionEnd,
+	}
+}
+
+// MarkIndexEntry marks text in the document as an index entry by inserting an
+// XE field after it. entry is the index heading (default: text) and subentry,
+// if set, the second-level heading below it. Only the first occurrence is
+// marked unless opts.AllOccurrences is set; text in a TOC or index is skipped.
+func (u *Updater) MarkIndexEntry(text, entry, subentry string, opts IndexEntryOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if text == "" {
+		return NewValidationError("text", "index entry text cannot be empty")
+	}
+	if entry == "" {
+		entry = text
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	xe := generateXEFieldXML(entry, subentry, opts)
+	updated, count := markIndexEntries(raw, text, string(xe), opts.AllOccurrences)
+	if count == 0 {
+		return fmt.Errorf("text %q not found in document", text)
+	}
+
+	if err := atomicWriteFile(docPath, updated, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+	return nil
+}
+
+// InsertIndex inserts an INDEX field into the document. Word builds the index
+// from the XE fields when the field is updated, unless opts.Prebuild is set.
+func (u *Updater) InsertIndex(opts IndexOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if opts.Columns == 0 {
+		opts.Columns = 2
+	}
+	if opts.Columns < 1 || opts.Columns > 4 {
+		return NewValidationError("Columns", "index columns must be between 1 and 4")
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	tocOpts := TOCOptions{
+		Position: opts.Position,
+		Anchor:   opts.Anchor,
+	}
+	updated, err := insertTOCAtPosition(raw, generateIndexXML(opts), tocOpts)
+	if err != nil {
+		return fmt.Errorf("insert index: %w", err)
+	}
+	if opts.Prebuild {
+		index, err := insertedFieldIndex(raw, "INDEX", tocOpts)
+		if err != nil {
+			return fmt.Errorf("prebuild index: %w", err)
+		}
+		if updated, err = prebuildIndex(updated, index); err != nil {
+			return fmt.Errorf("prebuild index: %w", err)
+		}
+		if err := u.ensureFieldResultStyles(updated); err != nil {
+			return err
+		}
+	}
+
+	if err := atomicWriteFile(docPath, updated, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+	return nil
+}
+
+// RebuildIndex regenerates every index in the document from its XE fields.
+// Entries are sorted alphabetically with subentries indented below them, and
+// page numbers are estimated from explicit page and section breaks. An index
+// with several columns is placed in its own continuous section.
+func (u *Updater) RebuildIndex() error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	for i := range namedFields(raw, "INDEX") {
+		if raw, err = prebuildIndex(raw, i); err != nil {
+			return err
+		}
+	}
+	if err := u.ensureFieldResultStyles(raw); err != nil {
+		return err
+	}
+
+	if err := atomicWriteFile(docPath, raw, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+	return nil
+}
+
+// generateXEFieldXML creates an XE field. XE fields have no result.
+func generateXEFieldXML(entry, subentry string, opts IndexEntryOptions) []byte {
+	text := escapeIndexEntryText(entry)
+	if subentry != "" {
+		text += ":" + escapeIndexEntryText(subentry)
+	}
+	instr := fmt.Sprintf(`XE "%s"`, text)
+	if opts.PageRangeBookmark != "" {
+		instr += fmt.Sprintf(` \r "%s"`, opts.PageRangeBookmark)
+	}
+	if opts.Bold {
+		instr += ` \b`
+	}
+	if opts.Italic {
+		instr += ` \i`
+	}
+	if opts.CrossReference != "" {
+		instr += fmt.Sprintf(` \t "%s"`, fieldQuoteEscaper.Replace(opts.CrossReference))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<w:r><w:fldChar w:fldCharType="begin"/></w:r>`)
+	fmt.Fprintf(&buf, `<w:r><w:instrText xml:space="preserve"> %s </w:instrText></w:r>`, xmlEscape(instr))
+	buf.WriteString(fieldEndRunXML)
+	return buf.Bytes()
+}
+
+// escapeIndexEntryText escapes an XE entry for its quoted field argument,
+// then escapes its level separators
+func escapeIndexEntryText(text string) string {
+	return strings.ReplaceAll(fieldQuoteEscaper.Replace(text), ":", `\:`)
+}
+
+// splitIndexEntry splits an XE entry into its levels at unescaped colons
+func splitIndexEntry(text string) []string {
+	var levels []string
+	var level strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(level.String()); s != "" {
+			levels = append(levels, s)
+		}
+		level.Reset()
+	}
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && i+1 < len(text) && text[i+1] == ':':
+			level.WriteByte(':')
+			i++
+		case text[i] == ':':
+			flush()
+		default:
+			level.WriteByte(text[i])
+		}
+	}
+	flush()
+	return levels
+}
+
+// markIndexEntries inserts xe after the first occurrence of text in the body,
+// or after every occurrence when all is set. It returns the number of fields
+// inserted.
+func markIndexEntries(raw []byte, text, xe string, all bool) ([]byte, int) {
+	// Existing TOC and index entries are generated text, not content.
+	var skip []crossRefSpan
+	for _, name := range []string{"TOC", "INDEX"} {
+		for i := range namedFields(raw, name) {
+			if _, start, end, err := fieldParagraphs(raw, name, i); err == nil {
+				skip = append(skip, crossRefSpan{start: start, end: end})
+			}
+		}
+	}
+
+	type match struct {
+		para    crossRefSpan
+		offsets []int
+	}
+	var matches []match
+paragraphs:
+	for _, para := range bodyParagraphs(raw) {
+		for _, s := range skip {
+			if para.start >= s.start && para.start < s.end {
+				continue paragraphs
+			}
+		}
+		_, plain := paragraphTextSegments(raw[para.start:para.end])
+		var offsets []int
+		for pos := 0; ; {
+			idx := strings.Index(plain[pos:], text)
+			if idx == -1 {
+				break
+			}
+			pos += idx + len(text)
+			offsets = append(offsets, pos)
+			if !all {
+				break
+			}
+		}
+		if len(offsets) > 0 {
+			matches = append(matches, match{para: para, offsets: offsets})
+			if !all {
+				break
+			}
+		}
+	}
+
+	// XE fields add no run text, so the offsets stay valid as fields are
+	// inserted; paragraphs are rewritten back to front.
+	count := 0
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		para := raw[m.para.start:m.para.end]
+		for j := len(m.offsets) - 1; j >= 0; j-- {
+			if updated, ok := insertAtTextOffset(para, m.offsets[j], true, xe); ok {
+				para = updated
+				count++
+			}
+		}
+		raw = spliceBytes(raw, m.para.start, m.para.end, para)
+	}
+	return raw, count
+}
+
+// indexNode is an index heading with its page numbers, cross-references and
+// subentries
+type indexNode struct {
+	text      string
+	locators  []indexLocator
+	crossRefs []string
+	children  []*indexNode
+}
+
+// indexLocator is a page or page range of an index entry
+type indexLocator struct {
+	from, to     int
+	bold, italic bool
+}
+
+// child returns the subentry with the given text, adding it if needed
+func (n *indexNode) child(text string) *indexNode {
+	for _, c := range n.children {
+		if c.text == text {
+			return c
+		}
+	}
+	c := &indexNode{text: text}
+	n.children = append(n.children, c)
+	return c
+}
+
+// sortedChildren returns the subentries in alphabetical order
+func (n *indexNode) sortedChildren() []*indexNode {
+	children := append([]*indexNode{}, n.children...)
+	sort.SliceStable(children, func(i, j int) bool {
+		a, b := strings.ToLower(children[i].text), strings.ToLower(children[j].text)
+		if a != b {
+			return a < b
+		}
+		return children[i].text < children[j].text
+	})
+	return children
+}
+
+// collectIndexEntries builds the index tree from the document's XE fields,
+// skipping the index itself at raw[skipStart:skipEnd]
+func collectIndexEntries(raw []byte, skipStart, skipEnd int) *indexNode {
+	root := &indexNode{}
+	for _, f := range parseDocumentFields(raw) {
+		if f.start >= skipStart && f.start < skipEnd {
+			continue
+		}
+		code := parseFieldCode(f.instr.String())
+		if code.name != "XE" || len(code.args) == 0 {
+			continue
+		}
+		node := root
+		for _, level := range splitIndexEntry(code.args[0]) {
+			node = node.child(level)
+		}
+		if node == root {
+			continue
+		}
+
+		if ref := code.switches["t"]; ref != "" {
+			if !slices.Contains(node.crossRefs, ref) {
+				node.crossRefs = append(node.crossRefs, ref)
+			}
+			continue
+		}
+		page := estimatePageNumber(raw, f.start)
+		loc := indexLocator{from: page, to: page}
+		if name := code.switches["r"]; name != "" {
+			if start, endTag, _, ok := bookmarkRange(raw, name); ok {
+				loc.from, loc.to = estimatePageNumber(raw, start), estimatePageNumber(raw, endTag)
+			}
+		}
+		_, loc.bold = code.switches["b"]
+		_, loc.italic = code.switches["i"]
+		node.locators = append(node.locators, loc)
+	}
+	return root
+}
+
+// mergedLocators sorts locators by page and merges duplicates
+func mergedLocators(locators []indexLocator) []indexLocator {
+	sorted := append([]indexLocator{}, locators...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].from != sorted[j].from {
+			return sorted[i].from < sorted[j].from
+		}
+		return sorted[i].to < sorted[j].to
+	})
+	var merged []indexLocator
+	for _, loc := range sorted {
+		if n := len(merged); n > 0 && merged[n-1].from == loc.from && merged[n-1].to == loc.to {
+			merged[n-1].bold = merged[n-1].bold || loc.bold
+			merged[n-1].italic = merged[n-1].italic || loc.italic
+			continue
+		}
+		merged = append(merged, loc)
+	}
+	return merged
+}
+
+// prebuildIndex replaces the result of the index-th INDEX field with entries
+// built from the document's XE fields
+func prebuildIndex(raw []byte, index int) ([]byte, error) {
+	f, start, end, err := fieldParagraphs(raw, "INDEX", index)
+	if err != nil {
+		return nil, err
+	}
+	code := parseFieldCode(f.instr.String())
+	root := collectIndexEntries(raw, start, end)
+
+	columns := atoiOrZero(code.switches["c"])
+	if columns < 1 || columns > 4 {
+		columns = 1
+	}
+	width := tocTextWidth(raw, start)
+
+	// Several columns need a section of their own: the paragraph holding the
+	// field's begin ends the preceding section, and the last paragraph ends
+	// the index's continuous, multi-column section.
+	var before, within []byte
+	if columns > 1 && len(root.children) > 0 {
+		for _, r := range findSectionRanges(raw) {
+			if r.end >= start {
+				before = append([]byte{}, raw[r.start:r.end]...)
+				break
+			}
+		}
+		if before != nil {
+			within = setSectPrChild(append([]byte{}, before...), "type", `<w:type w:val="continuous"/>`)
+			within = setSectPrChild(within, "cols", generateSectionColumnsXML(SectionColumns{Count: columns, Space: 720}))
+			width = (width - 720*(columns-1)) / columns
+		}
+	}
+
+	generated := generateIndexEntriesXML(f.instr.String(), code, root, width, before, within)
+	raw = spliceBytes(raw, start, end, generated)
+
+	if within != nil {
+		// The text after the index continues on the same page.
+		for _, r := range findSectionRanges(raw) {
+			if r.start >= start+len(generated) {
+				sectPr := setSectPrChild(append([]byte{}, raw[r.start:r.end]...), "type", `<w:type w:val="continuous"/>`)
+				raw = spliceBytes(raw, r.start, r.end, sectPr)
+				break
+			}
+		}
+	}
+	return raw, nil
+}
+
+// indexParagraph is a generated index paragraph
+type indexParagraph struct {
+	pPr     string
+	content bytes.Buffer
+}
+
+// generateIndexEntriesXML writes the paragraphs of a prebuilt INDEX field.
+// The field begins in the first paragraph and ends in the last one.
+func generateIndexEntriesXML(instr string, code fieldCode, root *indexNode, width int, before, within []byte) []byte {
+	var paras []*indexParagraph
+	add := func(pPr string) *indexParagraph {
+		p := &indexParagraph{pPr: pPr}
+		paras = append(paras, p)
+		return p
+	}
+
+	if len(root.children) == 0 {
+		add("").content.WriteString("<w:r><w:t>No index entries found.</w:t></w:r>")
+	}
+	if before != nil {
+		add("<w:pPr>" + string(before) + "</w:pPr>")
+	}
+
+	separator, rightAlign := ", ", false
+	if sep, ok := code.switches["e"]; ok {
+		separator, rightAlign = sep, strings.Contains(sep, "\t")
+	}
+	headingFormat, letterHeadings := code.switches["h"]
+
+	var writeEntry func(n *indexNode, level int)
+	writeEntry = func(n *indexNode, level int) {
+		var pPr strings.Builder
+		fmt.Fprintf(&pPr, `<w:pPr><w:pStyle w:val="Index%d"/>`, level)
+		if rightAlign {
+			fmt.Fprintf(&pPr, `<w:tabs><w:tab w:val="right" w:leader="dot" w:pos="%d"/></w:tabs>`, width)
+		}
+		fmt.Fprintf(&pPr, `<w:ind w:left="%d" w:hanging="220"/></w:pPr>`, 220*level)
+		p := add(pPr.String())
+
+		p.content.WriteString("<w:r>")
+		writeRunTextWithControls(&p.content, n.text)
+		p.content.WriteString("</w:r>")
+		for i, loc := range mergedLocators(n.locators) {
+			p.content.WriteString("<w:r>")
+			if i == 0 {
+				writeRunTextWithControls(&p.content, separator)
+			} else {
+				writeRunTextWithControls(&p.content, ", ")
+			}
+			p.content.WriteString("</w:r>")
+			text := strconv.Itoa(loc.from)
+			if loc.to > loc.from {
+				text += "–" + strconv.Itoa(loc.to)
+			}
+			p.content.WriteString("<w:r>")
+			writeRunPropertiesXML(&p.content, RunOptions{Bold: loc.bold, Italic: loc.italic})
+			writeRunTextWithControls(&p.content, text)
+			p.content.WriteString("</w:r>")
+		}
+		for _, ref := range n.crossRefs {
+			p.content.WriteString("<w:r>")
+			writeRunTextWithControls(&p.content, ". "+ref)
+			p.content.WriteString("</w:r>")
+		}
+
+		for _, c := range n.sortedChildren() {
+			writeEntry(c, level+1)
+		}
+	}
+
+	lastGroup := ""
+	for i, n := range root.sortedChildren() {
+		if letterHeadings {
+			group := indexGroup(n.text)
+			if i == 0 || group != lastGroup {
+				switch {
+				case headingFormat != "":
+					p := add(`<w:pPr><w:pStyle w:val="IndexHeading"/><w:keepNext/></w:pPr>`)
+					p.content.WriteString("<w:r>")
+					writeRunPropertiesXML(&p.content, RunOptions{Bold: true})
+					writeRunTextWithControls(&p.content, strings.Replace(headingFormat, "A", group, 1))
+					p.content.WriteString("</w:r>")
+				case i > 0:
+					// An empty \h switch separates the groups with a blank line.
+					add("")
+				}
+			}
+			lastGroup = group
+		}
+		writeEntry(n, 1)
+	}
+
+	if within != nil {
+		add("<w:pPr>" + string(within) + "</w:pPr>")
+	}
+
+	var buf bytes.Buffer
+	for i, p := range paras {
+		buf.WriteString("<w:p>")
+		buf.WriteString(p.pPr)
+		if i == 0 {
+			writeFieldBeginXML(&buf, instr)
+		}
+		buf.Write(p.content.Bytes())
+		if i == len(paras)-1 {
+			buf.WriteString(fieldEndRunXML)
+		}
+		buf.WriteString("</w:p>")
+	}
+	return buf.Bytes()
+}
+
+// indexGroup returns the letter heading an entry is listed under: its
+// upper-cased first letter, or "Symbols" for entries starting otherwise
+func indexGroup(text string) string {
+	r, _ := utf8.DecodeRuneInString(text)
+	if !unicode.IsLetter(r) {
+		return "Symbols"
+	}
+	return string(unicode.ToUpper(r))
+}
+
+// generateIndexXML creates the XML for an INDEX field with placeholder text,
+// preceded by an optional title paragraph
+func generateIndexXML(opts IndexOptions) []byte {
+	var buf bytes.Buffer
+
+	if opts.Title != "" {
+		buf.Write(generateTOCTitleXML(opts.Title))
+	}
+
+	// INDEX field switches:
+	//   \c "2"  - number of columns
+	//   \h "A"  - letter headings
+	//   \e "→"  - separator between entry and page number (a tab here)
+	instr := fmt.Sprintf(`INDEX \c "%d"`, opts.Columns)
+	if opts.LetterHeadings {
+		instr += ` \h "A"`
+	}
+	if opts.RightAlignPageNumbers {
+		instr += ` \e "` + "\t" + `"`
+	}
+
+	buf.WriteString("<w:p>")
+	writeFieldBeginXML(&buf, instr)
+	buf.WriteString("<w:r>")
+	buf.WriteString("<w:rPr><w:i/></w:rPr>")
+	buf.WriteString("<w:t>Update this field to show the Index</w:t>")
+	buf.WriteString("</w:r>")
+	buf.WriteString(fieldEndRunXML)
+	buf.WriteString("</w:p>")
+
+	return buf.Bytes()
+}
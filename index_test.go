@@ -0,0 +1,174 @@
+package godocx
+
+import (
+	"strings"
+	"testing"
+)
+
+func indexFixtureBody() string {
+	return `<w:p><w:r><w:t>Widgets are small. Widgets are </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>cheap</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:br w:type="page"/></w:r><w:r><w:t>Gadgets need widgets and "quotes".</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Apples: a fruit.</w:t></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="720" w:footer="720" w:gutter="0"/></w:sectPr>`
+}
+
+func TestMarkIndexEntry(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, indexFixtureBody()))
+
+	if err := u.MarkIndexEntry("Widgets", "", "", IndexEntryOptions{AllOccurrences: true, Bold: true}); err != nil {
+		t.Fatalf("MarkIndexEntry: %v", err)
+	}
+	if err := u.MarkIndexEntry("are cheap", "Widgets", "price: low", IndexEntryOptions{}); err != nil {
+		t.Fatalf("MarkIndexEntry across runs: %v", err)
+	}
+	if err := u.MarkIndexEntry("Gadgets", `"Gadgets"`, "", IndexEntryOptions{CrossReference: "See Widgets"}); err != nil {
+		t.Fatalf("MarkIndexEntry cross-reference: %v", err)
+	}
+	if err := u.MarkIndexEntry("Missing", "", "", IndexEntryOptions{}); err == nil {
+		t.Error("expected an error for text that does not occur")
+	}
+	if err := u.MarkIndexEntry("", "", "", IndexEntryOptions{}); err == nil {
+		t.Error("expected a validation error for empty text")
+	}
+
+	doc := readDocXML(t, u)
+	if got := strings.Count(doc, `> XE &quot;Widgets&quot; \b </w:instrText>`); got != 2 {
+		t.Errorf("expected 2 case-sensitive XE fields for Widgets, got %d in %s", got, doc)
+	}
+	for _, want := range []string{
+		`<w:t xml:space="preserve">Widgets</w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r>`,
+		`> XE &quot;Widgets:price\: low&quot; </w:instrText>`,
+		`<w:r><w:rPr><w:b/></w:rPr><w:t>cheap</w:t></w:r><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> XE &quot;Widgets:price`,
+		`> XE &quot;\&quot;Gadgets\&quot;&quot; \t &quot;See Widgets&quot; </w:instrText>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %s in document, got %s", want, doc)
+		}
+	}
+	// XE fields have no visible text.
+	texts := bodyParagraphTexts(t, u)
+	if texts[0] != "Widgets are small. Widgets are cheap" {
+		t.Errorf("marking must not change the text, got %q", texts[0])
+	}
+}
+
+func TestInsertIndex_Prebuild(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, indexFixtureBody()))
+
+	marks := []struct {
+		text, entry, subentry string
+		opts                  IndexEntryOptions
+	}{
+		{"Widgets", "", "", IndexEntryOptions{AllOccurrences: true}},
+		{"widgets", "Widgets", "", IndexEntryOptions{Italic: true}},
+		{"cheap", "Widgets", "price", IndexEntryOptions{}},
+		{"Gadgets", "", "", IndexEntryOptions{CrossReference: "See Widgets"}},
+		{"Apples", "", "", IndexEntryOptions{}},
+		{"a fruit", "apples", "", IndexEntryOptions{}},
+	}
+	for _, m := range marks {
+		if err := u.MarkIndexEntry(m.text, m.entry, m.subentry, m.opts); err != nil {
+			t.Fatalf("MarkIndexEntry(%q): %v", m.text, err)
+		}
+	}
+
+	opts := DefaultIndexOptions()
+	opts.Prebuild = true
+	if err := u.InsertIndex(opts); err != nil {
+		t.Fatalf("InsertIndex: %v", err)
+	}
+
+	texts := bodyParagraphTexts(t, u)
+	// Page numbers follow a tab, which has no visible text.
+	want := []string{"Index", "", "A", "Apples2", "apples2", "G", "Gadgets. See Widgets", "W", "Widgets1, 2", "price1", ""}
+	if got := texts[3:]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got index paragraphs %q, want %q", got, want)
+	}
+
+	doc := readDocXML(t, u)
+	for _, want := range []string{
+		`<w:pStyle w:val="Index2"/><w:tabs><w:tab w:val="right" w:leader="dot" w:pos="4320"/></w:tabs><w:ind w:left="440" w:hanging="220"/>`,
+		`<w:r><w:t>Widgets</w:t></w:r><w:r><w:tab/></w:r><w:r><w:t>1</w:t></w:r><w:r><w:t xml:space="preserve">, </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>2</w:t></w:r>`,
+		`<w:type w:val="continuous"/><w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="720" w:footer="720" w:gutter="0"/><w:cols w:num="2" w:space="720"/></w:sectPr></w:pPr><w:r><w:fldChar w:fldCharType="end"/>`,
+		`<w:body>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %s in document, got %s", want, doc)
+		}
+	}
+	if got := strings.Count(doc, `<w:type w:val="continuous"/>`); got != 2 {
+		t.Errorf("expected the index section and the following one to be continuous, got %d", got)
+	}
+
+	// The fixture has no styles part, so the index styles are created.
+	styles := readWordPart(t, u, "styles.xml")
+	for _, want := range []string{
+		`w:styleId="Index1"><w:name w:val="index 1"/>`,
+		`w:styleId="Index2"><w:name w:val="index 2"/>`,
+		`w:styleId="IndexHeading"><w:name w:val="index heading"/><w:basedOn w:val="Normal"/><w:next w:val="Index1"/>`,
+	} {
+		if !strings.Contains(styles, want) {
+			t.Errorf("expected %s in styles, got %s", want, styles)
+		}
+	}
+
+	// Rebuilding leaves the index unchanged.
+	if err := u.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex: %v", err)
+	}
+	if rebuilt := readDocXML(t, u); rebuilt != doc {
+		t.Errorf("rebuilding changed the index:\n%s\nwant\n%s", rebuilt, doc)
+	}
+	if rebuilt := readWordPart(t, u, "styles.xml"); rebuilt != styles {
+		t.Errorf("rebuilding must not add the index styles again, got %s", rebuilt)
+	}
+}
+
+func TestInsertIndex_NoEntries(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Body</w:t></w:r></w:p>`))
+
+	if err := u.InsertIndex(IndexOptions{Columns: 5}); err == nil {
+		t.Error("expected a validation error for 5 columns")
+	}
+	if err := u.InsertIndex(IndexOptions{Position: PositionEnd, Prebuild: true}); err != nil {
+		t.Fatalf("InsertIndex: %v", err)
+	}
+	doc := readDocXML(t, u)
+	if !strings.Contains(doc, `> INDEX \c &quot;2&quot; </w:instrText>`) || !strings.Contains(doc, "No index entries found.") {
+		t.Errorf("expected an empty two-column index, got %s", doc)
+	}
+}
+
+func TestInsertIndex_PrebuildEscapedEntries(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, indexFixtureBody()))
+
+	if err := u.MarkIndexEntry("Widgets", `C:\`, "", IndexEntryOptions{}); err != nil {
+		t.Fatalf("MarkIndexEntry: %v", err)
+	}
+	if err := u.MarkIndexEntry("cheap", "Drive", `"quoted": yes`, IndexEntryOptions{}); err != nil {
+		t.Fatalf("MarkIndexEntry subentry: %v", err)
+	}
+	if err := u.MarkIndexEntry("Gadgets", `Tools\`, "", IndexEntryOptions{CrossReference: `See "C:\"`}); err != nil {
+		t.Fatalf("MarkIndexEntry cross-reference: %v", err)
+	}
+
+	doc := readDocXML(t, u)
+	for _, want := range []string{
+		`> XE &quot;C\:\\&quot; </w:instrText>`,
+		`> XE &quot;Drive:\&quot;quoted\&quot;\: yes&quot; </w:instrText>`,
+		`> XE &quot;Tools\\&quot; \t &quot;See \&quot;C:\\\&quot;&quot; </w:instrText>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %s in document, got %s", want, doc)
+		}
+	}
+
+	if err := u.InsertIndex(IndexOptions{Columns: 1, Position: PositionEnd, Prebuild: true}); err != nil {
+		t.Fatalf("InsertIndex: %v", err)
+	}
+	texts := bodyParagraphTexts(t, u)
+	want := []string{`C:\, 1`, "Drive", `"quoted": yes, 1`, `Tools\. See "C:\"`}
+	if got := texts[len(texts)-len(want):]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got index paragraphs %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package godocx
+
+import (
+	"fmt"
+)
+
+// LineNumberRestart defines when line numbering starts over
+type LineNumberRestart string
+
+const (
+	// LineNumberRestartNewPage restarts numbering on each page (default)
+	LineNumberRestartNewPage LineNumberRestart = "newPage"
+	// LineNumberRestartNewSection restarts numbering at the start of each section
+	LineNumberRestartNewSection LineNumberRestart = "newSection"
+	// LineNumberRestartContinuous numbers lines continuously across pages and sections
+	LineNumberRestartContinuous LineNumberRestart = "continuous"
+)
+
+// LineNumberOptions defines line numbering in the margin of a section
+type LineNumberOptions struct {
+	// CountBy shows a number on every Nth line (1 numbers every line).
+	// Zero turns line numbering off.
+	CountBy int
+
+	// Start is the number of the first line (default: 1)
+	Start int
+
+	// Distance is the gap between the numbers and the text in twips (default: automatic)
+	Distance int
+
+	// Restart defines when numbering starts over (default: each page)
+	Restart LineNumberRestart
+}
+
+// SetLineNumbering configures line numbering for every section of the
+// document. Use UpdateSection to number the lines of a single section.
+// CountBy zero removes line numbering.
+func (u *Updater) SetLineNumbering(opts LineNumberOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if err := validateLineNumberOptions(opts); err != nil {
+		return err
+	}
+
+	element := generateLineNumberTypeXML(opts)
+	return u.updateAllSectionProperties(func(sectPr []byte) ([]byte, error) {
+		return setSectPrChild(sectPr, "lnNumType", element), nil
+	})
+}
+
+// validateLineNumberOptions checks the line numbering settings
+func validateLineNumberOptions(opts LineNumberOptions) error {
+	if opts.CountBy < 0 || opts.CountBy > 100 {
+		return NewValidationError("CountBy", "line number interval must be between 0 and 100")
+	}
+	if opts.Start < 0 {
+		return NewValidationError("Start", "line number start must be >= 0")
+	}
+	if opts.Distance < 0 {
+		return NewValidationError("Distance", "line number distance must be >= 0")
+	}
+	switch opts.Restart {
+	case "", LineNumberRestartNewPage, LineNumberRestartNewSection, LineNumberRestartContinuous:
+	default:
+		return NewValidationError("Restart", fmt.Sprintf("invalid line number restart: %s", opts.Restart))
+	}
+	return nil
+}
+
+// generateLineNumberTypeXML builds a <w:lnNumType> element, or "" when
+// CountBy is zero. w:start is zero-based, so Start 1 is written as 0.
+func generateLineNumberTypeXML(opts LineNumberOptions) string {
+	if opts.CountBy <= 0 {
+		return ""
+	}
+	attrs := fmt.Sprintf(` w:countBy="%d"`, opts.CountBy)
+	if opts.Start > 1 {
+		attrs += fmt.Sprintf(` w:start="%d"`, opts.Start-1)
+	}
+	if opts.Distance > 0 {
+		attrs += fmt.Sprintf(` w:distance="%d"`, opts.Distance)
+	}
+	if opts.Restart != "" {
+		attrs += fmt.Sprintf(` w:restart="%s"`, opts.Restart)
+	}
+	return fmt.Sprintf(`<w:lnNumType%s/>`, attrs)
+}
+
+// parseLineNumberType reads the line numbering of a sectPr, or nil if the
+// section has none
+func parseLineNumberType(sectPr []byte) *LineNumberOptions {
+	tag := sectPrChildTag(sectPr, "lnNumType")
+	if tag == nil {
+		return nil
+	}
+	opts := &LineNumberOptions{
+		CountBy:  atoiOrZero(xmlAttrValue(tag, "w:countBy")),
+		Start:    atoiOrZero(xmlAttrValue(tag, "w:start")) + 1,
+		Distance: atoiOrZero(xmlAttrValue(tag, "w:distance")),
+		Restart:  LineNumberRestart(xmlAttrValue(tag, "w:restart")),
+	}
+	if opts.CountBy == 0 {
+		// Word treats a missing countBy as numbering turned off.
+		return nil
+	}
+	return opts
+}
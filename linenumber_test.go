@@ -0,0 +1,76 @@
+package godocx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetLineNumbering_AllSections(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	opts := LineNumberOptions{CountBy: 5, Start: 1, Distance: 360, Restart: LineNumberRestartContinuous}
+	if err := u.SetLineNumbering(opts); err != nil {
+		t.Fatalf("SetLineNumbering: %v", err)
+	}
+
+	doc := readDocXML(t, u)
+	want := `<w:lnNumType w:countBy="5" w:distance="360" w:restart="continuous"/>`
+	if strings.Count(doc, want) != 2 || !strings.Contains(doc, want+`<w:pgNumType`) || !strings.Contains(doc, want+`<w:cols`) {
+		t.Errorf("expected lnNumType in schema order in both sections, got %s", doc)
+	}
+
+	sections, err := u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	for _, sec := range sections {
+		if sec.LineNumbers == nil || *sec.LineNumbers != opts {
+			t.Errorf("section %d: got line numbers %+v, want %+v", sec.Index, sec.LineNumbers, opts)
+		}
+	}
+
+	// Turn numbering off in the second section only.
+	if err := u.UpdateSection(2, SectionOptions{LineNumbers: &LineNumberOptions{}}); err != nil {
+		t.Fatalf("UpdateSection: %v", err)
+	}
+	sections, err = u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	if sections[0].LineNumbers == nil || sections[1].LineNumbers != nil {
+		t.Errorf("expected numbering only in section 1, got %+v / %+v", sections[0].LineNumbers, sections[1].LineNumbers)
+	}
+
+	if err := u.SetLineNumbering(LineNumberOptions{CountBy: 1, Restart: "everyLine"}); err == nil {
+		t.Error("expected validation error for invalid restart")
+	}
+}
+
+func TestLineNumberStartIsZeroBasedInXML(t *testing.T) {
+	got := generateLineNumberTypeXML(LineNumberOptions{CountBy: 1, Start: 10, Restart: LineNumberRestartNewSection})
+	want := `<w:lnNumType w:countBy="1" w:start="9" w:restart="newSection"/>`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if parsed := parseLineNumberType([]byte(`<w:sectPr>` + got + `</w:sectPr>`)); parsed == nil || parsed.Start != 10 {
+		t.Errorf("expected start 10 read back, got %+v", parsed)
+	}
+}
+
+func TestSuppressLineNumbers(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Body</w:t></w:r></w:p>`))
+
+	if err := u.InsertParagraph(ParagraphOptions{
+		Text: "Caption", KeepNext: true, SuppressLineNumbers: true, Alignment: ParagraphAlignCenter, Position: PositionEnd,
+	}); err != nil {
+		t.Fatalf("InsertParagraph: %v", err)
+	}
+	if doc := readDocXML(t, u); !strings.Contains(doc, `<w:keepNext/><w:suppressLineNumbers/><w:jc w:val="center"/>`) {
+		t.Errorf("expected suppressLineNumbers in pPr order, got %s", doc)
+	}
+
+	style := string(generateStyleXML(StyleDefinition{ID: "BlockQuote", Type: StyleTypeParagraph, SuppressLineNumbers: true}))
+	if !strings.Contains(style, "<w:suppressLineNumbers/>") {
+		t.Errorf("expected suppressLineNumbers in style, got %s", style)
+	}
+}
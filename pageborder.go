@@ -0,0 +1,269 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// PageBorderDisplay defines which pages of a section show the page border
+type PageBorderDisplay string
+
+const (
+	// PageBorderAllPages shows the border on every page (default)
+	PageBorderAllPages PageBorderDisplay = "allPages"
+	// PageBorderFirstPage shows the border on the first page only
+	PageBorderFirstPage PageBorderDisplay = "firstPage"
+	// PageBorderNotFirstPage shows the border on every page but the first
+	PageBorderNotFirstPage PageBorderDisplay = "notFirstPage"
+)
+
+// PageBorderOffset defines what the border spacing is measured from
+type PageBorderOffset string
+
+const (
+	// PageBorderOffsetText measures the spacing from the text (default)
+	PageBorderOffsetText PageBorderOffset = "text"
+	// PageBorderOffsetPage measures the spacing from the page edge
+	PageBorderOffsetPage PageBorderOffset = "page"
+)
+
+// PageVerticalAlignment defines how text is aligned vertically on the page
+type PageVerticalAlignment string
+
+const (
+	// PageVAlignTop aligns text with the top margin (default)
+	PageVAlignTop PageVerticalAlignment = "top"
+	// PageVAlignCenter centers text between the top and bottom margins
+	PageVAlignCenter PageVerticalAlignment = "center"
+	// PageVAlignJustified spreads paragraphs evenly between the margins
+	PageVAlignJustified PageVerticalAlignment = "both"
+	// PageVAlignBottom aligns text with the bottom margin
+	PageVAlignBottom PageVerticalAlignment = "bottom"
+)
+
+// PageBorder defines one side of a page border
+type PageBorder struct {
+	// Style of the line; empty means no border on this side
+	Style BorderStyle
+
+	// Color is a hex color (default: "auto")
+	Color string
+
+	// Size is the line width in eighths of a point (2-96, default: 4)
+	Size int
+
+	// Space is the distance from the text or page edge in points (0-31)
+	Space int
+}
+
+// PageBorders defines the border drawn around the pages of a section
+type PageBorders struct {
+	Top    PageBorder
+	Left   PageBorder
+	Bottom PageBorder
+	Right  PageBorder
+
+	// Display selects the pages that show the border (default: all pages)
+	Display PageBorderDisplay
+
+	// OffsetFrom selects what Space is measured from (default: text)
+	OffsetFrom PageBorderOffset
+}
+
+// UniformPageBorders returns page borders using the same line on all four sides
+func UniformPageBorders(border PageBorder) *PageBorders {
+	return &PageBorders{Top: border, Left: border, Bottom: border, Right: border}
+}
+
+// validatePageLayoutDecorations checks the border and vertical alignment
+// settings of a page layout
+func validatePageLayoutDecorations(layout PageLayoutOptions) error {
+	switch layout.VerticalAlign {
+	case "", PageVAlignTop, PageVAlignCenter, PageVAlignJustified, PageVAlignBottom:
+	default:
+		return NewValidationError("VerticalAlign", fmt.Sprintf("invalid vertical alignment: %s", layout.VerticalAlign))
+	}
+
+	if layout.Borders == nil {
+		return nil
+	}
+	switch layout.Borders.Display {
+	case "", PageBorderAllPages, PageBorderFirstPage, PageBorderNotFirstPage:
+	default:
+		return NewValidationError("Borders", fmt.Sprintf("invalid border display: %s", layout.Borders.Display))
+	}
+	switch layout.Borders.OffsetFrom {
+	case "", PageBorderOffsetText, PageBorderOffsetPage:
+	default:
+		return NewValidationError("Borders", fmt.Sprintf("invalid border offset: %s", layout.Borders.OffsetFrom))
+	}
+	for _, side := range layout.Borders.sides() {
+		if side.border.Size != 0 && (side.border.Size < 2 || side.border.Size > 96) {
+			return NewValidationError("Borders", fmt.Sprintf("%s border size must be between 2 and 96 eighths of a point", side.name))
+		}
+		if side.border.Space < 0 || side.border.Space > 31 {
+			return NewValidationError("Borders", fmt.Sprintf("%s border space must be between 0 and 31 points", side.name))
+		}
+	}
+	return nil
+}
+
+type namedPageBorder struct {
+	name   string
+	border PageBorder
+}
+
+// sides returns the borders in CT_PageBorders order
+func (b PageBorders) sides() []namedPageBorder {
+	return []namedPageBorder{{"top", b.Top}, {"left", b.Left}, {"bottom", b.Bottom}, {"right", b.Right}}
+}
+
+// generatePageBordersXMLWithPrefix builds a <w:pgBorders> element, or "" when
+// no side has a border
+func generatePageBordersXMLWithPrefix(ns string, borders PageBorders) string {
+	var sides bytes.Buffer
+	for _, side := range borders.sides() {
+		if side.border.Style == "" {
+			continue
+		}
+		size := side.border.Size
+		if size == 0 {
+			size = 4
+		}
+		color := normalizeHexColor(side.border.Color)
+		if color == "" {
+			color = "auto"
+		}
+		fmt.Fprintf(&sides, `<%s:%s %s:val="%s" %s:sz="%d" %s:space="%d" %s:color="%s"/>`,
+			ns, side.name, ns, xmlEscape(string(side.border.Style)), ns, size, ns, side.border.Space, ns, color)
+	}
+	if sides.Len() == 0 {
+		return ""
+	}
+
+	attrs := ""
+	if borders.OffsetFrom != "" {
+		attrs += fmt.Sprintf(` %s:offsetFrom="%s"`, ns, borders.OffsetFrom)
+	}
+	if borders.Display != "" {
+		attrs += fmt.Sprintf(` %s:display="%s"`, ns, borders.Display)
+	}
+	return fmt.Sprintf(`<%s:pgBorders%s>%s</%s:pgBorders>`, ns, attrs, sides.String(), ns)
+}
+
+// generateVerticalAlignXMLWithPrefix builds a <w:vAlign> element, or "" when
+// no alignment is set
+func generateVerticalAlignXMLWithPrefix(ns string, align PageVerticalAlignment) string {
+	if align == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<%s:vAlign %s:val="%s"/>`, ns, ns, align)
+}
+
+// pageBorderSidePattern matches the side elements of <w:pgBorders>
+var pageBorderSidePattern = regexp.MustCompile(`<w:(top|left|bottom|right)\s[^>]*/>`)
+
+// parsePageBorders reads the page borders of a sectPr, or nil if it has none
+func parsePageBorders(sectPr []byte) *PageBorders {
+	start, end, ok := sectPrChildRange(sectPr, "pgBorders")
+	if !ok {
+		return nil
+	}
+	tag := sectPrChildTag(sectPr, "pgBorders")
+	borders := &PageBorders{
+		Display:    PageBorderDisplay(xmlAttrValue(tag, "w:display")),
+		OffsetFrom: PageBorderOffset(xmlAttrValue(tag, "w:offsetFrom")),
+	}
+	for _, m := range pageBorderSidePattern.FindAllSubmatch(sectPr[start:end], -1) {
+		border := PageBorder{
+			Style: BorderStyle(xmlAttrValue(m[0], "w:val")),
+			Color: xmlAttrValue(m[0], "w:color"),
+			Size:  atoiOrZero(xmlAttrValue(m[0], "w:sz")),
+			Space: atoiOrZero(xmlAttrValue(m[0], "w:space")),
+		}
+		switch string(m[1]) {
+		case "top":
+			borders.Top = border
+		case "left":
+			borders.Left = border
+		case "bottom":
+			borders.Bottom = border
+		case "right":
+			borders.Right = border
+		}
+	}
+	return borders
+}
+
+// displayBackgroundShapeSuccessors lists the settings.xml elements that
+// follow displayBackgroundShape in schema order.
+var displayBackgroundShapeSuccessors = append([]string{
+	"<w:printPostScriptOverText", "<w:printFractionalCharacterWidth", "<w:printFormsData",
+	"<w:embedTrueTypeFonts", "<w:embedSystemFonts", "<w:saveSubsetFonts", "<w:saveFormsData",
+	"<w:mirrorMargins", "<w:alignBordersAndEdges", "<w:bordersDoNotSurroundHeader",
+	"<w:bordersDoNotSurroundFooter", "<w:gutterAtTop", "<w:hideSpellingErrors",
+	"<w:hideGrammaticalErrors", "<w:activeWritingStyle", "<w:proofState", "<w:formsDesign",
+	"<w:attachedTemplate", "<w:linkStyles", "<w:stylePaneFormatFilter", "<w:stylePaneSortMethod",
+	"<w:documentType", "<w:mailMerge", "<w:revisionView", "<w:trackRevisions",
+	"<w:doNotTrackMoves", "<w:doNotTrackFormatting", "<w:documentProtection",
+	"<w:autoFormatOverride", "<w:styleLockTheme", "<w:styleLockQFSet", "<w:defaultTabStop",
+	"<w:autoHyphenation", "<w:consecutiveHyphenLimit", "<w:hyphenationZone",
+	"<w:doNotHyphenateCaps", "<w:showEnvelope", "<w:summaryLength", "<w:clickAndTypeStyle",
+	"<w:defaultTableStyle", "<w:evenAndOddHeaders",
+}, evenAndOddHeadersSuccessors...)
+
+// documentBackgroundPattern matches the document background element
+var documentBackgroundPattern = regexp.MustCompile(`(?s)<w:background(?:\s[^>]*)?(?:/>|>.*?</w:background>)`)
+
+// SetPageBackground sets the document background color (hex, e.g. "FFF8E7")
+// and turns on displayBackgroundShape in settings.xml so Word shows it.
+// An empty color removes the background.
+func (u *Updater) SetPageBackground(color string) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	element := ""
+	if color != "" {
+		normalized := normalizeHexColor(color)
+		if normalized == "" {
+			return NewValidationError("color", fmt.Sprintf("invalid hex color: %s", color))
+		}
+		element = fmt.Sprintf(`<w:background w:color="%s"/>`, normalized)
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	// w:background is the first child of w:document, before w:body.
+	if loc := documentBackgroundPattern.FindIndex(raw); loc != nil {
+		raw = spliceBytes(raw, loc[0], loc[1], []byte(element))
+	} else if element != "" {
+		bodyStart := findNextWordTagStart(raw, 0, "body")
+		if bodyStart == -1 {
+			return fmt.Errorf("could not find <w:body> tag")
+		}
+		raw = spliceBytes(raw, bodyStart, bodyStart, []byte(element))
+	}
+
+	if err := atomicWriteFile(docPath, raw, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+
+	displayShape := ""
+	if element != "" {
+		displayShape = "<w:displayBackgroundShape/>"
+	} else if _, err := os.Stat(filepath.Join(u.tempDir, "word", "settings.xml")); err != nil {
+		return nil
+	}
+	if err := u.setSettingsElement("displayBackgroundShape", displayShape, displayBackgroundShapeSuccessors); err != nil {
+		return fmt.Errorf("set displayBackgroundShape: %w", err)
+	}
+	return nil
+}
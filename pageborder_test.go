@@ -0,0 +1,106 @@
+package godocx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpdateSection_PageBordersAndVerticalAlign(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	cover := PageLayoutLetterPortrait()
+	cover.VerticalAlign = PageVAlignCenter
+	cover.Borders = UniformPageBorders(PageBorder{Style: BorderDouble, Color: "#1F4E79", Size: 24, Space: 24})
+	cover.Borders.OffsetFrom = PageBorderOffsetPage
+	cover.Borders.Display = PageBorderFirstPage
+	if err := u.UpdateSection(1, SectionOptions{PageLayout: cover}); err != nil {
+		t.Fatalf("UpdateSection: %v", err)
+	}
+
+	doc := readDocXML(t, u)
+	wantBorders := `<w:pgBorders w:offsetFrom="page" w:display="firstPage">` +
+		`<w:top w:val="double" w:sz="24" w:space="24" w:color="1F4E79"/>` +
+		`<w:left w:val="double" w:sz="24" w:space="24" w:color="1F4E79"/>` +
+		`<w:bottom w:val="double" w:sz="24" w:space="24" w:color="1F4E79"/>` +
+		`<w:right w:val="double" w:sz="24" w:space="24" w:color="1F4E79"/></w:pgBorders>`
+	if !strings.Contains(doc, `<w:pgMar `) || !strings.Contains(doc, wantBorders+`<w:pgNumType`) {
+		t.Errorf("expected page borders between pgMar and pgNumType, got %s", doc)
+	}
+	if !strings.Contains(doc, `<w:vAlign w:val="center"/><w:titlePg/>`) {
+		t.Errorf("expected vAlign before titlePg, got %s", doc)
+	}
+
+	sections, err := u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	got := sections[0].PageLayout
+	if got.VerticalAlign != PageVAlignCenter || got.Borders == nil {
+		t.Fatalf("expected borders and vAlign read back, got %+v", got)
+	}
+	want := *cover.Borders
+	want.Top.Color, want.Left.Color, want.Bottom.Color, want.Right.Color = "1F4E79", "1F4E79", "1F4E79", "1F4E79"
+	if !reflect.DeepEqual(*got.Borders, want) {
+		t.Errorf("got borders %+v, want %+v", *got.Borders, want)
+	}
+	if sections[1].PageLayout.Borders != nil || sections[1].PageLayout.VerticalAlign != "" {
+		t.Errorf("section 2 must be unaffected, got %+v", sections[1].PageLayout)
+	}
+
+	// Borders with no styled side remove the element.
+	if err := u.UpdateSection(1, SectionOptions{PageLayout: &PageLayoutOptions{
+		PageWidth: PageWidthLetter, PageHeight: PageHeightLetter, Borders: &PageBorders{},
+	}}); err != nil {
+		t.Fatalf("UpdateSection: %v", err)
+	}
+	if strings.Contains(readDocXML(t, u), "pgBorders") {
+		t.Error("expected page borders removed")
+	}
+}
+
+func TestPageLayout_DecorationValidation(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	bad := []PageLayoutOptions{
+		{VerticalAlign: "middle"},
+		{Borders: &PageBorders{Display: "everyOtherPage"}},
+		{Borders: UniformPageBorders(PageBorder{Style: BorderSingle, Size: 200})},
+		{Borders: UniformPageBorders(PageBorder{Style: BorderSingle, Space: 40})},
+	}
+	for i, layout := range bad {
+		if err := u.SetPageLayout(layout); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestSetPageBackground(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Certificate</w:t></w:r></w:p>`))
+
+	if err := u.SetPageBackground("#fff8e7"); err != nil {
+		t.Fatalf("SetPageBackground: %v", err)
+	}
+	if err := u.SetPageBackground("FDF5E6"); err != nil {
+		t.Fatalf("SetPageBackground again: %v", err)
+	}
+
+	doc := readDocXML(t, u)
+	if strings.Count(doc, "<w:background") != 1 || !strings.Contains(doc, `<w:background w:color="FDF5E6"/><w:body>`) {
+		t.Errorf("expected a single background before the body, got %s", doc)
+	}
+	if !strings.Contains(readWordPart(t, u, "settings.xml"), "<w:displayBackgroundShape/>") {
+		t.Error("expected displayBackgroundShape in settings.xml")
+	}
+
+	if err := u.SetPageBackground("not-a-color"); err == nil {
+		t.Error("expected validation error for invalid color")
+	}
+
+	if err := u.SetPageBackground(""); err != nil {
+		t.Fatalf("remove background: %v", err)
+	}
+	if strings.Contains(readDocXML(t, u), "w:background") || strings.Contains(readWordPart(t, u, "settings.xml"), "displayBackgroundShape") {
+		t.Error("expected background and displayBackgroundShape removed")
+	}
+}
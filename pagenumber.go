@@ -68,14 +68,8 @@ func (u *Updater) SetPageNumber(opts PageNumberOptions) error {
 // setPageNumberInSectPr updates or inserts pgNumType element in the document's sectPr.
 func setPageNumberInSectPr(docXML []byte, opts PageNumberOptions) ([]byte, error) {
 	// Build the pgNumType element
-	var pgNumType string
-	if opts.Start > 0 && opts.Format != "" {
-		pgNumType = fmt.Sprintf(`<w:pgNumType w:start="%d" w:fmt="%s"/>`, opts.Start, opts.Format)
-	} else if opts.Start > 0 {
-		pgNumType = fmt.Sprintf(`<w:pgNumType w:start="%d"/>`, opts.Start)
-	} else if opts.Format != "" {
-		pgNumType = fmt.Sprintf(`<w:pgNumType w:fmt="%s"/>`, opts.Format)
-	} else {
+	pgNumType := generatePageNumberTypeXML(opts)
+	if pgNumType == "" {
 		return docXML, nil
 	}
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/xml"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -102,6 +103,68 @@ type RunOptions struct {
 	// is emitted as a <w:hyperlink w:anchor="..."> element linking to a bookmark within
 	// the same document. Like URL hyperlinks, these are underlined and use Word blue by default.
 	BookmarkRef string
+
+	// Field, when set, emits the run as a field (page number, date, cross-reference, ...)
+	// with this run's character formatting. Text, URL and BookmarkRef are ignored.
+	Field *Field
+
+	// Equation, when set, emits the run as an inline Office Math equation converted
+	// from this LaTeX source (see InsertEquation). Text, URL and BookmarkRef are ignored.
+	Equation string
+
+	// Style is a character style ID (w:rStyle), e.g. "Strong"
+	Style string
+
+	// FontEastAsia and FontComplexScript set the East Asian and complex-script
+	// fonts (w:rFonts w:eastAsia / w:cs)
+	FontEastAsia      string
+	FontComplexScript string
+
+	// FontSizeComplexScript is the complex-script size in points; zero uses FontSize
+	FontSizeComplexScript float64
+
+	// BoldComplexScript and ItalicComplexScript format complex-script text
+	BoldComplexScript   bool
+	ItalicComplexScript bool
+
+	AllCaps             bool // Display as capital letters
+	SmallCaps           bool // Display lowercase letters as small capitals
+	DoubleStrikethrough bool // Two horizontal lines through the text
+	Hidden              bool // Hidden text (w:vanish)
+	RightToLeft         bool // Right-to-left text (w:rtl)
+
+	// UnderlineStyle selects the underline type and implies Underline, e.g.
+	// UnderlineDouble or UnderlineWave. UnderlineColor is a hex color.
+	UnderlineStyle UnderlineStyle
+	UnderlineColor string
+
+	// CharacterSpacing expands (positive) or condenses (negative) the space
+	// between characters, in points
+	CharacterSpacing float64
+
+	// Scale stretches characters horizontally, as a percentage (1-600; zero = 100)
+	Scale int
+
+	// Kerning turns on font kerning for text at or above this size in points
+	Kerning float64
+
+	// Position raises (positive) or lowers (negative) the text, in points
+	Position float64
+
+	// Shading is the background fill behind the text as a hex color
+	Shading string
+
+	// Border draws a box around the text
+	Border *ParagraphBorder
+
+	// Emphasis adds an East Asian emphasis mark to each character
+	Emphasis EmphasisMark
+
+	// Language, LanguageEastAsia and LanguageComplexScript are BCP 47 tags
+	// used for proofing, e.g. "en-US", "ja-JP", "ar-SA" (w:lang)
+	Language              string
+	LanguageEastAsia      string
+	LanguageComplexScript string
 }
 
 // ParagraphOptions defines options for paragraph insertion
@@ -143,6 +206,43 @@ type ParagraphOptions struct {
 	// Pagination control
 	KeepNext  bool // Keep this paragraph on the same page as the next (prevents orphaned headings)
 	KeepLines bool // Keep all lines of this paragraph together on the same page
+
+	// SuppressLineNumbers excludes this paragraph from section line numbering
+	SuppressLineNumbers bool
+
+	// Spacing in twips. LineSpacing is in 240ths of a line (240 = single) unless
+	// LineSpacingRule is exact or atLeast, in which case it is in twips.
+	SpaceBefore     int
+	SpaceAfter      int
+	LineSpacing     int
+	LineSpacingRule LineSpacingRule
+
+	// Indentation in twips. IndentFirst and IndentHanging are mutually exclusive.
+	IndentLeft    int
+	IndentRight   int
+	IndentFirst   int
+	IndentHanging int
+
+	// Borders draws lines around the paragraph
+	Borders *ParagraphBorders
+
+	// Shading is the background fill as a hex color (e.g. "F2F2F2")
+	Shading string
+
+	// Tabs defines custom tab stops, e.g. a right-aligned stop with a dot leader
+	Tabs []TabStop
+
+	PageBreakBefore   bool // Start this paragraph on a new page
+	ContextualSpacing bool // Ignore spacing between paragraphs of the same style
+	Bidi              bool // Right-to-left paragraph direction
+
+	// WidowControl prevents a single first or last line on a page when true
+	// and allows it when false, overriding the style. Nil keeps the style's
+	// setting.
+	WidowControl *bool
+
+	// OutlineLevel (1-9) makes the paragraph appear in the navigation pane and TOC
+	OutlineLevel int
 }
 
 type listNumberingIDs struct {
@@ -158,6 +258,9 @@ func (u *Updater) InsertParagraph(opts ParagraphOptions) error {
 	if opts.Text == "" && len(opts.Runs) == 0 {
 		return NewValidationError("text", "paragraph text cannot be empty: provide Text or at least one Run")
 	}
+	if err := validateParagraphFormat(opts); err != nil {
+		return err
+	}
 
 	// Default style to Normal if not specified
 	if opts.Style == "" {
@@ -213,6 +316,9 @@ func (u *Updater) InsertParagraph(opts ParagraphOptions) error {
 	if err != nil {
 		return fmt.Errorf("insert paragraph: %w", err)
 	}
+	if hasEquationRuns(opts.Runs) {
+		updated = ensureMathNamespace(updated)
+	}
 
 	// Write updated document
 	if err := atomicWriteFile(docPath, updated, 0o644); err != nil {
@@ -238,6 +344,9 @@ func (u *Updater) InsertParagraphs(paragraphs []ParagraphOptions) error {
 			return fmt.Errorf("paragraph %d: %w", i,
 				NewValidationError("text", "paragraph text cannot be empty: provide Text or at least one Run"))
 		}
+		if err := validateParagraphFormat(opts); err != nil {
+			return fmt.Errorf("paragraph %d: %w", i, err)
+		}
 	}
 
 	// Ensure numbering.xml exists once if any paragraph uses a list.
@@ -319,6 +428,9 @@ func (u *Updater) InsertParagraphs(paragraphs []ParagraphOptions) error {
 		if err != nil {
 			return fmt.Errorf("insert paragraph %d: %w", i, err)
 		}
+		if hasEquationRuns(opts.Runs) {
+			raw = ensureMathNamespace(raw)
+		}
 	}
 
 	// Write document.xml once.
@@ -357,7 +469,9 @@ func generateParagraphXML(opts ParagraphOptions, listIDs listNumberingIDs, resta
 		styleToApply = "ListParagraph"
 	}
 
-	// ECMA-376 CT_PPr element order: pStyle → keepNext → keepLines → numPr → jc
+	// ECMA-376 CT_PPr element order: pStyle → keepNext → keepLines → pageBreakBefore →
+	// widowControl → numPr → suppressLineNumbers → pBdr → shd → tabs → bidi → spacing →
+	// ind → contextualSpacing → jc → outlineLvl
 	// Add style if specified or determined
 	if styleToApply != StyleNormal {
 		fmt.Fprintf(&buf, `<w:pStyle w:val="%s"/>`, xmlEscape(string(styleToApply)))
@@ -370,6 +484,16 @@ func generateParagraphXML(opts ParagraphOptions, listIDs listNumberingIDs, resta
 	if opts.KeepLines {
 		buf.WriteString("<w:keepLines/>")
 	}
+	if opts.PageBreakBefore {
+		buf.WriteString("<w:pageBreakBefore/>")
+	}
+	if opts.WidowControl != nil {
+		if *opts.WidowControl {
+			buf.WriteString("<w:widowControl/>")
+		} else {
+			buf.WriteString(`<w:widowControl w:val="0"/>`)
+		}
+	}
 
 	// Add numbering properties: ListType takes precedence, then NumID direct override.
 	// OverrideNumID (when set) replaces the auto-selected numId within the ListType branch
@@ -396,29 +520,34 @@ func generateParagraphXML(opts ParagraphOptions, listIDs listNumberingIDs, resta
 		writeNumPrXML(&buf, opts.NumLevel, opts.NumID)
 	}
 
+	if opts.SuppressLineNumbers {
+		buf.WriteString("<w:suppressLineNumbers/>")
+	}
+	writeParagraphBordersXML(&buf, opts.Borders)
+	if shading := normalizeHexColor(opts.Shading); shading != "" {
+		fmt.Fprintf(&buf, `<w:shd w:val="clear" w:color="auto" w:fill="%s"/>`, shading)
+	}
+	writeTabStopsXML(&buf, opts.Tabs)
+	if opts.Bidi {
+		buf.WriteString("<w:bidi/>")
+	}
+	writeParagraphSpacingXML(&buf, opts)
+	writeParagraphIndentXML(&buf, opts)
+	if opts.ContextualSpacing {
+		buf.WriteString("<w:contextualSpacing/>")
+	}
+
 	if alignment, ok := paragraphAlignmentValue(opts.Alignment); ok {
 		fmt.Fprintf(&buf, `<w:jc w:val="%s"/>`, alignment)
 	}
+	if opts.OutlineLevel > 0 {
+		fmt.Fprintf(&buf, `<w:outlineLvl w:val="%d"/>`, opts.OutlineLevel-1)
+	}
 
 	buf.WriteString("</w:pPr>")
 
 	if len(opts.Runs) > 0 {
-		// Multi-run paragraph: emit one <w:r> per RunOptions entry.
-		// Runs with a URL are wrapped in <w:hyperlink> when a relationship ID is available.
-		// Runs with a BookmarkRef are wrapped in <w:hyperlink w:anchor="..."> for internal links.
-		for _, run := range opts.Runs {
-			if run.URL != "" {
-				if rID, ok := urlRelIDs[run.URL]; ok {
-					writeHyperlinkRunXML(&buf, run, rID)
-					continue
-				}
-			}
-			if run.BookmarkRef != "" {
-				writeInternalLinkRunXML(&buf, run)
-				continue
-			}
-			writeRunXML(&buf, run)
-		}
+		writeRunsXML(&buf, opts.Runs, urlRelIDs)
 	} else {
 		// Legacy single-run paragraph using top-level Text/Bold/Italic/Underline.
 		legacyRun := RunOptions{
@@ -435,60 +564,167 @@ func generateParagraphXML(opts ParagraphOptions, listIDs listNumberingIDs, resta
 	return buf.Bytes()
 }
 
+// writeRunsXML emits one <w:r> per RunOptions entry, or the runs of a field.
+// Runs with a URL are wrapped in <w:hyperlink> when a relationship ID is available.
+// Runs with a BookmarkRef are wrapped in <w:hyperlink w:anchor="..."> for internal links.
+func writeRunsXML(buf *bytes.Buffer, runs []RunOptions, urlRelIDs map[string]string) {
+	for _, run := range runs {
+		if run.Field != nil {
+			writeFieldXML(buf, *run.Field, run)
+			continue
+		}
+		if run.Equation != "" {
+			if nodes, err := parseLaTeX(run.Equation); err == nil {
+				writeOMathXML(buf, nodes, run)
+			}
+			continue
+		}
+		if run.URL != "" {
+			if rID, ok := urlRelIDs[run.URL]; ok {
+				writeHyperlinkRunXML(buf, run, rID)
+				continue
+			}
+		}
+		if run.BookmarkRef != "" {
+			writeInternalLinkRunXML(buf, run)
+			continue
+		}
+		writeRunXML(buf, run)
+	}
+}
+
 // writeRunXML emits a full <w:r>...</w:r> element for the given RunOptions.
 func writeRunXML(buf *bytes.Buffer, run RunOptions) {
 	buf.WriteString("<w:r>")
+	writeRunPropertiesXML(buf, run)
+	writeRunTextWithControls(buf, run.Text)
+	buf.WriteString("</w:r>")
+}
 
-	hasRPr := run.Bold || run.Italic || run.Underline || run.Strikethrough ||
-		run.Superscript || run.Subscript ||
-		run.Color != "" || run.Highlight != "" ||
-		run.FontSize > 0 || run.FontName != ""
+// writeRunPropertiesXML emits the <w:rPr> element for the character formatting
+// of run, or nothing when run carries no formatting. Elements follow the
+// ECMA-376 CT_RPr sequence.
+func writeRunPropertiesXML(buf *bytes.Buffer, run RunOptions) {
+	var rPr bytes.Buffer
 
-	if hasRPr {
-		buf.WriteString("<w:rPr>")
+	if run.Style != "" {
+		fmt.Fprintf(&rPr, `<w:rStyle w:val="%s"/>`, xmlEscape(run.Style))
+	}
+	if run.FontName != "" || run.FontEastAsia != "" || run.FontComplexScript != "" {
+		rPr.WriteString("<w:rFonts")
 		if run.FontName != "" {
-			fmt.Fprintf(buf, `<w:rFonts w:ascii="%s" w:hAnsi="%s"/>`,
-				xmlEscape(run.FontName), xmlEscape(run.FontName))
+			fmt.Fprintf(&rPr, ` w:ascii="%s" w:hAnsi="%s"`, xmlEscape(run.FontName), xmlEscape(run.FontName))
 		}
-		if run.Bold {
-			buf.WriteString("<w:b/>")
+		if run.FontEastAsia != "" {
+			fmt.Fprintf(&rPr, ` w:eastAsia="%s"`, xmlEscape(run.FontEastAsia))
 		}
-		if run.Italic {
-			buf.WriteString("<w:i/>")
+		if run.FontComplexScript != "" {
+			fmt.Fprintf(&rPr, ` w:cs="%s"`, xmlEscape(run.FontComplexScript))
 		}
-		if run.Strikethrough {
-			buf.WriteString("<w:strike/>")
+		rPr.WriteString("/>")
+	}
+	writeOnOff := func(on bool, element string) {
+		if on {
+			rPr.WriteString(element)
 		}
-		if run.Color != "" {
-			// normalizeHexColor validates and normalises the value; skip invalid strings
-			// to avoid emitting malformed XML attribute values.
-			if normalized := normalizeHexColor(run.Color); normalized != "" {
-				fmt.Fprintf(buf, `<w:color w:val="%s"/>`, normalized)
-			}
+	}
+	writeOnOff(run.Bold, "<w:b/>")
+	writeOnOff(run.BoldComplexScript, "<w:bCs/>")
+	writeOnOff(run.Italic, "<w:i/>")
+	writeOnOff(run.ItalicComplexScript, "<w:iCs/>")
+	writeOnOff(run.AllCaps, "<w:caps/>")
+	writeOnOff(run.SmallCaps, "<w:smallCaps/>")
+	writeOnOff(run.Strikethrough, "<w:strike/>")
+	writeOnOff(run.DoubleStrikethrough, "<w:dstrike/>")
+	writeOnOff(run.Hidden, "<w:vanish/>")
+
+	if run.Color != "" {
+		// normalizeHexColor validates and normalises the value; skip invalid strings
+		// to avoid emitting malformed XML attribute values.
+		if normalized := normalizeHexColor(run.Color); normalized != "" {
+			fmt.Fprintf(&rPr, `<w:color w:val="%s"/>`, normalized)
+		}
+	}
+	if run.CharacterSpacing != 0 {
+		// w:spacing is in twentieths of a point.
+		fmt.Fprintf(&rPr, `<w:spacing w:val="%d"/>`, int(math.Round(run.CharacterSpacing*20)))
+	}
+	if run.Scale > 0 && run.Scale != 100 {
+		fmt.Fprintf(&rPr, `<w:w w:val="%d"/>`, run.Scale)
+	}
+	if run.Kerning > 0 {
+		fmt.Fprintf(&rPr, `<w:kern w:val="%d"/>`, int(math.Round(run.Kerning*FontSizeHalfPointsFactor)))
+	}
+	if run.Position != 0 {
+		fmt.Fprintf(&rPr, `<w:position w:val="%d"/>`, int(math.Round(run.Position*FontSizeHalfPointsFactor)))
+	}
+	if run.FontSize > 0 {
+		// w:sz / w:szCs values are in half-points (see FontSizeHalfPointsFactor).
+		fmt.Fprintf(&rPr, `<w:sz w:val="%d"/>`, int(run.FontSize*FontSizeHalfPointsFactor))
+	}
+	if csSize := run.FontSizeComplexScript; csSize > 0 || run.FontSize > 0 {
+		if csSize == 0 {
+			csSize = run.FontSize
 		}
-		if run.Highlight != "" {
-			fmt.Fprintf(buf, `<w:highlight w:val="%s"/>`, xmlEscape(run.Highlight))
+		fmt.Fprintf(&rPr, `<w:szCs w:val="%d"/>`, int(csSize*FontSizeHalfPointsFactor))
+	}
+	if run.Highlight != "" {
+		fmt.Fprintf(&rPr, `<w:highlight w:val="%s"/>`, xmlEscape(run.Highlight))
+	}
+	if run.Underline || run.UnderlineStyle != "" {
+		style := run.UnderlineStyle
+		if style == "" {
+			style = UnderlineSingle
 		}
-		if run.Underline {
-			buf.WriteString(`<w:u w:val="single"/>`)
+		fmt.Fprintf(&rPr, `<w:u w:val="%s"`, xmlEscape(string(style)))
+		if color := normalizeHexColor(run.UnderlineColor); color != "" {
+			fmt.Fprintf(&rPr, ` w:color="%s"`, color)
 		}
-		if run.FontSize > 0 {
-			// w:sz / w:szCs values are in half-points (see FontSizeHalfPointsFactor).
-			hp := int(run.FontSize * FontSizeHalfPointsFactor)
-			fmt.Fprintf(buf, `<w:sz w:val="%d"/>`, hp)
-			fmt.Fprintf(buf, `<w:szCs w:val="%d"/>`, hp)
+		rPr.WriteString("/>")
+	}
+	if run.Border != nil && run.Border.Style != "" {
+		size := run.Border.Size
+		if size == 0 {
+			size = 4
 		}
-		if run.Superscript {
-			buf.WriteString(`<w:vertAlign w:val="superscript"/>`)
-		} else if run.Subscript {
-			buf.WriteString(`<w:vertAlign w:val="subscript"/>`)
+		color := normalizeHexColor(run.Border.Color)
+		if color == "" {
+			color = "auto"
 		}
-		buf.WriteString("</w:rPr>")
+		fmt.Fprintf(&rPr, `<w:bdr w:val="%s" w:sz="%d" w:space="%d" w:color="%s"/>`,
+			xmlEscape(string(run.Border.Style)), size, run.Border.Space, color)
+	}
+	if shading := normalizeHexColor(run.Shading); shading != "" {
+		fmt.Fprintf(&rPr, `<w:shd w:val="clear" w:color="auto" w:fill="%s"/>`, shading)
+	}
+	if run.Superscript {
+		rPr.WriteString(`<w:vertAlign w:val="superscript"/>`)
+	} else if run.Subscript {
+		rPr.WriteString(`<w:vertAlign w:val="subscript"/>`)
+	}
+	writeOnOff(run.RightToLeft, "<w:rtl/>")
+	if run.Emphasis != "" && run.Emphasis != EmphasisNone {
+		fmt.Fprintf(&rPr, `<w:em w:val="%s"/>`, xmlEscape(string(run.Emphasis)))
+	}
+	if run.Language != "" || run.LanguageEastAsia != "" || run.LanguageComplexScript != "" {
+		rPr.WriteString("<w:lang")
+		if run.Language != "" {
+			fmt.Fprintf(&rPr, ` w:val="%s"`, xmlEscape(run.Language))
+		}
+		if run.LanguageEastAsia != "" {
+			fmt.Fprintf(&rPr, ` w:eastAsia="%s"`, xmlEscape(run.LanguageEastAsia))
+		}
+		if run.LanguageComplexScript != "" {
+			fmt.Fprintf(&rPr, ` w:bidi="%s"`, xmlEscape(run.LanguageComplexScript))
+		}
+		rPr.WriteString("/>")
 	}
 
-	writeRunTextWithControls(buf, run.Text)
-
-	buf.WriteString("</w:r>")
+	if rPr.Len() > 0 {
+		buf.WriteString("<w:rPr>")
+		buf.Write(rPr.Bytes())
+		buf.WriteString("</w:rPr>")
+	}
 }
 
 // writeHyperlinkRunXML emits a <w:hyperlink> element wrapping a styled run.
@@ -0,0 +1,260 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// LineSpacingRule defines how ParagraphOptions.LineSpacing is interpreted
+type LineSpacingRule string
+
+const (
+	// LineSpacingAuto measures line spacing in 240ths of a line (240 = single, 480 = double)
+	LineSpacingAuto LineSpacingRule = "auto"
+	// LineSpacingExact sets the line height to exactly LineSpacing twips
+	LineSpacingExact LineSpacingRule = "exact"
+	// LineSpacingAtLeast sets a minimum line height of LineSpacing twips
+	LineSpacingAtLeast LineSpacingRule = "atLeast"
+)
+
+// TabAlignment defines how text aligns at a tab stop
+type TabAlignment string
+
+const (
+	TabAlignLeft    TabAlignment = "left"
+	TabAlignCenter  TabAlignment = "center"
+	TabAlignRight   TabAlignment = "right"
+	TabAlignDecimal TabAlignment = "decimal"
+	TabAlignBar     TabAlignment = "bar"
+)
+
+// TabLeader defines the character that fills the space before a tab stop
+type TabLeader string
+
+const (
+	TabLeaderNone       TabLeader = "none"
+	TabLeaderDot        TabLeader = "dot"
+	TabLeaderHyphen     TabLeader = "hyphen"
+	TabLeaderUnderscore TabLeader = "underscore"
+	TabLeaderMiddleDot  TabLeader = "middleDot"
+	TabLeaderHeavy      TabLeader = "heavy"
+)
+
+// TabStop defines a custom tab stop
+type TabStop struct {
+	// Position is the distance from the left indent in twips
+	Position int
+
+	// Alignment of text at the stop (default: left)
+	Alignment TabAlignment
+
+	// Leader fills the space before the stop (default: none)
+	Leader TabLeader
+}
+
+// ParagraphBorder defines one side of a paragraph border
+type ParagraphBorder struct {
+	// Style of the line; empty means no border on this side
+	Style BorderStyle
+
+	// Color is a hex color (default: "auto")
+	Color string
+
+	// Size is the line width in eighths of a point (2-96, default: 4)
+	Size int
+
+	// Space is the distance from the text in points (0-31)
+	Space int
+}
+
+// ParagraphBorders defines the borders around a paragraph. Between is drawn
+// between consecutive paragraphs that share the same borders.
+type ParagraphBorders struct {
+	Top     ParagraphBorder
+	Left    ParagraphBorder
+	Bottom  ParagraphBorder
+	Right   ParagraphBorder
+	Between ParagraphBorder
+}
+
+// namedParagraphBorder pairs a paragraph border side with its element name
+type namedParagraphBorder struct {
+	name   string
+	border ParagraphBorder
+}
+
+// sides returns the borders in CT_PBdr order
+func (b ParagraphBorders) sides() []namedParagraphBorder {
+	return []namedParagraphBorder{
+		{"top", b.Top}, {"left", b.Left}, {"bottom", b.Bottom}, {"right", b.Right}, {"between", b.Between},
+	}
+}
+
+// validateParagraphFormat checks the spacing, indentation, border, tab and
+// outline settings of a paragraph, and the fields in its runs
+func validateParagraphFormat(opts ParagraphOptions) error {
+	if opts.SpaceBefore < 0 || opts.SpaceAfter < 0 || opts.LineSpacing < 0 {
+		return NewValidationError("Spacing", "paragraph spacing must be >= 0")
+	}
+	switch opts.LineSpacingRule {
+	case "", LineSpacingAuto, LineSpacingExact, LineSpacingAtLeast:
+	default:
+		return NewValidationError("LineSpacingRule", fmt.Sprintf("invalid line spacing rule: %s", opts.LineSpacingRule))
+	}
+	if opts.IndentFirst < 0 || opts.IndentHanging < 0 {
+		return NewValidationError("Indent", "first-line and hanging indents must be >= 0")
+	}
+	if opts.IndentFirst > 0 && opts.IndentHanging > 0 {
+		return NewValidationError("Indent", "IndentFirst and IndentHanging cannot both be set")
+	}
+	if opts.OutlineLevel < 0 || opts.OutlineLevel > 9 {
+		return NewValidationError("OutlineLevel", "outline level must be between 1 and 9")
+	}
+	if opts.Shading != "" && normalizeHexColor(opts.Shading) == "" {
+		return NewValidationError("Shading", fmt.Sprintf("invalid hex color: %s", opts.Shading))
+	}
+
+	for i, tab := range opts.Tabs {
+		if tab.Position < 0 {
+			return NewValidationError("Tabs", fmt.Sprintf("tab stop %d position must be >= 0", i+1))
+		}
+		switch tab.Alignment {
+		case "", TabAlignLeft, TabAlignCenter, TabAlignRight, TabAlignDecimal, TabAlignBar:
+		default:
+			return NewValidationError("Tabs", fmt.Sprintf("tab stop %d has invalid alignment: %s", i+1, tab.Alignment))
+		}
+		switch tab.Leader {
+		case "", TabLeaderNone, TabLeaderDot, TabLeaderHyphen, TabLeaderUnderscore, TabLeaderMiddleDot, TabLeaderHeavy:
+		default:
+			return NewValidationError("Tabs", fmt.Sprintf("tab stop %d has invalid leader: %s", i+1, tab.Leader))
+		}
+	}
+
+	for i, run := range opts.Runs {
+		if run.Field != nil {
+			if err := validateField(run.Field); err != nil {
+				return fmt.Errorf("run %d: %w", i+1, err)
+			}
+		}
+		if run.Equation != "" {
+			if _, err := parseLaTeX(run.Equation); err != nil {
+				return fmt.Errorf("run %d: %w", i+1, NewValidationError("Equation", err.Error()))
+			}
+		}
+		if run.Scale < 0 || run.Scale > 600 {
+			return fmt.Errorf("run %d: %w", i+1, NewValidationError("Scale", "character scale must be between 1 and 600 percent"))
+		}
+		if run.UnderlineStyle != "" && !validUnderlineStyle(run.UnderlineStyle) {
+			return fmt.Errorf("run %d: %w", i+1, NewValidationError("UnderlineStyle", fmt.Sprintf("invalid underline style: %s", run.UnderlineStyle)))
+		}
+		switch run.Emphasis {
+		case "", EmphasisNone, EmphasisDot, EmphasisComma, EmphasisCircle, EmphasisUnderDot:
+		default:
+			return fmt.Errorf("run %d: %w", i+1, NewValidationError("Emphasis", fmt.Sprintf("invalid emphasis mark: %s", run.Emphasis)))
+		}
+	}
+
+	if opts.Borders != nil {
+		for _, side := range opts.Borders.sides() {
+			if side.border.Size != 0 && (side.border.Size < 2 || side.border.Size > 96) {
+				return NewValidationError("Borders", fmt.Sprintf("%s border size must be between 2 and 96 eighths of a point", side.name))
+			}
+			if side.border.Space < 0 || side.border.Space > 31 {
+				return NewValidationError("Borders", fmt.Sprintf("%s border space must be between 0 and 31 points", side.name))
+			}
+		}
+	}
+	return nil
+}
+
+// writeParagraphBordersXML writes a <w:pBdr> element when any side has a style
+func writeParagraphBordersXML(buf *bytes.Buffer, borders *ParagraphBorders) {
+	if borders == nil {
+		return
+	}
+	var sides bytes.Buffer
+	for _, side := range borders.sides() {
+		if side.border.Style == "" {
+			continue
+		}
+		size := side.border.Size
+		if size == 0 {
+			size = 4
+		}
+		color := normalizeHexColor(side.border.Color)
+		if color == "" {
+			color = "auto"
+		}
+		fmt.Fprintf(&sides, `<w:%s w:val="%s" w:sz="%d" w:space="%d" w:color="%s"/>`,
+			side.name, xmlEscape(string(side.border.Style)), size, side.border.Space, color)
+	}
+	if sides.Len() == 0 {
+		return
+	}
+	buf.WriteString("<w:pBdr>")
+	buf.Write(sides.Bytes())
+	buf.WriteString("</w:pBdr>")
+}
+
+// writeTabStopsXML writes a <w:tabs> element for the custom tab stops
+func writeTabStopsXML(buf *bytes.Buffer, tabs []TabStop) {
+	if len(tabs) == 0 {
+		return
+	}
+	buf.WriteString("<w:tabs>")
+	for _, tab := range tabs {
+		alignment := tab.Alignment
+		if alignment == "" {
+			alignment = TabAlignLeft
+		}
+		fmt.Fprintf(buf, `<w:tab w:val="%s"`, alignment)
+		if tab.Leader != "" && tab.Leader != TabLeaderNone {
+			fmt.Fprintf(buf, ` w:leader="%s"`, tab.Leader)
+		}
+		fmt.Fprintf(buf, ` w:pos="%d"/>`, tab.Position)
+	}
+	buf.WriteString("</w:tabs>")
+}
+
+// writeParagraphSpacingXML writes a <w:spacing> element when any spacing is set.
+// A line spacing without a rule is measured in 240ths of a line.
+func writeParagraphSpacingXML(buf *bytes.Buffer, opts ParagraphOptions) {
+	if opts.SpaceBefore == 0 && opts.SpaceAfter == 0 && opts.LineSpacing == 0 {
+		return
+	}
+	buf.WriteString("<w:spacing")
+	if opts.SpaceBefore > 0 {
+		fmt.Fprintf(buf, ` w:before="%d"`, opts.SpaceBefore)
+	}
+	if opts.SpaceAfter > 0 {
+		fmt.Fprintf(buf, ` w:after="%d"`, opts.SpaceAfter)
+	}
+	if opts.LineSpacing > 0 {
+		rule := opts.LineSpacingRule
+		if rule == "" {
+			rule = LineSpacingAuto
+		}
+		fmt.Fprintf(buf, ` w:line="%d" w:lineRule="%s"`, opts.LineSpacing, rule)
+	}
+	buf.WriteString("/>")
+}
+
+// writeParagraphIndentXML writes a <w:ind> element when any indent is set
+func writeParagraphIndentXML(buf *bytes.Buffer, opts ParagraphOptions) {
+	if opts.IndentLeft == 0 && opts.IndentRight == 0 && opts.IndentFirst == 0 && opts.IndentHanging == 0 {
+		return
+	}
+	buf.WriteString("<w:ind")
+	if opts.IndentLeft != 0 {
+		fmt.Fprintf(buf, ` w:left="%d"`, opts.IndentLeft)
+	}
+	if opts.IndentRight != 0 {
+		fmt.Fprintf(buf, ` w:right="%d"`, opts.IndentRight)
+	}
+	if opts.IndentHanging > 0 {
+		fmt.Fprintf(buf, ` w:hanging="%d"`, opts.IndentHanging)
+	} else if opts.IndentFirst > 0 {
+		fmt.Fprintf(buf, ` w:firstLine="%d"`, opts.IndentFirst)
+	}
+	buf.WriteString("/>")
+}
@@ -0,0 +1,93 @@
+package godocx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateParagraphXML_FormattingOrder(t *testing.T) {
+	widowControl := true
+	opts := ParagraphOptions{
+		Text:                "Espresso",
+		Style:               StyleNormal,
+		KeepNext:            true,
+		PageBreakBefore:     true,
+		WidowControl:        &widowControl,
+		SuppressLineNumbers: true,
+		Borders: &ParagraphBorders{
+			Bottom: ParagraphBorder{Style: BorderSingle, Color: "#4472C4", Size: 6, Space: 1},
+		},
+		Shading:           "f2f2f2",
+		Tabs:              []TabStop{{Position: 9000, Alignment: TabAlignRight, Leader: TabLeaderDot}},
+		Bidi:              true,
+		SpaceBefore:       120,
+		SpaceAfter:        60,
+		LineSpacing:       300,
+		LineSpacingRule:   LineSpacingAtLeast,
+		IndentLeft:        720,
+		IndentHanging:     360,
+		ContextualSpacing: true,
+		Alignment:         ParagraphAlignJustify,
+		OutlineLevel:      2,
+	}
+	if err := validateParagraphFormat(opts); err != nil {
+		t.Fatalf("validateParagraphFormat: %v", err)
+	}
+
+	got := string(generateParagraphXML(opts, listNumberingIDs{}, 0, nil))
+	want := `<w:pPr><w:keepNext/><w:pageBreakBefore/><w:widowControl/><w:suppressLineNumbers/>` +
+		`<w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="4472C4"/></w:pBdr>` +
+		`<w:shd w:val="clear" w:color="auto" w:fill="F2F2F2"/>` +
+		`<w:tabs><w:tab w:val="right" w:leader="dot" w:pos="9000"/></w:tabs>` +
+		`<w:bidi/><w:spacing w:before="120" w:after="60" w:line="300" w:lineRule="atLeast"/>` +
+		`<w:ind w:left="720" w:hanging="360"/><w:contextualSpacing/>` +
+		`<w:jc w:val="both"/><w:outlineLvl w:val="1"/></w:pPr>`
+	if !strings.Contains(got, want) {
+		t.Errorf("unexpected pPr:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestGenerateParagraphXML_WidowControlOff(t *testing.T) {
+	widowControl := false
+	got := string(generateParagraphXML(ParagraphOptions{Text: "x", WidowControl: &widowControl}, listNumberingIDs{}, 0, nil))
+	if !strings.Contains(got, `<w:widowControl w:val="0"/>`) {
+		t.Errorf("expected widow control turned off, got %s", got)
+	}
+	if got := string(generateParagraphXML(ParagraphOptions{Text: "x"}, listNumberingIDs{}, 0, nil)); strings.Contains(got, "widowControl") {
+		t.Errorf("expected no widow control when unset, got %s", got)
+	}
+}
+
+func TestInsertParagraph_PriceListTabs(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Menu</w:t></w:r></w:p>`))
+
+	err := u.InsertParagraphs([]ParagraphOptions{
+		{Text: "Espresso\t2.50", Tabs: []TabStop{{Position: 8640, Alignment: TabAlignRight, Leader: TabLeaderDot}}, Position: PositionEnd},
+		{Text: "Flat white\t3.80", Tabs: []TabStop{{Position: 8640, Alignment: TabAlignRight, Leader: TabLeaderDot}}, Position: PositionEnd},
+	})
+	if err != nil {
+		t.Fatalf("InsertParagraphs: %v", err)
+	}
+	if doc := readDocXML(t, u); strings.Count(doc, `<w:tab w:val="right" w:leader="dot" w:pos="8640"/>`) != 2 {
+		t.Errorf("expected a dot-leader tab stop on both lines, got %s", doc)
+	}
+}
+
+func TestParagraphFormatValidation(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Body</w:t></w:r></w:p>`))
+
+	bad := []ParagraphOptions{
+		{Text: "x", SpaceAfter: -1},
+		{Text: "x", LineSpacing: 240, LineSpacingRule: "double"},
+		{Text: "x", IndentFirst: 360, IndentHanging: 360},
+		{Text: "x", OutlineLevel: 10},
+		{Text: "x", Shading: "grey"},
+		{Text: "x", Tabs: []TabStop{{Position: 100, Leader: "stars"}}},
+		{Text: "x", Borders: &ParagraphBorders{Top: ParagraphBorder{Style: BorderSingle, Size: 100}}},
+	}
+	for i, opts := range bad {
+		if err := u.InsertParagraph(opts); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package godocx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// UnderlineStyle defines the underline type of a run
+type UnderlineStyle string
+
+const (
+	UnderlineSingle     UnderlineStyle = "single"
+	UnderlineWords      UnderlineStyle = "words"
+	UnderlineDouble     UnderlineStyle = "double"
+	UnderlineThick      UnderlineStyle = "thick"
+	UnderlineDotted     UnderlineStyle = "dotted"
+	UnderlineDash       UnderlineStyle = "dash"
+	UnderlineDotDash    UnderlineStyle = "dotDash"
+	UnderlineDotDotDash UnderlineStyle = "dotDotDash"
+	UnderlineWave       UnderlineStyle = "wave"
+	UnderlineWavyDouble UnderlineStyle = "wavyDouble"
+)
+
+// EmphasisMark defines the East Asian emphasis mark drawn on each character
+type EmphasisMark string
+
+const (
+	EmphasisNone     EmphasisMark = "none"
+	EmphasisDot      EmphasisMark = "dot"
+	EmphasisComma    EmphasisMark = "comma"
+	EmphasisCircle   EmphasisMark = "circle"
+	EmphasisUnderDot EmphasisMark = "underDot"
+)
+
+// validUnderlineStyle reports whether style is an ST_Underline value. Besides
+// the UnderlineStyle constants these include the heavy and long dash styles,
+// which GetParagraphRuns reads from existing documents.
+func validUnderlineStyle(style UnderlineStyle) bool {
+	switch style {
+	case UnderlineSingle, UnderlineWords, UnderlineDouble, UnderlineThick, UnderlineDotted,
+		UnderlineDash, UnderlineDotDash, UnderlineDotDotDash, UnderlineWave, UnderlineWavyDouble,
+		"none", "dottedHeavy", "dashedHeavy", "dashLong", "dashLongHeavy", "dashDotHeavy",
+		"dashDotDotHeavy", "wavyHeavy":
+		return true
+	}
+	return false
+}
+
+var (
+	// runElementPattern matches a <w:r> element, but not <w:rPr> or <w:rStyle>
+	runElementPattern = regexp.MustCompile(`(?s)<w:r(?:\s[^>]*)?>(.*?)</w:r>`)
+
+	// runPropertiesPattern matches the <w:rPr> of a run
+	runPropertiesPattern = regexp.MustCompile(`(?s)<w:rPr>(.*?)</w:rPr>`)
+
+	// runPropertyPattern matches one child element of <w:rPr>
+	runPropertyPattern = regexp.MustCompile(`<w:([A-Za-z]+)(\s[^>]*?)?/?>`)
+
+	// runContentPattern matches the text-bearing children of a run
+	runContentPattern = regexp.MustCompile(`<w:t(?:\s[^>]*)?>([^<]*)</w:t>|<w:(tab|br|cr)(?:\s[^>]*)?/>`)
+
+	// hyperlinkElementPattern matches a <w:hyperlink> element
+	hyperlinkElementPattern = regexp.MustCompile(`(?s)<w:hyperlink(\s[^>]*)?>(.*?)</w:hyperlink>`)
+)
+
+// GetParagraphRuns returns the runs of every body paragraph that has text,
+// with their character formatting, in the same order as GetParagraphText.
+// Runs inside hyperlinks carry the link's URL or BookmarkRef.
+func (u *Updater) GetParagraphRuns() ([][]RunOptions, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "document.xml"))
+	if err != nil {
+		return nil, NewXMLParseError("document.xml", err)
+	}
+	targets := u.documentRelationshipTargets()
+
+	var paragraphs [][]RunOptions
+	for _, para := range extractParaPattern.FindAll(raw, -1) {
+		if u.extractTextFromXML(para) == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, parseParagraphRuns(para, targets))
+	}
+	return paragraphs, nil
+}
+
+// parseParagraphRuns reads the text runs of a paragraph. targets maps
+// relationship IDs to hyperlink URLs.
+func parseParagraphRuns(para []byte, targets map[string]string) []RunOptions {
+	links := hyperlinkElementPattern.FindAllSubmatchIndex(para, -1)
+
+	var runs []RunOptions
+	for _, loc := range runElementPattern.FindAllSubmatchIndex(para, -1) {
+		run, ok := parseRun(para[loc[2]:loc[3]])
+		if !ok {
+			continue
+		}
+		for _, link := range links {
+			if loc[0] < link[0] || loc[1] > link[1] {
+				continue
+			}
+			var tag []byte
+			if link[2] != -1 {
+				tag = para[link[2]:link[3]]
+			}
+			if anchor := xmlAttrValue(tag, "w:anchor"); anchor != "" {
+				run.BookmarkRef = anchor
+			} else if id := xmlAttrValue(tag, "r:id"); id != "" {
+				run.URL = targets[id]
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// parseRun reads the text and formatting of a run's content. Runs without
+// text, such as field characters and drawings, report false.
+func parseRun(content []byte) (RunOptions, bool) {
+	var run RunOptions
+	if m := runPropertiesPattern.FindSubmatchIndex(content); m != nil {
+		run = parseRunProperties(content[m[2]:m[3]])
+		content = append(append([]byte{}, content[:m[0]]...), content[m[1]:]...)
+	}
+
+	var text strings.Builder
+	found := false
+	for _, m := range runContentPattern.FindAllSubmatch(content, -1) {
+		found = true
+		switch string(m[2]) {
+		case "tab":
+			text.WriteString("\t")
+		case "br", "cr":
+			text.WriteString("\n")
+		default:
+			text.WriteString(xmlUnescape(string(m[1])))
+		}
+	}
+	run.Text = text.String()
+	return run, found
+}
+
+// parseRunProperties reads the character formatting in the content of a
+// <w:rPr> element. It is the inverse of writeRunPropertiesXML.
+func parseRunProperties(rPr []byte) RunOptions {
+	var run RunOptions
+	var size, csSize float64
+
+	halfPoints := func(tag []byte) float64 {
+		return float64(atoiOrZero(xmlAttrValue(tag, "w:val"))) / FontSizeHalfPointsFactor
+	}
+
+	for _, m := range runPropertyPattern.FindAllSubmatch(rPr, -1) {
+		tag := m[0]
+		val := xmlAttrValue(tag, "w:val")
+		on := isOnOffTrue(val)
+
+		switch string(m[1]) {
+		case "rStyle":
+			run.Style = val
+		case "rFonts":
+			run.FontName = xmlAttrValue(tag, "w:ascii")
+			run.FontEastAsia = xmlAttrValue(tag, "w:eastAsia")
+			run.FontComplexScript = xmlAttrValue(tag, "w:cs")
+		case "b":
+			run.Bold = on
+		case "bCs":
+			run.BoldComplexScript = on
+		case "i":
+			run.Italic = on
+		case "iCs":
+			run.ItalicComplexScript = on
+		case "caps":
+			run.AllCaps = on
+		case "smallCaps":
+			run.SmallCaps = on
+		case "strike":
+			run.Strikethrough = on
+		case "dstrike":
+			run.DoubleStrikethrough = on
+		case "vanish":
+			run.Hidden = on
+		case "color":
+			if val != "auto" {
+				run.Color = val
+			}
+		case "spacing":
+			run.CharacterSpacing = float64(atoiOrZero(val)) / 20
+		case "w":
+			run.Scale = atoiOrZero(val)
+		case "kern":
+			run.Kerning = halfPoints(tag)
+		case "position":
+			run.Position = halfPoints(tag)
+		case "sz":
+			size = halfPoints(tag)
+		case "szCs":
+			csSize = halfPoints(tag)
+		case "highlight":
+			if val != "none" {
+				run.Highlight = val
+			}
+		case "u":
+			if val != "" && val != "none" {
+				run.Underline = true
+				if val != string(UnderlineSingle) {
+					run.UnderlineStyle = UnderlineStyle(val)
+				}
+				if color := xmlAttrValue(tag, "w:color"); color != "auto" {
+					run.UnderlineColor = color
+				}
+			}
+		case "bdr":
+			if val != "" && val != string(BorderNone) && val != "nil" {
+				run.Border = &ParagraphBorder{
+					Style: BorderStyle(val),
+					Color: xmlAttrValue(tag, "w:color"),
+					Size:  atoiOrZero(xmlAttrValue(tag, "w:sz")),
+					Space: atoiOrZero(xmlAttrValue(tag, "w:space")),
+				}
+			}
+		case "shd":
+			if fill := xmlAttrValue(tag, "w:fill"); fill != "auto" {
+				run.Shading = fill
+			}
+		case "vertAlign":
+			run.Superscript = val == "superscript"
+			run.Subscript = val == "subscript"
+		case "rtl":
+			run.RightToLeft = on
+		case "em":
+			if val != string(EmphasisNone) {
+				run.Emphasis = EmphasisMark(val)
+			}
+		case "lang":
+			run.Language = val
+			run.LanguageEastAsia = xmlAttrValue(tag, "w:eastAsia")
+			run.LanguageComplexScript = xmlAttrValue(tag, "w:bidi")
+		}
+	}
+
+	run.FontSize = size
+	if csSize != size {
+		run.FontSizeComplexScript = csSize
+	}
+	return run
+}
@@ -0,0 +1,118 @@
+package godocx
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteRunPropertiesXML_SchemaOrder(t *testing.T) {
+	run := RunOptions{
+		Style:                 "Emphasis",
+		FontName:              "Calibri",
+		FontEastAsia:          "MS Mincho",
+		FontComplexScript:     "Arial",
+		Bold:                  true,
+		BoldComplexScript:     true,
+		SmallCaps:             true,
+		DoubleStrikethrough:   true,
+		Hidden:                true,
+		Color:                 "C00000",
+		CharacterSpacing:      1.5,
+		Scale:                 90,
+		Kerning:               14,
+		Position:              -3,
+		FontSize:              12,
+		FontSizeComplexScript: 14,
+		Highlight:             "yellow",
+		UnderlineStyle:        UnderlineWave,
+		UnderlineColor:        "#0070c0",
+		Border:                &ParagraphBorder{Style: BorderSingle, Size: 4},
+		Shading:               "FFF2CC",
+		Superscript:           true,
+		RightToLeft:           true,
+		Emphasis:              EmphasisDot,
+		Language:              "en-US",
+		LanguageEastAsia:      "ja-JP",
+	}
+
+	var buf bytes.Buffer
+	writeRunPropertiesXML(&buf, run)
+	want := `<w:rPr><w:rStyle w:val="Emphasis"/>` +
+		`<w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="MS Mincho" w:cs="Arial"/>` +
+		`<w:b/><w:bCs/><w:smallCaps/><w:dstrike/><w:vanish/><w:color w:val="C00000"/>` +
+		`<w:spacing w:val="30"/><w:w w:val="90"/><w:kern w:val="28"/><w:position w:val="-6"/>` +
+		`<w:sz w:val="24"/><w:szCs w:val="28"/><w:highlight w:val="yellow"/>` +
+		`<w:u w:val="wave" w:color="0070C0"/><w:bdr w:val="single" w:sz="4" w:space="0" w:color="auto"/>` +
+		`<w:shd w:val="clear" w:color="auto" w:fill="FFF2CC"/><w:vertAlign w:val="superscript"/>` +
+		`<w:rtl/><w:em w:val="dot"/><w:lang w:val="en-US" w:eastAsia="ja-JP"/></w:rPr>`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected rPr:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestGetParagraphRuns_RoundTrip(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Intro</w:t></w:r></w:p>`))
+
+	runs := []RunOptions{
+		{Text: "Plain "},
+		{Text: "Heading\tcaps", AllCaps: true, FontSize: 11, CharacterSpacing: -0.5, Language: "de-DE"},
+		{Text: "عربي", RightToLeft: true, FontComplexScript: "Arial", BoldComplexScript: true, FontSizeComplexScript: 13, LanguageComplexScript: "ar-SA"},
+		{Text: "boxed", Border: &ParagraphBorder{Style: BorderDouble, Color: "FF0000", Size: 6, Space: 1}, Shading: "EEEEEE", Scale: 150},
+		{Text: "see above", BookmarkRef: "intro"},
+	}
+	if err := u.InsertParagraph(ParagraphOptions{Runs: runs, Position: PositionEnd}); err != nil {
+		t.Fatalf("InsertParagraph: %v", err)
+	}
+
+	paragraphs, err := u.GetParagraphRuns()
+	if err != nil {
+		t.Fatalf("GetParagraphRuns: %v", err)
+	}
+	if len(paragraphs) != 2 || paragraphs[0][0].Text != "Intro" {
+		t.Fatalf("expected two paragraphs, got %+v", paragraphs)
+	}
+
+	// Internal links are written blue and underlined.
+	want := append([]RunOptions{}, runs...)
+	want[4].Color, want[4].Underline = "0563C1", true
+	if got := paragraphs[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("runs did not round-trip:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseRunProperties_OnOffValues(t *testing.T) {
+	run := parseRunProperties([]byte(`<w:b w:val="0"/><w:i w:val="true"/><w:u w:val="none"/><w:color w:val="auto"/><w:sz w:val="20"/><w:szCs w:val="20"/>`))
+	if run.Bold || !run.Italic || run.Underline || run.Color != "" || run.FontSize != 10 || run.FontSizeComplexScript != 0 {
+		t.Errorf("unexpected run %+v", run)
+	}
+}
+
+func TestInsertParagraph_RunFormatValidation(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, `<w:p><w:r><w:t>Body</w:t></w:r></w:p>`))
+
+	bad := []RunOptions{
+		{Text: "x", Scale: 5000},
+		{Text: "x", Scale: -10},
+		{Text: "x", UnderlineStyle: "bogus"},
+		{Text: "x", Emphasis: `dot"/><w:foo`},
+	}
+	for i, run := range bad {
+		if err := u.InsertParagraph(ParagraphOptions{Runs: []RunOptions{run}, Position: PositionEnd}); err == nil {
+			t.Errorf("case %d: expected validation error for %+v", i, run)
+		}
+	}
+	if doc := readDocXML(t, u); strings.Contains(doc, "bogus") || strings.Contains(doc, "w:foo") {
+		t.Errorf("invalid runs must not be written, got %s", doc)
+	}
+
+	good := []RunOptions{
+		{Text: "wide", Scale: 600},
+		{Text: "heavy", UnderlineStyle: "dashLongHeavy"},
+		{Text: "marked", Emphasis: EmphasisUnderDot},
+	}
+	if err := u.InsertParagraph(ParagraphOptions{Runs: good, Position: PositionEnd}); err != nil {
+		t.Fatalf("InsertParagraph: %v", err)
+	}
+}
@@ -0,0 +1,715 @@
+package godocx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// sectionRange is the byte range of one top-level <w:sectPr> element in
+// document.xml. Sections appear in document order: paragraph-level sectPr
+// elements first, followed by the body-level sectPr of the final section.
+type sectionRange struct {
+	start, end int
+}
+
+// findSectionRanges returns every top-level <w:sectPr> element in docXML.
+// sectPr elements nested inside <w:sectPrChange> (tracked revisions) are part
+// of their enclosing section and are not reported separately.
+func findSectionRanges(docXML []byte) []sectionRange {
+	var ranges []sectionRange
+	pos := 0
+	for {
+		start := findNextWordTagStart(docXML, pos, "sectPr")
+		if start == -1 {
+			return ranges
+		}
+		end := sectionElementEnd(docXML, start)
+		if end == -1 {
+			return ranges
+		}
+		ranges = append(ranges, sectionRange{start: start, end: end})
+		pos = end
+	}
+}
+
+// sectionElementEnd returns the offset just past the <w:sectPr> element that
+// opens at start, accounting for nested sectPr elements, or -1 if unclosed.
+func sectionElementEnd(docXML []byte, start int) int {
+	return wordElementEnd(docXML, start, "sectPr")
+}
+
+// wordElementEnd returns the offset just past the <w:tag> element that opens
+// at start, accounting for nested elements of the same tag, or -1 if unclosed.
+func wordElementEnd(docXML []byte, start int, tag string) int {
+	closeTag := []byte("</w:" + tag + ">")
+	depth := 0
+	pos := start
+	for {
+		openIdx := findNextWordTagStart(docXML, pos, tag)
+		closeIdx := len(docXML)
+		if closeRel := bytes.Index(docXML[pos:], closeTag); closeRel != -1 {
+			closeIdx = pos + closeRel
+		} else if openIdx == -1 {
+			return -1
+		}
+
+		if openIdx != -1 && openIdx < closeIdx {
+			tagEndRel := bytes.IndexByte(docXML[openIdx:], '>')
+			if tagEndRel == -1 {
+				return -1
+			}
+			tagEnd := openIdx + tagEndRel + 1
+			if docXML[tagEnd-2] == '/' {
+				if depth == 0 {
+					return tagEnd
+				}
+			} else {
+				depth++
+			}
+			pos = tagEnd
+			continue
+		}
+
+		if closeIdx == len(docXML) {
+			return -1
+		}
+		depth--
+		pos = closeIdx + len(closeTag)
+		if depth == 0 {
+			return pos
+		}
+	}
+}
+
+// updateSectionProperties applies fn to the sectPr of the given section
+// (1-based) in document.xml. A document without any sectPr gets a body-level
+// one so that section 1 always exists.
+func (u *Updater) updateSectionProperties(section int, fn func(sectPr []byte) ([]byte, error)) error {
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	ranges := findSectionRanges(raw)
+	if len(ranges) == 0 {
+		bodyEnd := bytes.LastIndex(raw, []byte("</w:body>"))
+		if bodyEnd == -1 {
+			return fmt.Errorf("could not find </w:body> tag")
+		}
+		sectPr := []byte("<w:sectPr></w:sectPr>")
+		updated := make([]byte, 0, len(raw)+len(sectPr))
+		updated = append(updated, raw[:bodyEnd]...)
+		updated = append(updated, sectPr...)
+		updated = append(updated, raw[bodyEnd:]...)
+		raw = updated
+		ranges = []sectionRange{{start: bodyEnd, end: bodyEnd + len(sectPr)}}
+	}
+
+	if section < 1 || section > len(ranges) {
+		return NewValidationError("section", fmt.Sprintf("section %d out of range (document has %d)", section, len(ranges)))
+	}
+	r := ranges[section-1]
+
+	sectPr, err := fn(raw[r.start:r.end])
+	if err != nil {
+		return err
+	}
+
+	result := make([]byte, 0, len(raw)+len(sectPr))
+	result = append(result, raw[:r.start]...)
+	result = append(result, sectPr...)
+	result = append(result, raw[r.end:]...)
+
+	if err := atomicWriteFile(docPath, result, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+	return nil
+}
+
+// SectionColumns describes the text columns of a section
+type SectionColumns struct {
+	// Count is the number of equal-width columns (1 for a single column)
+	Count int
+
+	// Space is the gap between equal-width columns in twips
+	Space int
+
+	// Separator draws a vertical line between columns
+	Separator bool
+
+	// Custom gives each column its own width and gap. When set, the columns
+	// are not equal width and Count and Space are ignored.
+	Custom []SectionColumn
+}
+
+// SectionColumn is one column of a section with custom column widths
+type SectionColumn struct {
+	// Width is the column width in twips
+	Width int
+
+	// Space is the gap after the column in twips
+	Space int
+}
+
+// maxSectionColumns is the largest column count Word supports
+const maxSectionColumns = 45
+
+// HeaderFooterReference links a section to a header or footer part
+type HeaderFooterReference struct {
+	// Type is "default", "first" or "even"
+	Type string
+
+	// RelID is the relationship ID in document.xml.rels
+	RelID string
+
+	// Target is the part file name, e.g. "header1.xml"
+	Target string
+
+	// Inherited is true when the section has no reference of this type and
+	// uses the previous section's part (Word's "Link to Previous")
+	Inherited bool
+}
+
+// Section describes the properties of one document section
+type Section struct {
+	// Index is the 1-based position of the section in the document
+	Index int
+
+	// BreakType is how the section starts; empty means Word's default (next page)
+	BreakType SectionBreakType
+
+	// PageLayout holds the page size, orientation and margins
+	PageLayout PageLayoutOptions
+
+	// PageNumber holds the page number restart value and format, if set
+	PageNumber PageNumberOptions
+
+	// LineNumbers holds the line numbering settings, or nil if lines are not numbered
+	LineNumbers *LineNumberOptions
+
+	// Columns holds the section's column layout
+	Columns SectionColumns
+
+	// Headers and Footers list the header/footer parts the section uses,
+	// including those inherited from earlier sections
+	Headers []HeaderFooterReference
+	Footers []HeaderFooterReference
+
+	// TitlePage reports whether the first page uses a different header/footer
+	TitlePage bool
+}
+
+// SectionOptions defines changes to apply to one section.
+// Nil fields leave the corresponding properties unchanged.
+type SectionOptions struct {
+	// BreakType changes how the section starts; empty keeps the current type
+	BreakType SectionBreakType
+
+	// PageLayout replaces the page size, orientation and margins
+	PageLayout *PageLayoutOptions
+
+	// PageNumber replaces the page numbering restart value and format
+	PageNumber *PageNumberOptions
+
+	// LineNumbers replaces the line numbering; CountBy zero turns it off
+	LineNumbers *LineNumberOptions
+
+	// Columns replaces the column layout
+	Columns *SectionColumns
+}
+
+// sectPrChildOrder is the CT_SectPr child sequence (ECMA-376 §17.6.17).
+// Header and footer references share the first position.
+var sectPrChildOrder = []string{
+	"headerReference", "footnotePr", "endnotePr", "type", "pgSz", "pgMar",
+	"paperSrc", "pgBorders", "lnNumType", "pgNumType", "cols", "formProt",
+	"vAlign", "noEndnote", "titlePg", "textDirection", "bidi", "rtlGutter",
+	"docGrid", "printerSettings", "sectPrChange",
+}
+
+var hdrFtrReferencePattern = regexp.MustCompile(`<w:(header|footer)Reference\s[^>]*/>`)
+
+// Sections returns every section in the document in order. Paragraph-level
+// section breaks come first; the last entry is the body-level section.
+func (u *Updater) Sections() ([]Section, error) {
+	if u == nil {
+		return nil, fmt.Errorf("updater is nil")
+	}
+
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "document.xml"))
+	if err != nil {
+		return nil, fmt.Errorf("read document.xml: %w", err)
+	}
+
+	targets := u.documentRelationshipTargets()
+
+	ranges := findSectionRanges(raw)
+	sections := make([]Section, 0, len(ranges))
+	for i, r := range ranges {
+		sec := parseSectPr(raw[r.start:r.end], targets)
+		sec.Index = i + 1
+		if i > 0 {
+			prev := sections[i-1]
+			sec.Headers = inheritHeaderFooterReferences(sec.Headers, prev.Headers)
+			sec.Footers = inheritHeaderFooterReferences(sec.Footers, prev.Footers)
+		}
+		sections = append(sections, sec)
+	}
+	return sections, nil
+}
+
+// inheritHeaderFooterReferences adds the previous section's references for
+// every type the section does not define itself, marked as inherited.
+func inheritHeaderFooterReferences(own, prev []HeaderFooterReference) []HeaderFooterReference {
+	for _, p := range prev {
+		defined := false
+		for _, o := range own {
+			if o.Type == p.Type {
+				defined = true
+				break
+			}
+		}
+		if !defined {
+			p.Inherited = true
+			own = append(own, p)
+		}
+	}
+	return own
+}
+
+// headerFooterRef returns the header ("header") or footer ("footer")
+// reference of refType used by the section.
+func (s Section) headerFooterRef(hdrFtr, refType string) (HeaderFooterReference, bool) {
+	refs := s.Headers
+	if hdrFtr == "footer" {
+		refs = s.Footers
+	}
+	for _, ref := range refs {
+		if ref.Type == refType {
+			return ref, true
+		}
+	}
+	return HeaderFooterReference{}, false
+}
+
+// UpdateSection changes the properties of one section (1-based) without
+// affecting other sections. Elements not covered by opts are preserved.
+func (u *Updater) UpdateSection(index int, opts SectionOptions) error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+	if opts.BreakType != "" {
+		if err := validateSectionBreakType(opts.BreakType); err != nil {
+			return err
+		}
+	}
+	if opts.PageLayout != nil {
+		if err := validatePageLayoutOptions(*opts.PageLayout); err != nil {
+			return err
+		}
+	}
+	if opts.PageNumber != nil && opts.PageNumber.Start < 0 {
+		return NewValidationError("PageNumber", "page number start must be >= 0")
+	}
+	if opts.LineNumbers != nil {
+		if err := validateLineNumberOptions(*opts.LineNumbers); err != nil {
+			return err
+		}
+	}
+	if opts.Columns != nil {
+		if err := validateSectionColumns(*opts.Columns); err != nil {
+			return err
+		}
+	}
+
+	return u.updateSectionProperties(index, func(sectPr []byte) ([]byte, error) {
+		if opts.BreakType != "" {
+			sectPr = setSectPrChild(sectPr, "type", fmt.Sprintf(`<w:type w:val="%s"/>`, opts.BreakType))
+		}
+		if opts.PageLayout != nil {
+			sectPr = setSectPrPageLayout(sectPr, *opts.PageLayout)
+		}
+		if opts.PageNumber != nil {
+			sectPr = setSectPrChild(sectPr, "pgNumType", generatePageNumberTypeXML(*opts.PageNumber))
+		}
+		if opts.LineNumbers != nil {
+			sectPr = setSectPrChild(sectPr, "lnNumType", generateLineNumberTypeXML(*opts.LineNumbers))
+		}
+		if opts.Columns != nil {
+			sectPr = setSectPrChild(sectPr, "cols", generateSectionColumnsXML(*opts.Columns))
+		}
+		return sectPr, nil
+	})
+}
+
+// updateAllSectionProperties applies fn to every sectPr in document.xml,
+// creating a body-level one if the document has none.
+func (u *Updater) updateAllSectionProperties(fn func(sectPr []byte) ([]byte, error)) error {
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	ranges := findSectionRanges(raw)
+	if len(ranges) == 0 {
+		return u.updateSectionProperties(1, fn)
+	}
+
+	// Rewrite back to front so earlier offsets stay valid.
+	for i := len(ranges) - 1; i >= 0; i-- {
+		r := ranges[i]
+		sectPr, err := fn(raw[r.start:r.end])
+		if err != nil {
+			return err
+		}
+		raw = spliceBytes(raw, r.start, r.end, sectPr)
+	}
+
+	if err := atomicWriteFile(docPath, raw, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+	return nil
+}
+
+// parseSectPr reads the section properties from one sectPr element.
+// targets maps relationship IDs to part names for header/footer references.
+func parseSectPr(sectPr []byte, targets map[string]string) Section {
+	var sec Section
+
+	if tag := sectPrChildTag(sectPr, "type"); tag != nil {
+		sec.BreakType = SectionBreakType(xmlAttrValue(tag, "w:val"))
+	}
+	if tag := sectPrChildTag(sectPr, "pgSz"); tag != nil {
+		sec.PageLayout.PageWidth = atoiOrZero(xmlAttrValue(tag, "w:w"))
+		sec.PageLayout.PageHeight = atoiOrZero(xmlAttrValue(tag, "w:h"))
+		sec.PageLayout.Orientation = OrientationPortrait
+		if xmlAttrValue(tag, "w:orient") == string(OrientationLandscape) {
+			sec.PageLayout.Orientation = OrientationLandscape
+		}
+	}
+	if tag := sectPrChildTag(sectPr, "pgMar"); tag != nil {
+		sec.PageLayout.MarginTop = atoiOrZero(xmlAttrValue(tag, "w:top"))
+		sec.PageLayout.MarginRight = atoiOrZero(xmlAttrValue(tag, "w:right"))
+		sec.PageLayout.MarginBottom = atoiOrZero(xmlAttrValue(tag, "w:bottom"))
+		sec.PageLayout.MarginLeft = atoiOrZero(xmlAttrValue(tag, "w:left"))
+		sec.PageLayout.MarginHeader = atoiOrZero(xmlAttrValue(tag, "w:header"))
+		sec.PageLayout.MarginFooter = atoiOrZero(xmlAttrValue(tag, "w:footer"))
+		sec.PageLayout.MarginGutter = atoiOrZero(xmlAttrValue(tag, "w:gutter"))
+	}
+	sec.PageLayout.Borders = parsePageBorders(sectPr)
+	if tag := sectPrChildTag(sectPr, "vAlign"); tag != nil {
+		sec.PageLayout.VerticalAlign = PageVerticalAlignment(xmlAttrValue(tag, "w:val"))
+	}
+	sec.LineNumbers = parseLineNumberType(sectPr)
+	if tag := sectPrChildTag(sectPr, "pgNumType"); tag != nil {
+		sec.PageNumber.Start = atoiOrZero(xmlAttrValue(tag, "w:start"))
+		sec.PageNumber.Format = PageNumberFormat(xmlAttrValue(tag, "w:fmt"))
+	}
+
+	sec.Columns = parseSectionColumns(sectPr)
+
+	if tag := sectPrChildTag(sectPr, "titlePg"); tag != nil {
+		sec.TitlePage = isOnOffTrue(xmlAttrValue(tag, "w:val"))
+	}
+
+	limit := sectPrChildLimit(sectPr)
+	for _, m := range hdrFtrReferencePattern.FindAllSubmatchIndex(sectPr[:limit], -1) {
+		tag := sectPr[m[0]:m[1]]
+		ref := HeaderFooterReference{
+			Type:  xmlAttrValue(tag, "w:type"),
+			RelID: xmlAttrValue(tag, "r:id"),
+		}
+		ref.Target = targets[ref.RelID]
+		if string(sectPr[m[2]:m[3]]) == "header" {
+			sec.Headers = append(sec.Headers, ref)
+		} else {
+			sec.Footers = append(sec.Footers, ref)
+		}
+	}
+
+	return sec
+}
+
+// documentRelationshipTargets maps relationship IDs in document.xml.rels to
+// their targets. A missing or unreadable rels part yields an empty map.
+func (u *Updater) documentRelationshipTargets() map[string]string {
+	targets := make(map[string]string)
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "_rels", "document.xml.rels"))
+	if err != nil {
+		return targets
+	}
+	var rels relationships
+	if err := xml.Unmarshal(raw, &rels); err != nil {
+		return targets
+	}
+	for _, rel := range rels.Relationships {
+		targets[rel.ID] = rel.Target
+	}
+	return targets
+}
+
+// setSectPrPageLayout replaces the page size and margins of a sectPr, and the
+// columns, borders and vertical alignment when the layout sets them.
+func setSectPrPageLayout(sectPr []byte, layout PageLayoutOptions) []byte {
+	orientAttr := ""
+	if layout.Orientation == OrientationLandscape {
+		orientAttr = ` w:orient="landscape"`
+	}
+	pgSz := fmt.Sprintf(`<w:pgSz w:w="%d" w:h="%d"%s/>`, layout.PageWidth, layout.PageHeight, orientAttr)
+	pgMar := fmt.Sprintf(`<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="%d" w:footer="%d" w:gutter="%d"/>`,
+		layout.MarginTop, layout.MarginRight, layout.MarginBottom, layout.MarginLeft,
+		layout.MarginHeader, layout.MarginFooter, layout.MarginGutter)
+
+	sectPr = setSectPrChild(sectPr, "pgSz", pgSz)
+	sectPr = setSectPrChild(sectPr, "pgMar", pgMar)
+	if layout.Borders != nil {
+		sectPr = setSectPrChild(sectPr, "pgBorders", generatePageBordersXMLWithPrefix("w", *layout.Borders))
+	}
+	if layout.Columns != nil {
+		sectPr = setSectPrChild(sectPr, "cols", generateSectionColumnsXML(*layout.Columns))
+	}
+	if layout.VerticalAlign != "" {
+		sectPr = setSectPrChild(sectPr, "vAlign", generateVerticalAlignXMLWithPrefix("w", layout.VerticalAlign))
+	}
+	return sectPr
+}
+
+// generatePageNumberTypeXML builds a <w:pgNumType> element, or "" when
+// neither a start value nor a format is set.
+func generatePageNumberTypeXML(opts PageNumberOptions) string {
+	switch {
+	case opts.Start > 0 && opts.Format != "":
+		return fmt.Sprintf(`<w:pgNumType w:start="%d" w:fmt="%s"/>`, opts.Start, opts.Format)
+	case opts.Start > 0:
+		return fmt.Sprintf(`<w:pgNumType w:start="%d"/>`, opts.Start)
+	case opts.Format != "":
+		return fmt.Sprintf(`<w:pgNumType w:fmt="%s"/>`, opts.Format)
+	}
+	return ""
+}
+
+// validatePageLayoutOptions checks the optional column, border and vertical
+// alignment settings of a page layout
+func validatePageLayoutOptions(layout PageLayoutOptions) error {
+	if layout.Columns != nil {
+		if err := validateSectionColumns(*layout.Columns); err != nil {
+			return err
+		}
+	}
+	return validatePageLayoutDecorations(layout)
+}
+
+// validateSectionColumns checks a column layout
+func validateSectionColumns(cols SectionColumns) error {
+	if len(cols.Custom) > 0 {
+		if len(cols.Custom) > maxSectionColumns {
+			return NewValidationError("Columns", fmt.Sprintf("at most %d columns are supported", maxSectionColumns))
+		}
+		for i, col := range cols.Custom {
+			if col.Width <= 0 || col.Space < 0 {
+				return NewValidationError("Columns", fmt.Sprintf("column %d needs a positive width and a non-negative space", i+1))
+			}
+		}
+		return nil
+	}
+	if cols.Count < 1 || cols.Count > maxSectionColumns {
+		return NewValidationError("Columns", fmt.Sprintf("column count must be between 1 and %d", maxSectionColumns))
+	}
+	if cols.Space < 0 {
+		return NewValidationError("Columns", "column space must be >= 0")
+	}
+	return nil
+}
+
+// generateSectionColumnsXML builds a <w:cols> element.
+func generateSectionColumnsXML(cols SectionColumns) string {
+	return generateSectionColumnsXMLWithPrefix("w", cols)
+}
+
+// generateSectionColumnsXMLWithPrefix builds a <w:cols> element using the
+// given WordprocessingML namespace prefix.
+func generateSectionColumnsXMLWithPrefix(ns string, cols SectionColumns) string {
+	sep := ""
+	if cols.Separator {
+		sep = fmt.Sprintf(` %s:sep="1"`, ns)
+	}
+
+	if len(cols.Custom) > 0 {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, `<%s:cols %s:num="%d"%s %s:equalWidth="0">`, ns, ns, len(cols.Custom), sep, ns)
+		for _, col := range cols.Custom {
+			fmt.Fprintf(&buf, `<%s:col %s:w="%d" %s:space="%d"/>`, ns, ns, col.Width, ns, col.Space)
+		}
+		fmt.Fprintf(&buf, `</%s:cols>`, ns)
+		return buf.String()
+	}
+
+	if cols.Count <= 1 {
+		return fmt.Sprintf(`<%s:cols %s:space="%d"%s/>`, ns, ns, cols.Space, sep)
+	}
+	return fmt.Sprintf(`<%s:cols %s:num="%d" %s:space="%d"%s/>`, ns, ns, cols.Count, ns, cols.Space, sep)
+}
+
+// colPattern matches the <w:col> children of a custom column layout
+var colPattern = regexp.MustCompile(`<w:col\s[^>]*/>`)
+
+// parseSectionColumns reads the column layout of a sectPr.
+func parseSectionColumns(sectPr []byte) SectionColumns {
+	cols := SectionColumns{Count: 1}
+	start, end, ok := sectPrChildRange(sectPr, "cols")
+	if !ok {
+		return cols
+	}
+	element := sectPr[start:end]
+	tag := sectPrChildTag(sectPr, "cols")
+
+	if n := atoiOrZero(xmlAttrValue(tag, "w:num")); n > 0 {
+		cols.Count = n
+	}
+	cols.Space = atoiOrZero(xmlAttrValue(tag, "w:space"))
+	if sep := xmlAttrValue(tag, "w:sep"); sep != "" {
+		cols.Separator = isOnOffTrue(sep)
+	}
+
+	if equal := xmlAttrValue(tag, "w:equalWidth"); equal != "" && !isOnOffTrue(equal) {
+		for _, col := range colPattern.FindAll(element, -1) {
+			cols.Custom = append(cols.Custom, SectionColumn{
+				Width: atoiOrZero(xmlAttrValue(col, "w:w")),
+				Space: atoiOrZero(xmlAttrValue(col, "w:space")),
+			})
+		}
+	}
+	return cols
+}
+
+// sectPrChildLimit returns the offset where the direct children of a sectPr
+// end: the start of a nested <w:sectPrChange>, or the closing tag.
+func sectPrChildLimit(sectPr []byte) int {
+	if idx := findNextWordTagStart(sectPr, 0, "sectPrChange"); idx != -1 {
+		return idx
+	}
+	if idx := bytes.LastIndex(sectPr, []byte("</w:sectPr>")); idx != -1 {
+		return idx
+	}
+	return len(sectPr)
+}
+
+// sectPrChildRange locates a direct child element of a sectPr by local name.
+func sectPrChildRange(sectPr []byte, tag string) (start, end int, ok bool) {
+	openEnd := bytes.IndexByte(sectPr, '>') + 1
+	limit := sectPrChildLimit(sectPr)
+	if openEnd <= 0 || openEnd > limit {
+		return 0, 0, false
+	}
+
+	start = findNextWordTagStart(sectPr, openEnd, tag)
+	if start == -1 || start >= limit {
+		return 0, 0, false
+	}
+	tagEndRel := bytes.IndexByte(sectPr[start:], '>')
+	if tagEndRel == -1 {
+		return 0, 0, false
+	}
+	end = start + tagEndRel + 1
+	if sectPr[end-2] != '/' {
+		closeTag := []byte("</w:" + tag + ">")
+		closeRel := bytes.Index(sectPr[end:], closeTag)
+		if closeRel == -1 {
+			return 0, 0, false
+		}
+		end += closeRel + len(closeTag)
+	}
+	return start, end, true
+}
+
+// sectPrChildTag returns the opening tag of a direct sectPr child, or nil.
+func sectPrChildTag(sectPr []byte, tag string) []byte {
+	start, end, ok := sectPrChildRange(sectPr, tag)
+	if !ok {
+		return nil
+	}
+	tagEnd := bytes.IndexByte(sectPr[start:end], '>')
+	return sectPr[start : start+tagEnd+1]
+}
+
+// setSectPrChild replaces the direct sectPr child with the given local name
+// by element, or inserts element at its schema position. An empty element
+// removes the child.
+func setSectPrChild(sectPr []byte, tag, element string) []byte {
+	sectPr = expandSelfClosingSectPr(sectPr)
+
+	if start, end, ok := sectPrChildRange(sectPr, tag); ok {
+		return spliceBytes(sectPr, start, end, []byte(element))
+	}
+	if element == "" {
+		return sectPr
+	}
+
+	rank := sectPrChildRank(tag)
+	insertAt := sectPrChildLimit(sectPr)
+	for _, later := range sectPrChildOrder[rank+1:] {
+		if start, _, ok := sectPrChildRange(sectPr, later); ok && start < insertAt {
+			insertAt = start
+		}
+	}
+	return spliceBytes(sectPr, insertAt, insertAt, []byte(element))
+}
+
+// sectPrChildRank returns the schema position of a sectPr child.
+func sectPrChildRank(tag string) int {
+	if tag == "footerReference" {
+		return 0
+	}
+	for i, name := range sectPrChildOrder {
+		if name == tag {
+			return i
+		}
+	}
+	return len(sectPrChildOrder) - 1
+}
+
+// xmlAttrValue returns the unescaped value of attr in a single XML tag, or "".
+func xmlAttrValue(tag []byte, attr string) string {
+	needle := []byte(attr + `="`)
+	for pos := 0; ; {
+		idx := bytes.Index(tag[pos:], needle)
+		if idx == -1 {
+			return ""
+		}
+		idx += pos
+		if idx > 0 && (tag[idx-1] == ' ' || tag[idx-1] == '\t' || tag[idx-1] == '\n' || tag[idx-1] == '\r') {
+			valStart := idx + len(needle)
+			valEnd := bytes.IndexByte(tag[valStart:], '"')
+			if valEnd == -1 {
+				return ""
+			}
+			return xmlUnescape(string(tag[valStart : valStart+valEnd]))
+		}
+		pos = idx + len(needle)
+	}
+}
+
+// atoiOrZero parses s as an integer, returning 0 for empty or invalid input.
+func atoiOrZero(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// isOnOffTrue interprets an ST_OnOff attribute value; an absent value is true.
+func isOnOffTrue(val string) bool {
+	switch val {
+	case "0", "false", "off":
+		return false
+	}
+	return true
+}
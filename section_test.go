@@ -0,0 +1,196 @@
+package godocx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const twoSectionBody = `<w:p><w:r><w:t>Report</w:t></w:r></w:p>` +
+	`<w:p><w:pPr><w:sectPr><w:headerReference w:type="default" r:id="rId7"/>` +
+	`<w:pgSz w:w="12240" w:h="15840"/>` +
+	`<w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="720" w:footer="720" w:gutter="0"/>` +
+	`<w:pgNumType w:fmt="lowerRoman"/><w:titlePg/></w:sectPr></w:pPr></w:p>` +
+	`<w:p><w:r><w:t>Appendix</w:t></w:r></w:p>` +
+	`<w:sectPr><w:type w:val="nextPage"/><w:pgSz w:w="15840" w:h="12240" w:orient="landscape"/>` +
+	`<w:pgMar w:top="720" w:right="720" w:bottom="720" w:left="720" w:header="360" w:footer="360" w:gutter="0"/>` +
+	`<w:cols w:num="2" w:space="360"/></w:sectPr>`
+
+func TestSections_ReadsEverySection(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	rels := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId7" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>` +
+		`</Relationships>`
+	if err := os.WriteFile(filepath.Join(u.TempDir(), "word", "_rels", "document.xml.rels"), []byte(rels), 0o644); err != nil {
+		t.Fatalf("write rels: %v", err)
+	}
+
+	sections, err := u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(sections))
+	}
+
+	first := sections[0]
+	if first.Index != 1 || first.PageLayout.Orientation != OrientationPortrait || first.PageLayout.MarginTop != 1440 {
+		t.Errorf("unexpected first section %+v", first)
+	}
+	if first.PageNumber.Format != PageNumLowerRoman || !first.TitlePage || first.Columns.Count != 1 {
+		t.Errorf("unexpected first section numbering %+v", first)
+	}
+	if len(first.Headers) != 1 || first.Headers[0].Target != "header1.xml" || first.Headers[0].Type != "default" {
+		t.Errorf("unexpected header references %+v", first.Headers)
+	}
+
+	last := sections[1]
+	if last.BreakType != SectionBreakNextPage || last.PageLayout.Orientation != OrientationLandscape || last.PageLayout.PageWidth != 15840 {
+		t.Errorf("unexpected last section %+v", last)
+	}
+	if !reflect.DeepEqual(last.Columns, SectionColumns{Count: 2, Space: 360}) {
+		t.Errorf("unexpected columns %+v", last.Columns)
+	}
+}
+
+func TestUpdateSection_ChangesOnlyTargetSection(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	err := u.UpdateSection(1, SectionOptions{
+		BreakType:  SectionBreakOddPage,
+		PageLayout: PageLayoutA4Landscape(),
+		PageNumber: &PageNumberOptions{Start: 1, Format: PageNumDecimal},
+		Columns:    &SectionColumns{Count: 3, Space: 240},
+	})
+	if err != nil {
+		t.Fatalf("UpdateSection: %v", err)
+	}
+
+	sections, err := u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	first := sections[0]
+	if first.BreakType != SectionBreakOddPage || first.PageLayout.PageWidth != PageHeightA4 ||
+		first.PageLayout.Orientation != OrientationLandscape {
+		t.Errorf("section 1 not updated: %+v", first)
+	}
+	if first.PageNumber.Start != 1 || first.Columns.Count != 3 || !first.TitlePage || len(first.Headers) != 1 {
+		t.Errorf("section 1 lost or missed properties: %+v", first)
+	}
+	if sections[1].PageLayout.PageWidth != 15840 || sections[1].Columns.Count != 2 {
+		t.Errorf("section 2 must be unchanged: %+v", sections[1])
+	}
+
+	docXML := readDocXML(t, u)
+	want := `<w:sectPr><w:headerReference w:type="default" r:id="rId7"/><w:type w:val="oddPage"/><w:pgSz`
+	if !strings.Contains(docXML, want) {
+		t.Errorf("expected type after header reference, got %s", docXML)
+	}
+	if !strings.Contains(docXML, `<w:pgNumType w:start="1" w:fmt="decimal"/><w:cols w:num="3" w:space="240"/><w:titlePg/>`) {
+		t.Errorf("expected elements in schema order, got %s", docXML)
+	}
+}
+
+func TestUpdateSection_Validation(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, twoSectionBody))
+
+	if err := u.UpdateSection(3, SectionOptions{BreakType: SectionBreakContinuous}); err == nil {
+		t.Error("expected error for out-of-range section")
+	}
+	if err := u.UpdateSection(1, SectionOptions{BreakType: "sideways"}); err == nil {
+		t.Error("expected error for invalid break type")
+	}
+	if err := u.UpdateSection(1, SectionOptions{Columns: &SectionColumns{}}); err == nil {
+		t.Error("expected error for zero columns")
+	}
+}
+
+func TestSetSectPrChild(t *testing.T) {
+	tests := []struct {
+		name, sectPr, tag, element, want string
+	}{
+		{"replace", `<w:sectPr><w:pgSz w:w="1"/></w:sectPr>`, "pgSz", `<w:pgSz w:w="2"/>`, `<w:sectPr><w:pgSz w:w="2"/></w:sectPr>`},
+		{"insert before later", `<w:sectPr><w:pgMar w:top="1"/></w:sectPr>`, "pgSz", `<w:pgSz/>`, `<w:sectPr><w:pgSz/><w:pgMar w:top="1"/></w:sectPr>`},
+		{"append", `<w:sectPr><w:pgSz/></w:sectPr>`, "cols", `<w:cols/>`, `<w:sectPr><w:pgSz/><w:cols/></w:sectPr>`},
+		{"self-closing", `<w:sectPr/>`, "type", `<w:type w:val="continuous"/>`, `<w:sectPr><w:type w:val="continuous"/></w:sectPr>`},
+		{"remove", `<w:sectPr><w:cols><w:col w:w="1"/></w:cols></w:sectPr>`, "cols", "", `<w:sectPr></w:sectPr>`},
+		{"ignores tracked change", `<w:sectPr><w:sectPrChange><w:sectPr><w:cols/></w:sectPr></w:sectPrChange></w:sectPr>`, "cols", `<w:cols w:num="2"/>`,
+			`<w:sectPr><w:cols w:num="2"/><w:sectPrChange><w:sectPr><w:cols/></w:sectPr></w:sectPrChange></w:sectPr>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(setSectPrChild([]byte(tt.sectPr), tt.tag, tt.element)); got != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSectionColumns_NewsletterLayout(t *testing.T) {
+	body := `<w:p><w:r><w:t>Masthead</w:t></w:r></w:p><w:p><w:r><w:t>Lead story</w:t></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	// A continuous break after the single-column masthead; the story section
+	// that follows also starts continuously, on the same page, in three columns.
+	single := PageLayoutLetterPortrait()
+	single.Columns = &SectionColumns{Count: 1}
+	if err := u.InsertSectionBreak(BreakOptions{Position: PositionAfterText, Anchor: "Masthead", SectionType: SectionBreakContinuous, PageLayout: single}); err != nil {
+		t.Fatalf("InsertSectionBreak: %v", err)
+	}
+	layout := PageLayoutLetterPortrait()
+	layout.Columns = &SectionColumns{Custom: []SectionColumn{{Width: 3000, Space: 360}, {Width: 2000, Space: 360}, {Width: 3000}}, Separator: true}
+	if err := u.UpdateSection(2, SectionOptions{BreakType: SectionBreakContinuous, PageLayout: layout}); err != nil {
+		t.Fatalf("UpdateSection: %v", err)
+	}
+	if err := u.InsertColumnBreak(BreakOptions{Position: PositionBeforeText, Anchor: "Lead story"}); err != nil {
+		t.Fatalf("InsertColumnBreak: %v", err)
+	}
+
+	doc := readDocXML(t, u)
+	if !strings.Contains(doc, `<w:cols w:num="3" w:sep="1" w:equalWidth="0"><w:col w:w="3000" w:space="360"/><w:col w:w="2000" w:space="360"/><w:col w:w="3000" w:space="0"/></w:cols>`) {
+		t.Errorf("expected custom columns in body section, got %s", doc)
+	}
+	if !strings.Contains(doc, `<w:p><w:r><w:br w:type="column"/></w:r></w:p><w:p><w:r><w:t>Lead story`) {
+		t.Errorf("expected column break before lead story, got %s", doc)
+	}
+
+	sections, err := u.Sections()
+	if err != nil {
+		t.Fatalf("Sections: %v", err)
+	}
+	if sections[0].BreakType != SectionBreakContinuous || sections[0].Columns.Count != 1 {
+		t.Errorf("unexpected masthead section %+v", sections[0])
+	}
+	want := SectionColumns{Count: 3, Separator: true, Custom: layout.Columns.Custom}
+	if !reflect.DeepEqual(sections[1].Columns, want) {
+		t.Errorf("got columns %+v, want %+v", sections[1].Columns, want)
+	}
+}
+
+func TestGenerateSectionColumnsXML(t *testing.T) {
+	tests := []struct {
+		cols SectionColumns
+		want string
+	}{
+		{SectionColumns{Count: 1, Space: 720}, `<w:cols w:space="720"/>`},
+		{SectionColumns{Count: 2, Space: 360, Separator: true}, `<w:cols w:num="2" w:space="360" w:sep="1"/>`},
+		{SectionColumns{Custom: []SectionColumn{{Width: 4000, Space: 200}, {Width: 5000}}}, `<w:cols w:num="2" w:equalWidth="0"><w:col w:w="4000" w:space="200"/><w:col w:w="5000" w:space="0"/></w:cols>`},
+	}
+	for _, tt := range tests {
+		if got := generateSectionColumnsXML(tt.cols); got != tt.want {
+			t.Errorf("got %s\nwant %s", got, tt.want)
+		}
+	}
+
+	for _, bad := range []SectionColumns{{Count: 46}, {Count: 2, Space: -1}, {Custom: []SectionColumn{{Width: 0}}}} {
+		if err := validateSectionColumns(bad); err == nil {
+			t.Errorf("expected validation error for %+v", bad)
+		}
+	}
+}
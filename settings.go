@@ -1,6 +1,7 @@
 package godocx
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,6 +17,10 @@ const (
 	minimalSettingsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
 <w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
 <w:updateFields w:val="1"/>
+</w:settings>`
+
+	emptySettingsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
 </w:settings>`
 )
 
@@ -82,6 +87,61 @@ func (u *Updater) ForceFieldUpdateOnOpen() error {
 	return u.markHeaderFooterFieldsDirty()
 }
 
+// ensureSettingsXML returns the path of word/settings.xml, creating an empty
+// settings part wired into [Content_Types].xml and the document relationships
+// if the document does not have one.
+func (u *Updater) ensureSettingsXML() (string, error) {
+	settingsPath := filepath.Join(u.tempDir, "word", "settings.xml")
+	if _, err := os.Stat(settingsPath); err == nil {
+		return settingsPath, nil
+	}
+
+	if err := atomicWriteFile(settingsPath, []byte(emptySettingsXML), 0o644); err != nil {
+		return "", fmt.Errorf("write settings.xml: %w", err)
+	}
+	if err := u.addSettingsContentType(); err != nil {
+		return "", fmt.Errorf("add settings content type: %w", err)
+	}
+	if err := u.addSettingsRelationship(); err != nil {
+		return "", fmt.Errorf("add settings relationship: %w", err)
+	}
+	return settingsPath, nil
+}
+
+// setSettingsElement replaces the settings.xml element with the given local
+// name by element, or inserts it before the first of successors present (or
+// before </w:settings>). An empty element removes it.
+func (u *Updater) setSettingsElement(tag, element string, successors []string) error {
+	settingsPath, err := u.ensureSettingsXML()
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return fmt.Errorf("read settings.xml: %w", err)
+	}
+
+	pattern := regexp.MustCompile(fmt.Sprintf(`(?s)<w:%s(?:\s[^>]*)?(?:/>|>.*?</w:%s>)`, tag, tag))
+	if loc := pattern.FindIndex(raw); loc != nil {
+		raw = spliceBytes(raw, loc[0], loc[1], []byte(element))
+	} else if element != "" {
+		insertAt := bytes.LastIndex(raw, []byte("</w:settings>"))
+		if insertAt == -1 {
+			return fmt.Errorf("could not find </w:settings> tag")
+		}
+		for _, next := range successors {
+			if idx := bytes.Index(raw, []byte(next)); idx != -1 && idx < insertAt {
+				insertAt = idx
+			}
+		}
+		raw = spliceBytes(raw, insertAt, insertAt, []byte(element))
+	} else {
+		return nil
+	}
+
+	return atomicWriteFile(settingsPath, raw, 0o644)
+}
+
 // markHeaderFooterFieldsDirty scans every word/header*.xml and word/footer*.xml
 // file and adds w:dirty="true" to each <w:fldChar w:fldCharType="begin"> element
 // that does not already carry the attribute. This is required because the
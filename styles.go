@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -60,6 +61,9 @@ type StyleDefinition struct {
 	KeepLines    bool
 	PageBreakBef bool
 
+	// SuppressLineNumbers excludes paragraphs with this style from line numbering
+	SuppressLineNumbers bool
+
 	// Outline level (0-8, paragraph styles only, used for TOC)
 	OutlineLevel int
 }
@@ -212,6 +216,10 @@ func generateStyleParagraphProps(def StyleDefinition) string {
 		inner.WriteString("<w:pageBreakBefore/>")
 		hasProps = true
 	}
+	if def.SuppressLineNumbers {
+		inner.WriteString("<w:suppressLineNumbers/>")
+		hasProps = true
+	}
 
 	if def.OutlineLevel > 0 && def.OutlineLevel <= 9 {
 		inner.WriteString(fmt.Sprintf(`<w:outlineLvl w:val="%d"/>`, def.OutlineLevel-1))
@@ -365,3 +373,36 @@ func (u *Updater) ensureStylesRelationship() error {
 
 	return nil
 }
+
+var (
+	// styleNamePattern matches a style definition and its display name
+	styleNamePattern = regexp.MustCompile(`(?s)<w:style\s[^>]*?w:styleId="([^"]*)"[^>]*>.*?<w:name w:val="([^"]*)"`)
+
+	// headingNamePattern matches the built-in heading style names and IDs
+	headingNamePattern = regexp.MustCompile(`^(?i:heading) ?([1-9])$`)
+)
+
+// readStyleNames maps the style IDs defined in styles.xml to their display
+// names. A missing styles part yields an empty map.
+func (u *Updater) readStyleNames() map[string]string {
+	names := make(map[string]string)
+	raw, err := os.ReadFile(filepath.Join(u.tempDir, "word", "styles.xml"))
+	if err != nil {
+		return names
+	}
+	for _, m := range styleNamePattern.FindAllSubmatch(raw, -1) {
+		names[xmlUnescape(string(m[1]))] = xmlUnescape(string(m[2]))
+	}
+	return names
+}
+
+// styleHeadingLevel returns the level of a built-in heading style, or 0.
+// styleNames maps style IDs to display names.
+func styleHeadingLevel(styleNames map[string]string, styleID string) int {
+	for _, name := range []string{styleNames[styleID], styleID} {
+		if m := headingNamePattern.FindStringSubmatch(name); m != nil {
+			return atoiOrZero(m[1])
+		}
+	}
+	return 0
+}
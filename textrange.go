@@ -0,0 +1,364 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// textSegment is one <w:t> element inside a paragraph, mapped to its position
+// in the paragraph's concatenated run text.
+type textSegment struct {
+	elemStart, elemEnd int // byte range of <w:t ...>...</w:t> in the paragraph XML
+	plainStart         int // offset of the segment's first character in the plain text
+	plain              string
+}
+
+// paragraphTextSegments returns the <w:t> segments of a paragraph together
+// with the concatenated, unescaped text they contain.
+func paragraphTextSegments(paraXML []byte) ([]textSegment, string) {
+	var segments []textSegment
+	var plain strings.Builder
+
+	searchPos := 0
+	for {
+		tStart := findNextWordTagStart(paraXML, searchPos, "t")
+		if tStart == -1 {
+			break
+		}
+		openEndRel := bytes.IndexByte(paraXML[tStart:], '>')
+		if openEndRel == -1 {
+			break
+		}
+		// Self-closing <w:t/> carries no text.
+		if paraXML[tStart+openEndRel-1] == '/' {
+			searchPos = tStart + openEndRel + 1
+			continue
+		}
+		textStart := tStart + openEndRel + 1
+		closeRel := bytes.Index(paraXML[textStart:], []byte("</w:t>"))
+		if closeRel == -1 {
+			break
+		}
+		textEnd := textStart + closeRel
+
+		text := xmlUnescape(string(paraXML[textStart:textEnd]))
+		segments = append(segments, textSegment{
+			elemStart:  tStart,
+			elemEnd:    textEnd + len("</w:t>"),
+			plainStart: plain.Len(),
+			plain:      text,
+		})
+		plain.WriteString(text)
+		searchPos = textEnd + len("</w:t>")
+	}
+
+	return segments, plain.String()
+}
+
+// insertAtTextBoundary inserts markup immediately before the first character
+// of text (atEnd false) or immediately after its last character (atEnd true)
+// inside paraXML, splitting the run at the boundary when needed. Run
+// properties are duplicated onto both halves of a split run. Returns false if
+// text does not occur in the paragraph's run text.
+func insertAtTextBoundary(paraXML []byte, text string, atEnd bool, markup string) ([]byte, bool) {
+	if text == "" {
+		return nil, false
+	}
+	_, plain := paragraphTextSegments(paraXML)
+	start := strings.Index(plain, text)
+	if start == -1 {
+		return nil, false
+	}
+
+	offset := start
+	if atEnd {
+		offset = start + len(text)
+	}
+	return insertAtTextOffset(paraXML, offset, atEnd, markup)
+}
+
+// insertAtTextOffset inserts markup at byte offset of the paragraph's run
+// text, splitting the run there when needed. With atEnd the markup follows
+// the character before offset; otherwise it precedes the character at offset.
+func insertAtTextOffset(paraXML []byte, offset int, atEnd bool, markup string) ([]byte, bool) {
+	segments, _ := paragraphTextSegments(paraXML)
+	for _, seg := range segments {
+		segEnd := seg.plainStart + len(seg.plain)
+		inSegment := offset >= seg.plainStart && offset < segEnd
+		if atEnd {
+			inSegment = offset > seg.plainStart && offset <= segEnd
+		}
+		if !inSegment {
+			continue
+		}
+		result, err := splitRunAtSegment(paraXML, seg, offset-seg.plainStart, markup)
+		if err != nil {
+			return nil, false
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// wrapTextInParagraph inserts before ahead of the first occurrence of text in
+// paraXML and after directly behind it. Returns false if text is not found.
+func wrapTextInParagraph(paraXML []byte, text, before, after string) ([]byte, bool) {
+	// Insert the closing markup first so that the opening boundary is still
+	// located at the same run offset afterwards.
+	withEnd, ok := insertAtTextBoundary(paraXML, text, true, after)
+	if !ok {
+		return nil, false
+	}
+	return insertAtTextBoundary(withEnd, text, false, before)
+}
+
+// splitRunAtSegment splits the run containing seg so that markup can be
+// placed k bytes into the segment's plain text, then inserts markup at that
+// point. When the boundary coincides with the start or end of the run's
+// content no split is performed and markup is placed beside the run.
+func splitRunAtSegment(paraXML []byte, seg textSegment, k int, markup string) ([]byte, error) {
+	runStart := lastWordRunStart(paraXML, seg.elemStart)
+	if runStart == -1 {
+		return nil, fmt.Errorf("no run encloses text segment")
+	}
+	runCloseRel := bytes.Index(paraXML[seg.elemEnd:], []byte("</w:r>"))
+	if runCloseRel == -1 {
+		return nil, fmt.Errorf("unclosed run")
+	}
+	runClose := seg.elemEnd + runCloseRel
+	runEnd := runClose + len("</w:r>")
+
+	openEndRel := bytes.IndexByte(paraXML[runStart:], '>')
+	if openEndRel == -1 {
+		return nil, fmt.Errorf("malformed run tag")
+	}
+	openEnd := runStart + openEndRel + 1
+	openTag := paraXML[runStart:openEnd]
+
+	contentStart := openEnd
+	var rPr []byte
+	if loc := runRprPattern.FindIndex(paraXML[openEnd:seg.elemStart]); loc != nil && loc[0] == 0 {
+		rPr = paraXML[openEnd : openEnd+loc[1]]
+		contentStart = openEnd + loc[1]
+	}
+
+	var head, tail bytes.Buffer
+	head.Write(paraXML[contentStart:seg.elemStart])
+	writeTextElement(&head, seg.plain[:k])
+	writeTextElement(&tail, seg.plain[k:])
+	tail.Write(paraXML[seg.elemEnd:runClose])
+
+	var buf bytes.Buffer
+	buf.Grow(len(paraXML) + len(markup) + len(openTag) + len(rPr) + 32)
+	buf.Write(paraXML[:runStart])
+	switch {
+	case head.Len() == 0:
+		buf.WriteString(markup)
+		buf.Write(paraXML[runStart:runEnd])
+	case tail.Len() == 0:
+		buf.Write(paraXML[runStart:runEnd])
+		buf.WriteString(markup)
+	default:
+		buf.Write(openTag)
+		buf.Write(rPr)
+		buf.Write(head.Bytes())
+		buf.WriteString("</w:r>")
+		buf.WriteString(markup)
+		buf.Write(openTag)
+		buf.Write(rPr)
+		buf.Write(tail.Bytes())
+		buf.WriteString("</w:r>")
+	}
+	buf.Write(paraXML[runEnd:])
+
+	return buf.Bytes(), nil
+}
+
+// writeTextElement writes text as a single <w:t> element, preserving spaces.
+// Nothing is written for empty text.
+func writeTextElement(buf *bytes.Buffer, text string) {
+	if text == "" {
+		return
+	}
+	buf.WriteString(`<w:t xml:space="preserve">`)
+	buf.WriteString(xmlEscape(text))
+	buf.WriteString("</w:t>")
+}
+
+// rewriteFirstParagraph applies fn to each paragraph at or after byte offset
+// from until fn reports a match, and splices the rewritten paragraph back into
+// the document. ok is false if no paragraph matched.
+func rewriteFirstParagraph(docXML []byte, from int, fn func(para []byte) ([]byte, bool)) (result []byte, ok bool, err error) {
+	searchPos := from
+	for {
+		paraStart := findNextParagraphStart(docXML, searchPos)
+		if paraStart == -1 {
+			return nil, false, nil
+		}
+		paraEndRel := bytes.Index(docXML[paraStart:], []byte("</w:p>"))
+		if paraEndRel == -1 {
+			return nil, false, fmt.Errorf("could not find paragraph end for anchor search")
+		}
+		paraEnd := paraStart + paraEndRel + len("</w:p>")
+
+		if updated, ok := fn(docXML[paraStart:paraEnd]); ok {
+			result = make([]byte, 0, len(docXML)+len(updated)-(paraEnd-paraStart))
+			result = append(result, docXML[:paraStart]...)
+			result = append(result, updated...)
+			result = append(result, docXML[paraEnd:]...)
+			return result, true, nil
+		}
+
+		searchPos = paraEnd
+	}
+}
+
+// insertAtDocumentText finds the first paragraph at or after from whose run
+// text contains text and inserts markup at the requested boundary.
+func insertAtDocumentText(docXML []byte, from int, text string, atEnd bool, markup string) ([]byte, error) {
+	result, ok, err := rewriteFirstParagraph(docXML, from, func(para []byte) ([]byte, bool) {
+		return insertAtTextBoundary(para, text, atEnd, markup)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("anchor text %q not found in document", text)
+	}
+	return result, nil
+}
+
+// wrapDocumentText places before ahead of the first occurrence of text in the
+// document and after directly behind it, splitting runs as needed. When
+// endText is non-empty the range instead ends after the first occurrence of
+// endText that follows the start of text, so it may span several paragraphs.
+func wrapDocumentText(docXML []byte, text, endText, before, after string) ([]byte, error) {
+	if endText == "" {
+		result, ok, err := rewriteFirstParagraph(docXML, 0, func(para []byte) ([]byte, bool) {
+			return wrapTextInParagraph(para, text, before, after)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("anchor text %q not found in document", text)
+		}
+		return result, nil
+	}
+
+	withStart, err := insertAtDocumentText(docXML, 0, text, false, before)
+	if err != nil {
+		return nil, err
+	}
+	startIdx := bytes.Index(withStart, []byte(before))
+
+	// The rest of the start paragraph begins at a run boundary (the start
+	// markup sits between runs), so it can be scanned as its own fragment.
+	pos := startIdx + len(before)
+	if paraEndRel := bytes.Index(withStart[pos:], []byte("</w:p>")); paraEndRel != -1 {
+		paraEnd := pos + paraEndRel + len("</w:p>")
+		if updated, ok := insertAtTextBoundary(withStart[pos:paraEnd], endText, true, after); ok {
+			result := make([]byte, 0, len(withStart)+len(after)+64)
+			result = append(result, withStart[:pos]...)
+			result = append(result, updated...)
+			result = append(result, withStart[paraEnd:]...)
+			return result, nil
+		}
+		pos = paraEnd
+	}
+
+	result, err := insertAtDocumentText(withStart, pos, endText, true, after)
+	if err != nil {
+		return nil, fmt.Errorf("anchor end: %w", err)
+	}
+	return result, nil
+}
+
+// lastWordRunStart returns the index of the last <w:r> or <w:r ...> opening
+// tag before pos, or -1 if none exists.
+func lastWordRunStart(docXML []byte, pos int) int {
+	needle := []byte("<w:r")
+	for end := pos; end > 0; {
+		idx := bytes.LastIndex(docXML[:end], needle)
+		if idx == -1 {
+			return -1
+		}
+		next := idx + len(needle)
+		if next < len(docXML) {
+			ch := docXML[next]
+			if ch == '>' || ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' {
+				return idx
+			}
+		}
+		end = idx
+	}
+	return -1
+}
+
+// enclosingParagraph returns the byte range of the <w:p> element containing pos.
+func enclosingParagraph(docXML []byte, pos int) (start, end int, ok bool) {
+	for searchEnd := pos; searchEnd > 0; {
+		idx := bytes.LastIndex(docXML[:searchEnd], []byte("<w:p"))
+		if idx == -1 {
+			return 0, 0, false
+		}
+		if findNextParagraphStart(docXML, idx) == idx {
+			endRel := bytes.Index(docXML[pos:], []byte("</w:p>"))
+			if endRel == -1 {
+				return 0, 0, false
+			}
+			return idx, pos + endRel + len("</w:p>"), true
+		}
+		searchEnd = idx
+	}
+	return 0, 0, false
+}
+
+// enclosingRun returns the byte range of the <w:r> element containing pos.
+func enclosingRun(docXML []byte, pos int) (start, end int, ok bool) {
+	start = lastWordRunStart(docXML, pos)
+	if start == -1 {
+		return 0, 0, false
+	}
+	endRel := bytes.Index(docXML[pos:], []byte("</w:r>"))
+	if endRel == -1 {
+		return 0, 0, false
+	}
+	return start, pos + endRel + len("</w:r>"), true
+}
+
+// removeReferenceRuns removes every element matched by refPattern. The run
+// holding a reference is dropped when nothing but run properties remains, or
+// when the reference declares w:customMarkFollows, since the run's text is
+// then the reference mark itself.
+func removeReferenceRuns(docXML []byte, refPattern *regexp.Regexp) []byte {
+	for {
+		refLoc := refPattern.FindIndex(docXML)
+		if refLoc == nil {
+			return docXML
+		}
+
+		runStart, runEnd, ok := enclosingRun(docXML, refLoc[0])
+		if !ok {
+			docXML = append(docXML[:refLoc[0]:refLoc[0]], docXML[refLoc[1]:]...)
+			continue
+		}
+
+		// Check whether anything besides run properties remains in the run.
+		rest := make([]byte, 0, runEnd-runStart)
+		rest = append(rest, docXML[runStart:refLoc[0]]...)
+		rest = append(rest, docXML[refLoc[1]:runEnd]...)
+		openEnd := bytes.IndexByte(rest, '>')
+		inner := rest[openEnd+1 : len(rest)-len("</w:r>")]
+		inner = runRprPattern.ReplaceAll(inner, nil)
+
+		markFollows := bytes.Contains(docXML[refLoc[0]:refLoc[1]], []byte(`w:customMarkFollows="1"`))
+		if markFollows || len(bytes.TrimSpace(inner)) == 0 {
+			docXML = append(docXML[:runStart:runStart], docXML[runEnd:]...)
+		} else {
+			docXML = append(docXML[:refLoc[0]:refLoc[0]], docXML[refLoc[1]:]...)
+		}
+	}
+}
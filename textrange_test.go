@@ -0,0 +1,91 @@
+package godocx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInsertAtTextBoundary_SplitsRunAndKeepsFormatting(t *testing.T) {
+	para := []byte(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Hello world</w:t></w:r></w:p>`)
+
+	got, ok := insertAtTextBoundary(para, "world", false, "<X/>")
+	if !ok {
+		t.Fatal("expected text to be found")
+	}
+	want := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Hello </w:t></w:r>` +
+		`<X/><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">world</w:t></w:r></w:p>`
+	if string(got) != want {
+		t.Errorf("unexpected split:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestInsertAtTextBoundary_RunBoundaryDoesNotSplit(t *testing.T) {
+	para := []byte(`<w:p><w:r><w:t>Hello </w:t></w:r><w:r><w:t>world</w:t></w:r></w:p>`)
+
+	got, ok := insertAtTextBoundary(para, "Hello ", true, "<X/>")
+	if !ok {
+		t.Fatal("expected text to be found")
+	}
+	want := `<w:p><w:r><w:t>Hello </w:t></w:r><X/><w:r><w:t>world</w:t></w:r></w:p>`
+	if string(got) != want {
+		t.Errorf("unexpected result:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestWrapTextInParagraph_SpansRuns(t *testing.T) {
+	para := []byte(`<w:p><w:r><w:t>The quick </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>brown fox</w:t></w:r></w:p>`)
+
+	got, ok := wrapTextInParagraph(para, "quick brown", "[", "]")
+	if !ok {
+		t.Fatal("expected text to be found")
+	}
+	s := string(got)
+	if plain := extractParagraphPlainText(got); plain != "The quick brown fox" {
+		t.Errorf("text changed by wrapping: %q", plain)
+	}
+	start, end := strings.Index(s, "["), strings.Index(s, "]")
+	if start == -1 || end == -1 || end < start {
+		t.Fatalf("markers missing or out of order: %s", s)
+	}
+	if inner := extractParagraphPlainText([]byte(s[start:end])); inner != "quick brown" {
+		t.Errorf("expected range to cover %q, got %q", "quick brown", inner)
+	}
+	if !strings.Contains(s, `<w:rPr><w:i/></w:rPr><w:t xml:space="preserve"> fox</w:t>`) {
+		t.Errorf("expected italic formatting kept on split tail: %s", s)
+	}
+}
+
+func TestInsertAtTextBoundary_EscapedText(t *testing.T) {
+	para := []byte(`<w:p><w:r><w:t>R&amp;D budget</w:t></w:r></w:p>`)
+
+	got, ok := insertAtTextBoundary(para, "R&D", true, "<X/>")
+	if !ok {
+		t.Fatal("expected unescaped text to match")
+	}
+	if !strings.Contains(string(got), `R&amp;D</w:t></w:r><X/>`) {
+		t.Errorf("expected split after escaped text, got %s", got)
+	}
+}
+
+func TestWrapDocumentText_AcrossParagraphs(t *testing.T) {
+	doc := []byte(`<w:body><w:p><w:r><w:t>end here</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>start here</w:t></w:r></w:p><w:p><w:r><w:t>then end here</w:t></w:r></w:p></w:body>`)
+
+	got, err := wrapDocumentText(doc, "start", "end here", "[", "]")
+	if err != nil {
+		t.Fatalf("wrapDocumentText: %v", err)
+	}
+	s := string(got)
+	if strings.Index(s, "[") > strings.Index(s, "]") {
+		t.Fatalf("end marker must follow start marker: %s", s)
+	}
+	if !strings.Contains(s, `then end here</w:t></w:r>]`) {
+		t.Errorf("expected range to close in the third paragraph: %s", s)
+	}
+}
+
+func TestWrapDocumentText_NotFound(t *testing.T) {
+	if _, err := wrapDocumentText([]byte(`<w:p><w:r><w:t>abc</w:t></w:r></w:p>`), "xyz", "", "[", "]"); err == nil {
+		t.Error("expected error for missing text")
+	}
+}
@@ -21,6 +21,11 @@ type TOCOptions struct {
 
 	// Anchor text for position-based insertion
 	Anchor string
+
+	// Prebuild fills in the TOC entries from the document's headings instead
+	// of placeholder text, so the TOC reads correctly without Word.
+	// See RebuildTOC.
+	Prebuild bool
 }
 
 // CaptionListOptions defines options for Table of Figures / Table of Tables.
@@ -33,6 +38,10 @@ type CaptionListOptions struct {
 
 	// Anchor text for position-based insertion.
 	Anchor string
+
+	// Prebuild fills in the list entries from the document's captions instead
+	// of placeholder text. See RebuildTOC.
+	Prebuild bool
 }
 
 // DefaultTOCOptions returns default TOC options
@@ -62,7 +71,8 @@ func DefaultTableOfTablesOptions() CaptionListOptions {
 
 // InsertTOC inserts a Table of Contents field into the document.
 // The TOC uses Word field codes and will be populated when the document
-// is opened in Word and the user updates the field (Ctrl+A, F9).
+// is opened in Word and the user updates the field (Ctrl+A, F9), unless
+// opts.Prebuild is set.
 func (u *Updater) InsertTOC(opts TOCOptions) error {
 	if u == nil {
 		return fmt.Errorf("updater is nil")
@@ -84,6 +94,11 @@ func (u *Updater) InsertTOC(opts TOCOptions) error {
 	if err != nil {
 		return fmt.Errorf("insert TOC: %w", err)
 	}
+	if opts.Prebuild {
+		if updated, err = u.prebuildInsertedTOC(raw, updated, opts); err != nil {
+			return fmt.Errorf("prebuild TOC: %w", err)
+		}
+	}
 
 	if err := os.WriteFile(docPath, updated, 0o644); err != nil {
 		return fmt.Errorf("write document.xml: %w", err)
@@ -117,13 +132,19 @@ func (u *Updater) insertCaptionList(opts CaptionListOptions, captionType Caption
 		return fmt.Errorf("read document.xml: %w", err)
 	}
 
-	updated, err := insertTOCAtPosition(raw, listXML, TOCOptions{
+	tocOpts := TOCOptions{
 		Position: opts.Position,
 		Anchor:   opts.Anchor,
-	})
+	}
+	updated, err := insertTOCAtPosition(raw, listXML, tocOpts)
 	if err != nil {
 		return fmt.Errorf("insert caption list: %w", err)
 	}
+	if opts.Prebuild {
+		if updated, err = u.prebuildInsertedTOC(raw, updated, tocOpts); err != nil {
+			return fmt.Errorf("prebuild caption list: %w", err)
+		}
+	}
 
 	if err := os.WriteFile(docPath, updated, 0o644); err != nil {
 		return fmt.Errorf("write document.xml: %w", err)
@@ -246,6 +267,21 @@ func generateTOCTitleXML(title string) []byte {
 
 // insertTOCAtPosition inserts the TOC XML at the specified position
 func insertTOCAtPosition(docXML, tocXML []byte, opts TOCOptions) ([]byte, error) {
+	insertPos, err := tocInsertPosition(docXML, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, len(docXML)+len(tocXML))
+	result = append(result, docXML[:insertPos]...)
+	result = append(result, tocXML...)
+	result = append(result, docXML[insertPos:]...)
+
+	return result, nil
+}
+
+// tocInsertPosition returns the offset at which a TOC is inserted
+func tocInsertPosition(docXML []byte, opts TOCOptions) (int, error) {
 	var insertPos int
 	var err error
 
@@ -253,12 +289,12 @@ func insertTOCAtPosition(docXML, tocXML []byte, opts TOCOptions) ([]byte, error)
 	case PositionBeginning:
 		insertPos, err = findBodyContentStart(docXML)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
 	case PositionEnd:
 		bodyEnd := bytes.Index(docXML, []byte("</w:body>"))
 		if bodyEnd == -1 {
-			return nil, fmt.Errorf("could not find </w:body> tag")
+			return 0, fmt.Errorf("could not find </w:body> tag")
 		}
 		if sectPrPos := bytes.LastIndex(docXML[:bodyEnd], []byte("<w:sectPr")); sectPrPos != -1 {
 			insertPos = sectPrPos
@@ -267,36 +303,41 @@ func insertTOCAtPosition(docXML, tocXML []byte, opts TOCOptions) ([]byte, error)
 		}
 	case PositionAfterText:
 		if opts.Anchor == "" {
-			return nil, fmt.Errorf("anchor text required for PositionAfterText")
+			return 0, fmt.Errorf("anchor text required for PositionAfterText")
 		}
 		_, insertPos, err = findParagraphRangeByAnchor(docXML, opts.Anchor)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
 	case PositionBeforeText:
 		if opts.Anchor == "" {
-			return nil, fmt.Errorf("anchor text required for PositionBeforeText")
+			return 0, fmt.Errorf("anchor text required for PositionBeforeText")
 		}
 		insertPos, _, err = findParagraphRangeByAnchor(docXML, opts.Anchor)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
 	default:
-		return nil, fmt.Errorf("invalid insert position")
+		return 0, fmt.Errorf("invalid insert position")
 	}
 
-	result := make([]byte, 0, len(docXML)+len(tocXML))
-	result = append(result, docXML[:insertPos]...)
-	result = append(result, tocXML...)
-	result = append(result, docXML[insertPos:]...)
+	return insertPos, nil
+}
 
-	return result, nil
+// TOCUpdateOptions defines options for UpdateTOC
+type TOCUpdateOptions struct {
+	// Prebuild also fills in the entries of every TOC, Table of Figures and
+	// Table of Tables from the current headings and captions, as RebuildTOC
+	// does, so the document reads correctly without Word.
+	Prebuild bool
 }
 
 // UpdateTOC marks an existing Table of Contents for update.
 // When the document is opened in Word, it will prompt the user to
 // update the TOC field to reflect the current document headings.
-func (u *Updater) UpdateTOC() error {
+// Pass TOCUpdateOptions{Prebuild: true} to also fill in the entries
+// without Word.
+func (u *Updater) UpdateTOC(opts ...TOCUpdateOptions) error {
 	if u == nil {
 		return fmt.Errorf("updater is nil")
 	}
@@ -307,6 +348,11 @@ func (u *Updater) UpdateTOC() error {
 		return fmt.Errorf("read document.xml: %w", err)
 	}
 
+	if len(opts) > 0 && opts[0].Prebuild {
+		if raw, err = u.prebuildAllTOCs(raw); err != nil {
+			return err
+		}
+	}
 	updated := markTOCForUpdate(raw)
 
 	if err := os.WriteFile(docPath, updated, 0o644); err != nil {
@@ -0,0 +1,414 @@
+package godocx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultTextWidth is the text width of a Letter page with 1" margins, in twips
+const defaultTextWidth = 9360
+
+// outlineLevelPattern matches a paragraph's outline level
+var outlineLevelPattern = regexp.MustCompile(`<w:outlineLvl w:val="(\d)"`)
+
+// tocEntry is a heading or caption listed in a prebuilt TOC
+type tocEntry struct {
+	level    int
+	text     string
+	start    int // paragraph content offsets in the document
+	end      int
+	bookmark string
+}
+
+// RebuildTOC regenerates the entries of every Table of Contents, Table of
+// Figures and Table of Tables in the document from the current headings and
+// captions. Each entry is a TOC1-TOC9 (or "Table of Figures") paragraph with
+// a right-aligned, dot-leader page number, linked to a hidden _Toc bookmark on
+// its heading. Page numbers are PAGEREF fields whose cached results are
+// estimated from explicit page and section breaks, so the TOC reads correctly
+// without a field update.
+func (u *Updater) RebuildTOC() error {
+	if u == nil {
+		return fmt.Errorf("updater is nil")
+	}
+
+	docPath := filepath.Join(u.tempDir, "word", "document.xml")
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return fmt.Errorf("read document.xml: %w", err)
+	}
+
+	if raw, err = u.prebuildAllTOCs(raw); err != nil {
+		return err
+	}
+
+	if err := atomicWriteFile(docPath, raw, 0o644); err != nil {
+		return fmt.Errorf("write document.xml: %w", err)
+	}
+	return nil
+}
+
+// prebuildAllTOCs prebuilds every TOC field of the document and adds the
+// styles its entries use
+func (u *Updater) prebuildAllTOCs(raw []byte) ([]byte, error) {
+	styleNames := u.readStyleNames()
+	var err error
+	for i := range namedFields(raw, "TOC") {
+		if raw, err = prebuildTOC(raw, i, styleNames); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.ensureFieldResultStyles(raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// namedFields returns the complex fields of the document with the given
+// name, such as TOC or INDEX, in document order
+func namedFields(raw []byte, name string) []*docField {
+	var fields []*docField
+	for _, f := range parseDocumentFields(raw) {
+		if !f.simple && parseFieldCode(f.instr.String()).name == name {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// fieldParagraphs returns the offsets of the paragraphs holding the index-th
+// field with the given name, from its begin run to its end run
+func fieldParagraphs(raw []byte, name string, index int) (*docField, int, int, error) {
+	fields := namedFields(raw, name)
+	if index >= len(fields) {
+		return nil, 0, 0, fmt.Errorf("%s field %d not found", name, index+1)
+	}
+	f := fields[index]
+	start := lastWordTagStart(raw, f.start, "p")
+	endRel := bytes.Index(raw[f.resultEnd:], []byte("</w:p>"))
+	if f.start == -1 || start == -1 || endRel == -1 {
+		return nil, 0, 0, fmt.Errorf("malformed %s field %d", name, index+1)
+	}
+	return f, start, f.resultEnd + endRel + len("</w:p>"), nil
+}
+
+// insertedFieldIndex returns the ordinal among the fields with the given
+// name of the field that insertTOCAtPosition placed into original
+func insertedFieldIndex(original []byte, name string, opts TOCOptions) (int, error) {
+	insertPos, err := tocInsertPosition(original, opts)
+	if err != nil {
+		return 0, err
+	}
+	index := 0
+	for _, f := range namedFields(original, name) {
+		if f.start < insertPos {
+			index++
+		}
+	}
+	return index, nil
+}
+
+// prebuildTOC replaces the result of the index-th TOC field with entries
+// built from the document, adding _Toc bookmarks to the listed paragraphs
+func prebuildTOC(raw []byte, index int, styleNames map[string]string) ([]byte, error) {
+	f, start, end, err := fieldParagraphs(raw, "TOC", index)
+	if err != nil {
+		return nil, err
+	}
+	code := parseFieldCode(f.instr.String())
+	entries := collectTOCEntries(raw, start, end, code, styleNames)
+
+	// Bookmark the entries back to front so earlier offsets stay valid.
+	id := nextBookmarkID(raw)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := &entries[i]
+		if e.bookmark = hiddenBookmarkAt(raw, "_Toc", e.start, e.end); e.bookmark == "" {
+			e.bookmark = uniqueHiddenBookmarkName(raw, "_Toc", id)
+			raw = insertBookmarkAround(raw, e.start, e.end, e.bookmark, id)
+			id++
+		}
+	}
+
+	f, start, end, err = fieldParagraphs(raw, "TOC", index)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]int, len(entries))
+	for i, e := range entries {
+		bmStart, _, _, _ := bookmarkRange(raw, e.bookmark)
+		pages[i] = estimatePageNumber(raw, bmStart)
+	}
+
+	toc := generateTOCEntriesXML(f.instr.String(), code, entries, pages, tocTextWidth(raw, start))
+	return spliceBytes(raw, start, end, toc), nil
+}
+
+// collectTOCEntries lists the paragraphs a TOC field includes, skipping the
+// TOC itself at raw[skipStart:skipEnd]. A \c switch lists captions of that
+// type; otherwise headings within the \o levels (and, with \u, paragraphs
+// with those outline levels) are listed.
+func collectTOCEntries(raw []byte, skipStart, skipEnd int, code fieldCode, styleNames map[string]string) []tocEntry {
+	captionType, isCaptionList := code.switches["c"]
+	minLevel, maxLevel := parseTOCLevels(code.switches["o"])
+	_, useOutline := code.switches["u"]
+
+	var entries []tocEntry
+	for _, para := range bodyParagraphs(raw) {
+		if para.start < skipEnd && para.end > skipStart {
+			continue
+		}
+		element := raw[para.start:para.end]
+
+		level := 0
+		if isCaptionList {
+			seq := "SEQ " + captionType
+			for _, instr := range fieldInstructions(element) {
+				if instr == seq || strings.HasPrefix(instr, seq+" ") {
+					level = 1
+					break
+				}
+			}
+		} else {
+			var pPr []byte
+			if idx := bytes.Index(element, []byte("</w:pPr>")); idx != -1 {
+				pPr = element[:idx]
+			}
+			if m := paragraphStylePattern.FindSubmatch(pPr); m != nil {
+				level = styleHeadingLevel(styleNames, xmlUnescape(string(m[1])))
+			}
+			if m := outlineLevelPattern.FindSubmatch(pPr); m != nil && useOutline {
+				level = atoiOrZero(string(m[1])) + 1
+			}
+			if level < minLevel || level > maxLevel {
+				level = 0
+			}
+		}
+		if level == 0 {
+			continue
+		}
+
+		text := strings.TrimSpace(extractVisibleText(element))
+		if text == "" {
+			continue
+		}
+		start, end := paragraphContentRange(raw, para)
+		entries = append(entries, tocEntry{level: level, text: text, start: start, end: end})
+	}
+	return entries
+}
+
+// parseTOCLevels parses an outline level range such as "1-3"; an empty range
+// means all nine levels
+func parseTOCLevels(levels string) (int, int) {
+	from, to, found := strings.Cut(levels, "-")
+	lo, hi := atoiOrZero(strings.TrimSpace(from)), atoiOrZero(strings.TrimSpace(to))
+	if !found {
+		hi = lo
+	}
+	if lo < 1 || hi < lo || hi > 9 {
+		return 1, 9
+	}
+	return lo, hi
+}
+
+// tocTextWidth returns the text width of the section holding pos, where TOC
+// page numbers are right-aligned
+func tocTextWidth(raw []byte, pos int) int {
+	for _, r := range findSectionRanges(raw) {
+		if r.end < pos {
+			continue
+		}
+		layout := parseSectPr(raw[r.start:r.end], nil).PageLayout
+		if width := layout.PageWidth - layout.MarginLeft - layout.MarginRight - layout.MarginGutter; width > 0 {
+			return width
+		}
+		break
+	}
+	return defaultTextWidth
+}
+
+// fieldEndRunXML is the run ending a generated TOC or INDEX field
+const fieldEndRunXML = `<w:r><w:fldChar w:fldCharType="end"/></w:r>`
+
+// writeFieldBeginXML writes the begin, instruction and separate runs of a
+// generated TOC or INDEX field
+func writeFieldBeginXML(buf *bytes.Buffer, instr string) {
+	buf.WriteString(`<w:r><w:fldChar w:fldCharType="begin"/></w:r>`)
+	fmt.Fprintf(buf, `<w:r><w:instrText xml:space="preserve"> %s </w:instrText></w:r>`, xmlEscape(strings.TrimSpace(instr)))
+	buf.WriteString(`<w:r><w:fldChar w:fldCharType="separate"/></w:r>`)
+}
+
+// generateTOCEntriesXML writes the paragraphs of a prebuilt TOC field. The
+// field begins in the first entry and ends in the last one.
+func generateTOCEntriesXML(instr string, code fieldCode, entries []tocEntry, pages []int, width int) []byte {
+	var buf bytes.Buffer
+	_, hyperlinks := code.switches["h"]
+	captionType, isCaptionList := code.switches["c"]
+	noPagesFrom, noPagesTo := 0, -1
+	if levels, ok := code.switches["n"]; ok {
+		noPagesFrom, noPagesTo = parseTOCLevels(levels)
+	}
+
+	if len(entries) == 0 {
+		message := "No table of contents entries found."
+		if isCaptionList {
+			message = "No table of figures entries found."
+		}
+		buf.WriteString("<w:p>")
+		writeFieldBeginXML(&buf, instr)
+		fmt.Fprintf(&buf, "<w:r><w:t>%s</w:t></w:r>", message)
+		buf.WriteString(fieldEndRunXML)
+		buf.WriteString("</w:p>")
+		return buf.Bytes()
+	}
+
+	for i, e := range entries {
+		style := "TOC" + strconv.Itoa(e.level)
+		if isCaptionList && captionType != "" {
+			style = "TableofFigures"
+		}
+		buf.WriteString("<w:p><w:pPr>")
+		fmt.Fprintf(&buf, `<w:pStyle w:val="%s"/>`, style)
+		fmt.Fprintf(&buf, `<w:tabs><w:tab w:val="right" w:leader="dot" w:pos="%d"/></w:tabs>`, width)
+		if e.level > 1 {
+			fmt.Fprintf(&buf, `<w:ind w:left="%d"/>`, (e.level-1)*220)
+		}
+		buf.WriteString("</w:pPr>")
+
+		if i == 0 {
+			writeFieldBeginXML(&buf, instr)
+		}
+		if hyperlinks {
+			fmt.Fprintf(&buf, `<w:hyperlink w:anchor="%s" w:history="1">`, xmlEscape(e.bookmark))
+		}
+		buf.WriteString("<w:r>")
+		writeRunTextWithControls(&buf, e.text)
+		buf.WriteString("</w:r>")
+		if e.level < noPagesFrom || e.level > noPagesTo {
+			buf.WriteString("<w:r><w:tab/></w:r>")
+			writeFieldXML(&buf, Field{
+				Instruction: "PAGEREF " + e.bookmark,
+				Switches:    []string{`\h`},
+				Result:      strconv.Itoa(pages[i]),
+			}, RunOptions{})
+		}
+		if hyperlinks {
+			buf.WriteString("</w:hyperlink>")
+		}
+		if i == len(entries)-1 {
+			buf.WriteString(fieldEndRunXML)
+		}
+		buf.WriteString("</w:p>")
+	}
+	return buf.Bytes()
+}
+
+// prebuildInsertedTOC builds the entries of the TOC field that
+// insertTOCAtPosition placed into original, giving updated
+func (u *Updater) prebuildInsertedTOC(original, updated []byte, opts TOCOptions) ([]byte, error) {
+	index, err := insertedFieldIndex(original, "TOC", opts)
+	if err != nil {
+		return nil, err
+	}
+	if updated, err = prebuildTOC(updated, index, u.readStyleNames()); err != nil {
+		return nil, err
+	}
+	if err := u.ensureFieldResultStyles(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// fieldResultStylePattern matches the built-in paragraph styles used by
+// prebuilt TOC, INDEX and BIBLIOGRAPHY entries and the bibliography title
+var fieldResultStylePattern = regexp.MustCompile(`<w:pStyle w:val="(TOC[1-9]|TableofFigures|Index[1-9]|IndexHeading|Bibliography|Heading1)"/>`)
+
+// ensureFieldResultStyles adds the built-in styles of prebuilt field results
+// in doc to styles.xml when the document does not define them, so entries
+// keep their indents and spacing instead of falling back to Normal
+func (u *Updater) ensureFieldResultStyles(doc []byte) error {
+	stylesPath := filepath.Join(u.tempDir, "word", "styles.xml")
+	raw, err := os.ReadFile(stylesPath)
+	wasAbsent := os.IsNotExist(err)
+	if err != nil && !wasAbsent {
+		return fmt.Errorf("read styles.xml: %w", err)
+	}
+
+	var missing []byte
+	seen := make(map[string]bool)
+	for _, m := range fieldResultStylePattern.FindAllSubmatch(doc, -1) {
+		id := string(m[1])
+		if seen[id] || strings.Contains(string(raw), `w:styleId="`+id+`"`) {
+			continue
+		}
+		seen[id] = true
+		missing = append(missing, fieldResultStyleXML(id)...)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var updated []byte
+	if wasAbsent {
+		updated = generateStylesDocument(missing)
+	} else {
+		updated, err = injectStyle(raw, missing)
+		if err != nil {
+			return fmt.Errorf("inject field result styles: %w", err)
+		}
+	}
+
+	if err := atomicWriteFile(stylesPath, updated, 0o644); err != nil {
+		return fmt.Errorf("write styles.xml: %w", err)
+	}
+	if wasAbsent {
+		if err := u.ensureStylesRelationship(); err != nil {
+			return fmt.Errorf("ensure styles relationship: %w", err)
+		}
+	}
+	return nil
+}
+
+// fieldResultStyleXML returns Word's definition of a built-in field result
+// style, such as TOC2 ("toc 2"), Index1 ("index 1") or Bibliography
+func fieldResultStyleXML(id string) string {
+	name, next, props, priority, visibility := "table of figures", "Normal", "", 99, `<w:unhideWhenUsed/>`
+	switch {
+	case id == "Bibliography":
+		name, priority = "Bibliography", 37
+	case id == "Heading1":
+		name, priority, visibility = "heading 1", 9, `<w:qFormat/>`
+		props = `<w:pPr><w:keepNext/><w:keepLines/><w:spacing w:before="240" w:after="0"/><w:outlineLvl w:val="0"/></w:pPr>` +
+			`<w:rPr><w:b/><w:bCs/><w:sz w:val="32"/><w:szCs w:val="32"/></w:rPr>`
+	case id == "IndexHeading":
+		name, next, props = "index heading", "Index1", `<w:rPr><w:b/><w:bCs/></w:rPr>`
+	case strings.HasPrefix(id, "Index"):
+		level := atoiOrZero(id[5:])
+		name = "index " + id[5:]
+		props = fmt.Sprintf(`<w:pPr><w:ind w:left="%d" w:hanging="220"/></w:pPr>`, 220*level)
+	case strings.HasPrefix(id, "TOC"):
+		level := atoiOrZero(id[3:])
+		name, priority = "toc "+id[3:], 39
+		props = `<w:pPr><w:spacing w:after="100"/>`
+		if level > 1 {
+			props += fmt.Sprintf(`<w:ind w:left="%d"/>`, (level-1)*220)
+		}
+		props += `</w:pPr>`
+	}
+	return fmt.Sprintf(
+		`<w:style w:type="paragraph" w:styleId="%s">`+
+			`<w:name w:val="%s"/>`+
+			`<w:basedOn w:val="Normal"/>`+
+			`<w:next w:val="%s"/>`+
+			`<w:uiPriority w:val="%d"/>`+
+			`%s`+
+			`%s`+
+			`</w:style>`,
+		id, name, next, priority, visibility, props,
+	)
+}
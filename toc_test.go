@@ -292,3 +292,184 @@ func TestEnsureVMLNamespaces_AlreadyPresent(t *testing.T) {
 		t.Errorf("expected 1 xmlns:v declaration, got %d", count)
 	}
 }
+
+func TestInsertTOC_Prebuild(t *testing.T) {
+	body := `<w:p><w:r><w:t>Cover</w:t></w:r></w:p>` +
+		crossRefFixtureBody() +
+		`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Breakdown</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading4"/></w:pPr><w:r><w:t>Too deep</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+
+	opts := DefaultTOCOptions()
+	opts.Position = PositionAfterText
+	opts.Anchor = "Cover"
+	opts.Prebuild = true
+	if err := u.InsertTOC(opts); err != nil {
+		t.Fatalf("InsertTOC: %v", err)
+	}
+
+	entries, err := u.GetTOCEntries()
+	if err != nil {
+		t.Fatalf("GetTOCEntries: %v", err)
+	}
+	want := []TOCEntry{{Level: 1, Text: "Results 1"}, {Level: 2, Text: "Breakdown 3"}}
+	if len(entries) != len(want) {
+		t.Fatalf("got entries %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if entries[i].Level != want[i].Level || strings.Join(strings.Fields(entries[i].Text), " ") != want[i].Text {
+			t.Errorf("entry %d: got %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	doc := readDocXML(t, u)
+	for _, s := range []string{
+		`<w:pStyle w:val="TOC2"/><w:tabs><w:tab w:val="right" w:leader="dot" w:pos="9360"/></w:tabs><w:ind w:left="220"/>`,
+		`<w:hyperlink w:anchor="_Toc`,
+		`<w:instrText xml:space="preserve"> PAGEREF _Toc`,
+		`<w:bookmarkStart w:id="2" w:name="_Toc`,
+	} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("expected %s in document, got %s", s, doc)
+		}
+	}
+	if strings.Contains(doc, "Update this field") {
+		t.Errorf("prebuilt TOC must not keep the placeholder")
+	}
+
+	// Rebuilding reuses the bookmarks and replaces the entries.
+	if err := u.RebuildTOC(); err != nil {
+		t.Fatalf("RebuildTOC: %v", err)
+	}
+	rebuilt := readDocXML(t, u)
+	if got := strings.Count(rebuilt, `w:name="_Toc`); got != 2 {
+		t.Errorf("expected 2 _Toc bookmarks after rebuilding, got %d", got)
+	}
+	if entries, err := u.GetTOCEntries(); err != nil || len(entries) != 2 {
+		t.Errorf("expected 2 TOC entries after rebuilding, got %+v (%v)", entries, err)
+	}
+}
+
+func TestUpdateTOC_Prebuild(t *testing.T) {
+	body := `<w:p><w:r><w:t>Cover</w:t></w:r></w:p>` + crossRefFixtureBody() +
+		`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Breakdown</w:t></w:r></w:p>`
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, body))
+	opts := DefaultTOCOptions()
+	opts.Position = PositionAfterText
+	opts.Anchor = "Cover"
+	if err := u.InsertTOC(opts); err != nil {
+		t.Fatalf("InsertTOC: %v", err)
+	}
+
+	// Without Prebuild the placeholder stays
+	if err := u.UpdateTOC(); err != nil {
+		t.Fatalf("UpdateTOC: %v", err)
+	}
+	if doc := readDocXML(t, u); !strings.Contains(doc, "Update this field") {
+		t.Errorf("expected the placeholder to be kept, got %s", doc)
+	}
+
+	if err := u.UpdateTOC(TOCUpdateOptions{Prebuild: true}); err != nil {
+		t.Fatalf("UpdateTOC: %v", err)
+	}
+	doc := readDocXML(t, u)
+	if strings.Contains(doc, "Update this field") {
+		t.Errorf("prebuilt TOC must not keep the placeholder")
+	}
+	if got := strings.Count(doc, `w:dirty="true"`); got != 1 {
+		t.Errorf("expected the TOC to stay marked for update once, got %d", got)
+	}
+	entries, err := u.GetTOCEntries()
+	if err != nil || len(entries) != 2 || entries[1].Level != 2 {
+		t.Errorf("expected 2 prebuilt TOC entries, got %+v (%v)", entries, err)
+	}
+	if styles := readWordPart(t, u, "styles.xml"); !strings.Contains(styles, `w:styleId="TOC2"`) {
+		t.Errorf("expected the TOC styles to be added, got %s", styles)
+	}
+}
+
+func TestInsertTableOfFigures_Prebuild(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, crossRefFixtureBody()))
+	// Number the captions first; the list copies their cached text.
+	if err := u.UpdateFields(); err != nil {
+		t.Fatalf("UpdateFields: %v", err)
+	}
+
+	opts := DefaultTableOfFiguresOptions()
+	opts.Prebuild = true
+	if err := u.InsertTableOfFigures(opts); err != nil {
+		t.Fatalf("InsertTableOfFigures: %v", err)
+	}
+	tables := DefaultTableOfTablesOptions()
+	tables.Position = PositionEnd
+	tables.Prebuild = true
+	if err := u.InsertTableOfTables(tables); err != nil {
+		t.Fatalf("InsertTableOfTables: %v", err)
+	}
+
+	texts := bodyParagraphTexts(t, u)
+	want := []string{"Table of Figures", "Figure 1: Sales by region2", "Figure 2: Costs3"}
+	if strings.Join(texts[:3], "|") != strings.Join(want, "|") {
+		t.Errorf("got paragraphs %q, want %q", texts[:3], want)
+	}
+	if last := texts[len(texts)-1]; last != "No table of figures entries found." {
+		t.Errorf("expected an empty table of tables, got %q", last)
+	}
+	if doc := readDocXML(t, u); strings.Count(doc, `<w:pStyle w:val="TableofFigures"/>`) != 2 {
+		t.Errorf("expected 2 Table of Figures entries, got %s", doc)
+	}
+}
+
+func TestInsertTOC_PrebuildAddsMissingStyles(t *testing.T) {
+	body := `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Intro</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Scope</w:t></w:r></w:p>`
+	docXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<w:body>` + body + `</w:body></w:document>`
+	stylesXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="TOC1"><w:name w:val="toc 1"/></w:style>` +
+		`</w:styles>`
+	u := newUpdaterFromFixture(t, buildIntegrationDocxFromParts(t, docXML, stylesXML, ""))
+
+	opts := DefaultTOCOptions()
+	opts.Prebuild = true
+	if err := u.InsertTOC(opts); err != nil {
+		t.Fatalf("InsertTOC: %v", err)
+	}
+
+	styles := readWordPart(t, u, "styles.xml")
+	if got := strings.Count(styles, `w:styleId="TOC1"`); got != 1 {
+		t.Errorf("expected the existing TOC1 style to be kept once, got %d", got)
+	}
+	if !strings.Contains(styles, `<w:style w:type="paragraph" w:styleId="TOC2"><w:name w:val="toc 2"/>`) ||
+		!strings.Contains(styles, `<w:ind w:left="220"/>`) {
+		t.Errorf("expected a TOC2 style to be added, got %s", styles)
+	}
+	if strings.Contains(styles, `w:styleId="TOC3"`) {
+		t.Errorf("expected only the styles the entries use, got %s", styles)
+	}
+	checkWellFormedXML(t, styles)
+}
+
+func TestInsertTableOfFigures_PrebuildCreatesStyles(t *testing.T) {
+	u := newUpdaterFromFixture(t, buildIntegrationFixture(t, crossRefFixtureBody()))
+
+	opts := DefaultTableOfFiguresOptions()
+	opts.Prebuild = true
+	if err := u.InsertTableOfFigures(opts); err != nil {
+		t.Fatalf("InsertTableOfFigures: %v", err)
+	}
+
+	styles := readWordPart(t, u, "styles.xml")
+	if !strings.Contains(styles, `w:styleId="TableofFigures"><w:name w:val="table of figures"/>`) {
+		t.Errorf("expected a Table of Figures style, got %s", styles)
+	}
+	rels := readWordPart(t, u, "_rels/document.xml.rels")
+	if !strings.Contains(rels, `Target="styles.xml"`) {
+		t.Errorf("expected a styles relationship, got %s", rels)
+	}
+}
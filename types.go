@@ -15,6 +15,8 @@ type SeriesData struct {
 	Name   string
 	Values []float64
 	Color  string // Hex color code (e.g., "FF0000" for red) - optional
+	// Bubble sizes, one per category - required for bubble charts
+	BubbleSizes []float64
 }
 
 // ImageOptions defines options for image insertion
@@ -143,6 +145,18 @@ type PageLayoutOptions struct {
 	MarginHeader int // Header distance from edge
 	MarginFooter int // Footer distance from edge
 	MarginGutter int // Gutter margin for binding
+
+	// Columns sets the text columns of the section (nil for a single column,
+	// or to keep the current columns when updating an existing section)
+	Columns *SectionColumns
+
+	// Borders draws a border around the pages of the section (nil for none,
+	// or to keep the current borders when updating an existing section)
+	Borders *PageBorders
+
+	// VerticalAlign aligns text vertically between the top and bottom
+	// margins, e.g. centered cover pages (empty for Word's default, top)
+	VerticalAlign PageVerticalAlignment
 }
 
 // Page size constants in twips (1/1440 inch)
@@ -196,7 +196,7 @@ func (u *Updater) createWatermarkHeader(watermarkXML []byte) error {
 	}
 
 	// Update document.xml sectPr with header reference
-	if err := u.updateDocumentForHeaderFooter("default", "header", relID, false, false); err != nil {
+	if err := u.updateDocumentForHeaderFooter(0, "default", "header", relID, false, false); err != nil {
 		return fmt.Errorf("update document: %w", err)
 	}
 